@@ -0,0 +1,55 @@
+package copper
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+// checkAPIKeyLocations checks that the keys of the apiKey security schemes of the operation are only sent where the
+// schemes document them. A key in the wrong place often works anyway since servers tend to look in several places, but
+// it violates the contract, and a key in the query ends up in logs. Since a key in the wrong place does not satisfy the
+// scheme, the validator library reports it as missing without saying where it was found, or not at all if another
+// scheme is satisfied or the security is optional.
+func checkAPIKeyLocations(req *http.Request, doc *v3.Document, op *v3.Operation) error {
+	if op == nil || doc.Components == nil {
+		return nil
+	}
+
+	var errs []error
+	seen := make(map[string]bool)
+	for _, requirement := range operationSecurity(doc, op) {
+		for name := range requirement.Requirements.FromOldest() {
+			scheme := doc.Components.SecuritySchemes.GetOrZero(name)
+			if seen[name] || scheme == nil || !strings.EqualFold(scheme.Type, "apiKey") {
+				continue
+			}
+			seen[name] = true
+
+			for _, in := range []string{"header", "query", "cookie"} {
+				if in != scheme.In && hasAPIKey(req, in, scheme.Name) {
+					errs = append(errs, fmt.Errorf("API key %s of security scheme %s is sent in the %s, but is documented to be sent in the %s",
+						scheme.Name, name, in, scheme.In))
+				}
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// hasAPIKey returns true if the request has a key with the given name in the location.
+func hasAPIKey(req *http.Request, in, name string) bool {
+	switch in {
+	case "header":
+		return req.Header.Get(name) != ""
+	case "query":
+		return req.URL.Query().Has(name)
+	case "cookie":
+		_, err := req.Cookie(name)
+		return err == nil
+	}
+	return false
+}
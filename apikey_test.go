@@ -0,0 +1,55 @@
+package copper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyLocations(t *testing.T) {
+	f, err := os.ReadFile("testdata/apikey-spec.yaml")
+	require.NoError(t, err)
+
+	tt := []struct {
+		name   string
+		target string
+		header string
+		cookie string
+		err    string
+	}{
+		{"in the header", "/things", "X-API-Key", "", ""},
+		{"in the query", "/things?X-API-Key=secret", "", "", "API key X-API-Key of security scheme HeaderKey is sent in the query, but is documented to be sent in the header"},
+		{"in a cookie", "/things", "", "X-API-Key", "is sent in the cookie"},
+		{"in the header and the query", "/things?X-API-Key=secret", "X-API-Key", "", "is sent in the query"},
+		{"optional in the query", "/optional?key=secret", "", "", ""},
+		{"optional left out", "/optional", "", "", ""},
+		{"optional in the header", "/optional", "key", "", "API key key of security scheme QueryKey is sent in the header, but is documented to be sent in the query"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			v, err := NewVerifier(f, WithRequestValidation(), WithoutFullCoverage())
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, tc.target, nil)
+			if tc.header != "" {
+				req.Header.Set(tc.header, "secret")
+			}
+			if tc.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: tc.cookie, Value: "secret"})
+			}
+			v.Record(&http.Response{StatusCode: http.StatusNoContent, Request: req})
+
+			if tc.err == "" {
+				assert.NoError(t, v.CurrentError())
+			} else {
+				assert.ErrorIs(t, v.CurrentError(), ErrSecurityViolation)
+				assert.ErrorContains(t, v.CurrentError(), tc.err)
+			}
+		})
+	}
+}
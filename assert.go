@@ -0,0 +1,87 @@
+package copper
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// AssertNoErrors fails the test if anything recorded so far has been found to break the spec. Unlike Verify, the
+// coverage is not checked, so it can be used in the middle of a test, before everything has been recorded. It returns
+// true if there were no errors.
+func AssertNoErrors(t testing.TB, v *Verifier) bool {
+	t.Helper()
+
+	v.mu.Lock()
+	errs := slices.Clone(v.errors)
+	v.mu.Unlock()
+
+	if len(errs) == 0 {
+		return true
+	}
+	lines := make([]string, 0, len(errs))
+	for _, err := range errs {
+		lines = append(lines, "  "+err.Error())
+	}
+	t.Errorf("copper: %d %s found in the recorded traffic:\n%s", len(errs), plural(len(errs), "error"), strings.Join(lines, "\n"))
+	return false
+}
+
+// AssertCovered fails the test if the response code of the method and path in the spec has not been checked yet. The
+// path is the one of the spec, like /things/{id}. It returns true if the coordinate has been checked.
+func AssertCovered(t testing.TB, v *Verifier, method, path string, responseCode int) bool {
+	t.Helper()
+
+	method = strings.ToUpper(method)
+	code := strconv.Itoa(responseCode)
+
+	v.mu.Lock()
+	has, checked := v.endpoints.Has(path, method, code), v.endpoints.IsChecked(path, method, code)
+	v.mu.Unlock()
+
+	switch {
+	case !has:
+		t.Errorf("copper: %s %s %s is not part of the spec", method, path, code)
+		return false
+	case !checked:
+		t.Errorf("copper: %s %s %s has not been checked", method, path, code)
+		return false
+	}
+	return true
+}
+
+// AssertFullCoverage fails the test if any coordinate of the spec has not been checked yet, listing the ones that are
+// missing. Unlike Verify, it checks the coverage even if full coverage is not required, and does not fail for other
+// errors. It returns true if everything has been checked.
+func AssertFullCoverage(t testing.TB, v *Verifier) bool {
+	t.Helper()
+
+	byPath := v.UncheckedByPath()
+	if len(byPath) == 0 {
+		return true
+	}
+	t.Errorf("copper: %v", &CoverageError{Missing: byPath})
+	return false
+}
+
+// AssertNoErrorsOf fails the test if anything recorded so far has been found to break the spec in the way of the
+// sentinel, like ErrSecurityViolation, while other errors are left for Verify. It returns true if there were none.
+func AssertNoErrorsOf(t testing.TB, v *Verifier, sentinel SentinelError) bool {
+	t.Helper()
+
+	v.mu.Lock()
+	var lines []string
+	for _, err := range v.errors {
+		if verr, ok := err.(*VerificationError); ok && verr.sentinel == sentinel {
+			lines = append(lines, "  "+err.Error())
+		}
+	}
+	v.mu.Unlock()
+
+	if len(lines) == 0 {
+		return true
+	}
+	t.Errorf("copper: %d %s of %q found in the recorded traffic:\n%s", len(lines), plural(len(lines), "error"), sentinel, strings.Join(lines, "\n"))
+	return false
+}
@@ -0,0 +1,69 @@
+package copper
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingT records the failures of the assertions, instead of failing the test.
+type recordingT struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recordingT) Helper() {}
+
+func (r *recordingT) Errorf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func TestAssertions(t *testing.T) {
+	f, err := os.ReadFile("testdata/thing-spec.yaml")
+	require.NoError(t, err)
+
+	v, err := NewVerifier(f, WithoutFullCoverage())
+	require.NoError(t, err)
+
+	record := func(path, body string) {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		v.Record(&http.Response{
+			StatusCode: http.StatusOK,
+			Request:    req,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+		})
+	}
+
+	rt := &recordingT{TB: t}
+	assert.True(t, AssertNoErrors(rt, v))
+	assert.False(t, AssertCovered(rt, v, http.MethodGet, "/ping", 200))
+	assert.False(t, AssertCovered(rt, v, http.MethodGet, "/ping", 201))
+	assert.False(t, AssertFullCoverage(rt, v))
+	assert.Equal(t, []string{
+		"copper: GET /ping 200 has not been checked",
+		"copper: GET /ping 201 is not part of the spec",
+		"copper: 2 coordinates on 2 paths\n  /other: GET 200\n  /ping: GET 200",
+	}, rt.failures)
+
+	record("/ping", `{"message": "pong"}`)
+	record("/other", `{"thing": "thing"}`)
+	record("/missing", `{}`)
+
+	rt = &recordingT{TB: t}
+	assert.True(t, AssertCovered(rt, v, "get", "/ping", 200))
+	assert.True(t, AssertFullCoverage(rt, v))
+	assert.True(t, AssertNoErrorsOf(rt, v, ErrResponseInvalid))
+	assert.False(t, AssertNoErrorsOf(rt, v, ErrNotPartOfSpec))
+	assert.False(t, AssertNoErrors(rt, v))
+	require.Len(t, rt.failures, 2)
+	assert.True(t, strings.HasPrefix(rt.failures[0], `copper: 1 error of "not part of spec" found in the recorded traffic:`+"\n  not part of spec: GET /missing"), rt.failures[0])
+	assert.True(t, strings.HasPrefix(rt.failures[1], "copper: 1 error found in the recorded traffic:\n  not part of spec: GET /missing"), rt.failures[1])
+}
@@ -0,0 +1,77 @@
+package copper
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+// WithAuthErrorCoverage is a functional Option for contract testing the error paths of authentication, rather than
+// only counting their status codes. Every operation that requires credentials must have had a 401 or 403 response
+// recorded, and requests without the credentials of the operation must have been rejected with 401, so a 403 or a
+// successful response to them is reported. Requests with credentials may get a 401 response, since the credentials can
+// be invalid in ways that the spec does not describe. The failures are reported with ErrSecurityViolation.
+func WithAuthErrorCoverage() Option {
+	return func(c *config) {
+		c.authErrorCoverage = true
+	}
+}
+
+// isAuthError returns true for the status codes of responses to requests that were rejected for their credentials.
+func isAuthError(status int) bool {
+	return status == http.StatusUnauthorized || status == http.StatusForbidden
+}
+
+// checkAuthResponse checks that a request without the credentials of the operation was rejected with 401.
+func checkAuthResponse(req *http.Request, status int, doc *v3.Document, op *v3.Operation) error {
+	if status == http.StatusUnauthorized {
+		return nil
+	}
+	if err := checkCredentials(req, doc, op); err != nil {
+		return fmt.Errorf("%d response, but %w, so 401 is expected", status, err)
+	}
+	return nil
+}
+
+// securedOperation returns true if the operation requires credentials, which it does not if it has no security
+// requirements, or an empty one that makes them optional.
+func securedOperation(doc *v3.Document, op *v3.Operation) bool {
+	security := operationSecurity(doc, op)
+	return len(security) > 0 && !slices.ContainsFunc(security, func(r *base.SecurityRequirement) bool {
+		return r.Requirements.Len() == 0
+	})
+}
+
+// uncoveredAuthErrors returns an error for each operation that requires credentials, but that no 401 or 403 response
+// has been recorded for, sorted by path and method.
+func (v *Verifier) uncoveredAuthErrors() []error {
+	ends := v.endpoints.All()
+	sortEndpoints(ends)
+
+	var errs []error
+	for i, e := range ends {
+		if i > 0 && ends[i-1].Path == e.Path && ends[i-1].Method == e.Method {
+			continue
+		}
+		op := v.endpoints.paths[e.Path].methods[e.Method].operation
+		if !securedOperation(v.model, op) ||
+			v.endpoints.IsChecked(e.Path, e.Method, "401") || v.endpoints.IsChecked(e.Path, e.Method, "403") {
+			continue
+		}
+
+		var err error
+		if r, _ := documentedResponse(op, http.StatusUnauthorized); r == nil {
+			if r, _ := documentedResponse(op, http.StatusForbidden); r == nil {
+				err = fmt.Errorf("%s %s: the operation requires credentials, but documents no 401 or 403 response", e.Method, e.Path)
+			}
+		}
+		if err == nil {
+			err = fmt.Errorf("%s %s: no 401 or 403 response has been recorded for the operation, which requires credentials", e.Method, e.Path)
+		}
+		errs = append(errs, v.conf.withTemplate(joinError(ErrSecurityViolation, err)))
+	}
+	return errs
+}
@@ -0,0 +1,132 @@
+package copper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithAuthErrorCoverage(t *testing.T) {
+	f, err := os.ReadFile("testdata/auth-errors-spec.yaml")
+	require.NoError(t, err)
+
+	type exchange struct {
+		target string
+		token  string
+		status int
+	}
+	tt := []struct {
+		name      string
+		opts      []Option
+		exchanges []exchange
+		errs      []string
+	}{
+		{
+			name: "rejected without credentials",
+			exchanges: []exchange{
+				{"/things", "", http.StatusUnauthorized},
+				{"/things", "abc", http.StatusOK},
+				{"/public", "", http.StatusOK},
+				{"/optional", "", http.StatusOK},
+			},
+			errs: []string{
+				"security violation: GET /legacy: the operation requires credentials, but documents no 401 or 403 response",
+			},
+		},
+		{
+			name: "forbidden with credentials",
+			exchanges: []exchange{
+				{"/things", "abc", http.StatusForbidden},
+			},
+			errs: []string{
+				"security violation: GET /legacy: the operation requires credentials, but documents no 401 or 403 response",
+			},
+		},
+		{
+			name: "invalid credentials",
+			exchanges: []exchange{
+				{"/things", "expired", http.StatusUnauthorized},
+			},
+			errs: []string{
+				"security violation: GET /legacy: the operation requires credentials, but documents no 401 or 403 response",
+			},
+		},
+		{
+			name: "never rejected",
+			exchanges: []exchange{
+				{"/things", "abc", http.StatusOK},
+			},
+			errs: []string{
+				"security violation: GET /legacy: the operation requires credentials, but documents no 401 or 403 response",
+				"security violation: GET /things: no 401 or 403 response has been recorded for the operation, which requires credentials",
+			},
+		},
+		{
+			name: "forbidden without credentials",
+			exchanges: []exchange{
+				{"/things", "", http.StatusForbidden},
+			},
+			errs: []string{
+				"security violation: GET /things: 403 response, but credentials are missing for the security schemes Bearer, so 401 is expected",
+				"security violation: GET /legacy: the operation requires credentials, but documents no 401 or 403 response",
+			},
+		},
+		{
+			name: "accepted without credentials",
+			exchanges: []exchange{
+				{"/things", "", http.StatusUnauthorized},
+				{"/things", "", http.StatusOK},
+			},
+			errs: []string{
+				"security violation: GET /things: 200 response, but credentials are missing for the security schemes Bearer, so 401 is expected",
+				"security violation: GET /legacy: the operation requires credentials, but documents no 401 or 403 response",
+			},
+		},
+		{
+			name: "with security validation",
+			opts: []Option{WithSecurityValidation()},
+			exchanges: []exchange{
+				{"/things", "", http.StatusUnauthorized},
+				{"/things", "", http.StatusOK},
+			},
+			errs: []string{
+				"security violation: GET /things: 200 response, but credentials are missing for the security schemes Bearer, so 401 is expected",
+				"security violation: GET /legacy: the operation requires credentials, but documents no 401 or 403 response",
+			},
+		},
+		{
+			name: "filtered",
+			opts: []Option{WithExcludePaths("/legacy")},
+			exchanges: []exchange{
+				{"/things", "", http.StatusUnauthorized},
+			},
+			errs: []string{},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			v, err := NewVerifier(f, append(tc.opts, WithAuthErrorCoverage(), WithoutFullCoverage())...)
+			require.NoError(t, err)
+
+			for _, e := range tc.exchanges {
+				req := httptest.NewRequest(http.MethodGet, e.target, nil)
+				if e.token != "" {
+					req.Header.Set("Authorization", "Bearer "+e.token)
+				}
+				v.Record(&http.Response{StatusCode: e.status, Request: req})
+			}
+
+			errs := make([]string, 0, len(tc.errs))
+			for _, err := range v.CurrentErrors() {
+				assert.ErrorIs(t, err, ErrSecurityViolation)
+				errs = append(errs, err.Error())
+			}
+			assert.Equal(t, tc.errs, errs)
+		})
+	}
+}
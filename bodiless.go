@@ -0,0 +1,88 @@
+package copper
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// isBodiless returns true for responses that must not have a body: responses to HEAD requests, informational (1xx)
+// responses, 204 No Content, 205 Reset Content and 304 Not Modified.
+func isBodiless(req *http.Request, statusCode int) bool {
+	return req.Method == http.MethodHead ||
+		(statusCode >= 100 && statusCode < 200) ||
+		statusCode == http.StatusNoContent ||
+		statusCode == http.StatusResetContent ||
+		statusCode == http.StatusNotModified
+}
+
+// checkBodiless checks that a bodiless response is empty, and that a 304 is only returned for a conditional request
+// since nothing else can be "not modified".
+func checkBodiless(req *http.Request, statusCode int, body []byte) error {
+	var errs []error
+	if len(body) > 0 {
+		errs = append(errs, fmt.Errorf("%d response to %s must not have a body, but it has %d bytes",
+			statusCode, req.Method, len(body)))
+	}
+
+	if statusCode == http.StatusNotModified && !isConditional(req) {
+		errs = append(errs, errors.New("304 response to a request without If-None-Match or If-Modified-Since"))
+	}
+	return errors.Join(errs...)
+}
+
+func isConditional(req *http.Request) bool {
+	return req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != ""
+}
+
+// checkContentLength checks that the Content-Length header of the response agrees with the size of the body that was
+// read. Clients trust the header to know where the body ends, so a wrong one truncates the body or hangs the
+// connection, which a recorder or a router adapter does not notice. The header is not allowed at all on 1xx and 204
+// responses, nor together with Transfer-Encoding. Responses to HEAD requests and 304 responses carry the length that
+// the body would have had, so it can not be compared with their empty bodies.
+func checkContentLength(req *http.Request, res *http.Response, size int64) error {
+	values := res.Header.Values("Content-Length")
+	if len(values) == 0 {
+		return nil
+	}
+
+	if res.StatusCode < 200 || res.StatusCode == http.StatusNoContent {
+		return fmt.Errorf("%d response must not have a Content-Length header", res.StatusCode)
+	}
+	if len(res.TransferEncoding) > 0 {
+		return errors.New("response must not have both a Content-Length and a Transfer-Encoding header")
+	}
+
+	declared, err := parseContentLength(values)
+	if err != nil {
+		return err
+	}
+	if req.Method == http.MethodHead || res.StatusCode == http.StatusNotModified {
+		return nil
+	}
+	if declared != size {
+		return fmt.Errorf("Content-Length header says %d bytes, but the body has %d bytes", declared, size)
+	}
+	return nil
+}
+
+// parseContentLength parses the values of a Content-Length header, which may be repeated, or be a list, as long as all
+// the values are the same.
+func parseContentLength(values []string) (int64, error) {
+	var declared int64 = -1
+	for _, v := range values {
+		for _, s := range strings.Split(v, ",") {
+			n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("Content-Length header %q is not a number of bytes", v)
+			}
+			if declared >= 0 && n != declared {
+				return 0, fmt.Errorf("Content-Length header has different values: %s", strings.Join(values, ", "))
+			}
+			declared = n
+		}
+	}
+	return declared, nil
+}
@@ -0,0 +1,152 @@
+package copper
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBodilessResponses(t *testing.T) {
+	f, err := os.ReadFile("testdata/conditional-spec.yaml")
+	require.NoError(t, err)
+
+	tt := []struct {
+		name        string
+		method      string
+		ifNoneMatch string
+		statusCode  int
+		body        string
+		valid       bool
+	}{
+		{"empty 204", http.MethodDelete, "", http.StatusNoContent, "", true},
+		{"204 with body", http.MethodDelete, "", http.StatusNoContent, `{"name":"thing"}`, false},
+		{"empty 304 for conditional request", http.MethodGet, `"abc"`, http.StatusNotModified, "", true},
+		{"304 with body", http.MethodGet, `"abc"`, http.StatusNotModified, `{"name":"thing"}`, false},
+		{"304 for unconditional request", http.MethodGet, "", http.StatusNotModified, "", false},
+		{"empty HEAD with documented content", http.MethodHead, "", http.StatusOK, "", true},
+		{"HEAD with body", http.MethodHead, "", http.StatusOK, `{}`, false},
+		{"undocumented 204", http.MethodGet, "", http.StatusNoContent, "", false},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			v, err := NewVerifier(f, WithoutFullCoverage())
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(tc.method, "/thing", nil)
+			if tc.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tc.ifNoneMatch)
+			}
+			v.Record(&http.Response{
+				StatusCode: tc.statusCode,
+				Request:    req,
+				Body:       io.NopCloser(strings.NewReader(tc.body)),
+			})
+
+			if tc.valid {
+				assert.NoError(t, v.CurrentError())
+			} else {
+				assert.ErrorIs(t, v.CurrentError(), ErrResponseInvalid)
+			}
+		})
+	}
+}
+
+func TestContentLength(t *testing.T) {
+	f, err := os.ReadFile("testdata/conditional-spec.yaml")
+	require.NoError(t, err)
+
+	tt := []struct {
+		name          string
+		method        string
+		statusCode    int
+		contentLength []string
+		chunked       bool
+		body          string
+		err           string
+	}{
+		{"matching", http.MethodGet, http.StatusOK, []string{"16"}, false, `{"name":"thing"}`, ""},
+		{"repeated", http.MethodGet, http.StatusOK, []string{"16", "16, 16"}, false, `{"name":"thing"}`, ""},
+		{"too long", http.MethodGet, http.StatusOK, []string{"20"}, false, `{"name":"thing"}`, "Content-Length header says 20 bytes, but the body has 16 bytes"},
+		{"too short", http.MethodGet, http.StatusOK, []string{"2"}, false, `{"name":"thing"}`, "Content-Length header says 2 bytes"},
+		{"different values", http.MethodGet, http.StatusOK, []string{"16", "17"}, false, `{"name":"thing"}`, "different values"},
+		{"not a number", http.MethodGet, http.StatusOK, []string{"lots"}, false, `{"name":"thing"}`, "not a number of bytes"},
+		{"with Transfer-Encoding", http.MethodGet, http.StatusOK, []string{"16"}, true, `{"name":"thing"}`, "both a Content-Length and a Transfer-Encoding"},
+		{"HEAD with the length of GET", http.MethodHead, http.StatusOK, []string{"16"}, false, "", ""},
+		{"on 204", http.MethodDelete, http.StatusNoContent, []string{"0"}, false, "", "204 response must not have a Content-Length header"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			v, err := NewVerifier(f, WithoutFullCoverage())
+			require.NoError(t, err)
+
+			res := &http.Response{
+				StatusCode: tc.statusCode,
+				Request:    httptest.NewRequest(tc.method, "/thing", nil),
+				Header:     http.Header{"Content-Length": tc.contentLength},
+				Body:       io.NopCloser(strings.NewReader(tc.body)),
+			}
+			if tc.body != "" {
+				res.Header.Set("Content-Type", "application/json")
+			}
+			if tc.chunked {
+				res.TransferEncoding = []string{"chunked"}
+			}
+			v.Record(res)
+
+			if tc.err == "" {
+				assert.NoError(t, v.CurrentError())
+			} else {
+				assert.ErrorIs(t, v.CurrentError(), ErrResponseInvalid)
+				assert.ErrorContains(t, v.CurrentError(), tc.err)
+			}
+		})
+	}
+}
+
+func TestConditionalRequestCoverage(t *testing.T) {
+	f, err := os.ReadFile("testdata/conditional-spec.yaml")
+	require.NoError(t, err)
+
+	s := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.Method == http.MethodDelete:
+				w.WriteHeader(http.StatusNoContent)
+			case r.Header.Get("If-None-Match") == `"v1"`:
+				w.WriteHeader(http.StatusNotModified)
+			default:
+				w.Header().Set("ETag", `"v1"`)
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"name":"thing"}`))
+			}
+		}),
+	)
+	defer s.Close()
+
+	c, err := WrapClient(http.DefaultClient, strings.NewReader(string(f)))
+	require.NoError(t, err)
+
+	res, err := c.Get(s.URL + "/thing")
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodGet, s.URL+"/thing", nil)
+	require.NoError(t, err)
+	req.Header.Set("If-None-Match", res.Header.Get("ETag"))
+	_, err = c.Do(req)
+	require.NoError(t, err)
+
+	_, err = c.Head(s.URL + "/thing")
+	require.NoError(t, err)
+	_, err = c.Delete(s.URL + "/thing")
+	require.NoError(t, err)
+
+	c.Verify(t)
+}
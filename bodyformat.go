@@ -0,0 +1,82 @@
+package copper
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/orderedmap"
+)
+
+// WithIgnoredUnsupportedBodyFormats is a functional Option for not reporting bodies with a documented schema that can
+// not be validated, like XML or form data. By default, such bodies are reported with ErrUnsupportedBodyFormat, so that
+// a contract that is not checked does not pass unnoticed.
+func WithIgnoredUnsupportedBodyFormats() Option {
+	return func(c *config) {
+		c.ignoreUnsupportedBodyFormats = true
+	}
+}
+
+// checkBodyFormat returns an error if the body has a documented schema that it can not be validated against. Only JSON
+// bodies are validated against their schemas, along with multipart request bodies. Schemas that any body satisfies,
+// like a binary string, do not need validation.
+func checkBodyFormat(content *orderedmap.Map[string, *v3.MediaType], h http.Header, body []byte, request bool) error {
+	if len(body) == 0 || content == nil {
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(h.Get("Content-Type"))
+	if err != nil || strings.Contains(mediaType, "json") {
+		return nil
+	}
+	if request && strings.HasPrefix(mediaType, "multipart/") {
+		return nil
+	}
+
+	documented := content.GetOrZero(mediaType)
+	if documented == nil || documented.Schema == nil || acceptsAnyBody(documented.Schema.Schema()) {
+		return nil
+	}
+	return fmt.Errorf("%s body can not be validated against its schema", mediaType)
+}
+
+// acceptsAnyBody checks if the schema is satisfied by any body, which is the case for an empty schema and for a plain
+// string.
+func acceptsAnyBody(s *base.Schema) bool {
+	if s == nil {
+		return true
+	}
+	if len(s.AllOf)+len(s.AnyOf)+len(s.OneOf) > 0 || s.Not != nil || orderedmap.Len(s.Properties) > 0 || s.Items != nil ||
+		len(s.Enum) > 0 || s.Pattern != "" || s.MinLength != nil || s.MaxLength != nil {
+		return false
+	}
+	return len(s.Type) == 0 || slices.Equal(s.Type, []string{"string"})
+}
+
+// checkRequestBodyFormat is checkBodyFormat for the body of the request.
+func checkRequestBodyFormat(req *http.Request, op *v3.Operation) error {
+	if op == nil || op.RequestBody == nil {
+		return nil
+	}
+	body, err := readBody(&req.Body)
+	if err != nil {
+		return nil
+	}
+	return checkBodyFormat(op.RequestBody.Content, req.Header, body, true)
+}
+
+// checkResponseBodyFormat is checkBodyFormat for the body of the response.
+func checkResponseBodyFormat(req *http.Request, res *http.Response, op *v3.Operation) error {
+	response, _ := documentedResponse(op, res.StatusCode)
+	if response == nil || isBodiless(req, res.StatusCode) {
+		return nil
+	}
+	body, err := readBody(&res.Body)
+	if err != nil {
+		return nil
+	}
+	return checkBodyFormat(response.Content, res.Header, body, false)
+}
@@ -0,0 +1,87 @@
+package copper
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnsupportedBodyFormat(t *testing.T) {
+	f, err := os.ReadFile("testdata/bodyformat-spec.yaml")
+	require.NoError(t, err)
+
+	record := func(v *Verifier, reqType, reqBody, resType, resBody string) {
+		req := httptest.NewRequest(http.MethodPost, "/things", strings.NewReader(reqBody))
+		req.Header.Set("Content-Type", reqType)
+		v.Record(&http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {resType}},
+			Body:       io.NopCloser(strings.NewReader(resBody)),
+			Request:    req,
+		})
+	}
+
+	tt := []struct {
+		name     string
+		reqType  string
+		reqBody  string
+		resType  string
+		resBody  string
+		expected []string
+	}{
+		{
+			"bodies that can be validated",
+			"text/plain", "a thing",
+			"application/json", `{"name": "a thing"}`,
+			nil,
+		},
+		{
+			"plain strings do not need validation",
+			"text/plain", "a thing",
+			"text/plain", "a thing",
+			nil,
+		},
+		{
+			"response body with a schema",
+			"text/plain", "a thing",
+			"application/xml", "<thing><name>a thing</name></thing>",
+			[]string{"unsupported body format: POST /things: response application/xml body can not be validated against its schema"},
+		},
+		{
+			"request body with a schema",
+			"application/x-www-form-urlencoded", "name=thing",
+			"text/plain", "a thing",
+			[]string{"unsupported body format: POST /things: request application/x-www-form-urlencoded body can not be validated against its schema"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			v, err := NewVerifier(f, WithRequestValidation())
+			require.NoError(t, err)
+			record(v, tc.reqType, tc.reqBody, tc.resType, tc.resBody)
+
+			var msgs []string
+			for _, err := range v.CurrentErrors() {
+				assert.ErrorIs(t, err, ErrUnsupportedBodyFormat)
+				assert.NotErrorIs(t, err, ErrResponseInvalid)
+				msgs = append(msgs, err.Error())
+			}
+			assert.Equal(t, tc.expected, msgs)
+		})
+	}
+
+	t.Run("unsupported formats can be ignored", func(t *testing.T) {
+		v, err := NewVerifier(f, WithRequestValidation(), WithIgnoredUnsupportedBodyFormats())
+		require.NoError(t, err)
+		record(v, "application/x-www-form-urlencoded", "name=thing", "application/xml", "<thing/>")
+
+		assert.NoError(t, v.CurrentError())
+	})
+}
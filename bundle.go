@@ -0,0 +1,53 @@
+//go:build !copper_lite
+
+package copper
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+
+	"github.com/pb33f/libopenapi/bundler"
+	"github.com/pb33f/libopenapi/datamodel"
+	"github.com/pb33f/libopenapi/index"
+)
+
+// Bundle reads the spec found at entry in the given file system, and returns a single self-contained spec document
+// where all external references have been inlined. References are resolved relative to the directory of the entry, and
+// only files inside of that directory can be referenced. Local references within the entry document are kept as they
+// are, and circular references are left untouched, so the bundled document is equivalent to the original one.
+//
+// This can be used to vendor a single spec file into a test package, which then can be passed to WrapClient or
+// NewVerifier.
+func Bundle(fsys fs.FS, entry string) ([]byte, error) {
+	specBytes, err := fs.ReadFile(fsys, entry)
+	if err != nil {
+		return nil, fmt.Errorf("could not read spec: %w", err)
+	}
+
+	dir := path.Dir(entry)
+	dirFS, err := fs.Sub(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("could not open spec directory: %w", err)
+	}
+
+	localFS, err := index.NewLocalFSWithConfig(&index.LocalFSConfig{
+		BaseDirectory: dir,
+		DirFS:         dirFS,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not index spec directory: %w", err)
+	}
+
+	conf := datamodel.NewDocumentConfiguration()
+	conf.BasePath = dir
+	conf.SpecFilePath = path.Base(entry)
+	conf.LocalFS = localFS
+	conf.ExtractRefsSequentially = true
+
+	bundled, err := bundler.BundleBytes(specBytes, conf)
+	if err != nil {
+		return nil, fmt.Errorf("could not bundle spec: %w", err)
+	}
+	return bundled, nil
+}
@@ -0,0 +1,58 @@
+//go:build !copper_lite
+
+package copper
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundle(t *testing.T) {
+	t.Run("external refs are inlined", func(t *testing.T) {
+		bundled, err := Bundle(os.DirFS("testdata"), "bundle/openapi.yaml")
+		require.NoError(t, err)
+		assert.NotContains(t, string(bundled), "pong.yaml")
+
+		v, err := NewVerifier(bundled)
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+		v.Record(&http.Response{
+			StatusCode: 200,
+			Request:    req,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{"message": 2}`)),
+		})
+		assert.ErrorIs(t, v.CurrentError(), ErrResponseInvalid)
+	})
+
+	t.Run("spec can be read from any file system", func(t *testing.T) {
+		spec, err := os.ReadFile("testdata/bundle/openapi.yaml")
+		require.NoError(t, err)
+		schema, err := os.ReadFile("testdata/bundle/schemas/pong.yaml")
+		require.NoError(t, err)
+
+		fsys := fstest.MapFS{
+			"api/openapi.yaml":      {Data: spec},
+			"api/schemas/pong.yaml": {Data: schema},
+		}
+
+		bundled, err := Bundle(fsys, "api/openapi.yaml")
+		require.NoError(t, err)
+		assert.NotContains(t, string(bundled), "pong.yaml")
+		assert.Contains(t, string(bundled), "message")
+	})
+
+	t.Run("missing entry fails", func(t *testing.T) {
+		_, err := Bundle(os.DirFS("testdata"), "bundle/missing.yaml")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,33 @@
+// Package chiadapter records the requests that a chi router serves into a copper.Verifier, using the route pattern
+// that chi matched to find the path in the spec.
+package chiadapter
+
+import (
+	"net/http"
+
+	"github.com/callebjorkell/copper"
+	"github.com/callebjorkell/copper/internal/capture"
+	"github.com/go-chi/chi/v5"
+)
+
+// Middleware returns chi middleware that records every request and the response to it into the verifier. Use it with
+// the Use method of the router, so that the route pattern is known when the request is recorded.
+func Middleware(v *copper.Verifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := capture.BufferRequest(r); err != nil {
+				http.Error(w, "could not read request body", http.StatusBadRequest)
+				return
+			}
+
+			cw := capture.NewWriter(w)
+			next.ServeHTTP(cw, r)
+
+			var route string
+			if rctx := chi.RouteContext(r.Context()); rctx != nil {
+				route = rctx.RoutePattern()
+			}
+			v.RecordRoute(cw.Response(r), route)
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package chiadapter
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/callebjorkell/copper"
+	"github.com/callebjorkell/copper/internal/routertest"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRouter(v *copper.Verifier) http.Handler {
+	r := chi.NewRouter()
+	r.Use(Middleware(v))
+	r.Route("/api/things", func(r chi.Router) {
+		r.Get("/export", routertest.Export)
+		r.Get("/{thingID}", routertest.GetThing)
+		r.Put("/{thingID}", routertest.PutThing)
+	})
+	return r
+}
+
+func TestMiddleware(t *testing.T) {
+	routertest.Test(t, routertest.Middleware(newRouter), http.StatusMethodNotAllowed)
+
+	t.Run("the route pattern of a subrouter is matched", func(t *testing.T) {
+		router := routertest.Middleware(newRouter)
+		assert.Equal(t, []string{"/things/export"}, routertest.Matched(t, router, http.MethodGet, "/api/things/export"))
+		assert.Equal(t, []string{"/things/{id}"}, routertest.Matched(t, router, http.MethodGet, "/api/things/abc"))
+	})
+}
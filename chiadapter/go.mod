@@ -0,0 +1,30 @@
+module github.com/callebjorkell/copper/chiadapter
+
+go 1.23.0
+
+toolchain go1.23.2
+
+require (
+	github.com/callebjorkell/copper v0.0.0-00010101000000-000000000000
+	github.com/go-chi/chi/v5 v5.2.1
+	github.com/stretchr/testify v1.9.0
+)
+
+require (
+	github.com/bahlo/generic-list-go v0.2.0 // indirect
+	github.com/buger/jsonparser v1.1.1 // indirect
+	github.com/davecgh/go-spew v1.1.2-0.20180830191138-d8f796af33cc // indirect
+	github.com/dlclark/regexp2 v1.11.0 // indirect
+	github.com/dprotaso/go-yit v0.0.0-20240618133044-5a0af90af097 // indirect
+	github.com/mailru/easyjson v0.7.7 // indirect
+	github.com/pb33f/libopenapi v0.18.7 // indirect
+	github.com/pb33f/libopenapi-validator v0.2.2 // indirect
+	github.com/pmezard/go-difflib v1.0.1-0.20181226105442-5d4384ee4fb2 // indirect
+	github.com/santhosh-tekuri/jsonschema/v6 v6.0.1 // indirect
+	github.com/vmware-labs/yaml-jsonpath v0.3.2 // indirect
+	github.com/wk8/go-ordered-map/v2 v2.1.9-0.20240815153524-6ea36470d1bd // indirect
+	golang.org/x/text v0.28.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/callebjorkell/copper => ../
@@ -1,20 +1,48 @@
 package copper
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptrace"
+	"net/textproto"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HTTPClient is the set of methods that both http.Client and ValidatingClient have, with the same signatures. Code that
+// takes an HTTPClient, like an SDK, can be given a real client in production and a ValidatingClient in contract tests.
+// The methods that only ValidatingClient has, like Put and Delete, are left out so that http.Client still fits.
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+	Get(url string) (*http.Response, error)
+	Head(url string) (*http.Response, error)
+	Post(url, contentType string, body io.Reader) (*http.Response, error)
+	PostForm(url string, data url.Values) (*http.Response, error)
+}
+
+var (
+	_ HTTPClient = (*http.Client)(nil)
+	_ HTTPClient = (*ValidatingClient)(nil)
 )
 
 // ValidatingClient provides an HTTP client, and wraps the main methods, recording any and all paths that are being
-// called.
+// called. Like http.Client, it is safe for concurrent use by goroutines.
 type ValidatingClient struct {
-	c *http.Client
+	// c is replaced with a changed copy rather than changed, so that the requests that are being sent keep the client
+	// they started with. clientMu guards it.
+	c        *http.Client
+	clientMu sync.Mutex
 	*Verifier
+	throttle *throttle
 }
 
 // WrapClient takes an HTTP client and io.Reader for the OpenAPI spec. The spec is parsed, and wraps the client so that
-// the outbound calls are now recorded when made.
+// the outbound calls are now recorded when made. With WithTLSConfig or WithInsecureTLS, a copy of the client is wrapped
+// instead, so that the TLS configuration does not leak into other users of it, like http.DefaultClient.
 func WrapClient(c *http.Client, spec io.Reader, opts ...Option) (*ValidatingClient, error) {
 	s, err := io.ReadAll(spec)
 	if err != nil {
@@ -25,54 +53,228 @@ func WrapClient(c *http.Client, spec io.Reader, opts ...Option) (*ValidatingClie
 	if err != nil {
 		return nil, fmt.Errorf("could not create verifier: %w", err)
 	}
+	return newValidatingClient(c, verifier)
+}
+
+// newValidatingClient wraps the client so that it records the requests that it sends with the verifier.
+func newValidatingClient(c *http.Client, verifier *Verifier) (*ValidatingClient, error) {
+	verifier.mu.Lock()
+	tlsConfig := verifier.conf.tlsConfig
+	verifier.mu.Unlock()
+
+	c, err := withTLSConfig(c, tlsConfig)
+	if err != nil {
+		return nil, fmt.Errorf("could not wrap client: %w", err)
+	}
 
 	return &ValidatingClient{
 		c:        c,
 		Verifier: verifier,
+		throttle: &throttle{},
 	}, nil
 }
 
+// MustWrapClient is like WrapClient, but panics if the spec can not be read or the Verifier can not be created. It is
+// meant for test helpers and examples, where a spec that can not be used is a bug in the test.
+func MustWrapClient(c *http.Client, spec io.Reader, opts ...Option) *ValidatingClient {
+	client, err := WrapClient(c, spec, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return client
+}
+
 // WithClient returns a new client using the same validator, but a new client. This can be useful to change transport
-// or authorization settings, while still contributing to the same spec validation.
+// or authorization settings, while still contributing to the same spec validation. The rate limit is shared with the
+// new client, since the requests go to the same server. With WithTLSConfig or WithInsecureTLS, a copy of the new client
+// is wrapped with the TLS configuration, like in WrapClient.
 func (v *ValidatingClient) WithClient(c *http.Client) (*ValidatingClient, error) {
 	if v == nil {
 		return nil, fmt.Errorf("cannot switch client on nil validator")
 	}
 
-	return &ValidatingClient{
-		c:        c,
-		Verifier: v.Verifier,
-	}, nil
+	client, err := newValidatingClient(c, v.Verifier)
+	if err != nil {
+		return nil, err
+	}
+	client.throttle = v.throttle
+	return client, nil
 }
 
-// Do takes any http.Request, sends it to the server it and then records the result.
+// Do takes any http.Request, sends it to the server it and then records the result. Informational (1xx) responses
+// received before the final response are not validated, but are noted in the request log if logging is enabled. A
+// request body that can not be read again, since the request has no GetBody, is buffered first so that it can still be
+// validated and logged.
 func (v *ValidatingClient) Do(r *http.Request) (*http.Response, error) {
-	return v.recordResponse(v.c.Do(r))
+	return v.recordResponse(v.send(r))
+}
+
+// send sends the request with the wrapped client, without recording the response.
+func (v *ValidatingClient) send(r *http.Request) (*http.Response, error) {
+	return v.Verifier.send(r, v.throttle, v.client().Do)
+}
+
+// client returns the wrapped client.
+func (v *ValidatingClient) client() *http.Client {
+	v.clientMu.Lock()
+	defer v.clientMu.Unlock()
+	return v.c
+}
+
+// changeClient replaces the wrapped client with a copy that the function changes, like withTLSConfig does, so that
+// neither the client that was given nor the requests that are being sent with it are affected.
+func (v *ValidatingClient) changeClient(change func(*http.Client)) {
+	v.clientMu.Lock()
+	defer v.clientMu.Unlock()
+	client := *v.c
+	change(&client)
+	v.c = &client
+}
+
+// send prepares the request as the client options say, and sends it with the function, which is the Do of a client or
+// the RoundTrip of a transport, waiting for the rate limit of the throttle if there is one. The response is not
+// recorded.
+func (v *Verifier) send(r *http.Request, throttle *throttle,
+	do func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	trace := &httptrace.ClientTrace{
+		Got1xxResponse: func(code int, header textproto.MIMEHeader) error {
+			v.noteInformational(r, code, http.Header(header))
+			return nil
+		},
+	}
+	ctx := (&timer{}).hook(r.Context(), trace)
+	r = r.WithContext(httptrace.WithClientTrace(ctx, trace))
+	opts := v.clientOptions()
+	r = resolveRelative(r, opts.base)
+	if opts.requestIDs {
+		r = withRequestID(r)
+	}
+
+	r, err := replayable(r)
+	if err != nil {
+		return nil, err
+	}
+	if opts.rateLimit == 0 {
+		return do(r)
+	}
+
+	if err := throttle.wait(r.Context(), opts.rateLimit); err != nil {
+		return nil, err
+	}
+	res, err := do(r)
+	if err == nil && res.StatusCode == http.StatusTooManyRequests {
+		throttle.backOff(res.Header.Get("Retry-After"), time.Now())
+	}
+	return res, err
+}
+
+// resolveRelative points a request with a relative URL to the base URL, if there is one. The request is changed, so it
+// has to be a copy.
+func resolveRelative(r *http.Request, base *url.URL) *http.Request {
+	if base != nil && !r.URL.IsAbs() {
+		r.URL = resolveURL(base, r.URL)
+		r.Host = r.URL.Host
+	}
+	return r
+}
+
+// resolveURL joins the base URL with the path of a relative URL, which keeps its query and fragment. The escaped path is
+// joined, so that encoded characters like %2F stay encoded instead of becoming separators.
+func resolveURL(base, rel *url.URL) *url.URL {
+	resolved := base.JoinPath(rel.EscapedPath())
+	resolved.RawQuery = rel.RawQuery
+	resolved.Fragment = rel.Fragment
+	return resolved
+}
+
+// maxBufferedBody is the largest request body that Do buffers to make it replayable.
+const maxBufferedBody = 10 * 1024 * 1024
+
+// replayable buffers the body of a request that can not be read again, and sets GetBody so that the body can still be
+// validated and logged once it has been sent. The request must be a copy, since it is changed. Bodies larger than
+// maxBufferedBody are sent as they are, without being validated.
+func replayable(r *http.Request) (*http.Request, error) {
+	if r.Body == nil || r.Body == http.NoBody || r.GetBody != nil {
+		return r, nil
+	}
+
+	body := r.Body
+	buf, err := io.ReadAll(io.LimitReader(body, maxBufferedBody+1))
+	if err != nil {
+		// Like http.Client.Do, the body is closed even when the request can not be sent.
+		_ = body.Close()
+		return nil, fmt.Errorf("could not read request body: %w", err)
+	}
+	if len(buf) > maxBufferedBody {
+		// What has been read is put back in front of the rest.
+		r.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(buf), body), body}
+		return r, nil
+	}
+	if err := body.Close(); err != nil {
+		return nil, fmt.Errorf("could not close request body: %w", err)
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(buf))
+	r.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(buf)), nil
+	}
+	return r, nil
 }
 
 // Head is a convenience method for recording responses for HTTP HEAD requests
 func (v *ValidatingClient) Head(url string) (resp *http.Response, err error) {
-	return v.recordResponse(v.c.Head(url))
+	req, err := http.NewRequest(http.MethodHead, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return v.Do(req)
 }
 
 // Get is a convenience method for recording responses for HTTP GET requests
 func (v *ValidatingClient) Get(url string) (resp *http.Response, err error) {
-	return v.recordResponse(v.c.Get(url))
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return v.Do(req)
 }
 
 // Put is a convenience method for recording responses for HTTP PUT requests
 func (v *ValidatingClient) Put(url string, contentType string, body io.Reader) (resp *http.Response, err error) {
 	req, err := http.NewRequest(http.MethodPut, url, body)
-	req.Header.Set("Content-Type", contentType)
 	if err != nil {
 		return nil, err
 	}
-	return v.recordResponse(v.c.Do(req))
+	req.Header.Set("Content-Type", contentType)
+	return v.Do(req)
 }
 
 // Post is a convenience method for recording responses for HTTP POST requests
 func (v *ValidatingClient) Post(url string, contentType string, body io.Reader) (resp *http.Response, err error) {
-	return v.recordResponse(v.c.Post(url, contentType, body))
+	req, err := http.NewRequest(http.MethodPost, url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	return v.Do(req)
+}
+
+// PostForm is a convenience method for recording responses for HTTP POST requests with URL encoded form data
+func (v *ValidatingClient) PostForm(url string, data url.Values) (resp *http.Response, err error) {
+	return v.Post(url, "application/x-www-form-urlencoded", strings.NewReader(data.Encode()))
+}
+
+// Patch is a convenience method for recording responses for HTTP PATCH requests
+func (v *ValidatingClient) Patch(url string, contentType string, body io.Reader) (resp *http.Response, err error) {
+	req, err := http.NewRequest(http.MethodPatch, url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	return v.Do(req)
 }
 
 // Delete records response for HTTP DELETE requests
@@ -81,7 +283,41 @@ func (v *ValidatingClient) Delete(url string) (resp *http.Response, err error) {
 	if err != nil {
 		return nil, err
 	}
-	return v.recordResponse(v.c.Do(req))
+	return v.Do(req)
+}
+
+// CloseIdleConnections closes the idle connections of the wrapped client, like http.Client.CloseIdleConnections.
+func (v *ValidatingClient) CloseIdleConnections() {
+	v.client().CloseIdleConnections()
+}
+
+// Jar returns the cookie jar of the wrapped client, which is nil if it does not have one.
+func (v *ValidatingClient) Jar() http.CookieJar {
+	return v.client().Jar
+}
+
+// SetJar sets the cookie jar of the wrapped client. A copy of the client is changed, so other uses of it, like when it
+// is http.DefaultClient, are not affected, and neither are the requests that are already being sent.
+func (v *ValidatingClient) SetJar(jar http.CookieJar) {
+	v.changeClient(func(c *http.Client) { c.Jar = jar })
+}
+
+// Timeout returns the time limit for the requests of the wrapped client, where zero means no timeout.
+func (v *ValidatingClient) Timeout() time.Duration {
+	return v.client().Timeout
+}
+
+// SetTimeout sets the time limit for the requests of the wrapped client. Like with SetJar, a copy of the client is
+// changed.
+func (v *ValidatingClient) SetTimeout(timeout time.Duration) {
+	v.changeClient(func(c *http.Client) { c.Timeout = timeout })
+}
+
+// SetCheckRedirect sets the policy for handling redirects of the wrapped client, like http.Client.CheckRedirect. Only
+// the final response is recorded, since that is the one that the request is answered with. Like with SetJar, a copy of
+// the client is changed.
+func (v *ValidatingClient) SetCheckRedirect(check func(req *http.Request, via []*http.Request) error) {
+	v.changeClient(func(c *http.Client) { c.CheckRedirect = check })
 }
 
 func (v *ValidatingClient) recordResponse(resp *http.Response, err error) (*http.Response, error) {
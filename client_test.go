@@ -4,12 +4,16 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/http/cookiejar"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -200,6 +204,53 @@ func TestRequestLogging(t *testing.T) {
 	c.Verify(t)
 }
 
+func TestWithVerbosity(t *testing.T) {
+	f, err := os.ReadFile("testdata/minimal-spec.yaml")
+	require.NoError(t, err)
+
+	s := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}),
+	)
+	defer s.Close()
+
+	t.Run("progress", func(t *testing.T) {
+		store := &logStore{}
+		c, err := WrapClient(http.DefaultClient, bytes.NewReader(f), WithRequestLogging(store), WithVerbosity(LogProgress),
+			WithoutFullCoverage())
+		require.NoError(t, err)
+
+		_, err = c.Get(s.URL + "/ping")
+		require.NoError(t, err)
+		_, err = c.Get(s.URL + "/missing")
+		require.NoError(t, err)
+
+		if assert.Len(t, store.logs, 2) {
+			assert.Regexp(t, `^copper endpoint="GET /ping 204" verdict="ok" errors=0 duration=\S+ dns=\S+ connect=\S+ tls=\S+ ttfb=\S+$`,
+				store.logs[0])
+			assert.Regexp(t, `^copper endpoint="GET /missing 204" verdict="not part of spec" errors=1 duration=\S+ dns=\S+ connect=\S+ tls=\S+ ttfb=\S+$`,
+				store.logs[1])
+		}
+	})
+
+	t.Run("progress and dumps", func(t *testing.T) {
+		store := &logStore{}
+		c, err := WrapClient(http.DefaultClient, bytes.NewReader(f), WithRequestLogging(store), WithVerbosity(LogProgressAndDumps))
+		require.NoError(t, err)
+
+		_, err = c.Get(s.URL + "/ping")
+		require.NoError(t, err)
+
+		assert.Len(t, store.logs, 3)
+	})
+
+	t.Run("verbosity without a logger", func(t *testing.T) {
+		_, err := WrapClient(http.DefaultClient, bytes.NewReader(f), WithVerbosity(LogProgress))
+		assert.ErrorIs(t, err, ErrInvalidOptions)
+	})
+}
+
 func TestValidationErrors(t *testing.T) {
 	f, err := os.ReadFile("testdata/number-spec.yaml")
 	require.NoError(t, err)
@@ -297,3 +348,244 @@ func TestRequestBodyValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestInformationalResponses(t *testing.T) {
+	f, err := os.ReadFile("testdata/minimal-spec.yaml")
+	require.NoError(t, err)
+
+	s := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Link", "</style.css>; rel=preload; as=style")
+			w.WriteHeader(http.StatusEarlyHints)
+			w.WriteHeader(http.StatusNoContent)
+		}),
+	)
+	defer s.Close()
+
+	store := &logStore{}
+	c, err := WrapClient(http.DefaultClient, bytes.NewReader(f), WithRequestLogging(store))
+	require.NoError(t, err)
+
+	res, err := c.Get(s.URL + "/ping")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, res.StatusCode)
+
+	if assert.Len(t, store.logs, 3) {
+		assert.Contains(t, store.logs[0], "103 Early Hints")
+	}
+
+	c.Verify(t)
+}
+
+func TestMustWrapClient(t *testing.T) {
+	f, err := os.ReadFile("testdata/thing-spec.yaml")
+	require.NoError(t, err)
+
+	assert.NotNil(t, MustWrapClient(http.DefaultClient, bytes.NewReader(f)))
+	assert.Panics(t, func() { MustWrapClient(http.DefaultClient, strings.NewReader("not a spec")) })
+}
+
+func TestHTTPClient(t *testing.T) {
+	f, err := os.ReadFile("testdata/thing-spec.yaml")
+	require.NoError(t, err)
+
+	var got []string
+	s := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			got = append(got, r.Method+" "+r.Header.Get("Content-Type"))
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"message":"pong!"}`))
+		}),
+	)
+	defer s.Close()
+
+	ping := func(c HTTPClient) error {
+		res, err := c.Get(s.URL + "/ping")
+		if err != nil {
+			return err
+		}
+		return res.Body.Close()
+	}
+
+	v := MustWrapClient(http.DefaultClient, bytes.NewReader(f), WithoutFullCoverage())
+	assert.NoError(t, ping(http.DefaultClient))
+	assert.NoError(t, ping(v))
+	v.Verify(t)
+	assert.Equal(t, []Endpoint{{Path: "/other", Method: "GET", ResponseCode: "200"}}, v.Unchecked())
+
+	_, err = v.Patch(s.URL+"/ping", "application/json", strings.NewReader(`{}`))
+	assert.NoError(t, err)
+	_, err = v.PostForm(s.URL+"/ping", url.Values{"a": {"b"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"GET ", "GET ", "PATCH application/json", "POST application/x-www-form-urlencoded"}, got)
+}
+
+func TestClientControls(t *testing.T) {
+	f, err := os.ReadFile("testdata/thing-spec.yaml")
+	require.NoError(t, err)
+
+	s := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, "/ping", http.StatusFound)
+		}),
+	)
+	defer s.Close()
+
+	hc := &http.Client{}
+	c := MustWrapClient(hc, bytes.NewReader(f), WithoutFullCoverage())
+
+	c.SetTimeout(5 * time.Second)
+	assert.Equal(t, 5*time.Second, c.Timeout())
+	assert.Zero(t, hc.Timeout, "the wrapped client is a copy")
+
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	assert.Nil(t, c.Jar())
+	c.SetJar(jar)
+	assert.Equal(t, jar, c.Jar())
+	assert.Nil(t, hc.Jar)
+
+	c.SetCheckRedirect(func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	})
+	res, err := c.Get(s.URL + "/other")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusFound, res.StatusCode)
+	assert.ErrorIs(t, c.CurrentError(), ErrResponseInvalid)
+	assert.Nil(t, hc.CheckRedirect)
+
+	c.CloseIdleConnections()
+}
+
+func TestReplayableBodies(t *testing.T) {
+	f, err := os.ReadFile("testdata/request-body-spec.yaml")
+	require.NoError(t, err)
+
+	var received string
+	s := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			b, _ := io.ReadAll(r.Body)
+			received = string(b)
+			w.WriteHeader(http.StatusNoContent)
+		}),
+	)
+	defer s.Close()
+
+	tt := []struct {
+		name        string
+		body        string
+		shouldError bool
+	}{
+		{"according to spec", `{"input":"pem"}`, false},
+		{"missing input field", `{"message":"stuff"}`, true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			c := MustWrapClient(http.DefaultClient, bytes.NewReader(f), WithRequestValidation())
+
+			// A body of a type that http.NewRequest does not know can not be read again.
+			req, err := http.NewRequest(http.MethodPost, s.URL+"/req", io.NopCloser(strings.NewReader(tc.body)))
+			require.NoError(t, err)
+			require.Nil(t, req.GetBody)
+			req.Header.Set("Content-Type", "application/json")
+
+			_, err = c.Do(req)
+			require.NoError(t, err)
+			assert.Equal(t, tc.body, received)
+			if tc.shouldError {
+				assert.ErrorIs(t, c.CurrentError(), ErrRequestInvalid)
+			} else {
+				assert.NoError(t, c.CurrentError())
+			}
+		})
+	}
+
+	t.Run("too large to buffer", func(t *testing.T) {
+		body := strings.Repeat("x", maxBufferedBody+1)
+		req, err := http.NewRequest(http.MethodPost, "/", io.NopCloser(strings.NewReader(body)))
+		require.NoError(t, err)
+
+		req, err = replayable(req)
+		require.NoError(t, err)
+		assert.Nil(t, req.GetBody)
+		b, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+		assert.Equal(t, len(body), len(b))
+	})
+
+	t.Run("body that can not be read", func(t *testing.T) {
+		c := MustWrapClient(http.DefaultClient, bytes.NewReader(f))
+		body := &failingBody{}
+		req, err := http.NewRequest(http.MethodPost, s.URL+"/req", body)
+		require.NoError(t, err)
+
+		_, err = c.Do(req)
+		assert.ErrorContains(t, err, "could not read request body")
+		assert.True(t, body.closed)
+	})
+}
+
+// failingBody is a request body whose Read always fails.
+type failingBody struct {
+	closed bool
+}
+
+func (b *failingBody) Read([]byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+func (b *failingBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestWithBaseURL(t *testing.T) {
+	f, err := os.ReadFile("testdata/minimal-spec.yaml")
+	require.NoError(t, err)
+
+	var received string
+	s := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			received = r.URL.RequestURI()
+			if r.URL.Path == "/api/ping" {
+				w.WriteHeader(http.StatusNoContent)
+			} else {
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}),
+	)
+	defer s.Close()
+
+	c, err := WrapClient(http.DefaultClient, bytes.NewReader(f), WithBaseURL(s.URL+"/api"), WithServer(s.URL+"/api"))
+	require.NoError(t, err)
+
+	_, err = c.Get("/ping?verbose=true")
+	require.NoError(t, err)
+	assert.Equal(t, "/api/ping?verbose=true", received)
+
+	// Absolute URLs are sent as they are.
+	_, err = c.Get(s.URL + "/api/ping")
+	require.NoError(t, err)
+	assert.Equal(t, "/api/ping", received)
+	c.Verify(t)
+
+	t.Run("encoded path", func(t *testing.T) {
+		base, err := url.Parse("http://localhost:8080/api")
+		require.NoError(t, err)
+		rel, err := url.Parse("/files/a%2Fb%20c?x=1#top")
+		require.NoError(t, err)
+
+		resolved := resolveURL(base, rel)
+		assert.Equal(t, "http://localhost:8080/api/files/a%2Fb%20c?x=1#top", resolved.String())
+		assert.Equal(t, "/api/files/a/b c", resolved.Path)
+	})
+
+	tt := []string{"localhost:8080/api", "/api", "http://[::1"}
+	for _, base := range tt {
+		t.Run(base, func(t *testing.T) {
+			_, err := WrapClient(http.DefaultClient, bytes.NewReader(f), WithBaseURL(base))
+			assert.ErrorIs(t, err, ErrInvalidOptions)
+		})
+	}
+}
@@ -0,0 +1,309 @@
+//go:build !copper_lite
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/callebjorkell/copper"
+	"github.com/callebjorkell/copper/copperproxy"
+	"github.com/callebjorkell/copper/notify"
+	"gopkg.in/yaml.v3"
+)
+
+// daemonConfig is what the proxy is run with, either from the flags of the proxy command or from the YAML file of the
+// daemon command. Relative paths in the file are relative to the directory of the file.
+type daemonConfig struct {
+	// Spec is the file of the spec, or the http or https URL to download it from.
+	Spec   string `yaml:"spec"`
+	Target string `yaml:"target"`
+	Listen string `yaml:"listen"`
+	// Admin is the address that /healthz and /coverage are served on, apart from the traffic that is forwarded. They
+	// are not served if it is empty.
+	Admin string `yaml:"admin"`
+	// ShutdownTimeout is how long the requests that are in flight when stopped are waited for, before the reports are
+	// written anyway.
+	ShutdownTimeout   time.Duration `yaml:"shutdownTimeout"`
+	ValidateRequests  bool          `yaml:"validateRequests"`
+	Verify            bool          `yaml:"verify"`
+	CoverageThreshold float64       `yaml:"coverageThreshold"`
+	Exclude           struct {
+		Paths      []string `yaml:"paths"`
+		Tags       []string `yaml:"tags"`
+		Operations []string `yaml:"operations"`
+	} `yaml:"exclude"`
+	Ignore struct {
+		Operations []string            `yaml:"operations"`
+		Responses  map[string][]string `yaml:"responses"`
+	} `yaml:"ignore"`
+	Reports struct {
+		State string `yaml:"state"`
+		JSON  string `yaml:"json"`
+		HTML  string `yaml:"html"`
+		JUnit string `yaml:"junit"`
+		TAP   string `yaml:"tap"`
+	} `yaml:"reports"`
+	// Notify are the webhooks that a summary is posted to when stopped, if the traffic has errors.
+	Notify struct {
+		Webhook string `yaml:"webhook"`
+		Slack   string `yaml:"slack"`
+	} `yaml:"notify"`
+}
+
+// notifyTimeout is how long the notifiers are waited for when stopped.
+const notifyTimeout = 30 * time.Second
+
+// options returns the options of the Verifier of the proxy.
+func (c daemonConfig) options() []copper.Option {
+	var opts []copper.Option
+	if c.ValidateRequests {
+		opts = append(opts, copper.WithRequestValidation())
+	}
+	if c.CoverageThreshold > 0 {
+		opts = append(opts, copper.WithCoverageThreshold(c.CoverageThreshold))
+	}
+	if len(c.Exclude.Paths) > 0 {
+		opts = append(opts, copper.WithExcludePaths(c.Exclude.Paths...))
+	}
+	if len(c.Exclude.Tags) > 0 {
+		opts = append(opts, copper.WithExcludeTags(c.Exclude.Tags...))
+	}
+	if len(c.Exclude.Operations) > 0 {
+		opts = append(opts, copper.WithExcludeOperations(c.Exclude.Operations...))
+	}
+	if len(c.Ignore.Operations) > 0 {
+		opts = append(opts, copper.WithIgnoredOperations(c.Ignore.Operations...))
+	}
+	for _, op := range slices.Sorted(maps.Keys(c.Ignore.Responses)) {
+		opts = append(opts, copper.WithIgnoredResponses(op, c.Ignore.Responses[op]...))
+	}
+	return opts
+}
+
+// notifiers returns the notifiers for the webhooks that are configured.
+func (c daemonConfig) notifiers() []copper.Notifier {
+	var notifiers []copper.Notifier
+	if c.Notify.Webhook != "" {
+		notifiers = append(notifiers, notify.Webhook{URL: c.Notify.Webhook})
+	}
+	if c.Notify.Slack != "" {
+		notifiers = append(notifiers, notify.Slack{WebhookURL: c.Notify.Slack})
+	}
+	return notifiers
+}
+
+// readDaemonConfig reads the configuration of the daemon command from the YAML file. Fields that are not known are
+// errors, so that a misspelled one is not silently left out.
+func readDaemonConfig(name string) (daemonConfig, error) {
+	conf := daemonConfig{Listen: ":8081", Admin: ":8082", ShutdownTimeout: 10 * time.Second}
+	f, err := os.Open(name)
+	if err != nil {
+		return conf, err
+	}
+	defer f.Close()
+
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(&conf); err != nil && !errors.Is(err, io.EOF) {
+		return conf, fmt.Errorf("could not read config: %w", err)
+	}
+
+	dir := filepath.Dir(name)
+	resolve := func(p *string) {
+		if *p != "" && !filepath.IsAbs(*p) {
+			*p = filepath.Join(dir, *p)
+		}
+	}
+	if !isURL(conf.Spec) {
+		resolve(&conf.Spec)
+	}
+	for _, p := range []*string{&conf.Reports.State, &conf.Reports.JSON, &conf.Reports.HTML, &conf.Reports.JUnit, &conf.Reports.TAP} {
+		resolve(p)
+	}
+	return conf, nil
+}
+
+// daemon serves the proxy with the configuration in a YAML file, along with its health and coverage, until the context
+// is done.
+func daemon(ctx context.Context, args []string, stderr io.Writer) int {
+	fs := flag.NewFlagSet("daemon", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	config := fs.String("config", "copper.yaml", "read the configuration from the YAML `file`")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintln(stderr, "copper daemon: the configuration is only read from the config file")
+		return 2
+	}
+
+	conf, err := readDaemonConfig(*config)
+	if err != nil {
+		fmt.Fprintf(stderr, "copper daemon: %s\n", err)
+		return 2
+	}
+	return serve(ctx, "copper daemon", conf, stderr)
+}
+
+// serve forwards the traffic to the target through a copperproxy.Proxy until the context is done, and serves /healthz
+// and /coverage on the admin address. When stopped, the requests in flight are waited for, and then the reports are
+// written, the notifiers are notified if the traffic has errors, and the traffic is verified if asked to.
+func serve(ctx context.Context, command string, conf daemonConfig, stderr io.Writer) int {
+	if conf.Spec == "" || conf.Target == "" {
+		fmt.Fprintf(stderr, "%s: a spec and a target are needed\n", command)
+		return 2
+	}
+	targetURL, err := url.Parse(conf.Target)
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: could not parse target: %s\n", command, err)
+		return 2
+	}
+	specBytes, err := readSpec(conf.Spec)
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: could not read spec: %s\n", command, err)
+		return 2
+	}
+	p, err := copperproxy.NewProxy(targetURL, specBytes, conf.options()...)
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %s\n", command, err)
+		return 2
+	}
+
+	servers := map[*http.Server]net.Listener{}
+	l, err := net.Listen("tcp", conf.Listen)
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %s\n", command, err)
+		return 2
+	}
+	servers[&http.Server{Handler: p}] = l
+	fmt.Fprintf(stderr, "%s: forwarding %s to %s\n", command, l.Addr(), targetURL)
+	if conf.Admin != "" {
+		al, err := net.Listen("tcp", conf.Admin)
+		if err != nil {
+			_ = l.Close()
+			fmt.Fprintf(stderr, "%s: %s\n", command, err)
+			return 2
+		}
+		servers[&http.Server{Handler: adminHandler(p)}] = al
+		fmt.Fprintf(stderr, "%s: serving /healthz and /coverage on %s\n", command, al.Addr())
+	}
+
+	failed := make(chan error, len(servers))
+	for s, l := range servers {
+		go func() {
+			if err := s.Serve(l); !errors.Is(err, http.ErrServerClosed) {
+				failed <- err
+			}
+		}()
+	}
+	code := 0
+	select {
+	case <-ctx.Done():
+	case err := <-failed:
+		fmt.Fprintf(stderr, "%s: %s\n", command, err)
+		code = 2
+	}
+
+	shutdownCtx := context.Background()
+	if conf.ShutdownTimeout > 0 {
+		var cancel context.CancelFunc
+		shutdownCtx, cancel = context.WithTimeout(shutdownCtx, conf.ShutdownTimeout)
+		defer cancel()
+	}
+	for s := range servers {
+		if err := s.Shutdown(shutdownCtx); err != nil {
+			fmt.Fprintf(stderr, "%s: requests in flight are left out: %s\n", command, err)
+		}
+	}
+
+	if err := writeReports(p, conf); err != nil {
+		fmt.Fprintf(stderr, "%s: %s\n", command, err)
+		return 2
+	}
+	if notifiers := conf.notifiers(); len(notifiers) > 0 {
+		// The context is done by now, but the notifications are still sent.
+		notifyCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), notifyTimeout)
+		defer cancel()
+		if err := p.NotifyFailures(notifyCtx, notifiers...); err != nil {
+			fmt.Fprintf(stderr, "%s: %s\n", command, err)
+		}
+	}
+	if code != 0 {
+		return code
+	}
+	return verified(p.Verifier, command, conf.Verify, stderr)
+}
+
+// adminHandler serves the health of the proxy on /healthz, and the report of the traffic so far as JSON on /coverage.
+func adminHandler(p *copperproxy.Proxy) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = io.WriteString(w, "ok\n")
+	})
+	mux.HandleFunc("GET /coverage", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = p.Report().WriteJSON(w)
+	})
+	return mux
+}
+
+// writeReports writes the state and the reports of the proxy to the files that are configured, and creates the
+// directories of the files if they are missing.
+func writeReports(p *copperproxy.Proxy, conf daemonConfig) error {
+	sinks := []struct {
+		name  string
+		write func(io.Writer) error
+	}{
+		{conf.Reports.State, p.ExportState},
+		{conf.Reports.JSON, func(w io.Writer) error { return p.Report().WriteJSON(w) }},
+		{conf.Reports.HTML, func(w io.Writer) error { return p.Report().WriteHTML(w) }},
+		{conf.Reports.JUnit, p.WriteJUnit},
+		{conf.Reports.TAP, p.WriteTAP},
+	}
+	for _, s := range sinks {
+		if s.name == "" {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(s.name), 0o755); err != nil {
+			return err
+		}
+		if err := writeFile(s.name, nil, s.write); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readSpec reads the spec from the file, or downloads it if it is given as a URL.
+func readSpec(location string) ([]byte, error) {
+	if !isURL(location) {
+		return os.ReadFile(location)
+	}
+	res, err := http.Get(location)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not download %s: %s", location, res.Status)
+	}
+	return io.ReadAll(res.Body)
+}
+
+func isURL(location string) bool {
+	return strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://")
+}
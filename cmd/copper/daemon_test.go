@@ -0,0 +1,230 @@
+//go:build !copper_lite
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/callebjorkell/copper"
+	"github.com/callebjorkell/copper/notify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// freeAddress returns an address that was free a moment ago, since the commands do not report the ones they listen on.
+func freeAddress(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	require.NoError(t, l.Close())
+	return addr
+}
+
+func TestDaemon(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/other" {
+			close(started)
+			<-release
+			_, _ = w.Write([]byte(`{"thing": "yes"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"message": "pong"}`))
+	}))
+	defer backend.Close()
+
+	absSpec, err := filepath.Abs(spec)
+	require.NoError(t, err)
+	listen, admin := freeAddress(t), freeAddress(t)
+	dir := t.TempDir()
+	config := filepath.Join(dir, "copper.yaml")
+	require.NoError(t, os.WriteFile(config, []byte(`
+spec: `+absSpec+`
+target: `+backend.URL+`
+listen: `+listen+`
+admin: `+admin+`
+shutdownTimeout: 5s
+verify: true
+ignore:
+  responses:
+    GET /ping: ["500"]
+reports:
+  state: state.json
+  json: report.json
+  junit: out/junit.xml
+`), 0o600))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var stderr bytes.Buffer
+	code := make(chan int)
+	go func() {
+		code <- run(ctx, []string{"daemon", "-config", config}, nil, io.Discard, &stderr)
+	}()
+
+	require.Eventually(t, func() bool {
+		res, err := http.Get("http://" + admin + "/healthz")
+		if err != nil {
+			return false
+		}
+		_ = res.Body.Close()
+		return res.StatusCode == http.StatusOK
+	}, 5*time.Second, 10*time.Millisecond)
+
+	res, err := http.Get("http://" + listen + "/ping")
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+
+	res, err = http.Get("http://" + admin + "/coverage")
+	require.NoError(t, err)
+	var r copper.Report
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&r))
+	require.NoError(t, res.Body.Close())
+	assert.Equal(t, 2, r.Endpoints)
+	assert.Equal(t, 1, r.Checked)
+
+	// The request that is in flight when the daemon is stopped is waited for, and part of the final report.
+	inFlight := make(chan int)
+	go func() {
+		res, err := http.Get("http://" + listen + "/other")
+		if err != nil {
+			inFlight <- 0
+			return
+		}
+		_ = res.Body.Close()
+		inFlight <- res.StatusCode
+	}()
+	<-started
+	cancel()
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	assert.Equal(t, http.StatusOK, <-inFlight)
+	assert.Equal(t, 0, <-code, stderr.String())
+
+	b, err := os.ReadFile(filepath.Join(dir, "report.json"))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(b, &r))
+	assert.Equal(t, 2, r.Checked)
+	assert.FileExists(t, filepath.Join(dir, "state.json"))
+	assert.FileExists(t, filepath.Join(dir, "out", "junit.xml"))
+}
+
+func TestDaemon_Notify(t *testing.T) {
+	summaries := make(chan copper.Summary, 1)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var s copper.Summary
+		if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		summaries <- s
+	}))
+	defer webhook.Close()
+
+	absSpec, err := filepath.Abs(spec)
+	require.NoError(t, err)
+	admin := freeAddress(t)
+	config := filepath.Join(t.TempDir(), "copper.yaml")
+	require.NoError(t, os.WriteFile(config, []byte(`
+spec: `+absSpec+`
+target: http://localhost:8080
+listen: `+freeAddress(t)+`
+admin: `+admin+`
+verify: true
+notify:
+  webhook: `+webhook.URL+`
+`), 0o600))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var stderr bytes.Buffer
+	code := make(chan int)
+	go func() {
+		code <- run(ctx, []string{"daemon", "-config", config}, nil, io.Discard, &stderr)
+	}()
+	require.Eventually(t, func() bool {
+		res, err := http.Get("http://" + admin + "/healthz")
+		if err != nil {
+			return false
+		}
+		_ = res.Body.Close()
+		return res.StatusCode == http.StatusOK
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Nothing is covered, so the verification fails and the summary is posted.
+	cancel()
+	assert.Equal(t, 1, <-code, stderr.String())
+	select {
+	case s := <-summaries:
+		assert.Equal(t, 2, s.Endpoints)
+		assert.Equal(t, 0, s.Checked)
+		assert.NotEmpty(t, s.Errors)
+	default:
+		assert.Fail(t, "the webhook was not notified")
+	}
+}
+
+func TestDaemon_Config(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		file := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+		return file
+	}
+
+	t.Run("relative paths and defaults", func(t *testing.T) {
+		conf, err := readDaemonConfig(write("relative.yaml", "spec: spec.yaml\ntarget: http://localhost:8080\nreports:\n  html: out/report.html\n"))
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(dir, "spec.yaml"), conf.Spec)
+		assert.Equal(t, filepath.Join(dir, "out", "report.html"), conf.Reports.HTML)
+		assert.Equal(t, ":8081", conf.Listen)
+		assert.Equal(t, ":8082", conf.Admin)
+		assert.Equal(t, 10*time.Second, conf.ShutdownTimeout)
+		assert.Empty(t, conf.notifiers())
+	})
+
+	t.Run("notifiers", func(t *testing.T) {
+		conf, err := readDaemonConfig(write("notify.yaml", "notify:\n  webhook: https://example.com/hook\n  slack: https://hooks.slack.com/services/x\n"))
+		require.NoError(t, err)
+		assert.Equal(t, []copper.Notifier{
+			notify.Webhook{URL: "https://example.com/hook"},
+			notify.Slack{WebhookURL: "https://hooks.slack.com/services/x"},
+		}, conf.notifiers())
+	})
+
+	t.Run("spec url", func(t *testing.T) {
+		conf, err := readDaemonConfig(write("url.yaml", "spec: https://example.com/openapi.yaml\n"))
+		require.NoError(t, err)
+		assert.Equal(t, "https://example.com/openapi.yaml", conf.Spec)
+	})
+
+	tt := []struct {
+		name   string
+		args   []string
+		stderr string
+	}{
+		{name: "missing config", args: []string{"daemon", "-config", filepath.Join(dir, "missing.yaml")}, stderr: "missing.yaml"},
+		{name: "unknown field", args: []string{"daemon", "-config", write("unknown.yaml", "spec: spec.yaml\ntarget: http://localhost\nlisten_on: :80\n")}, stderr: "field listen_on not found"},
+		{name: "no target", args: []string{"daemon", "-config", write("target.yaml", "spec: spec.yaml\n")}, stderr: "a spec and a target are needed"},
+		{name: "missing spec", args: []string{"daemon", "-config", write("spec.yaml", "spec: missing.yaml\ntarget: http://localhost\n")}, stderr: "could not read spec"},
+		{name: "arguments", args: []string{"daemon", "spec.yaml"}, stderr: "only read from the config file"},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			assert.Equal(t, 2, run(context.Background(), tc.args, nil, &stdout, &stderr))
+			assert.Contains(t, stderr.String(), tc.stderr)
+		})
+	}
+}
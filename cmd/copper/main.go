@@ -0,0 +1,232 @@
+//go:build !copper_lite
+
+// Command copper verifies traffic against a spec outside of Go tests, for CI pipelines and stacks that are not written
+// in Go.
+//
+// The proxy command forwards the traffic of black-box test suites, written in any language, to the service under test.
+// Once it is stopped with an interrupt or SIGTERM, it waits for the requests in flight, writes the state and the report
+// of the traffic, and verifies it:
+//
+//	copper proxy -spec spec.yaml -target http://localhost:8080 [-listen :8081] [-admin :8082] [-o state.json]
+//	    [-report report.json] [-verify] [-validate-requests] [-shutdown-timeout 10s] [-notify-webhook url]
+//	    [-notify-slack url]
+//
+// The daemon command runs the proxy as a sidecar, with the configuration in a YAML file, and serves /healthz and
+// /coverage on the admin address:
+//
+//	copper daemon [-config copper.yaml]
+//
+// The report command writes a report of the proxy, or one written with Report.WriteJSON, in another format:
+//
+//	copper report [-format html] [-o report.html] report.json
+//
+// The merge command combines the states that separate Go test packages or CI shards have written with
+// Verifier.ExportState into one, and can verify the coverage of the union:
+//
+//	copper merge -spec spec.yaml [-o merged.json] [-verify] [-threshold percent] state.json...
+//
+// Flags can be given with one dash or two, like -spec or --spec.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/callebjorkell/copper"
+)
+
+const usage = `usage:
+  copper proxy -spec spec.yaml -target url [-listen address] [-admin address] [-o state.json] [-report report.json]
+      [-verify] [-validate-requests] [-shutdown-timeout duration] [-notify-webhook url] [-notify-slack url]
+  copper daemon [-config copper.yaml]
+  copper report [-format json|html] [-o file] [report.json]
+  copper merge -spec spec.yaml [-o merged.json] [-verify] [-threshold percent] state.json...`
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	os.Exit(run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run runs the command with the arguments, and returns the exit code: 0 on success, 1 if the verification fails and 2
+// if the command can not be run. The proxy command runs until the context is done.
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) > 0 {
+		switch args[0] {
+		case "proxy":
+			return proxy(ctx, args[1:], stderr)
+		case "daemon":
+			return daemon(ctx, args[1:], stderr)
+		case "report":
+			return report(args[1:], stdin, stdout, stderr)
+		case "merge":
+			return merge(args[1:], stdout, stderr)
+		}
+	}
+	fmt.Fprintln(stderr, usage)
+	return 2
+}
+
+// proxy serves a copperproxy.Proxy for the spec with the configuration of the flags.
+func proxy(ctx context.Context, args []string, stderr io.Writer) int {
+	fs := flag.NewFlagSet("proxy", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var conf daemonConfig
+	fs.StringVar(&conf.Spec, "spec", "", "verify the traffic against the spec in the `file` or at the URL")
+	fs.StringVar(&conf.Target, "target", "", "forward the traffic to the service at the `url`")
+	fs.StringVar(&conf.Listen, "listen", ":8081", "listen on the `address`")
+	fs.StringVar(&conf.Admin, "admin", "", "serve /healthz and /coverage on the `address`")
+	fs.DurationVar(&conf.ShutdownTimeout, "shutdown-timeout", 10*time.Second, "wait for requests in flight for the `duration` when stopped")
+	fs.StringVar(&conf.Reports.State, "o", "", "write the state to the `file` when stopped")
+	fs.StringVar(&conf.Reports.JSON, "report", "", "write the report as JSON to the `file` when stopped")
+	fs.BoolVar(&conf.Verify, "verify", false, "fail if the traffic has errors, or does not cover the spec")
+	fs.BoolVar(&conf.ValidateRequests, "validate-requests", false, "validate the requests as well as the responses")
+	fs.StringVar(&conf.Notify.Webhook, "notify-webhook", "", "post a summary as JSON to the `url` when stopped, if the traffic has errors")
+	fs.StringVar(&conf.Notify.Slack, "notify-slack", "", "post a summary to the Slack incoming webhook at the `url` when stopped, if the traffic has errors")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintln(stderr, "copper proxy: a spec and a target are needed")
+		return 2
+	}
+	return serve(ctx, "copper proxy", conf, stderr)
+}
+
+// report reads a report that was written as JSON, from the file or from stdin if none is given, and writes it in the
+// format.
+func report(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("report", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := fs.String("format", "html", "write the report as `json or html`")
+	output := fs.String("o", "", "write the report to the `file` rather than to stdout")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() > 1 {
+		fmt.Fprintln(stderr, "copper report: only one report can be given")
+		return 2
+	}
+
+	var write func(copper.Report, io.Writer) error
+	switch *format {
+	case "json":
+		write = copper.Report.WriteJSON
+	case "html":
+		write = copper.Report.WriteHTML
+	default:
+		fmt.Fprintf(stderr, "copper report: unknown format %q\n", *format)
+		return 2
+	}
+
+	in := stdin
+	if name := fs.Arg(0); name != "" && name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			fmt.Fprintf(stderr, "copper report: %s\n", err)
+			return 2
+		}
+		defer f.Close()
+		in = f
+	}
+	var r copper.Report
+	if err := json.NewDecoder(in).Decode(&r); err != nil {
+		fmt.Fprintf(stderr, "copper report: could not read report: %s\n", err)
+		return 2
+	}
+
+	if err := writeFile(*output, stdout, func(w io.Writer) error { return write(r, w) }); err != nil {
+		fmt.Fprintf(stderr, "copper report: %s\n", err)
+		return 2
+	}
+	return 0
+}
+
+// merge merges the states for the spec, writes the merged state and verifies it if asked to.
+func merge(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("merge", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	spec := fs.String("spec", "", "the states are for the spec in the `file`")
+	output := fs.String("o", "", "write the merged state to the `file` rather than to stdout")
+	verify := fs.Bool("verify", false, "fail if the merged state has errors, or does not cover the spec")
+	threshold := fs.Float64("threshold", 0, "verify the coverage against the `percent` rather than requiring full coverage")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *spec == "" || fs.NArg() == 0 {
+		fmt.Fprintln(stderr, "copper merge: a spec and at least one state are needed")
+		return 2
+	}
+
+	specBytes, err := os.ReadFile(*spec)
+	if err != nil {
+		fmt.Fprintf(stderr, "copper merge: could not read spec: %s\n", err)
+		return 2
+	}
+	var opts []copper.Option
+	if *threshold > 0 {
+		opts = append(opts, copper.WithCoverageThreshold(*threshold))
+	}
+	v, err := copper.NewVerifier(specBytes, opts...)
+	if err != nil {
+		fmt.Fprintf(stderr, "copper merge: %s\n", err)
+		return 2
+	}
+	for _, name := range fs.Args() {
+		if err := mergeFile(v, name); err != nil {
+			fmt.Fprintf(stderr, "copper merge: %s: %s\n", name, err)
+			return 2
+		}
+	}
+
+	if err := writeFile(*output, stdout, v.ExportState); err != nil {
+		fmt.Fprintf(stderr, "copper merge: %s\n", err)
+		return 2
+	}
+	return verified(v, "copper merge", *verify, stderr)
+}
+
+// mergeFile merges the state in the file into the Verifier.
+func mergeFile(v *copper.Verifier, name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return v.MergeState(f)
+}
+
+// writeFile writes to the file with the function, or to stdout if no file is given.
+func writeFile(name string, stdout io.Writer, write func(io.Writer) error) error {
+	if name == "" {
+		return write(stdout)
+	}
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// verified returns the exit code for the errors of the Verifier, if it is asked to verify them, and prints them.
+func verified(v *copper.Verifier, command string, verify bool, stderr io.Writer) int {
+	if !verify {
+		return 0
+	}
+	if err := v.CurrentError(); err != nil {
+		fmt.Fprintf(stderr, "%s: verification failed:\n%s\n", command, err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,218 @@
+//go:build !copper_lite
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/callebjorkell/copper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const spec = "../../testdata/thing-spec.yaml"
+
+func TestMerge(t *testing.T) {
+	f, err := os.ReadFile(spec)
+	require.NoError(t, err)
+	dir := t.TempDir()
+
+	shard := func(name string, paths ...string) string {
+		v, err := copper.NewVerifier(f)
+		require.NoError(t, err)
+		for _, p := range paths {
+			v.Record(&http.Response{
+				StatusCode: http.StatusOK,
+				Request:    httptest.NewRequest(http.MethodGet, p, nil),
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(`{"message": "pong", "thing": "yes"}`)),
+			})
+		}
+		file := filepath.Join(dir, name)
+		out, err := os.Create(file)
+		require.NoError(t, err)
+		defer out.Close()
+		require.NoError(t, v.ExportState(out))
+		return file
+	}
+	ping := shard("ping.json", "/ping")
+	other := shard("other.json", "/other")
+
+	tt := []struct {
+		name   string
+		args   []string
+		code   int
+		stderr string
+	}{
+		{name: "no command", args: nil, code: 2, stderr: "usage:"},
+		{name: "no spec", args: []string{"merge", ping}, code: 2, stderr: "a spec and at least one state"},
+		{name: "no states", args: []string{"merge", "-spec", spec}, code: 2, stderr: "a spec and at least one state"},
+		{name: "missing state", args: []string{"merge", "-spec", spec, filepath.Join(dir, "missing.json")}, code: 2, stderr: "missing.json"},
+		{name: "union", args: []string{"merge", "-verify", "-spec", spec, ping, other}, code: 0},
+		{name: "not covered", args: []string{"merge", "-verify", "-spec", spec, ping}, code: 1, stderr: "GET 200"},
+		{name: "threshold", args: []string{"merge", "-verify", "-threshold", "50", "-spec", spec, ping}, code: 0},
+		{name: "without verify", args: []string{"merge", "--spec", spec, ping}, code: 0},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			assert.Equal(t, tc.code, run(context.Background(), tc.args, nil, &stdout, &stderr), stderr.String())
+			assert.Contains(t, stderr.String(), tc.stderr)
+		})
+	}
+
+	t.Run("output", func(t *testing.T) {
+		merged := filepath.Join(dir, "merged.json")
+		var stdout, stderr bytes.Buffer
+		require.Equal(t, 0, run(context.Background(), []string{"merge", "-o", merged, "-spec", spec, ping, other}, nil, &stdout, &stderr))
+		assert.Empty(t, stdout.String())
+
+		v, err := copper.NewVerifier(f)
+		require.NoError(t, err)
+		b, err := os.ReadFile(merged)
+		require.NoError(t, err)
+		require.NoError(t, v.MergeState(bytes.NewReader(b)))
+		assert.Empty(t, v.Unchecked())
+	})
+}
+
+func TestProxy(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"message": "pong"}`))
+	}))
+	defer backend.Close()
+
+	addr := freeAddress(t)
+
+	dir := t.TempDir()
+	state, report := filepath.Join(dir, "state.json"), filepath.Join(dir, "report.json")
+	args := []string{
+		"proxy", "--spec", spec, "--target", backend.URL, "--listen", addr, "-o", state, "--report", report, "-verify",
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var stdout, stderr bytes.Buffer
+	code := make(chan int)
+	go func() {
+		code <- run(ctx, args, nil, &stdout, &stderr)
+	}()
+
+	var res *http.Response
+	require.Eventually(t, func() bool {
+		var err error
+		res, err = http.Get((&url.URL{Scheme: "http", Host: addr, Path: "/ping"}).String())
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	assert.JSONEq(t, `{"message": "pong"}`, string(body))
+
+	cancel()
+	assert.Equal(t, 1, <-code)
+	assert.Contains(t, stderr.String(), "/other: GET 200")
+	assert.Empty(t, stdout.String())
+
+	f, err := os.ReadFile(spec)
+	require.NoError(t, err)
+	v, err := copper.NewVerifier(f)
+	require.NoError(t, err)
+	b, err := os.ReadFile(state)
+	require.NoError(t, err)
+	require.NoError(t, v.MergeState(bytes.NewReader(b)))
+	assert.Len(t, v.Unchecked(), 1)
+
+	b, err = os.ReadFile(report)
+	require.NoError(t, err)
+	var r copper.Report
+	require.NoError(t, json.Unmarshal(b, &r))
+	assert.Equal(t, 2, r.Endpoints)
+	assert.Equal(t, 1, r.Checked)
+}
+
+func TestProxy_Arguments(t *testing.T) {
+	tt := []struct {
+		name   string
+		args   []string
+		stderr string
+	}{
+		{name: "no target", args: []string{"proxy", "-spec", spec}, stderr: "a spec and a target are needed"},
+		{name: "no spec", args: []string{"proxy", "-target", "http://localhost"}, stderr: "a spec and a target are needed"},
+		{name: "missing spec", args: []string{"proxy", "-target", "http://localhost", "-spec", "missing.yaml"}, stderr: "could not read spec"},
+		{name: "invalid target", args: []string{"proxy", "-target", "://", "-spec", spec}, stderr: "could not parse target"},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			assert.Equal(t, 2, run(context.Background(), tc.args, nil, &stdout, &stderr))
+			assert.Contains(t, stderr.String(), tc.stderr)
+		})
+	}
+}
+
+func TestReport(t *testing.T) {
+	r := copper.Report{
+		Title:     "thing test",
+		Version:   "1.0",
+		Endpoints: 1,
+		Checked:   1,
+		Coordinates: []copper.CoordinateReport{{
+			EndpointStatus: copper.EndpointStatus{
+				Endpoint: copper.Endpoint{Path: "/ping", Method: http.MethodGet, ResponseCode: "200"},
+				Checked:  true,
+				Hits:     1,
+			},
+			Errors: []string{},
+		}},
+		Waived: []copper.WaivedEndpoint{},
+		Errors: []string{},
+	}
+	var in bytes.Buffer
+	require.NoError(t, r.WriteJSON(&in))
+	dir := t.TempDir()
+	file := filepath.Join(dir, "report.json")
+	require.NoError(t, os.WriteFile(file, in.Bytes(), 0o600))
+
+	tt := []struct {
+		name     string
+		args     []string
+		code     int
+		contains string
+	}{
+		{name: "html from stdin", args: []string{"report", "--format", "html"}, code: 0, contains: "<td>/ping</td>"},
+		{name: "html by default", args: []string{"report", file}, code: 0, contains: "<td>/ping</td>"},
+		{name: "json", args: []string{"report", "-format", "json", file}, code: 0, contains: `"path": "/ping"`},
+		{name: "unknown format", args: []string{"report", "-format", "pdf", file}, code: 2, contains: `unknown format "pdf"`},
+		{name: "missing report", args: []string{"report", filepath.Join(dir, "missing.json")}, code: 2, contains: "missing.json"},
+		{name: "two reports", args: []string{"report", file, file}, code: 2, contains: "only one report"},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			assert.Equal(t, tc.code, run(context.Background(), tc.args, bytes.NewReader(in.Bytes()), &stdout, &stderr))
+			assert.Contains(t, stdout.String()+stderr.String(), tc.contains)
+		})
+	}
+
+	t.Run("output", func(t *testing.T) {
+		out := filepath.Join(dir, "report.html")
+		var stdout, stderr bytes.Buffer
+		require.Equal(t, 0, run(context.Background(), []string{"report", "-o", out, file}, nil, &stdout, &stderr))
+		assert.Empty(t, stdout.String())
+		b, err := os.ReadFile(out)
+		require.NoError(t, err)
+		assert.Contains(t, string(b), "1 of 1 coordinates checked (100.0%)")
+	})
+}
@@ -0,0 +1,115 @@
+package copper
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+// PathCollision is a pair of paths of the spec that can match the same URL with the same method, where neither is
+// more specific than the other. Precedence still picks one of them, but it is likely not the one that the author of
+// the spec meant for every URL, which makes the coverage of the two paths depend on which URLs the tests happen to use.
+type PathCollision struct {
+	// Paths are the two paths, in the order of the spec.
+	Paths [2]string `json:"paths"`
+	// Methods are the methods that both paths have.
+	Methods []string `json:"methods"`
+	// Example is a URL path that both paths match, which the first path in the order of precedence gets.
+	Example string `json:"example"`
+}
+
+func (c PathCollision) String() string {
+	return fmt.Sprintf("%s and %s both match %s for %s", c.Paths[0], c.Paths[1], c.Example, strings.Join(c.Methods, ", "))
+}
+
+// PathCollisions returns the paths of the spec that collide, in the order of the spec. The spec is not rejected for
+// them, but the ambiguity should be resolved by changing one of the paths.
+func (v *Verifier) PathCollisions() []PathCollision {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	return slices.Clone(v.endpoints.collisions)
+}
+
+// findCollisions compares every pair of paths in the spec. A pair with a static segment in one and a template in the
+// other, and the other way around further on, like /a/{b}/c and /a/x/{d}, collides, as do paths that only differ by
+// the names of their parameters. A static path next to a template that covers it, like /things/export and
+// /things/{id}, is what precedence is for, and is not a collision.
+func findCollisions(doc *v3.Document) []PathCollision {
+	if doc.Paths == nil {
+		return nil
+	}
+
+	var collisions []PathCollision
+	keys := slices.Collect(doc.Paths.PathItems.KeysFromOldest())
+	for i, a := range keys {
+		for _, b := range keys[i+1:] {
+			example, ok := collide(a, b)
+			if !ok {
+				continue
+			}
+			methods := sharedMethods(doc.Paths.PathItems.GetOrZero(a), doc.Paths.PathItems.GetOrZero(b))
+			if len(methods) == 0 {
+				// A request is passed over to the path that has its method, so these never compete.
+				continue
+			}
+			collisions = append(collisions, PathCollision{Paths: [2]string{a, b}, Methods: methods, Example: example})
+		}
+	}
+	return collisions
+}
+
+// collide checks if the paths can match the same URL with neither being more specific, and returns a URL path that
+// both match if they do.
+func collide(a, b string) (string, bool) {
+	aSegs := strings.Split(strings.Trim(a, "/"), "/")
+	bSegs := strings.Split(strings.Trim(b, "/"), "/")
+	if len(aSegs) != len(bSegs) {
+		return "", false
+	}
+
+	var aStatic, bStatic bool
+	example := make([]string, len(aSegs))
+	for i := range aSegs {
+		aTemplate, bTemplate := isTemplateSegment(aSegs[i]), isTemplateSegment(bSegs[i])
+		switch {
+		case !aTemplate && !bTemplate:
+			if aSegs[i] != bSegs[i] {
+				return "", false
+			}
+			example[i] = aSegs[i]
+		case !aTemplate:
+			aStatic = true
+			example[i] = aSegs[i]
+		case !bTemplate:
+			bStatic = true
+			example[i] = bSegs[i]
+		default:
+			example[i] = aSegs[i]
+		}
+	}
+
+	// When only one of them has static segments where the other has templates, that one is more specific.
+	if aStatic != bStatic {
+		return "", false
+	}
+	return "/" + strings.Join(example, "/"), true
+}
+
+// sharedMethods returns the methods that both path items have operations for.
+func sharedMethods(a, b *v3.PathItem) []string {
+	if a == nil || b == nil {
+		return nil
+	}
+
+	var methods []string
+	bOps := b.GetOperations()
+	for method := range a.GetOperations().KeysFromOldest() {
+		if bOps.GetOrZero(method) != nil {
+			methods = append(methods, strings.ToUpper(method))
+		}
+	}
+	return methods
+}
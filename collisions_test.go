@@ -0,0 +1,52 @@
+package copper
+
+import (
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPathCollisions(t *testing.T) {
+	f, err := os.ReadFile("testdata/collision-spec.yaml")
+	require.NoError(t, err)
+
+	v, err := NewVerifier(f)
+	require.NoError(t, err)
+
+	collisions := v.PathCollisions()
+	assert.Equal(t, []PathCollision{
+		{Paths: [2]string{"/a/{b}/c", "/a/x/{d}"}, Methods: []string{http.MethodGet}, Example: "/a/x/c"},
+	}, collisions)
+	assert.Equal(t, "/a/{b}/c and /a/x/{d} both match /a/x/c for GET", collisions[0].String())
+
+	// Resetting the verifier keeps the diagnostics of the spec.
+	v.Reset()
+	assert.Len(t, v.PathCollisions(), 1)
+}
+
+func TestCollide(t *testing.T) {
+	tt := []struct {
+		name    string
+		a, b    string
+		example string
+		collide bool
+	}{
+		{"crossing templates", "/a/{b}/c", "/a/x/{d}", "/a/x/c", true},
+		{"parameter names only", "/things/{id}", "/things/{name}", "/things/{id}", true},
+		{"static path covered by a template", "/things/export", "/things/{id}", "", false},
+		{"template covering a static path", "/{kind}/export", "/things/export", "", false},
+		{"different static segments", "/things/{id}", "/items/{id}", "", false},
+		{"different lengths", "/things/{id}", "/things/{id}/parts", "", false},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			example, ok := collide(tc.a, tc.b)
+			assert.Equal(t, tc.collide, ok)
+			assert.Equal(t, tc.example, example)
+		})
+	}
+}
@@ -0,0 +1,304 @@
+package copper
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/orderedmap"
+)
+
+// templateParams matches the parameters of path templates, which are renamed freely between specs.
+var templateParams = regexp.MustCompile(`\{[^}]*}`)
+
+// CheckCompatibility checks, without any traffic, that the provider spec has everything that the consumer spec relies
+// on: every operation and response of the consumer, with schemas that are compatible. What the provider responds with
+// has to be accepted by the schemas of the consumer, and what the consumer sends has to be accepted by the schemas of
+// the provider. Paths are matched by their templates, so the parameters can have different names in the two specs.
+//
+// The returned error joins a VerificationError for every problem: ErrNotPartOfSpec for operations and responses that
+// the provider lacks, ErrRequestInvalid for parameters and request bodies, and ErrResponseInvalid for responses.
+// Schemas are compared by their types, properties, enums and nullability, and schemas that use allOf, anyOf or oneOf
+// are not compared further. Nil is returned if the specs are compatible.
+func CheckCompatibility(consumerSpec, providerSpec []byte) error {
+	consumer, err := NewVerifier(consumerSpec)
+	if err != nil {
+		return fmt.Errorf("could not read consumer spec: %w", err)
+	}
+	provider, err := NewVerifier(providerSpec)
+	if err != nil {
+		return fmt.Errorf("could not read provider spec: %w", err)
+	}
+
+	if consumer.model.Paths == nil {
+		return nil
+	}
+	providerPaths := make(map[string]string)
+	if provider.model.Paths != nil {
+		for path := range provider.model.Paths.PathItems.KeysFromOldest() {
+			providerPaths[templateParams.ReplaceAllString(path, "{}")] = path
+		}
+	}
+
+	var errs []error
+	for path, item := range consumer.model.Paths.PathItems.FromOldest() {
+		providerPath, ok := providerPaths[templateParams.ReplaceAllString(path, "{}")]
+		var providerItem *v3.PathItem
+		if ok {
+			providerItem = provider.model.Paths.PathItems.GetOrZero(providerPath)
+		}
+		for method, op := range item.GetOperations().FromOldest() {
+			method = strings.ToUpper(method)
+			var providerOp *v3.Operation
+			if ok {
+				providerOp = providerItem.GetOperations().GetOrZero(strings.ToLower(method))
+			}
+			if providerOp == nil {
+				errs = append(errs, joinError(ErrNotPartOfSpec, fmt.Errorf("%s %s is not in the provider spec", method, path)))
+				continue
+			}
+
+			// Path parameters are matched by their place in the path, since their names can differ.
+			renamed := make(map[string]string)
+			names := templateParams.FindAllString(path, -1)
+			for i, name := range templateParams.FindAllString(providerPath, -1) {
+				renamed[strings.Trim(name, "{}")] = strings.Trim(names[i], "{}")
+			}
+			params := slices.Concat(item.Parameters, op.Parameters)
+			providerParams := slices.Concat(providerItem.Parameters, providerOp.Parameters)
+			errs = append(errs, compareOperations(method, path, op, providerOp, params, providerParams, renamed)...)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// compareOperations compares the parameters, request body and responses of an operation of the consumer with the one
+// of the provider. The path parameters of the provider are renamed to the ones of the consumer.
+func compareOperations(method, path string, consumer, provider *v3.Operation, params, providerParams []*v3.Parameter,
+	renamed map[string]string) []error {
+	var errs []error
+	prefix := fmt.Sprintf("%s %s", method, path)
+
+	var req compatibility
+	req.parameters(params, providerParams, renamed)
+	if consumer.RequestBody != nil || provider.RequestBody != nil {
+		req.requestBody(consumer.RequestBody, provider.RequestBody)
+	}
+	for _, err := range req.errs {
+		errs = append(errs, joinError(ErrRequestInvalid, fmt.Errorf("%s: %w", prefix, err)))
+	}
+
+	if consumer.Responses == nil {
+		return errs
+	}
+	codes := slices.Collect(consumer.Responses.Codes.KeysFromOldest())
+	if consumer.Responses.Default != nil {
+		codes = append(codes, "default")
+	}
+	for _, code := range codes {
+		response := consumer.Responses.Default
+		if code != "default" {
+			response = consumer.Responses.Codes.GetOrZero(code)
+		}
+		providerResponse := matchingResponse(provider, code)
+		if providerResponse == nil {
+			errs = append(errs, joinError(ErrNotPartOfSpec, fmt.Errorf("%s %s response is not in the provider spec", prefix, code)))
+			continue
+		}
+
+		res := compatibility{sender: "provider", receiver: "consumer"}
+		res.content(response.Content, providerResponse.Content, "")
+		for _, err := range res.errs {
+			errs = append(errs, joinError(ErrResponseInvalid, fmt.Errorf("%s %s response: %w", prefix, code, err)))
+		}
+	}
+	return errs
+}
+
+// matchingResponse returns the response of the operation for the response code of another spec: the same code, the
+// range of the code or else the default response.
+func matchingResponse(op *v3.Operation, code string) *v3.Response {
+	if op.Responses == nil {
+		return nil
+	}
+	if response := op.Responses.Codes.GetOrZero(code); response != nil {
+		return response
+	}
+	if _, err := strconv.Atoi(code); err == nil {
+		if response := op.Responses.Codes.GetOrZero(code[:1] + "XX"); response != nil {
+			return response
+		}
+	}
+	return op.Responses.Default
+}
+
+// compatibility collects the problems with what a sender can send to a receiver.
+type compatibility struct {
+	// sender and receiver name the sides, "consumer" or "provider", for the messages.
+	sender, receiver string
+	errs             []error
+	seen             map[[2]*base.Schema]bool
+}
+
+func (c *compatibility) addf(format string, args ...any) {
+	c.errs = append(c.errs, fmt.Errorf(format, args...))
+}
+
+// parameters checks that the consumer sends the parameters that the provider requires, with values that the provider
+// accepts. Path parameters of the provider are looked up by the names that the consumer has for them.
+func (c *compatibility) parameters(consumer, provider []*v3.Parameter, renamed map[string]string) {
+	c.sender, c.receiver = "consumer", "provider"
+	for _, p := range provider {
+		name := p.Name
+		if p.In == "path" && renamed[name] != "" {
+			name = renamed[name]
+		}
+		i := slices.IndexFunc(consumer, func(cp *v3.Parameter) bool { return cp.Name == name && cp.In == p.In })
+		required := p.Required != nil && *p.Required
+		switch {
+		case i < 0 && required:
+			c.addf("%s parameter %s is required by the provider, but not sent by the consumer", p.In, name)
+		case i < 0:
+		case required && (consumer[i].Required == nil || !*consumer[i].Required):
+			c.addf("%s parameter %s is required by the provider, but optional for the consumer", p.In, name)
+			fallthrough
+		default:
+			c.proxy(consumer[i].Schema, p.Schema, pointer("parameters", p.In, name))
+		}
+	}
+}
+
+// requestBody checks that the provider accepts the request body of the consumer.
+func (c *compatibility) requestBody(consumer, provider *v3.RequestBody) {
+	c.sender, c.receiver = "consumer", "provider"
+	switch {
+	case provider == nil:
+		c.addf("request body of the consumer is not accepted by the provider")
+	case consumer == nil:
+		if provider.Required != nil && *provider.Required {
+			c.addf("request body is required by the provider, but not sent by the consumer")
+		}
+	default:
+		c.content(consumer.Content, provider.Content, pointer("requestBody"))
+	}
+}
+
+// content checks that every media type that the consumer uses is one that the provider has, with a compatible schema.
+// Media types that only the provider has are left out, since the consumer never negotiates them.
+func (c *compatibility) content(consumer, provider *orderedmap.Map[string, *v3.MediaType], location string) {
+	for name, mt := range consumer.FromOldest() {
+		other := provider.GetOrZero(name)
+		if other == nil {
+			c.addf("%s is used by the consumer, but not by the provider", name)
+			continue
+		}
+		if c.sender == "consumer" {
+			c.proxy(mt.Schema, other.Schema, location+pointer("content", name, "schema"))
+		} else {
+			c.proxy(other.Schema, mt.Schema, location+pointer("content", name, "schema"))
+		}
+	}
+}
+
+func (c *compatibility) proxy(sender, receiver *base.SchemaProxy, location string) {
+	if receiver == nil || sender == nil {
+		// A missing schema on the receiving side accepts anything, and one on the sending side can not be compared.
+		return
+	}
+	if s, r := sender.Schema(), receiver.Schema(); s != nil && r != nil {
+		c.schema(s, r, location)
+	}
+}
+
+// schema checks that every value of the sender schema is accepted by the receiver schema.
+func (c *compatibility) schema(sender, receiver *base.Schema, location string) {
+	if c.seen == nil {
+		c.seen = make(map[[2]*base.Schema]bool)
+	}
+	if c.seen[[2]*base.Schema{sender, receiver}] {
+		return
+	}
+	c.seen[[2]*base.Schema{sender, receiver}] = true
+
+	if len(sender.AllOf)+len(sender.AnyOf)+len(sender.OneOf)+len(receiver.AllOf)+len(receiver.AnyOf)+len(receiver.OneOf) > 0 {
+		return
+	}
+	where := displayLocation(location)
+
+	senderTypes, receiverTypes := schemaTypes(sender), schemaTypes(receiver)
+	if len(receiverTypes) > 0 {
+		if len(senderTypes) == 0 {
+			c.addf("%s: the %s allows any type, but the %s only accepts %s", where, c.sender, c.receiver,
+				strings.Join(receiverTypes, ", "))
+		}
+		for _, t := range senderTypes {
+			if !slices.Contains(receiverTypes, t) && (t != "integer" || !slices.Contains(receiverTypes, "number")) {
+				c.addf("%s: the %s sends %s, but the %s only accepts %s", where, c.sender, t, c.receiver,
+					strings.Join(receiverTypes, ", "))
+			}
+		}
+	}
+	if schemaNullable(sender) && !schemaNullable(receiver) {
+		c.addf("%s: the %s can send null, but the %s does not accept it", where, c.sender, c.receiver)
+	}
+
+	if accepted := enumValues(receiver); len(accepted) > 0 {
+		if len(enumValues(sender)) == 0 {
+			c.addf("%s: the %s only accepts %s, but the %s has no enum", where, c.receiver, strings.Join(accepted, ", "),
+				c.sender)
+		}
+		for _, value := range enumValues(sender) {
+			if !slices.Contains(accepted, value) {
+				c.addf("%s: the %s can send %s, which the %s does not accept", where, c.sender, value, c.receiver)
+			}
+		}
+	}
+
+	for _, name := range receiver.Required {
+		if !slices.Contains(sender.Required, name) {
+			c.addf("%s: property %s is required by the %s, but optional for the %s", where, name, c.receiver, c.sender)
+		}
+	}
+	closed := receiver.AdditionalProperties != nil && receiver.AdditionalProperties.IsB() && !receiver.AdditionalProperties.B
+	for name, p := range sender.Properties.FromOldest() {
+		other := receiver.Properties.GetOrZero(name)
+		if other == nil {
+			if closed {
+				c.addf("%s: property %s is sent by the %s, but not accepted by the %s", where, name, c.sender, c.receiver)
+			}
+			continue
+		}
+		c.proxy(p, other, location+pointer("properties", name))
+	}
+
+	if sender.Items != nil && sender.Items.IsA() && receiver.Items != nil && receiver.Items.IsA() {
+		c.proxy(sender.Items.A, receiver.Items.A, location+pointer("items"))
+	}
+}
+
+// schemaTypes returns the types of the schema, without null.
+func schemaTypes(s *base.Schema) []string {
+	return slices.DeleteFunc(slices.Clone(s.Type), func(t string) bool { return t == "null" })
+}
+
+// schemaNullable checks if the schema accepts null, with nullable in OpenAPI 3.0 or the null type in 3.1.
+func schemaNullable(s *base.Schema) bool {
+	return (s.Nullable != nil && *s.Nullable) || slices.Contains(s.Type, "null")
+}
+
+// enumValues returns the values that the schema is limited to, with enum or with the const of OpenAPI 3.1.
+func enumValues(s *base.Schema) []string {
+	values := make([]string, 0, len(s.Enum)+1)
+	for _, n := range s.Enum {
+		values = append(values, n.Value)
+	}
+	if s.Const != nil {
+		values = append(values, s.Const.Value)
+	}
+	return values
+}
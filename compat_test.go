@@ -0,0 +1,50 @@
+package copper
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckCompatibility(t *testing.T) {
+	consumer, err := os.ReadFile("testdata/compat-consumer-spec.yaml")
+	require.NoError(t, err)
+	provider, err := os.ReadFile("testdata/compat-provider-spec.yaml")
+	require.NoError(t, err)
+
+	t.Run("same spec", func(t *testing.T) {
+		assert.NoError(t, CheckCompatibility(consumer, consumer))
+	})
+
+	t.Run("different specs", func(t *testing.T) {
+		err := CheckCompatibility(consumer, provider)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrNotPartOfSpec)
+		assert.ErrorIs(t, err, ErrRequestInvalid)
+		assert.ErrorIs(t, err, ErrResponseInvalid)
+
+		expected := []string{
+			"request invalid: GET /things/{id}: header parameter X-Tenant is required by the provider, but not sent by the consumer",
+			"response invalid: GET /things/{id} 200 response: /content/application~1json/schema: property name is required by the consumer, but optional for the provider",
+			"response invalid: GET /things/{id} 200 response: /content/application~1json/schema: property owner is sent by the provider, but not accepted by the consumer",
+			"response invalid: GET /things/{id} 200 response: /content/application~1json/schema/properties/name: the provider can send null, but the consumer does not accept it",
+			"response invalid: GET /things/{id} 200 response: /content/application~1json/schema/properties/status: the provider can send archived, which the consumer does not accept",
+			"not part of spec: GET /other is not in the provider spec",
+		}
+		assert.ElementsMatch(t, expected, strings.Split(err.Error(), "\n"))
+	})
+
+	t.Run("wider request schema", func(t *testing.T) {
+		// The provider accepts any number for the size, which includes the integers that the consumer sends.
+		err := CheckCompatibility(consumer, provider)
+		assert.NotContains(t, err.Error(), "PUT")
+	})
+
+	t.Run("invalid spec", func(t *testing.T) {
+		assert.ErrorContains(t, CheckCompatibility([]byte("nope"), provider), "could not read consumer spec")
+		assert.ErrorContains(t, CheckCompatibility(consumer, []byte("nope")), "could not read provider spec")
+	})
+}
@@ -0,0 +1,72 @@
+// Package copperproxy is a reverse proxy that records the traffic it forwards into a copper.Verifier, so that black-box
+// test suites that are not written in Go, like Postman collections, k6 scripts or Cypress tests, can have their
+// coverage of the spec and the validity of the responses verified by pointing them at the proxy rather than the
+// service.
+package copperproxy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+
+	"github.com/callebjorkell/copper"
+	"github.com/callebjorkell/copper/internal/capture"
+)
+
+// Proxy is an http.Handler that forwards every request to the target, and records the request and the response to it
+// into the Verifier that it embeds. It is safe for concurrent use.
+type Proxy struct {
+	proxy *httputil.ReverseProxy
+	*copper.Verifier
+}
+
+var _ http.Handler = (*Proxy)(nil)
+
+// NewProxy returns a Proxy that forwards to the target, and verifies the traffic against the spec. The options are the
+// same as for copper.WrapClient, since the proxy is a client of the target, so requests are only validated with
+// copper.WithRequestValidation. Paths of requests are joined to the path of the target, and the Host header is set to
+// the host of the target, with the original one in X-Forwarded-Host.
+func NewProxy(target *url.URL, spec []byte, opts ...copper.Option) (*Proxy, error) {
+	verifier, err := copper.NewVerifier(spec, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("could not create verifier: %w", err)
+	}
+
+	return &Proxy{
+		proxy: &httputil.ReverseProxy{
+			Rewrite: func(r *httputil.ProxyRequest) {
+				r.SetURL(target)
+				r.SetXForwarded()
+			},
+			ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+				if failed, ok := r.Context().Value(failedKey{}).(*bool); ok {
+					*failed = true
+				}
+				http.Error(w, fmt.Sprintf("could not reach %s: %s", target.Host, err), http.StatusBadGateway)
+			},
+		},
+		Verifier: verifier,
+	}, nil
+}
+
+// failedKey is the context key of the flag that the error handler sets when the target could not be reached.
+type failedKey struct{}
+
+// ServeHTTP forwards the request to the target, and then records it with the response. Requests that the target can not
+// be reached for are answered with 502 Bad Gateway, but not recorded, since the response is not the one of the target.
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := capture.BufferRequest(r); err != nil {
+		http.Error(w, "could not read request body", http.StatusBadRequest)
+		return
+	}
+
+	var failed bool
+	cw := capture.NewWriter(w)
+	p.proxy.ServeHTTP(cw, r.WithContext(context.WithValue(r.Context(), failedKey{}, &failed)))
+	if failed {
+		return
+	}
+	p.Record(cw.Response(r))
+}
@@ -0,0 +1,106 @@
+package copperproxy
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/callebjorkell/copper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newBackend(t *testing.T) *url.URL {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/things/export", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/csv")
+		_, _ = w.Write([]byte("id\n1\n"))
+	})
+	mux.HandleFunc("GET /api/things/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.PathValue("id") == "broken" {
+			_, _ = w.Write([]byte(`{"name": "no id"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id": "` + r.PathValue("id") + `"}`))
+	})
+	mux.HandleFunc("PUT /api/things/{id}", func(w http.ResponseWriter, r *http.Request) {
+		// The backend must still get the body.
+		body, _ := io.ReadAll(r.Body)
+		if len(body) == 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	target, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	return target
+}
+
+func TestProxy(t *testing.T) {
+	f, err := os.ReadFile("../testdata/route-spec.yaml")
+	require.NoError(t, err)
+
+	serve := func(h http.Handler, method, url, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("traffic is covered", func(t *testing.T) {
+		p, err := NewProxy(newBackend(t), f, copper.WithRequestValidation())
+		require.NoError(t, err)
+
+		rec := serve(p, http.MethodGet, "/api/things/abc", "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"id": "abc"}`, rec.Body.String())
+		assert.Equal(t, http.StatusOK, serve(p, http.MethodGet, "/api/things/export", "").Code)
+		assert.Equal(t, http.StatusNoContent, serve(p, http.MethodPut, "/api/things/abc", `{"name": "thing"}`).Code)
+
+		assert.NoError(t, p.CurrentError())
+	})
+
+	t.Run("invalid responses are reported", func(t *testing.T) {
+		p, err := NewProxy(newBackend(t), f, copper.WithoutFullCoverage())
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, serve(p, http.MethodGet, "/api/things/broken", "").Code)
+		assert.ErrorIs(t, p.CurrentError(), copper.ErrResponseInvalid)
+	})
+
+	t.Run("invalid requests are reported", func(t *testing.T) {
+		p, err := NewProxy(newBackend(t), f, copper.WithRequestValidation(), copper.WithoutFullCoverage())
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusNoContent, serve(p, http.MethodPut, "/api/things/abc", `{"title": "thing"}`).Code)
+		assert.ErrorIs(t, p.CurrentError(), copper.ErrRequestInvalid)
+	})
+
+	t.Run("unreachable target", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+		target, err := url.Parse(server.URL)
+		require.NoError(t, err)
+		p, err := NewProxy(target, f, copper.WithoutFullCoverage())
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusBadGateway, serve(p, http.MethodGet, "/api/things/abc", "").Code)
+		assert.NoError(t, p.CurrentError())
+		assert.Len(t, p.Unchecked(), 3)
+	})
+
+	t.Run("invalid spec", func(t *testing.T) {
+		_, err := NewProxy(newBackend(t), []byte("borked"))
+		assert.Error(t, err)
+	})
+}
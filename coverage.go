@@ -0,0 +1,30 @@
+package copper
+
+// WithCoverageThreshold is a functional Option for requiring a percentage of the coordinates of the spec to be checked,
+// rather than all of them, so that copper can be adopted for an existing service and the coverage ramped up gradually.
+// The coordinates that have not been checked are only reported when the coverage is below the threshold, and the
+// coverage of security schemes and request media types is not required.
+func WithCoverageThreshold(percent float64) Option {
+	return func(c *config) {
+		c.coverageThreshold = percent
+	}
+}
+
+// Coverage returns the percentage of the coordinates of the spec that have been checked. A spec without any coordinates
+// is fully covered.
+func (v *Verifier) Coverage() float64 {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	return v.endpoints.Coverage()
+}
+
+// requiresCoverage returns whether the coordinates that have not been checked are errors, which they are when full
+// coverage is required, or when the coverage is below the threshold of WithCoverageThreshold.
+func (v *Verifier) requiresCoverage() bool {
+	conf := v.endpoints.conf
+	if conf.disableFullCoverage {
+		return false
+	}
+	return conf.coverageThreshold == 0 || v.endpoints.Coverage() < conf.coverageThreshold
+}
@@ -0,0 +1,80 @@
+package copper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithCoverageThreshold(t *testing.T) {
+	f, err := os.ReadFile("testdata/filters-spec.yaml")
+	require.NoError(t, err)
+
+	record := func(v *Verifier, status int, target string) {
+		v.Record(&http.Response{StatusCode: status, Request: httptest.NewRequest(http.MethodGet, target, nil)})
+	}
+
+	t.Run("coverage", func(t *testing.T) {
+		v, err := NewVerifier(f)
+		require.NoError(t, err)
+		assert.Equal(t, 0.0, v.Coverage())
+
+		record(v, 204, "/things")
+		record(v, 204, "/things/a")
+		record(v, 404, "/things/b")
+		assert.Equal(t, 50.0, v.Coverage())
+	})
+
+	t.Run("undocumented response codes", func(t *testing.T) {
+		spec, err := os.ReadFile("testdata/thing-spec.yaml")
+		require.NoError(t, err)
+		v, err := NewVerifier(spec, WithCoverageThreshold(60))
+		require.NoError(t, err)
+
+		record(v, 200, "/ping")
+		coverage := v.Coverage()
+		for status := 418; status <= 422; status++ {
+			record(v, status, "/ping")
+		}
+		assert.Equal(t, coverage, v.Coverage())
+		assert.ErrorIs(t, v.CurrentError(), ErrNotChecked)
+		assert.Len(t, v.Unchecked(), len(v.Endpoints())-1)
+	})
+
+	t.Run("below and above the threshold", func(t *testing.T) {
+		v, err := NewVerifier(f, WithCoverageThreshold(50), WithRequestValidation())
+		require.NoError(t, err)
+
+		record(v, 204, "/things")
+		record(v, 204, "/things/a")
+		assert.ErrorIs(t, v.CurrentError(), ErrNotChecked)
+		assert.ErrorContains(t, v.CurrentError(), "which leaves the coverage at 33.3%, below the threshold of 50%")
+
+		record(v, 404, "/things/b")
+		assert.NoError(t, v.CurrentError(), "neither the coordinates nor the security scheme need to be covered")
+		assert.Len(t, v.Unchecked(), 3)
+	})
+
+	t.Run("violations are reported above the threshold", func(t *testing.T) {
+		v, err := NewVerifier(f, WithCoverageThreshold(10))
+		require.NoError(t, err)
+
+		record(v, 204, "/things")
+		record(v, 500, "/things")
+		assert.ErrorIs(t, v.CurrentError(), ErrResponseInvalid)
+		assert.NotErrorIs(t, v.CurrentError(), ErrNotChecked)
+	})
+
+	t.Run("invalid thresholds", func(t *testing.T) {
+		_, err := NewVerifier(f, WithCoverageThreshold(150))
+		assert.ErrorIs(t, err, ErrInvalidOptions)
+		assert.ErrorContains(t, err, "WithCoverageThreshold is given 150, but the percentage must be between 0 and 100")
+
+		_, err = NewVerifier(f, WithCoverageThreshold(80), WithoutFullCoverage())
+		assert.ErrorContains(t, err, "WithCoverageThreshold has no effect together with WithoutFullCoverage")
+	})
+}
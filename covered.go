@@ -0,0 +1,101 @@
+//go:build !copper_lite
+
+package copper
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CoveredSpec writes the spec as YAML, with only the paths, operations and responses that have been checked. Everything
+// else, like the components and the info, is written as it is in the spec, so the document describes the verified
+// surface of the API, for generating clients or docs that are limited to it. Operations that are discriminated by a
+// query parameter are kept whole if any of their values have been checked.
+func (v *Verifier) CoveredSpec(w io.Writer) error {
+	var root yaml.Node
+	if err := yaml.Unmarshal(v.spec, &root); err != nil {
+		return fmt.Errorf("could not parse spec: %w", err)
+	}
+	if len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return errors.New("could not parse spec: the document is not an object")
+	}
+
+	// The responses that have been checked, by path and method.
+	covered := make(map[string]map[string]map[string]bool)
+	v.mu.Lock()
+	for _, e := range v.endpoints.All() {
+		if !v.endpoints.IsChecked(e.Path, e.Method, e.ResponseCode) {
+			continue
+		}
+		path, _, _ := strings.Cut(e.Path, "?")
+		if covered[path] == nil {
+			covered[path] = make(map[string]map[string]bool)
+		}
+		method := strings.ToLower(e.Method)
+		if covered[path][method] == nil {
+			covered[path][method] = make(map[string]bool)
+		}
+		covered[path][method][e.ResponseCode] = true
+	}
+	v.mu.Unlock()
+
+	if paths := mappingValue(root.Content[0], "paths"); paths != nil && paths.Kind == yaml.MappingNode {
+		filterMapping(paths, func(path string, item *yaml.Node) bool {
+			methods, ok := covered[path]
+			if !ok {
+				return false
+			}
+			if item.Kind != yaml.MappingNode || mappingValue(item, "$ref") != nil {
+				// A referenced path item can not be filtered, and is kept as it is.
+				return true
+			}
+			filterMapping(item, func(key string, op *yaml.Node) bool {
+				if !isMethod(key) {
+					return true
+				}
+				codes, ok := methods[key]
+				if !ok {
+					return false
+				}
+				if responses := mappingValue(op, "responses"); responses != nil && responses.Kind == yaml.MappingNode {
+					filterMapping(responses, func(code string, _ *yaml.Node) bool {
+						return codes[code] || strings.HasPrefix(code, "x-")
+					})
+				}
+				return true
+			})
+			return true
+		})
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&root); err != nil {
+		return fmt.Errorf("could not write spec: %w", err)
+	}
+	return enc.Close()
+}
+
+// filterMapping keeps the entries of a YAML mapping that keep returns true for, in their order.
+func filterMapping(node *yaml.Node, keep func(key string, value *yaml.Node) bool) {
+	kept := node.Content[:0]
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if keep(node.Content[i].Value, node.Content[i+1]) {
+			kept = append(kept, node.Content[i], node.Content[i+1])
+		}
+	}
+	node.Content = kept
+}
+
+// isMethod checks if a key of a path item is an operation.
+func isMethod(key string) bool {
+	switch key {
+	case "get", "put", "post", "delete", "options", "head", "patch", "trace":
+		return true
+	}
+	return false
+}
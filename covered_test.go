@@ -0,0 +1,65 @@
+//go:build !copper_lite
+
+package copper
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoveredSpec(t *testing.T) {
+	f, err := os.ReadFile("testdata/status-headers-spec.yaml")
+	require.NoError(t, err)
+
+	v, err := NewVerifier(f)
+	require.NoError(t, err)
+
+	unauthorized := func() *http.Response {
+		return &http.Response{
+			StatusCode: http.StatusUnauthorized,
+			Header:     http.Header{"Www-Authenticate": {"Bearer"}},
+			Body:       http.NoBody,
+			Request:    httptest.NewRequest(http.MethodPost, "/things", nil),
+		}
+	}
+	v.Record(unauthorized())
+
+	var buf bytes.Buffer
+	require.NoError(t, v.CoveredSpec(&buf))
+	assert.Equal(t, `openapi: 3.0.1
+info:
+  title: status headers test
+  version: '1.0'
+paths:
+  /things:
+    post:
+      responses:
+        "401":
+          description: Not logged in
+`, buf.String())
+
+	// The covered spec is a spec of its own, which the same traffic covers completely.
+	covered, err := NewVerifier(buf.Bytes())
+	require.NoError(t, err)
+	covered.Record(unauthorized())
+	assert.NoError(t, covered.CurrentError())
+}
+
+func TestCoveredSpec_Nothing(t *testing.T) {
+	f, err := os.ReadFile("testdata/thing-spec.yaml")
+	require.NoError(t, err)
+
+	v, err := NewVerifier(f)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, v.CoveredSpec(&buf))
+	assert.Contains(t, buf.String(), "paths: {}")
+	assert.NotContains(t, buf.String(), "/ping")
+}
@@ -0,0 +1,128 @@
+package copper
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+var _ fmt.Stringer = (*Verifier)(nil)
+
+// String returns the same as DebugString, so that a Verifier can be given directly to fmt and loggers.
+func (v *Verifier) String() string {
+	return v.DebugString()
+}
+
+// DebugString summarizes the state of the Verifier on a few lines: the title and version of the spec, the options that
+// are in effect, how many coordinates have been checked and how many errors there are of each sentinel. It is meant to
+// be dumped by failing CI runs, for post-mortems.
+func (v *Verifier) DebugString() string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	total := len(v.endpoints.All())
+	checked := total - len(v.endpoints.Unchecked())
+
+	errs := v.currentErrors()
+	counts := make(map[string]int)
+	for _, err := range errs {
+		var verr *VerificationError
+		if errors.As(err, &verr) {
+			counts[verr.Sentinel().Error()]++
+		}
+	}
+	sentinels := make([]string, 0, len(counts))
+	for sentinel, n := range counts {
+		sentinels = append(sentinels, fmt.Sprintf("%s: %d", sentinel, n))
+	}
+	slices.Sort(sentinels)
+
+	options := v.conf.describe()
+	if len(options) == 0 {
+		options = []string{"defaults"}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "copper verifier for %s %s\n", v.model.Info.Title, v.model.Info.Version)
+	fmt.Fprintf(&b, "options: %s\n", strings.Join(options, ", "))
+	fmt.Fprintf(&b, "endpoints: %d of %d checked, %d waived\n", checked, total, len(v.endpoints.waived))
+	fmt.Fprintf(&b, "errors: %d", len(errs))
+	if len(sentinels) > 0 {
+		fmt.Fprintf(&b, " (%s)", strings.Join(sentinels, ", "))
+	}
+	return b.String()
+}
+
+// describe lists the options that differ from the defaults, named like the functions that set them.
+func (c config) describe() []string {
+	var opts []string
+	flag := func(set bool, name string) {
+		if set {
+			opts = append(opts, name)
+		}
+	}
+
+	if c.serverBase != "" {
+		opts = append(opts, fmt.Sprintf("WithServer(%q)", c.serverBase))
+	}
+	if c.baseURL != "" {
+		opts = append(opts, fmt.Sprintf("WithBaseURL(%q)", c.baseURL))
+	}
+	flag(c.checkInternalServerErrors, "WithInternalServerErrors")
+	flag(c.checkRequest, "WithRequestValidation")
+	flag(c.securityValidation, "WithSecurityValidation")
+	flag(c.authErrorCoverage, "WithAuthErrorCoverage")
+	flag(c.disableFullCoverage, "WithoutFullCoverage")
+	flag(c.disableResponseValidation, "WithoutResponseValidation")
+	flag(c.headFromGet, "WithHeadFromGet")
+	flag(c.headCoverageFromGet, "WithHeadCoverageFromGet")
+	flag(c.problemDetails, "WithProblemDetails")
+	flag(c.rateLimitHeaders, "WithRateLimitHeaders")
+	flag(c.links, "WithLinks")
+	flag(c.strictQueryEncoding, "WithStrictQueryEncoding")
+	flag(c.headerValues == JoinedHeaderValues, "WithHeaderValues(JoinedHeaderValues)")
+	flag(c.strictFormats, "WithStrictFormats")
+	flag(c.strictResponseProperties, "WithStrictResponseProperties")
+	flag(c.nullability == NullableLenient, "WithNullability(NullableLenient)")
+	flag(c.nullability == NullableStrict, "WithNullability(NullableStrict)")
+	flag(c.ecmaPatterns, "WithECMAPatterns")
+	flag(c.exampleValidation, "WithExampleValidation")
+	flag(c.contentSniffing, "WithContentSniffing")
+	flag(c.jwtClaims, "WithJWTClaims")
+	flag(c.statusHeaders != nil, "WithStatusHeaders")
+	flag(c.errorTemplates.Errors != nil || c.errorTemplates.Summary != nil, "WithErrorTemplates")
+	flag(c.ignoreUnsupportedBodyFormats, "WithIgnoredUnsupportedBodyFormats")
+	flag(c.requestLogger != nil, "WithRequestLogging")
+	flag(c.verbosity == LogProgress, "WithVerbosity(LogProgress)")
+	flag(c.verbosity == LogProgressAndDumps, "WithVerbosity(LogProgressAndDumps)")
+	flag(c.violationHandler != nil, "WithViolationHandler")
+	flag(c.requestIDs, "WithRequestIDs")
+	flag(c.tlsConfig != nil && c.tlsConfig.InsecureSkipVerify, "WithInsecureTLS")
+	flag(c.tlsConfig != nil && !c.tlsConfig.InsecureSkipVerify, "WithTLSConfig")
+	flag(c.requestMediaTypeCoverage, "WithRequestMediaTypeCoverage")
+	flag(c.swagger2, "WithSwagger2")
+	opts = append(opts, c.filters.describe()...)
+	if c.maxDepth != defaultMaxDepth {
+		opts = append(opts, fmt.Sprintf("WithMaxDepth(%d)", c.maxDepth))
+	}
+	if c.coverageThreshold != 0 {
+		opts = append(opts, fmt.Sprintf("WithCoverageThreshold(%g)", c.coverageThreshold))
+	}
+	if c.rateLimit != 0 {
+		opts = append(opts, fmt.Sprintf("WithRateLimit(%g)", c.rateLimit))
+	}
+	if c.errorWindow != 0 {
+		opts = append(opts, fmt.Sprintf("WithErrorWindow(%d)", c.errorWindow))
+	}
+	if c.snapshotPath != "" {
+		opts = append(opts, fmt.Sprintf("WithSnapshots(%q, %s)", c.snapshotPath, c.snapshotInterval))
+	}
+	if c.goldenDir != "" {
+		opts = append(opts, fmt.Sprintf("WithGoldenFiles(%q)", c.goldenDir))
+	}
+	if c.sampling != 1 {
+		opts = append(opts, fmt.Sprintf("WithSampling(%g)", c.sampling))
+	}
+	return opts
+}
@@ -0,0 +1,42 @@
+package copper
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebugString(t *testing.T) {
+	f, err := os.ReadFile("testdata/thing-spec.yaml")
+	require.NoError(t, err)
+
+	t.Run("defaults", func(t *testing.T) {
+		v, err := NewVerifier(f)
+		require.NoError(t, err)
+
+		assert.Equal(t, "copper verifier for thing test 1.0\n"+
+			"options: defaults\n"+
+			"endpoints: 0 of 2 checked, 0 waived\n"+
+			"errors: 1 (not checked: 1)", v.DebugString())
+	})
+
+	t.Run("state", func(t *testing.T) {
+		v, err := NewVerifier(f, WithRequestValidation(), WithoutFullCoverage(), WithMaxDepth(8), WithSampling(0.5))
+		require.NoError(t, err)
+
+		v.Record(&http.Response{StatusCode: http.StatusOK, Request: httptest.NewRequest(http.MethodGet, "/missing", nil)})
+		v.Record(&http.Response{StatusCode: http.StatusTeapot, Request: httptest.NewRequest(http.MethodGet, "/ping", nil)})
+
+		expected := "copper verifier for thing test 1.0\n" +
+			"options: WithRequestValidation, WithoutFullCoverage, WithMaxDepth(8), WithSampling(0.5)\n" +
+			"endpoints: 0 of 2 checked, 0 waived\n" +
+			"errors: 2 (not part of spec: 1, response invalid: 1)"
+		assert.Equal(t, expected, v.DebugString())
+		assert.Equal(t, expected, fmt.Sprint(v))
+	})
+}
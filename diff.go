@@ -0,0 +1,97 @@
+package copper
+
+import (
+	"errors"
+)
+
+// StateDiff is how the state of one Verifier differs from another, like the state of a run on the main branch and the
+// state of a run for a change, as restored with json.Unmarshal.
+type StateDiff struct {
+	// Lost are the coordinates that were checked in the first state, but not in the second, sorted by path, method and
+	// response code.
+	Lost []Endpoint `json:"lost"`
+	// Gained are the coordinates that were checked in the second state, but not in the first, in the same order.
+	Gained []Endpoint `json:"gained"`
+	// NewViolations are the violations of the second state that the first does not have, in the order they were found.
+	// Errors for coverage are left out, since the coverage is in Lost and Gained.
+	NewViolations []string `json:"newViolations"`
+	// Hits are the coordinates that are checked in both states, but were recorded a different number of times.
+	Hits []HitChange `json:"hits"`
+}
+
+// HitChange is a coordinate that was recorded a different number of times in two states.
+type HitChange struct {
+	Endpoint
+	Before int `json:"before"`
+	After  int `json:"after"`
+}
+
+// Regressed returns true if the second state has lost coverage or has new violations, which is what a check of a
+// change in CI would fail for.
+func (d StateDiff) Regressed() bool {
+	return len(d.Lost) > 0 || len(d.NewViolations) > 0
+}
+
+// DiffStates compares the coverage and violations of two Verifiers, where a is the state to compare with, like the one
+// of the main branch, and b is the new state. The coordinates are matched by path, method and response code, so the two
+// do not have to be for the exact same spec.
+func DiffStates(a, b *Verifier) StateDiff {
+	d := StateDiff{Lost: []Endpoint{}, Gained: []Endpoint{}, NewViolations: []string{}, Hits: []HitChange{}}
+
+	before := make(map[coordinate]EndpointStatus)
+	for _, s := range a.Endpoints() {
+		if s.Checked {
+			before[s.coordinate()] = s
+		}
+	}
+	after := make(map[coordinate]EndpointStatus)
+	for _, s := range b.Endpoints() {
+		if !s.Checked {
+			continue
+		}
+		after[s.coordinate()] = s
+
+		old, ok := before[s.coordinate()]
+		switch {
+		case !ok:
+			d.Gained = append(d.Gained, s.Endpoint)
+		case old.Hits != s.Hits:
+			d.Hits = append(d.Hits, HitChange{Endpoint: s.Endpoint, Before: old.Hits, After: s.Hits})
+		}
+	}
+	// Endpoints are sorted, so the lost ones are as well.
+	for _, s := range a.Endpoints() {
+		if _, ok := after[s.coordinate()]; s.Checked && !ok {
+			d.Lost = append(d.Lost, s.Endpoint)
+		}
+	}
+
+	// The same violation can be found several times, so they are counted rather than just looked up.
+	seen := make(map[string]int)
+	for _, err := range a.CurrentErrors() {
+		if !errors.Is(err, ErrNotChecked) {
+			seen[violation(err)]++
+		}
+	}
+	for _, err := range b.CurrentErrors() {
+		if errors.Is(err, ErrNotChecked) {
+			continue
+		}
+		if seen[violation(err)] > 0 {
+			seen[violation(err)]--
+			continue
+		}
+		d.NewViolations = append(d.NewViolations, err.Error())
+	}
+	return d
+}
+
+// violation returns the message of the error without the ID of the request, which differs between runs even when the
+// violation is the same.
+func violation(err error) string {
+	var verr *VerificationError
+	if errors.As(err, &verr) && verr.requestID != "" {
+		return verr.sentinel.Error() + ": " + verr.err.Error()
+	}
+	return err.Error()
+}
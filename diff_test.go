@@ -0,0 +1,57 @@
+package copper
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffStates(t *testing.T) {
+	f, err := os.ReadFile("testdata/thing-spec.yaml")
+	require.NoError(t, err)
+
+	record := func(v *Verifier, path, body string) {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		v.Record(&http.Response{
+			StatusCode: http.StatusOK,
+			Request:    req,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+		})
+	}
+
+	base, err := NewVerifier(f)
+	require.NoError(t, err)
+	record(base, "/ping", `{"message": "pong"}`)
+	record(base, "/ping", `{"message": "pong"}`)
+	record(base, "/missing", `{}`)
+
+	change, err := NewVerifier(f)
+	require.NoError(t, err)
+	record(change, "/ping", `{"message": "pong"}`)
+	record(change, "/other", `{"thing": "thing"}`)
+	record(change, "/missing", `{}`)
+	record(change, "/missing", `{}`)
+
+	d := DiffStates(base, change)
+	assert.Empty(t, d.Lost)
+	assert.Equal(t, []Endpoint{{Path: "/other", Method: "GET", ResponseCode: "200"}}, d.Gained)
+	assert.Equal(t, []HitChange{{Endpoint: Endpoint{Path: "/ping", Method: "GET", ResponseCode: "200"}, Before: 2, After: 1}}, d.Hits)
+	require.Len(t, d.NewViolations, 1)
+	assert.Contains(t, d.NewViolations[0], "not part of spec: GET /missing")
+	assert.True(t, d.Regressed())
+
+	d = DiffStates(change, base)
+	assert.Equal(t, []Endpoint{{Path: "/other", Method: "GET", ResponseCode: "200"}}, d.Lost)
+	assert.Empty(t, d.Gained)
+	assert.Empty(t, d.NewViolations)
+	assert.True(t, d.Regressed())
+
+	assert.False(t, DiffStates(base, base).Regressed())
+}
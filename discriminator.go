@@ -0,0 +1,112 @@
+package copper
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"gopkg.in/yaml.v3"
+)
+
+// discriminator returns the query parameter that the operation is discriminated by, and its documented values, or an
+// empty name if the operation has no x-copper-discriminator-param. The parameter must be a query parameter of the
+// operation or the path item, with an enum of the values.
+func discriminator(item *v3.PathItem, op *v3.Operation) (string, []string, error) {
+	if op == nil || op.Extensions == nil {
+		return "", nil, nil
+	}
+	node := op.Extensions.GetOrZero(extDiscriminatorParam)
+	if node == nil {
+		return "", nil, nil
+	}
+	if node.Kind != yaml.ScalarNode || node.Value == "" {
+		return "", nil, fmt.Errorf("%s must be the name of a query parameter", extDiscriminatorParam)
+	}
+
+	name := node.Value
+	var param *v3.Parameter
+	// The parameters of the operation override the ones of the path item.
+	for _, p := range append(op.Parameters, item.Parameters...) {
+		if p != nil && p.In == "query" && p.Name == name {
+			param = p
+			break
+		}
+	}
+	if param == nil {
+		return "", nil, fmt.Errorf("%s names %s, which is not a query parameter of the operation", extDiscriminatorParam, name)
+	}
+
+	var values []string
+	if param.Schema != nil {
+		if s := param.Schema.Schema(); s != nil {
+			for _, value := range s.Enum {
+				values = append(values, value.Value)
+			}
+		}
+	}
+	if len(values) == 0 {
+		return "", nil, fmt.Errorf("%s names %s, which does not have an enum of the operations", extDiscriminatorParam, name)
+	}
+	return name, values, nil
+}
+
+// checkDiscriminators checks that the discriminating parameters of all operations can be used, so that a typo does not
+// go unnoticed as operations that are covered as one.
+func checkDiscriminators(doc *v3.Document) error {
+	if doc.Paths == nil {
+		return nil
+	}
+
+	var errs []error
+	for path, item := range doc.Paths.PathItems.FromOldest() {
+		for method, op := range item.GetOperations().FromOldest() {
+			if _, _, err := discriminator(item, op); err != nil {
+				errs = append(errs, fmt.Errorf("%s %s: %w", strings.ToUpper(method), path, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// discriminatedPaths returns the paths of the endpoints tree for an operation, which is one for every value of its
+// discriminating parameter, like /jobs?action=start, or just the path for operations without one.
+func discriminatedPaths(path string, item *v3.PathItem, op *v3.Operation) []string {
+	name, values, _ := discriminator(item, op)
+	if name == "" {
+		return []string{path}
+	}
+
+	paths := make([]string, 0, len(values))
+	for _, value := range values {
+		paths = append(paths, discriminatedPath(path, name, value))
+	}
+	return paths
+}
+
+func discriminatedPath(path, name, value string) string {
+	return path + "?" + url.Values{name: {value}}.Encode()
+}
+
+// coveredPath returns the path of the endpoints tree that the request covers, which for a discriminated operation
+// depends on the value of the discriminating parameter. An error is returned if the value is not one of the documented
+// ones, since the request then is not for any of the logical operations.
+func coveredPath(req *http.Request, item *v3.PathItem, foundPath string) (string, error) {
+	name, values, _ := discriminator(item, item.GetOperations().GetOrZero(strings.ToLower(req.Method)))
+	if name == "" {
+		return foundPath, nil
+	}
+
+	value := req.URL.Query().Get(name)
+	for _, v := range values {
+		if v == value {
+			return discriminatedPath(foundPath, name, value), nil
+		}
+	}
+	if !req.URL.Query().Has(name) {
+		return "", fmt.Errorf("query parameter %s is missing, so the operation can not be told", name)
+	}
+	return "", fmt.Errorf("%s=%s is not one of the documented operations", name, value)
+}
@@ -0,0 +1,114 @@
+package copper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscriminatorParam(t *testing.T) {
+	f, err := os.ReadFile("testdata/discriminator-spec.yaml")
+	require.NoError(t, err)
+
+	record := func(v *Verifier, method, url string, status int) {
+		v.Record(&http.Response{
+			StatusCode: status,
+			Body:       http.NoBody,
+			Request:    httptest.NewRequest(method, url, nil),
+		})
+	}
+
+	t.Run("every value is an endpoint", func(t *testing.T) {
+		v, err := NewVerifier(f)
+		require.NoError(t, err)
+
+		assert.Equal(t, []Endpoint{
+			{Path: "/jobs/{id}", Method: http.MethodDelete, ResponseCode: "204"},
+			{Path: "/jobs/{id}?action=start", Method: http.MethodPost, ResponseCode: "202", OperationID: "controlJob"},
+			{Path: "/jobs/{id}?action=stop", Method: http.MethodPost, ResponseCode: "202", OperationID: "controlJob"},
+		}, v.Unchecked())
+	})
+
+	t.Run("requests cover the endpoint of their value", func(t *testing.T) {
+		v, err := NewVerifier(f)
+		require.NoError(t, err)
+
+		record(v, http.MethodPost, "/jobs/1?action=start", http.StatusAccepted)
+		record(v, http.MethodDelete, "/jobs/1", http.StatusNoContent)
+
+		assert.Equal(t, []Endpoint{
+			{Path: "/jobs/{id}?action=stop", Method: http.MethodPost, ResponseCode: "202", OperationID: "controlJob"},
+		}, v.Unchecked())
+		assert.EqualError(t, v.CurrentError(), "not checked: 1 coordinate on 1 path\n  /jobs/{id}?action=stop: POST 202")
+	})
+
+	t.Run("unknown values are not part of the spec", func(t *testing.T) {
+		v, err := NewVerifier(f, WithoutFullCoverage())
+		require.NoError(t, err)
+
+		record(v, http.MethodPost, "/jobs/1?action=pause", http.StatusAccepted)
+		record(v, http.MethodPost, "/jobs/1", http.StatusAccepted)
+
+		errs := v.CurrentErrors()
+		require.Len(t, errs, 2)
+		assert.ErrorIs(t, errs[0], ErrNotPartOfSpec)
+		assert.ErrorContains(t, errs[0], "action=pause is not one of the documented operations")
+		assert.ErrorIs(t, errs[1], ErrNotPartOfSpec)
+		assert.ErrorContains(t, errs[1], "query parameter action is missing")
+		assert.Len(t, v.Unchecked(), 3)
+	})
+}
+
+func TestDiscriminatorParam_Invalid(t *testing.T) {
+	tt := []struct {
+		name   string
+		ext    string
+		param  string
+		errMsg string
+	}{
+		{
+			"parameter is not documented",
+			"kind",
+			"{name: action, in: query, schema: {type: string, enum: [start]}}",
+			"x-copper-discriminator-param names kind, which is not a query parameter of the operation",
+		},
+		{
+			"parameter is not in the query",
+			"action",
+			"{name: action, in: header, schema: {type: string, enum: [start]}}",
+			"x-copper-discriminator-param names action, which is not a query parameter of the operation",
+		},
+		{
+			"parameter has no enum",
+			"action",
+			"{name: action, in: query, schema: {type: string}}",
+			"x-copper-discriminator-param names action, which does not have an enum of the operations",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			spec := `openapi: 3.0.1
+info:
+  title: invalid discriminator
+  version: '1.0'
+paths:
+  /jobs:
+    post:
+      x-copper-discriminator-param: ` + tc.ext + `
+      parameters:
+        - ` + tc.param + `
+      responses:
+        "202":
+          description: Accepted
+`
+			_, err := NewVerifier([]byte(spec))
+			require.Error(t, err)
+			assert.ErrorContains(t, err, "POST /jobs: "+tc.errMsg)
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package copper
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/orderedmap"
+)
+
+// Download sends the request like Do, but streams the body of the response to w instead of keeping it in memory, which
+// makes large exports and downloads possible to cover. Bodies of binary media types, like images or archives, are not
+// validated, while the status code, the headers and the size of the body still are. Other bodies are validated as
+// usual once they have been streamed. The body of the returned response has already been read.
+func (v *ValidatingClient) Download(r *http.Request, w io.Writer) (*http.Response, error) {
+	res, err := v.send(r)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if !isBinaryMediaType(res.Header.Get("Content-Type")) {
+		var buf bytes.Buffer
+		if _, err := io.Copy(io.MultiWriter(w, &buf), res.Body); err != nil {
+			return nil, fmt.Errorf("could not download body: %w", err)
+		}
+		res.Body = io.NopCloser(&buf)
+		v.Record(res)
+		res.Body = http.NoBody
+		return res, nil
+	}
+
+	n, err := io.Copy(w, res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("could not download body: %w", err)
+	}
+	res.Body = http.NoBody
+	res.Request = res.Request.WithContext(context.WithValue(res.Request.Context(), streamedKey{}, n))
+	v.Record(res)
+	return res, nil
+}
+
+// streamedKey is the context key for the size of a response body that was streamed past the Verifier.
+type streamedKey struct{}
+
+// streamedSize returns the size of the body of the response to the request, if the body was streamed past the Verifier
+// rather than recorded.
+func streamedSize(req *http.Request) (int64, bool) {
+	n, ok := req.Context().Value(streamedKey{}).(int64)
+	return n, ok
+}
+
+// isBinaryMediaType checks if the content type is for binary data, which can not be validated against a schema anyway.
+func isBinaryMediaType(contentType string) bool {
+	if kind, ok := declaredKind(contentType); ok {
+		return kind == kindBinary
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(mediaType, "application/") && !strings.Contains(mediaType, "json") &&
+		!strings.Contains(mediaType, "xml") && mediaType != "application/x-www-form-urlencoded"
+}
+
+// checkStreamedResponse checks what is known about a response with a streamed body: that it is documented, with its
+// Content-Type and its required headers.
+func checkStreamedResponse(res *http.Response, response *v3.Response) error {
+	if response == nil {
+		return fmt.Errorf("%d response is not documented", res.StatusCode)
+	}
+
+	var errs []error
+	if orderedmap.Len(response.Content) > 0 {
+		mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+		if !documentsMediaType(response, mediaType) {
+			errs = append(errs, fmt.Errorf("%d response has Content-Type %s, which is not documented", res.StatusCode, mediaType))
+		}
+	}
+	return errors.Join(append(errs, checkResponseHeaders(response, res.Header))...)
+}
+
+// documentsMediaType checks if the content of the response has the media type, directly or through a wildcard like
+// image/* or */*.
+func documentsMediaType(response *v3.Response, mediaType string) bool {
+	for documented := range response.Content.KeysFromOldest() {
+		if matchesMediaTypes(mediaType, documented) {
+			return true
+		}
+	}
+	return false
+}
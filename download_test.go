@@ -0,0 +1,67 @@
+package copper
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownload(t *testing.T) {
+	f, err := os.ReadFile("testdata/download-spec.yaml")
+	require.NoError(t, err)
+
+	tt := []struct {
+		name        string
+		path        string
+		contentType string
+		disposition string
+		body        string
+		err         string
+	}{
+		{"binary", "/export", "application/zip", "attachment", "PK\x03\x04 not validated", ""},
+		{"missing header", "/export", "application/zip", "", "PK\x03\x04", "required header Content-Disposition is missing"},
+		{"undocumented content type", "/export", "application/pdf", "attachment", "%PDF", "200 response has Content-Type application/pdf, which is not documented"},
+		{"over the budget", "/export", "application/zip", "attachment", strings.Repeat("x", 2048), "response body is 2048 bytes, which exceeds the budget of 1024 bytes"},
+		{"json", "/report", "application/json", "", `{"rows": 5}`, ""},
+		{"invalid json", "/report", "application/json", "", `{"rows": "five"}`, "response invalid"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			s := httptest.NewServer(http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Content-Type", tc.contentType)
+					if tc.disposition != "" {
+						w.Header().Set("Content-Disposition", tc.disposition)
+					}
+					_, _ = w.Write([]byte(tc.body))
+				}),
+			)
+			defer s.Close()
+
+			c := MustWrapClient(http.DefaultClient, bytes.NewReader(f), WithoutFullCoverage())
+			req, err := http.NewRequest(http.MethodGet, s.URL+tc.path, nil)
+			require.NoError(t, err)
+
+			var out bytes.Buffer
+			res, err := c.Download(req, &out)
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusOK, res.StatusCode)
+			assert.Equal(t, tc.body, out.String())
+
+			if tc.err == "" {
+				assert.NoError(t, c.CurrentError())
+			} else {
+				assert.ErrorIs(t, c.CurrentError(), ErrResponseInvalid)
+				assert.ErrorContains(t, c.CurrentError(), tc.err)
+			}
+			AssertCovered(t, c.Verifier, http.MethodGet, tc.path, http.StatusOK)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package copper
+
+import (
+	"cmp"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+// maxDriftExample is how long the example value of a drifted field can be before it is cut short.
+const maxDriftExample = 200
+
+// DriftField is a property that responses have had, but that the schema of the response does not document. This is not
+// an error when the schema allows additional properties, but shows how payloads grow beyond the documented contract.
+type DriftField struct {
+	Endpoint
+	// Field is the JSON pointer of the property in the body, where the items of arrays are *, like /items/*/nickname.
+	Field string `json:"field"`
+	// Example is the first value that was seen for the property, as JSON, cut short if it is long.
+	Example string `json:"example"`
+	// Count is how many responses have had the property.
+	Count int `json:"count"`
+}
+
+// driftField is a property that a body has, but that its schema does not document.
+type driftField struct {
+	field string
+	value any
+}
+
+type driftKey struct {
+	coordinate
+	field string
+}
+
+// Drift returns the properties that the recorded responses have had, without them being documented in the schema of
+// the response, sorted by path, method, response code and field. Properties that the schema does not allow are also
+// reported as errors with WithStrictResponseProperties, but the drift is collected either way and never fails the
+// verification. Objects without any documented properties are maps, and do not drift. Responses that are not validated,
+// because of sampling or WithoutResponseValidation, are not looked at.
+func (v *Verifier) Drift() []DriftField {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	drift := make([]DriftField, 0, len(v.drift))
+	for _, d := range v.drift {
+		drift = append(drift, *d)
+	}
+	slices.SortFunc(drift, func(a, b DriftField) int {
+		return cmp.Or(compareEndpoints(a.Endpoint, b.Endpoint), strings.Compare(a.Field, b.Field))
+	})
+	return drift
+}
+
+// recordDrift adds the drift of a response to the state.
+func (v *Verifier) recordDrift(req *http.Request, res *http.Response, pathItem *v3.PathItem, foundPath string,
+	drift []driftField) {
+	if len(drift) == 0 {
+		return
+	}
+	path, err := coveredPath(req, pathItem, foundPath)
+	if err != nil {
+		path = foundPath
+	}
+	end := Endpoint{Path: path, Method: strings.ToUpper(req.Method), ResponseCode: strconv.Itoa(res.StatusCode)}
+
+	if v.drift == nil {
+		v.drift = make(map[driftKey]*DriftField)
+	}
+	// The items of an array can all have the same field, which only counts once for the response.
+	seen := make(map[driftKey]bool)
+	for _, d := range drift {
+		key := driftKey{coordinate: end.coordinate(), field: d.field}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if existing, ok := v.drift[key]; ok {
+			existing.Count++
+			continue
+		}
+		example, _ := json.Marshal(d.value)
+		if len(example) > maxDriftExample {
+			example = append(example[:maxDriftExample], "..."...)
+		}
+		v.drift[key] = &DriftField{Endpoint: end, Field: d.field, Example: string(example), Count: 1}
+	}
+}
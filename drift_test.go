@@ -0,0 +1,48 @@
+package copper
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrift(t *testing.T) {
+	f, err := os.ReadFile("testdata/strict-spec.yaml")
+	require.NoError(t, err)
+
+	bodies := []string{
+		`{"name": "Rex", "color": "brown", "tags": [{"name": "a", "extra": {"n": 1}}, {"name": "b", "extra": 2}],
+			"metadata": {"anything": "goes"}, "labels": {"vet": "vet@example.com"}, "owner": {"person": "Alice", "animal": "cat"}}`,
+		`{"name": "Rex", "color": "black"}`,
+		`{"name": "Rex", "notes": "` + strings.Repeat("x", maxDriftExample) + `"}`,
+	}
+
+	v := MustNewVerifier(f, WithoutFullCoverage())
+	for _, body := range bodies {
+		v.Record(&http.Response{
+			StatusCode: http.StatusCreated,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    httptest.NewRequest(http.MethodPost, "/pets", nil),
+		})
+	}
+	assert.NoError(t, v.CurrentError(), "drift does not fail the verification")
+
+	end := Endpoint{Path: "/pets", Method: http.MethodPost, ResponseCode: "201"}
+	expected := []DriftField{
+		{Endpoint: end, Field: "/color", Example: `"brown"`, Count: 2},
+		{Endpoint: end, Field: "/notes", Example: `"` + strings.Repeat("x", maxDriftExample-1) + "...", Count: 1},
+		{Endpoint: end, Field: "/owner/animal", Example: `"cat"`, Count: 1},
+		{Endpoint: end, Field: "/tags/*/extra", Example: `{"n":1}`, Count: 1},
+	}
+	assert.Equal(t, expected, v.Drift())
+
+	v.Reset()
+	assert.Empty(t, v.Drift())
+}
@@ -0,0 +1,21 @@
+//go:build !copper_lite
+
+package copper
+
+import (
+	"net/http"
+	"net/http/httputil"
+)
+
+// logDumps logs a dump of the request and of the response, each under a banner.
+func logDumps(logger RequestLogger, banner string, req *http.Request, res *http.Response) {
+	reqDump, err := httputil.DumpRequestOut(req, true)
+	if err == nil {
+		logger.Logf("REQUEST  %s ====\n%s", banner, string(reqDump))
+	}
+
+	resDump, err := httputil.DumpResponse(res, true)
+	if err == nil {
+		logger.Logf("RESPONSE %s ====\n%s", banner, string(resDump))
+	}
+}
@@ -0,0 +1,14 @@
+//go:build copper_lite
+
+package copper
+
+import (
+	"net/http"
+)
+
+// logDumps logs the request line and the status of the response under a banner, since the lite build leaves out the
+// dumping of whole requests and responses.
+func logDumps(logger RequestLogger, banner string, req *http.Request, res *http.Response) {
+	logger.Logf("REQUEST  %s ====\n%s %s", banner, req.Method, req.URL.RequestURI())
+	logger.Logf("RESPONSE %s ====\n%s", banner, res.Status)
+}
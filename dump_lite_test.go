@@ -0,0 +1,27 @@
+//go:build copper_lite
+
+package copper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dumpsHeaders is whether the request logs dump the headers of the requests and responses, which the lite build does
+// not.
+const dumpsHeaders = false
+
+func TestLogDumps(t *testing.T) {
+	store := &logStore{}
+	req := httptest.NewRequest(http.MethodPost, "http://localhost/things?limit=1", nil)
+	req.Header.Set("X-Request-Id", "trace-1")
+	logDumps(store, "0001", req, &http.Response{StatusCode: http.StatusCreated, Status: "201 Created"})
+
+	require.Len(t, store.logs, 2)
+	assert.Equal(t, "REQUEST  0001 ====\nPOST /things?limit=1", store.logs[0])
+	assert.Equal(t, "RESPONSE 0001 ====\n201 Created", store.logs[1])
+}
@@ -0,0 +1,41 @@
+//go:build !copper_lite
+
+package copper
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dumpsHeaders is whether the request logs dump the headers of the requests and responses, which the lite build does
+// not.
+const dumpsHeaders = true
+
+func TestLogDumps(t *testing.T) {
+	store := &logStore{}
+	req := httptest.NewRequest(http.MethodPost, "http://localhost/things?limit=1", strings.NewReader(`{"name":"a"}`))
+	req.Header.Set("X-Request-Id", "trace-1")
+	res := &http.Response{
+		StatusCode: http.StatusCreated,
+		Status:     "201 Created",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{"Location": {"/things/1"}},
+		Body:       io.NopCloser(strings.NewReader(`{"id":1}`)),
+	}
+	logDumps(store, "0001", req, res)
+
+	require.Len(t, store.logs, 2)
+	assert.Contains(t, store.logs[0], "REQUEST  0001 ====\nPOST /things?limit=1 HTTP/1.1")
+	assert.Contains(t, store.logs[0], "X-Request-Id: trace-1")
+	assert.Contains(t, store.logs[0], `{"name":"a"}`)
+	assert.Contains(t, store.logs[1], "RESPONSE 0001 ====\nHTTP/1.1 201 Created")
+	assert.Contains(t, store.logs[1], "Location: /things/1")
+	assert.Contains(t, store.logs[1], `{"id":1}`)
+}
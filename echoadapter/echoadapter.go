@@ -0,0 +1,78 @@
+// Package echoadapter records the requests that an echo server serves into a copper.Verifier, using the route that
+// echo matched to find the path in the spec. It also provides a handler that serves the live coverage as JSON.
+package echoadapter
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/callebjorkell/copper"
+	"github.com/callebjorkell/copper/internal/capture"
+	"github.com/labstack/echo/v4"
+)
+
+// Middleware returns echo middleware that records every request and the response to it into the verifier. Add it with
+// the Use method of the server, so that the route is known when the request is recorded. Errors returned by the handler
+// are passed to the error handler of the server before recording, so that the response that the client gets is the
+// one that is verified.
+func Middleware(v *copper.Verifier) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if err := capture.BufferRequest(req); err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, "could not read request body")
+			}
+
+			cw := capture.NewWriter(c.Response().Writer)
+			c.Response().Writer = cw
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+
+			v.RecordRoute(cw.Response(req), route(c.Path()))
+			return nil
+		}
+	}
+}
+
+// Coverage is the body served by CoverageHandler.
+type Coverage struct {
+	// Unchecked lists the coordinates of the spec that have not been checked yet.
+	Unchecked []copper.Endpoint `json:"unchecked"`
+	// Errors lists the errors that have been found so far, other than the coordinates that have not been checked.
+	Errors []string `json:"errors"`
+}
+
+// CoverageHandler returns an echo.HandlerFunc that serves the current coverage of the verifier as JSON, for example
+// to be polled by a dashboard while copper runs in a staging environment. Register it on a route that is not part of
+// the spec, and outside of the group that uses the Middleware, so that polling it is not recorded.
+func CoverageHandler(v *copper.Verifier) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		cov := Coverage{
+			Unchecked: v.Unchecked(),
+			Errors:    []string{},
+		}
+		if cov.Unchecked == nil {
+			cov.Unchecked = []copper.Endpoint{}
+		}
+		for _, err := range v.CurrentErrors() {
+			if !errors.Is(err, copper.ErrNotChecked) {
+				cov.Errors = append(cov.Errors, err.Error())
+			}
+		}
+		return c.JSON(http.StatusOK, cov)
+	}
+}
+
+// route converts an echo route like /things/:id into a path template like /things/{id}. Match-any routes have no
+// counterpart in a path template, and are left as they are.
+func route(path string) string {
+	segs := strings.Split(path, "/")
+	for i, seg := range segs {
+		if name, ok := strings.CutPrefix(seg, ":"); ok {
+			segs[i] = "{" + name + "}"
+		}
+	}
+	return strings.Join(segs, "/")
+}
@@ -0,0 +1,72 @@
+package echoadapter
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/callebjorkell/copper"
+	"github.com/callebjorkell/copper/internal/routertest"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newServer(v *copper.Verifier) *echo.Echo {
+	e := echo.New()
+	e.GET("/coverage", CoverageHandler(v))
+
+	things := e.Group("/api/things", Middleware(v))
+	things.GET("/export", echo.WrapHandler(http.HandlerFunc(routertest.Export)))
+	things.GET("/:thingID", echo.WrapHandler(http.HandlerFunc(routertest.GetThing)))
+	things.PUT("/:thingID", echo.WrapHandler(http.HandlerFunc(routertest.PutThing)))
+	// Errors that the handlers return are only turned into responses after the middleware, which still has to record
+	// them as they are sent.
+	things.DELETE("/:thingID", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusMethodNotAllowed)
+	})
+	return e
+}
+
+func TestMiddleware(t *testing.T) {
+	router := routertest.Middleware(func(v *copper.Verifier) http.Handler { return newServer(v) })
+	routertest.Test(t, router, http.StatusMethodNotAllowed)
+
+	t.Run("the path of a group route is matched", func(t *testing.T) {
+		assert.Equal(t, []string{"/things/export"}, routertest.Matched(t, router, http.MethodGet, "/api/things/export"))
+		assert.Equal(t, []string{"/things/{id}"}, routertest.Matched(t, router, http.MethodGet, "/api/things/abc"))
+	})
+}
+
+func TestCoverageHandler(t *testing.T) {
+	v, err := copper.NewVerifier(routertest.Spec(t))
+	require.NoError(t, err)
+	e := newServer(v)
+
+	coverage := func() Coverage {
+		rec := routertest.Serve(e, http.MethodGet, "/coverage", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var cov Coverage
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &cov))
+		return cov
+	}
+
+	cov := coverage()
+	assert.Len(t, cov.Unchecked, len(v.Unchecked()))
+	assert.Contains(t, cov.Unchecked, copper.Endpoint{Path: "/things/export", Method: http.MethodGet, ResponseCode: "200"})
+	assert.Empty(t, cov.Errors)
+
+	routertest.Serve(e, http.MethodGet, "/api/things/export", "")
+	routertest.Serve(e, http.MethodDelete, "/api/things/abc", "")
+
+	cov = coverage()
+	assert.NotContains(t, cov.Unchecked, copper.Endpoint{Path: "/things/export", Method: http.MethodGet, ResponseCode: "200"})
+	require.Len(t, cov.Errors, 1)
+	assert.Contains(t, cov.Errors[0], copper.ErrNotPartOfSpec.Error())
+}
+
+func TestRoute(t *testing.T) {
+	assert.Equal(t, "/api/things/{id}", route("/api/things/:id"))
+	assert.Equal(t, "/files/*", route("/files/*"))
+}
@@ -1,7 +1,10 @@
 package copper
 
 import (
+	"cmp"
+	"slices"
 	"strings"
+	"time"
 
 	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
 )
@@ -12,17 +15,32 @@ type methods struct {
 
 type responses struct {
 	responses map[string]bool
+	// operation is where the metadata of the coordinates comes from. It is nil for trees that are not loaded from a spec.
+	operation *v3.Operation
 }
 
 type endpoints struct {
-	paths                     map[string]methods
-	checkInternalServerErrors bool
+	paths map[string]methods
+	conf  config
+	// failures holds the errors found for the coordinates, for reports that are per coordinate.
+	failures map[coordinate][]error
+	// failed counts the errors found for the coordinates, including the ones that the error window has dropped.
+	failed map[coordinate]int
+	// traffic holds what has been recorded for each coordinate.
+	traffic map[coordinate]*traffic
+	// collisions are the pairs of paths that can match the same URL, found when the paths are loaded.
+	collisions []PathCollision
+	// waived are the coordinates of the spec that are left out of the tree, with the reason why.
+	waived []WaivedEndpoint
 }
 
-func newEndpoints(model *v3.Document, checkInternalServerErrors bool) *endpoints {
+func newEndpoints(model *v3.Document, conf config) *endpoints {
 	e := &endpoints{
-		paths:                     make(map[string]methods),
-		checkInternalServerErrors: checkInternalServerErrors,
+		paths:    make(map[string]methods),
+		conf:     conf,
+		failures: make(map[coordinate][]error),
+		failed:   make(map[coordinate]int),
+		traffic:  make(map[coordinate]*traffic),
 	}
 
 	e.loadPaths(model)
@@ -30,43 +48,167 @@ func newEndpoints(model *v3.Document, checkInternalServerErrors bool) *endpoints
 }
 
 func (e *endpoints) loadPaths(model *v3.Document) {
+	if model.Paths == nil {
+		// Documents of OpenAPI 3.1 can have only webhooks or components.
+		return
+	}
 	for path, pathItem := range model.Paths.PathItems.FromOldest() {
 		e.loadPath(path, pathItem)
 	}
+	e.collisions = findCollisions(model)
 }
 
 func (e *endpoints) loadPath(path string, i *v3.PathItem) {
+	for method, op := range i.GetOperations().FromNewest() {
+		reason, excluded := e.conf.filters.excluded(path, strings.ToUpper(method), op)
+		// Operations that are discriminated by a query parameter have a path for each of their logical operations.
+		for _, p := range discriminatedPaths(path, i, op) {
+			if excluded {
+				e.waiveOperation(p, strings.ToUpper(method), op, reason)
+			} else {
+				e.loadOperation(p, strings.ToUpper(method), op)
+			}
+		}
+	}
+}
+
+func (e *endpoints) loadOperation(path, method string, op *v3.Operation) {
 	if _, ok := e.paths[path]; !ok {
 		e.paths[path] = methods{
 			methods: make(map[string]responses),
 		}
 	}
 
-	for method, op := range i.GetOperations().FromNewest() {
-		method = strings.ToUpper(method)
-		if _, ok := e.paths[path].methods[method]; !ok {
-			e.paths[path].methods[method] = responses{
-				responses: make(map[string]bool),
-			}
+	if _, ok := e.paths[path].methods[method]; !ok {
+		e.paths[path].methods[method] = responses{
+			responses: make(map[string]bool),
+			operation: op,
 		}
+	}
 
-		if op.Responses != nil {
-			for responseCode := range op.Responses.Codes.KeysFromNewest() {
-				if !e.checkInternalServerErrors && responseCode == "500" {
-					continue
-				}
-
-				e.paths[path].methods[method].responses[responseCode] = false
+	conf := e.conf.forOperation(op)
+	if op.Responses != nil {
+		for responseCode := range op.Responses.Codes.KeysFromNewest() {
+			if reason, ignored := e.ignored(path, method, op, responseCode); ignored {
+				e.waived = append(e.waived, WaivedEndpoint{
+					Endpoint: e.paths[path].methods[method].endpoint(path, method, responseCode),
+					Reason:   reason,
+				})
+				continue
+			}
+			if !conf.checkInternalServerErrors && responseCode == "500" {
+				e.waived = append(e.waived, WaivedEndpoint{
+					Endpoint: e.paths[path].methods[method].endpoint(path, method, responseCode),
+					Reason:   "500 responses are not required without WithInternalServerErrors or x-copper-strict",
+				})
+				continue
 			}
+
+			e.paths[path].methods[method].responses[responseCode] = false
 		}
 	}
+	// Responses that are ignored without being documented are waived as well, so that the known gaps are listed.
+	for _, responseCode := range e.conf.filters.undocumentedIgnoredResponses(path, method, op) {
+		reason, _ := e.conf.filters.ignoredResponse(path, method, op, responseCode)
+		e.waived = append(e.waived, WaivedEndpoint{
+			Endpoint: e.paths[path].methods[method].endpoint(path, method, responseCode),
+			Reason:   reason,
+		})
+	}
+}
+
+// ignored returns whether a response of an operation is waived by WithIgnoredOperations or WithIgnoredResponses, and
+// the reason why.
+func (e *endpoints) ignored(path, method string, op *v3.Operation, responseCode string) (string, bool) {
+	if reason, ok := e.conf.filters.ignoredOperation(path, method, op); ok {
+		return reason, true
+	}
+	return e.conf.filters.ignoredResponse(path, method, op, responseCode)
+}
+
+// waiveOperation waives every coordinate of an operation that the filters leave out, with the reason why.
+func (e *endpoints) waiveOperation(path, method string, op *v3.Operation, reason string) {
+	if op.Responses == nil {
+		return
+	}
+	r := responses{operation: op}
+	for responseCode := range op.Responses.Codes.KeysFromOldest() {
+		e.waived = append(e.waived, WaivedEndpoint{Endpoint: r.endpoint(path, method, responseCode), Reason: reason})
+	}
 }
 
 // Endpoint represents a single coordinate in the endpoints tree.
 type Endpoint struct {
-	Path         string
-	Method       string
-	ResponseCode string
+	Path         string `json:"path"`
+	Method       string `json:"method"`
+	ResponseCode string `json:"responseCode"`
+	// OperationID, Summary and Tags are taken from the operation of the coordinate, if the spec has them.
+	OperationID string   `json:"operationId,omitempty"`
+	Summary     string   `json:"summary,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+// Operation names the operation of the coordinate for reports, like "getPetById (Pets)". It is the operation id, or
+// the summary if there is none, followed by the tags. An empty string is returned for operations with neither.
+func (e Endpoint) Operation() string {
+	name := cmp.Or(e.OperationID, e.Summary)
+	if name == "" {
+		return ""
+	}
+	if len(e.Tags) > 0 {
+		name += " (" + strings.Join(e.Tags, ", ") + ")"
+	}
+	return name
+}
+
+// coordinate is what identifies an Endpoint, and what the state of the endpoints tree is kept by.
+type coordinate struct {
+	path, method, responseCode string
+}
+
+func (e Endpoint) coordinate() coordinate {
+	return coordinate{path: e.Path, method: e.Method, responseCode: e.ResponseCode}
+}
+
+// MethodStatus is a method and response code pair of a path in the endpoints tree.
+type MethodStatus struct {
+	Method       string `json:"method"`
+	ResponseCode string `json:"responseCode"`
+}
+
+// EndpointStatus is a coordinate in the endpoints tree together with what has been recorded for it.
+type EndpointStatus struct {
+	Endpoint
+	// Checked is true if a request and response have been recorded for the coordinate.
+	Checked bool `json:"checked"`
+	// Failures is the number of errors found for the coordinate.
+	Failures int `json:"failures"`
+	// Hits is the number of times the coordinate has been recorded, which tells an endpoint that was covered by a
+	// single call apart from one that the tests exercise.
+	Hits int `json:"hits"`
+	// FirstSeen and LastSeen are when the coordinate was first and last recorded, and zero if it has not been. In long
+	// runs, they show an endpoint that stopped receiving traffic partway through.
+	FirstSeen time.Time `json:"firstSeen"`
+	LastSeen  time.Time `json:"lastSeen"`
+	// Timings are the average timings of the requests that were sent by the client from WrapClient, and nil if there
+	// were none. They are not part of the saved state.
+	Timings *Timings `json:"timings,omitempty"`
+}
+
+// WaivedEndpoint is a coordinate of the spec that is left out of the coverage, like a 500 response without
+// WithInternalServerErrors, together with the reason why. Reports list these, so that exclusions stay visible.
+type WaivedEndpoint struct {
+	Endpoint
+	Reason string `json:"reason"`
+}
+
+// traffic is what has been recorded for a coordinate.
+type traffic struct {
+	hits        int
+	first, last time.Time
+	// timed is the number of hits with timings, and timings their sum.
+	timed   int
+	timings Timings
 }
 
 func (e *endpoints) responseMap(path, method string) map[string]bool {
@@ -84,6 +226,12 @@ func (e *endpoints) responseMap(path, method string) map[string]bool {
 	return m.responses
 }
 
+// Has returns true if the coordinate is part of the endpoints tree, regardless of it being checked or not.
+func (e *endpoints) Has(path, method, resCode string) bool {
+	_, ok := e.responseMap(path, method)[resCode]
+	return ok
+}
+
 func (e *endpoints) IsChecked(path, method, resCode string) bool {
 	r := e.responseMap(path, method)
 	return r[resCode]
@@ -98,11 +246,7 @@ func (e *endpoints) Unchecked() []Endpoint {
 		for method, r := range m.methods {
 			for resCode, checked := range r.responses {
 				if !checked {
-					ends = append(ends, Endpoint{
-						Path:         path,
-						Method:       method,
-						ResponseCode: resCode,
-					})
+					ends = append(ends, r.endpoint(path, method, resCode))
 				}
 			}
 		}
@@ -110,14 +254,140 @@ func (e *endpoints) Unchecked() []Endpoint {
 	return ends
 }
 
-// MarkChecked will set an endpoint as checked, but only if it has been previously inserted. Will return false if
-// no endpoint is present for the coordinate. Returns true even if the endpoint was previously checked.
+// Coverage returns the percentage of the coordinates in the endpoints tree that have been checked, which is 100 for a
+// tree without coordinates.
+func (e *endpoints) Coverage() float64 {
+	total := len(e.All())
+	if total == 0 {
+		return 100
+	}
+	return float64(total-len(e.Unchecked())) * 100 / float64(total)
+}
+
+// MarkChecked will set an endpoint as checked and note the hit and its time, but only if it has been previously
+// inserted. Will return false if no endpoint is present for the coordinate, like for a response code that the spec
+// does not document. Returns true even if the endpoint was previously checked.
 func (e *endpoints) MarkChecked(path, method, resCode string) bool {
-	r := e.responseMap(path, method)
-	if r == nil {
+	if !e.Has(path, method, resCode) {
 		return false
 	}
 
-	r[resCode] = true
+	e.responseMap(path, method)[resCode] = true
+	now := time.Now()
+	c := coordinate{path: path, method: strings.ToUpper(method), responseCode: resCode}
+	t, ok := e.traffic[c]
+	if !ok {
+		t = &traffic{first: now}
+		e.traffic[c] = t
+	}
+	t.hits++
+	t.last = now
 	return true
 }
+
+// All returns every coordinate in the endpoints tree, sorted by path, method and response code.
+func (e *endpoints) All() []Endpoint {
+	var ends []Endpoint
+
+	for path, m := range e.paths {
+		for method, r := range m.methods {
+			for resCode := range r.responses {
+				ends = append(ends, r.endpoint(path, method, resCode))
+			}
+		}
+	}
+	sortEndpoints(ends)
+	return ends
+}
+
+// Waived returns the coordinates that are left out of the tree, sorted by path, method and response code.
+func (e *endpoints) Waived() []WaivedEndpoint {
+	waived := slices.Clone(e.waived)
+	slices.SortFunc(waived, func(a, b WaivedEndpoint) int {
+		return compareEndpoints(a.Endpoint, b.Endpoint)
+	})
+	return waived
+}
+
+// AddFailure notes an error found for a coordinate, but only if it is part of the endpoints tree. With an error window,
+// only the most recent errors of the coordinate are kept, but all of them are counted.
+func (e *endpoints) AddFailure(end Endpoint, err error) {
+	if !e.Has(end.Path, end.Method, end.ResponseCode) {
+		return
+	}
+	c := end.coordinate()
+	e.failed[c]++
+	e.failures[c] = append(e.failures[c], err)
+	if excess := len(e.failures[c]) - e.conf.errorWindow; e.conf.errorWindow > 0 && excess > 0 {
+		e.failures[c] = slices.Delete(e.failures[c], 0, excess)
+	}
+}
+
+// Failures returns the errors found for a coordinate.
+func (e *endpoints) Failures(end Endpoint) []error {
+	return e.failures[end.coordinate()]
+}
+
+// Traffic returns how many times a coordinate has been recorded, and when it was first and last recorded.
+func (e *endpoints) Traffic(end Endpoint) (int, time.Time, time.Time) {
+	t, ok := e.traffic[end.coordinate()]
+	if !ok {
+		return 0, time.Time{}, time.Time{}
+	}
+	return t.hits, t.first, t.last
+}
+
+// AddTimings adds the timings of a request to a coordinate that has been marked as checked.
+func (e *endpoints) AddTimings(end Endpoint, timings Timings) {
+	t, ok := e.traffic[end.coordinate()]
+	if !ok {
+		return
+	}
+	t.timed++
+	t.timings.DNS += timings.DNS
+	t.timings.Connect += timings.Connect
+	t.timings.TLS += timings.TLS
+	t.timings.TTFB += timings.TTFB
+}
+
+// Timings returns the average timings of a coordinate, or nil if none have been added.
+func (e *endpoints) Timings(end Endpoint) *Timings {
+	t, ok := e.traffic[end.coordinate()]
+	if !ok || t.timed == 0 {
+		return nil
+	}
+	n := time.Duration(t.timed)
+	return &Timings{
+		DNS:     t.timings.DNS / n,
+		Connect: t.timings.Connect / n,
+		TLS:     t.timings.TLS / n,
+		TTFB:    t.timings.TTFB / n,
+	}
+}
+
+// endpoint returns the Endpoint for a response code of the method, with the metadata of its operation.
+func (r responses) endpoint(path, method, resCode string) Endpoint {
+	end := Endpoint{
+		Path:         path,
+		Method:       method,
+		ResponseCode: resCode,
+	}
+	if r.operation != nil {
+		end.OperationID = r.operation.OperationId
+		end.Summary = r.operation.Summary
+		end.Tags = r.operation.Tags
+	}
+	return end
+}
+
+func sortEndpoints(ends []Endpoint) {
+	slices.SortFunc(ends, compareEndpoints)
+}
+
+func compareEndpoints(a, b Endpoint) int {
+	return cmp.Or(
+		strings.Compare(a.Path, b.Path),
+		strings.Compare(a.Method, b.Method),
+		strings.Compare(a.ResponseCode, b.ResponseCode),
+	)
+}
@@ -3,6 +3,7 @@ package copper
 import (
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -26,6 +27,7 @@ func TestEndpoints_MarkChecked(t *testing.T) {
 				},
 			},
 		},
+		traffic: make(map[coordinate]*traffic),
 	}
 
 	tt := []struct {
@@ -49,6 +51,13 @@ func TestEndpoints_MarkChecked(t *testing.T) {
 			"201",
 			false,
 		},
+		{
+			"undocumented response code will not be marked",
+			"/study/other/{id}",
+			http.MethodGet,
+			"500",
+			false,
+		},
 		{
 			"inserted endpoint can be marked",
 			"/study/other/{id}",
@@ -64,6 +73,18 @@ func TestEndpoints_MarkChecked(t *testing.T) {
 			assert.Equal(t, tc.expected, e.IsChecked(tc.path, tc.method, tc.resCode))
 		})
 	}
+
+	before := time.Now()
+	e.MarkChecked("/study/my/{id}", "put", "200")
+	hits, first, last := e.Traffic(Endpoint{Path: "/study/my/{id}", Method: http.MethodPut, ResponseCode: "200"})
+	assert.Equal(t, 2, hits)
+	assert.True(t, first.Before(before) || first.Equal(before))
+	assert.False(t, last.Before(before))
+
+	hits, first, last = e.Traffic(Endpoint{Path: "/other/endpoint", Method: http.MethodPut, ResponseCode: "201"})
+	assert.Equal(t, 0, hits)
+	assert.True(t, first.IsZero())
+	assert.True(t, last.IsZero())
 }
 
 func TestIsChecked(t *testing.T) {
@@ -2,6 +2,10 @@ package copper
 
 import (
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"text/template"
 )
 
 var _ error = SentinelError{}
@@ -19,6 +23,13 @@ var (
 	ErrNotPartOfSpec   = SentinelError{"not part of spec"}
 	ErrResponseInvalid = SentinelError{"response invalid"}
 	ErrRequestInvalid  = SentinelError{"request invalid"}
+	// ErrUnsupportedBodyFormat is for bodies with a documented schema that can not be validated, which is not the same
+	// as a body that is wrong.
+	ErrUnsupportedBodyFormat = SentinelError{"unsupported body format"}
+	// ErrSecurityViolation is for requests that break the security of the spec, like missing credentials or an API key
+	// in the wrong place, and for security schemes that are never exercised, so they can be told apart from the schema
+	// errors of requests.
+	ErrSecurityViolation = SentinelError{"security violation"}
 )
 
 func joinError(sentinel SentinelError, err error) *VerificationError {
@@ -31,16 +42,99 @@ func joinError(sentinel SentinelError, err error) *VerificationError {
 type VerificationError struct {
 	err      error
 	sentinel SentinelError
+	// template renders the error instead of the usual format, if it is set.
+	template *template.Template
+	// requestID is the ID of the request that the error was found in, if request IDs are used.
+	requestID string
+	// kind and endpoint are what the error was found for, if it was found in an exchange, and cause is the error
+	// without the method and path of the request that the message starts with. They are the details of Failures.
+	kind     FailureKind
+	endpoint Endpoint
+	cause    error
 }
 
 func (v *VerificationError) Sentinel() error {
 	return v.sentinel
 }
 
+// RequestID returns the ID of the request that the error was found in, from the X-Request-ID header, or an empty
+// string if there is none or WithRequestIDs is not given.
+func (v *VerificationError) RequestID() string {
+	return v.requestID
+}
+
 func (v *VerificationError) Error() string {
-	return fmt.Sprintf("%v: %v", v.sentinel.Error(), v.err.Error())
+	msg := fmt.Sprintf("%v: %v", v.sentinel.Error(), v.err.Error())
+	if v.requestID != "" {
+		msg += fmt.Sprintf(" (request ID %s)", v.requestID)
+	}
+	if v.template == nil {
+		return msg
+	}
+
+	data := ErrorData{Sentinel: v.sentinel.Error(), Message: v.err.Error(), RequestID: v.requestID}
+	rendered, ok := render(v.template, data)
+	if !ok {
+		return fmt.Sprintf("%s (error template failed: %s)", msg, rendered)
+	}
+	return rendered
 }
 
 func (v *VerificationError) Unwrap() []error {
 	return []error{v.err, v.sentinel}
 }
+
+// CoverageError is the error for the coordinates of the spec that have not been checked, when full coverage is
+// required, or the coverage is below the threshold of WithCoverageThreshold. It is wrapped in a VerificationError for
+// ErrNotChecked, and can be inspected with errors.As.
+type CoverageError struct {
+	// Missing are the method and response code pairs that have not been checked, by path, as UncheckedByPath returns.
+	Missing map[string][]MethodStatus
+	// Coverage is the percentage of the coordinates that have been checked, and Threshold the one that
+	// WithCoverageThreshold requires, which is zero when full coverage is required.
+	Coverage  float64
+	Threshold float64
+}
+
+// Count returns the number of coordinates that have not been checked.
+func (c *CoverageError) Count() int {
+	n := 0
+	for _, missing := range c.Missing {
+		n += len(missing)
+	}
+	return n
+}
+
+// Error lists the coordinates that have not been checked, on a line per path, like "/things/{id}: GET 200, 404; PUT
+// 204". Below a threshold, the coverage is given as well.
+func (c *CoverageError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d %s on %d %s", c.Count(), plural(c.Count(), "coordinate"), len(c.Missing),
+		plural(len(c.Missing), "path"))
+	if c.Threshold > 0 {
+		fmt.Fprintf(&b, ", which leaves the coverage at %.1f%%, below the threshold of %g%%", c.Coverage, c.Threshold)
+	}
+	for _, path := range slices.Sorted(maps.Keys(c.Missing)) {
+		b.WriteString("\n  " + path + ": ")
+		for i, m := range c.Missing[path] {
+			switch {
+			case i == 0:
+			case m.Method == c.Missing[path][i-1].Method:
+				b.WriteString(", ")
+				b.WriteString(m.ResponseCode)
+				continue
+			default:
+				b.WriteString("; ")
+			}
+			b.WriteString(m.Method + " " + m.ResponseCode)
+		}
+	}
+	return b.String()
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return noun
+	}
+	return noun + "s"
+}
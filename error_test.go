@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestJoinErrorIs(t *testing.T) {
@@ -38,3 +39,23 @@ func TestJoinErrorIs(t *testing.T) {
 		assert.ErrorContains(t, err, "my test error")
 	})
 }
+
+func TestCoverageError(t *testing.T) {
+	err := joinError(ErrNotChecked, &CoverageError{Missing: map[string][]MethodStatus{
+		"/things/{id}": {{"GET", "200"}, {"GET", "404"}, {"PUT", "204"}},
+		"/ping":        {{"GET", "200"}},
+	}})
+	assert.ErrorIs(t, err, ErrNotChecked)
+	assert.EqualError(t, err, "not checked: 4 coordinates on 2 paths\n  /ping: GET 200\n  /things/{id}: GET 200, 404; PUT 204")
+
+	var coverage *CoverageError
+	require.ErrorAs(t, err, &coverage)
+	assert.Equal(t, 4, coverage.Count())
+	assert.Len(t, coverage.Missing, 2)
+
+	t.Run("below a threshold", func(t *testing.T) {
+		err := &CoverageError{Missing: map[string][]MethodStatus{"/ping": {{"GET", "200"}}}, Coverage: 66.66, Threshold: 80}
+		assert.EqualError(t, err, "1 coordinate on 1 path, which leaves the coverage at 66.7%, below the threshold of 80%\n"+
+			"  /ping: GET 200")
+	})
+}
@@ -0,0 +1,173 @@
+package copper
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"gopkg.in/yaml.v3"
+)
+
+// WithExampleValidation is a functional Option for checking the examples of the spec against their schemas when the
+// verifier is created, so that examples that have drifted from the schemas are found before any traffic is recorded.
+// The example and examples of parameters, request bodies, responses and response headers are checked, along with the
+// example of every schema in the components. Bodies are only checked for JSON media types, and the examples of the
+// properties of a schema are not checked. A spec with examples that do not match is rejected.
+func WithExampleValidation() Option {
+	return func(c *config) {
+		c.exampleValidation = true
+	}
+}
+
+// exampleChecker collects the errors of the examples of a spec. Examples that are shared through references are only
+// checked once.
+type exampleChecker struct {
+	v       *Verifier
+	checked map[*yaml.Node]bool
+	errs    []error
+}
+
+// checkExamples returns an error for every example of the spec that does not match its schema.
+func (v *Verifier) checkExamples() error {
+	c := exampleChecker{v: v, checked: make(map[*yaml.Node]bool)}
+	if v.model.Paths != nil {
+		for path, item := range v.model.Paths.PathItems.FromOldest() {
+			for method, op := range item.GetOperations().FromOldest() {
+				c.checkOperation(strings.ToUpper(method)+" "+path+": ", item, op)
+			}
+		}
+	}
+	if v.model.Components != nil {
+		for name, proxy := range v.model.Components.Schemas.FromOldest() {
+			if s := proxy.Schema(); s != nil {
+				c.check(s.Example, proxy, "", "the example of the schema "+name)
+			}
+		}
+	}
+	return errors.Join(c.errs...)
+}
+
+func (c *exampleChecker) checkOperation(prefix string, item *v3.PathItem, op *v3.Operation) {
+	for _, p := range append(slices.Clone(item.Parameters), op.Parameters...) {
+		c.checkAll(p.Example, p.Examples, p.Schema, prefix, fmt.Sprintf("of the %s parameter %s", p.In, p.Name))
+	}
+
+	if op.RequestBody != nil {
+		c.checkContent(op.RequestBody.Content, prefix, "of the request body")
+	}
+
+	if op.Responses == nil {
+		return
+	}
+	for code, response := range op.Responses.Codes.FromOldest() {
+		c.checkResponse(response, prefix, code)
+	}
+	if op.Responses.Default != nil {
+		c.checkResponse(op.Responses.Default, prefix, "default")
+	}
+}
+
+func (c *exampleChecker) checkResponse(response *v3.Response, prefix, code string) {
+	c.checkContent(response.Content, prefix, "of the "+code+" response")
+	for name, header := range response.Headers.FromOldest() {
+		c.checkAll(header.Example, header.Examples, header.Schema, prefix, fmt.Sprintf("of the %s header of the %s response", name, code))
+	}
+}
+
+// checkContent checks the examples of the JSON media types of the content.
+func (c *exampleChecker) checkContent(content *orderedmap.Map[string, *v3.MediaType], prefix, of string) {
+	for mediaType, m := range content.FromOldest() {
+		if strings.Contains(mediaType, "json") {
+			c.checkAll(m.Example, m.Examples, m.Schema, prefix, of+" as "+mediaType)
+		}
+	}
+}
+
+// checkAll checks the example and the examples of something with a schema, which the description says what it is.
+func (c *exampleChecker) checkAll(example *yaml.Node, examples *orderedmap.Map[string, *base.Example], schema *base.SchemaProxy, prefix, of string) {
+	c.check(example, schema, prefix, "the example "+of)
+	for name, e := range examples.FromOldest() {
+		c.check(e.Value, schema, prefix, fmt.Sprintf("the example %q %s", name, of))
+	}
+}
+
+// check checks a single example against the schema, unless either is missing or the example has been checked before.
+func (c *exampleChecker) check(example *yaml.Node, schema *base.SchemaProxy, prefix, what string) {
+	if example == nil || schema == nil || c.checked[example] {
+		return
+	}
+	c.checked[example] = true
+
+	if err := c.v.validateExample(example, schema); err != nil {
+		c.errs = append(c.errs, fmt.Errorf("%s%s on line %d does not match its schema: %w", prefix, what, example.Line, err))
+	}
+}
+
+// validateExample validates the example against the schema. The schema is compiled inline like the validator library
+// does it, or from the spec document like the recursive schemas if it references itself.
+func (v *Verifier) validateExample(example *yaml.Node, proxy *base.SchemaProxy) error {
+	var value any
+	if err := example.Decode(&value); err != nil {
+		return fmt.Errorf("could not read example: %w", err)
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("example is not JSON: %w", err)
+	}
+	decoded, err := jsonschema.UnmarshalJSON(bytes.NewReader(b))
+	if err != nil {
+		return fmt.Errorf("example is not JSON: %w", err)
+	}
+
+	schema, err := v.exampleSchema(proxy)
+	if err != nil {
+		return err
+	}
+	err = schema.Validate(decoded)
+	var verr *jsonschema.ValidationError
+	if !errors.As(err, &verr) {
+		return err
+	}
+
+	bestUnionCauses(verr)
+	var problems []string
+	for _, p := range schemaParts(verr, "", decoded) {
+		if p.location == "" {
+			problems = append(problems, p.message)
+			continue
+		}
+		problems = append(problems, fmt.Sprintf("%s at %s", p.message, p.location))
+	}
+	return errors.New(strings.Join(problems, "; "))
+}
+
+// exampleSchema compiles the schema for validating examples.
+func (v *Verifier) exampleSchema(proxy *base.SchemaProxy) (*jsonschema.Schema, error) {
+	s := proxy.Schema()
+	if s == nil {
+		return nil, fmt.Errorf("could not read schema: %w", proxy.GetBuildError())
+	}
+	if rendered, err := s.RenderInline(); err == nil {
+		if schema, err := compileInline(rendered, v.conf.ecmaPatterns); err == nil {
+			return schema, nil
+		}
+	}
+	if !proxy.IsReference() {
+		return nil, errors.New("could not compile schema")
+	}
+
+	r := v.recursive
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.loadErr != nil {
+		return nil, fmt.Errorf("could not load spec for recursive schema: %w", r.loadErr)
+	}
+	return r.compiler.Compile(specResource + proxy.GetReference())
+}
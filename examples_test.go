@@ -0,0 +1,74 @@
+package copper
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExampleValidation(t *testing.T) {
+	f, err := os.ReadFile("testdata/examples-spec.yaml")
+	require.NoError(t, err)
+
+	t.Run("mismatches are reported", func(t *testing.T) {
+		_, err := NewVerifier(f, WithExampleValidation())
+		require.Error(t, err)
+		lines := strings.Split(err.Error(), "\n")
+		assert.Equal(t, []string{
+			"examples do not match their schemas: GET /things: the example of the query parameter limit on line 14 does not match its schema: maximum: got 500, want 100",
+			"GET /things: the example of the 200 response as application/json on line 30 does not match its schema: got string, want integer at /1/id",
+			"GET /things: the example of the X-Total header of the 200 response on line 22 does not match its schema: got string, want integer",
+			`POST /things: the example "unnamed" of the request body as application/json on line 78 does not match its schema: missing property 'name'`,
+			"GET /tree: the example of the 200 response as application/json on line 71 does not match its schema: got number, want string at /children/0/name",
+			"the example of the schema Color on line 94 does not match its schema: value must be one of 'red', 'green'",
+		}, lines)
+	})
+
+	t.Run("matching examples", func(t *testing.T) {
+		spec := `openapi: 3.0.1
+info:
+  title: examples test
+  version: '1.0'
+paths:
+  /things/{id}:
+    get:
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: integer
+          examples:
+            first:
+              value: 1
+      responses:
+        "200":
+          description: The thing
+          content:
+            application/json:
+              schema:
+                type: object
+                nullable: true
+                properties:
+                  name:
+                    type: string
+              example:
+                name: first
+            text/plain:
+              schema:
+                type: object
+              example: not an object
+`
+		_, err := NewVerifier([]byte(spec), WithExampleValidation())
+		assert.NoError(t, err)
+	})
+
+	t.Run("only with the option", func(t *testing.T) {
+		v, err := NewVerifier(f)
+		require.NoError(t, err)
+		assert.ErrorIs(t, v.SetOptions(WithExampleValidation()), ErrInvalidOptions)
+	})
+}
@@ -0,0 +1,132 @@
+package copper
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// extStrict turns on the same checks for an operation as the Strict option: request validation, coverage of 500
+	// responses, strict formats and strict response properties.
+	extStrict = "x-copper-strict"
+	// extRequestValidation turns request validation on or off for an operation.
+	extRequestValidation = "x-copper-request-validation"
+	// extResponseValidation turns response validation on or off for an operation.
+	extResponseValidation = "x-copper-response-validation"
+	// extMaxResponseBytes is the largest size in bytes that the response bodies of an operation are documented to have.
+	extMaxResponseBytes = "x-max-response-bytes"
+	// extDiscriminatorParam names the query parameter whose values tell the logical operations of an operation apart.
+	extDiscriminatorParam = "x-copper-discriminator-param"
+)
+
+// forOperation returns the config to use for a single operation, with the overrides from the extensions of the
+// operation applied. The more specific extensions take precedence over x-copper-strict.
+func (c config) forOperation(op *v3.Operation) config {
+	if op == nil {
+		return c
+	}
+
+	if extensionBool(op.Extensions, extStrict) {
+		c.checkRequest = true
+		c.checkInternalServerErrors = true
+		c.strictFormats = true
+		c.strictResponseProperties = true
+		c.disableResponseValidation = false
+	}
+	if on, ok := extensionSwitch(op.Extensions, extRequestValidation); ok {
+		c.checkRequest = on
+	}
+	if on, ok := extensionSwitch(op.Extensions, extResponseValidation); ok {
+		c.disableResponseValidation = !on
+	}
+	return c
+}
+
+// extensionSwitch returns the value of an extension that turns something on or off. Both booleans and "on"/"off" are
+// accepted. The second return value is false if the extension is not present or has some other value.
+func extensionSwitch(ext *orderedmap.Map[string, *yaml.Node], name string) (bool, bool) {
+	if ext == nil {
+		return false, false
+	}
+
+	node := ext.GetOrZero(name)
+	if node == nil {
+		return false, false
+	}
+
+	switch strings.ToLower(node.Value) {
+	case "on", "true":
+		return true, true
+	case "off", "false":
+		return false, true
+	}
+	return false, false
+}
+
+// extensionBool returns the boolean value of the named extension, and false if it is not present or not a boolean.
+func extensionBool(ext *orderedmap.Map[string, *yaml.Node], name string) bool {
+	if ext == nil {
+		return false
+	}
+
+	node := ext.GetOrZero(name)
+	if node == nil {
+		return false
+	}
+
+	b, err := strconv.ParseBool(node.Value)
+	return err == nil && b
+}
+
+// responseBudget returns the documented maximum size of the response bodies of the operation, and false if there is
+// none.
+func responseBudget(op *v3.Operation) (int64, bool, error) {
+	if op == nil || op.Extensions == nil {
+		return 0, false, nil
+	}
+
+	node := op.Extensions.GetOrZero(extMaxResponseBytes)
+	if node == nil {
+		return 0, false, nil
+	}
+
+	n, err := strconv.ParseInt(node.Value, 10, 64)
+	if err != nil || n < 0 || node.Kind != yaml.ScalarNode {
+		return 0, false, fmt.Errorf("%s must be a number of bytes, not %q", extMaxResponseBytes, node.Value)
+	}
+	return n, true, nil
+}
+
+// checkResponseBudgets checks that the budgets of all operations are numbers of bytes, so that a typo does not go
+// unnoticed as a budget that is never enforced.
+func checkResponseBudgets(doc *v3.Document) error {
+	if doc.Paths == nil {
+		return nil
+	}
+
+	var errs []error
+	for path, item := range doc.Paths.PathItems.FromOldest() {
+		for method, op := range item.GetOperations().FromOldest() {
+			if _, _, err := responseBudget(op); err != nil {
+				errs = append(errs, fmt.Errorf("%s %s: %w", strings.ToUpper(method), path, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkResponseSize checks the size of the response body against the budget of the operation. Payload bloat is part of
+// the contract, but not something a schema can express.
+func checkResponseSize(op *v3.Operation, size int64) error {
+	budget, ok, _ := responseBudget(op)
+	if ok && size > budget {
+		return fmt.Errorf("response body is %d bytes, which exceeds the budget of %d bytes", size, budget)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package copper
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOperationOverrides(t *testing.T) {
+	f, err := os.ReadFile("testdata/strictness-spec.yaml")
+	require.NoError(t, err)
+
+	t.Run("request validation can be turned off", func(t *testing.T) {
+		v, err := NewVerifier(f, WithRequestValidation(), WithoutFullCoverage())
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/lenient?id=nope", nil)
+		v.Record(&http.Response{StatusCode: 204, Request: req})
+		assert.NoError(t, v.CurrentError())
+	})
+
+	t.Run("strict operations validate requests", func(t *testing.T) {
+		v, err := NewVerifier(f, WithoutFullCoverage())
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/strict?id=nope", nil)
+		v.Record(&http.Response{StatusCode: 204, Request: req})
+		assert.ErrorIs(t, v.CurrentError(), ErrRequestInvalid)
+	})
+
+	t.Run("strict operations check formats and properties", func(t *testing.T) {
+		tt := []struct {
+			name string
+			body string
+			err  string
+		}{
+			{"valid", `{"created": "2024-01-02T03:04:05Z"}`, ""},
+			{"format", `{"created": "yesterday"}`, "date-time"},
+			{"undocumented property", `{"created": "2024-01-02T03:04:05Z", "extra": true}`, "extra"},
+		}
+		for _, tc := range tt {
+			t.Run(tc.name, func(t *testing.T) {
+				v, err := NewVerifier(f, WithoutFullCoverage())
+				require.NoError(t, err)
+
+				v.Record(&http.Response{
+					StatusCode: 200,
+					Request:    httptest.NewRequest(http.MethodGet, "/strict?id=1", nil),
+					Header:     http.Header{"Content-Type": []string{"application/json"}},
+					Body:       io.NopCloser(strings.NewReader(tc.body)),
+				})
+				if tc.err == "" {
+					assert.NoError(t, v.CurrentError())
+				} else {
+					assert.ErrorIs(t, v.CurrentError(), ErrResponseInvalid)
+					assert.ErrorContains(t, v.CurrentError(), tc.err)
+				}
+			})
+		}
+	})
+
+	t.Run("strict operations require 500 coverage", func(t *testing.T) {
+		v, err := NewVerifier(f)
+		require.NoError(t, err)
+
+		assert.True(t, v.endpoints.Has("/strict", http.MethodGet, "500"))
+		assert.False(t, v.endpoints.Has("/lenient", http.MethodGet, "500"))
+	})
+
+	t.Run("response validation can be turned off", func(t *testing.T) {
+		v, err := NewVerifier(f, WithoutFullCoverage())
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/unvalidated", nil)
+		v.Record(&http.Response{
+			StatusCode: 200,
+			Request:    req,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader("[]")),
+		})
+		assert.NoError(t, v.CurrentError())
+	})
+}
+
+func TestResponseBudget(t *testing.T) {
+	f, err := os.ReadFile("testdata/strictness-spec.yaml")
+	require.NoError(t, err)
+
+	tt := []struct {
+		name  string
+		body  string
+		valid bool
+	}{
+		{"within budget", "[1, 2, 3]", true},
+		{"exactly the budget", "[1, 2, 3, 4, 5 ]", true},
+		{"over budget", "[1, 2, 3, 4, 5, 6]", false},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			v, err := NewVerifier(f, WithoutFullCoverage())
+			require.NoError(t, err)
+
+			v.Record(&http.Response{
+				StatusCode: 200,
+				Request:    httptest.NewRequest(http.MethodGet, "/budget", nil),
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(tc.body)),
+			})
+			if tc.valid {
+				assert.NoError(t, v.CurrentError())
+			} else {
+				assert.ErrorIs(t, v.CurrentError(), ErrResponseInvalid)
+				assert.ErrorContains(t, v.CurrentError(), "response body is 18 bytes, which exceeds the budget of 16 bytes")
+			}
+		})
+	}
+
+	t.Run("invalid budget", func(t *testing.T) {
+		spec := strings.Replace(string(f), "x-max-response-bytes: 16", "x-max-response-bytes: 16kb", 1)
+		_, err := NewVerifier([]byte(spec))
+		assert.ErrorContains(t, err, `GET /budget: x-max-response-bytes must be a number of bytes, not "16kb"`)
+	})
+}
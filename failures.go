@@ -0,0 +1,288 @@
+package copper
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"maps"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pb33f/libopenapi-validator/helpers"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	validatorerr "github.com/pb33f/libopenapi-validator/errors"
+)
+
+// FailureKind is what a ValidationFailure is about.
+type FailureKind string
+
+const (
+	// FailureRequest is for requests that do not match the spec, security violations included.
+	FailureRequest FailureKind = "request"
+	// FailureResponse is for responses that do not match the spec.
+	FailureResponse FailureKind = "response"
+	// FailureCoverage is for the parts of the spec that have not been covered.
+	FailureCoverage FailureKind = "coverage"
+	// FailureUnknownPath is for requests to paths or methods that are not part of the spec.
+	FailureUnknownPath FailureKind = "unknown-path"
+)
+
+// ValidationFailure is a single failure of the verification, with the details of it as fields rather than in a message,
+// so that tooling can aggregate and deduplicate failures without parsing the errors. An error that lists several
+// problems, like a body with more than one invalid property, is a failure per problem.
+type ValidationFailure struct {
+	Kind FailureKind `json:"kind"`
+	// Sentinel is the sentinel error of the failure, like ErrResponseInvalid.
+	Sentinel SentinelError `json:"-"`
+	// Path is the path in the spec that the failure is for, like /things/{id}, or the path of the request for
+	// FailureUnknownPath. It is empty for failures that are not for a single path.
+	Path       string `json:"path,omitempty"`
+	Method     string `json:"method,omitempty"`
+	StatusCode int    `json:"statusCode,omitempty"`
+	// Pointer is the JSON pointer to the location in the spec that failed, like the keyword of a schema for an invalid
+	// body, or the response for a coordinate that is not covered. References in the schema of a body are followed as if
+	// they were inlined, unless the schema references itself.
+	Pointer string `json:"pointer,omitempty"`
+	// Location is the JSON pointer to the offending value in the body, and Value is that value, for failures of a schema.
+	Location string `json:"location,omitempty"`
+	Value    any    `json:"value,omitempty"`
+	// Message is the problem, without the sentinel, method and path of the error that it is part of.
+	Message   string `json:"message"`
+	RequestID string `json:"requestId,omitempty"`
+}
+
+// MarshalJSON writes the failure with the sentinel as its message.
+func (f ValidationFailure) MarshalJSON() ([]byte, error) {
+	type failure ValidationFailure
+	return json.Marshal(struct {
+		Sentinel string `json:"sentinel"`
+		failure
+	}{Sentinel: f.Sentinel.msg, failure: failure(f)})
+}
+
+// Failures returns the current failures of the Verifier, broken down from the errors that CurrentErrors returns. The
+// errors of coverage are a failure per coordinate that has not been checked.
+func (v *Verifier) Failures() []ValidationFailure {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	var failures []ValidationFailure
+	for _, err := range v.currentErrors() {
+		var verr *VerificationError
+		if errors.As(err, &verr) {
+			failures = append(failures, verr.failures()...)
+		}
+	}
+	return failures
+}
+
+// failures breaks the error down into a failure per problem.
+func (v *VerificationError) failures() []ValidationFailure {
+	base := ValidationFailure{
+		Kind:      v.failureKind(),
+		Sentinel:  v.sentinel,
+		Path:      v.endpoint.Path,
+		Method:    v.endpoint.Method,
+		RequestID: v.requestID,
+	}
+	base.StatusCode, _ = strconv.Atoi(v.endpoint.ResponseCode)
+
+	var coverage *CoverageError
+	if errors.As(v.err, &coverage) {
+		var failures []ValidationFailure
+		for _, path := range slices.Sorted(maps.Keys(coverage.Missing)) {
+			for _, m := range coverage.Missing[path] {
+				f := base
+				f.Path, f.Method = path, m.Method
+				f.StatusCode, _ = strconv.Atoi(m.ResponseCode)
+				specPath, _, _ := strings.Cut(path, "?")
+				f.Pointer = pointer("paths", specPath, strings.ToLower(m.Method), "responses", m.ResponseCode)
+				f.Message = m.Method + " " + m.ResponseCode + " is not checked"
+				failures = append(failures, f)
+			}
+		}
+		return failures
+	}
+
+	cause := v.cause
+	if cause == nil {
+		cause = v.err
+	}
+	parts := failureParts(cause)
+	failures := make([]ValidationFailure, 0, len(parts))
+	for _, p := range parts {
+		f := base
+		f.Pointer, f.Location, f.Value, f.Message = p.pointer, p.location, p.value, p.message
+		failures = append(failures, f)
+	}
+	return failures
+}
+
+// failureKind returns the kind that the error was found as, or the one of its sentinel if it was not found in an
+// exchange.
+func (v *VerificationError) failureKind() FailureKind {
+	if v.kind != "" {
+		return v.kind
+	}
+	switch v.sentinel {
+	case ErrNotChecked:
+		return FailureCoverage
+	case ErrNotPartOfSpec:
+		return FailureUnknownPath
+	case ErrRequestInvalid, ErrSecurityViolation:
+		return FailureRequest
+	default:
+		return FailureResponse
+	}
+}
+
+// failurePart is a single problem in an error.
+type failurePart struct {
+	pointer  string
+	location string
+	value    any
+	message  string
+}
+
+// failureParts splits the joined errors of the error, and the problems of the schema failures in them.
+func failureParts(err error) []failurePart {
+	switch e := err.(type) {
+	case *bodyError:
+		return e.parts
+	case interface{ Unwrap() []error }:
+		var parts []failurePart
+		for _, err := range e.Unwrap() {
+			parts = append(parts, failureParts(err)...)
+		}
+		return parts
+	}
+	return []failurePart{{message: err.Error()}}
+}
+
+// bodyError is an error of the validation of a body, along with the problems of the schema failures in it. It reads
+// the same as the error that it wraps.
+type bodyError struct {
+	err   error
+	parts []failurePart
+}
+
+func (b *bodyError) Error() string {
+	return b.err.Error()
+}
+
+func (b *bodyError) Unwrap() error {
+	return b.err
+}
+
+// failurePrinter prints the messages of schema failures, like the validator library does.
+var failurePrinter = message.NewPrinter(language.English)
+
+// newBodyError wraps the error of validating the body against the schema at the location in the spec, so that the
+// schema failures in it can be told apart. The validator library lists the failures of the parameters along with the
+// ones of the body, and those are kept as they are.
+func newBodyError(err error, location string, body []byte) error {
+	if err == nil {
+		return nil
+	}
+
+	var decoded any
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if dec.Decode(&decoded) != nil {
+		decoded = nil
+	}
+
+	var parts func(error) []failurePart
+	parts = func(err error) []failurePart {
+		switch e := err.(type) {
+		case *jsonschema.ValidationError:
+			return schemaParts(e, location, decoded)
+		case *validatorerr.ValidationError:
+			if p := validatorParts(e, location, decoded); p != nil {
+				return p
+			}
+		case interface{ Unwrap() []error }:
+			var result []failurePart
+			for _, err := range e.Unwrap() {
+				result = append(result, parts(err)...)
+			}
+			return result
+		}
+		return []failurePart{{message: err.Error()}}
+	}
+	// The JSON schema library finds the failures of properties in no particular order.
+	sorted := parts(err)
+	slices.SortStableFunc(sorted, func(a, b failurePart) int {
+		return cmp.Or(strings.Compare(a.location, b.location), strings.Compare(a.pointer, b.pointer))
+	})
+	return &bodyError{err: err, parts: sorted}
+}
+
+// schemaParts returns a part for every failure without causes in the error of the JSON schema library. Schemas that
+// are compiled from the spec document have locations in it, where others are compiled from the schema at the location.
+func schemaParts(err *jsonschema.ValidationError, location string, decoded any) []failurePart {
+	if len(err.Causes) > 0 {
+		var parts []failurePart
+		for _, c := range err.Causes {
+			parts = append(parts, schemaParts(c, location, decoded)...)
+		}
+		return parts
+	}
+
+	resource, fragment, _ := strings.Cut(err.SchemaURL, "#")
+	if unescaped, uerr := url.PathUnescape(fragment); uerr == nil {
+		fragment = unescaped
+	}
+	p := fragment + pointer(err.ErrorKind.KeywordPath()...)
+	if resource != specResource {
+		p = location + p
+	}
+	value, _ := valueAt(decoded, err.InstanceLocation)
+	return []failurePart{{
+		pointer:  p,
+		location: pointer(err.InstanceLocation...),
+		value:    value,
+		message:  err.ErrorKind.LocalizedString(failurePrinter),
+	}}
+}
+
+// validatorParts returns a part for every schema failure of a body in the error of the validator library, or nil if it
+// is not for the schema of a body. The locations of the failures are in the schema, so the ones in the body are found
+// in the error of the JSON schema library that they come from.
+func validatorParts(err *validatorerr.ValidationError, location string, decoded any) []failurePart {
+	if err.ValidationSubType != helpers.Schema ||
+		(err.ValidationType != helpers.RequestBodyValidation && err.ValidationType != helpers.ResponseBodyValidation) {
+		return nil
+	}
+
+	// The failures share the error that they come from, where the same keyword can fail for several values.
+	units := make(map[*jsonschema.ValidationError][]jsonschema.OutputUnit)
+	var parts []failurePart
+	for _, f := range err.SchemaValidationErrors {
+		if f.OriginalError == nil {
+			return nil
+		}
+		if _, ok := units[f.OriginalError]; !ok {
+			units[f.OriginalError] = f.OriginalError.BasicOutput().Errors
+		}
+		part := failurePart{pointer: location + f.Location, message: f.Reason}
+		for i, unit := range units[f.OriginalError] {
+			if unit.KeywordLocation == f.Location {
+				part.location = unit.InstanceLocation
+				units[f.OriginalError] = slices.Delete(units[f.OriginalError], i, i+1)
+				break
+			}
+		}
+		if tokens, ok := pointerTokens(part.location); ok {
+			part.value, _ = valueAt(decoded, tokens)
+		}
+		parts = append(parts, part)
+	}
+	return parts
+}
@@ -0,0 +1,172 @@
+package copper
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFailures(t *testing.T) {
+	f, err := os.ReadFile("testdata/failures-spec.yaml")
+	require.NoError(t, err)
+
+	jsonResponse := func(req *http.Request, status int, body string) *http.Response {
+		return &http.Response{
+			StatusCode: status,
+			Request:    req,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}
+	}
+	const thing = "/paths/~1things~1{id}/get/responses/200/content/application~1json/schema"
+
+	t.Run("a failure per property of a response", func(t *testing.T) {
+		v, err := NewVerifier(f, WithoutFullCoverage())
+		require.NoError(t, err)
+		v.Record(jsonResponse(httptest.NewRequest(http.MethodGet, "/things/1", nil), http.StatusOK, `{"id": "one", "tags": ["a", 2]}`))
+
+		assert.Equal(t, []ValidationFailure{
+			{
+				Kind:       FailureResponse,
+				Sentinel:   ErrResponseInvalid,
+				Path:       "/things/{id}",
+				Method:     http.MethodGet,
+				StatusCode: http.StatusOK,
+				Pointer:    thing + "/properties/id/type",
+				Location:   "/id",
+				Value:      "one",
+				Message:    "got string, want integer",
+			},
+			{
+				Kind:       FailureResponse,
+				Sentinel:   ErrResponseInvalid,
+				Path:       "/things/{id}",
+				Method:     http.MethodGet,
+				StatusCode: http.StatusOK,
+				Pointer:    thing + "/properties/tags/items/type",
+				Location:   "/tags/1",
+				Value:      json.Number("2"),
+				Message:    "got number, want string",
+			},
+		}, v.Failures())
+	})
+
+	t.Run("request", func(t *testing.T) {
+		v, err := NewVerifier(f, WithoutFullCoverage(), WithRequestValidation())
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodPost, "/things", strings.NewReader(`{"id": 1, "tags": "red"}`))
+		req.Header.Set("Content-Type", "application/json")
+		v.Record(&http.Response{StatusCode: http.StatusCreated, Request: req, Header: http.Header{}})
+
+		failures := v.Failures()
+		require.Len(t, failures, 1)
+		assert.Equal(t, FailureRequest, failures[0].Kind)
+		assert.Equal(t, ErrRequestInvalid, failures[0].Sentinel)
+		assert.Equal(t, "/things", failures[0].Path)
+		assert.Equal(t, "/paths/~1things/post/requestBody/content/application~1json/schema/properties/tags/type", failures[0].Pointer)
+		assert.Equal(t, "/tags", failures[0].Location)
+		assert.Equal(t, "red", failures[0].Value)
+	})
+
+	t.Run("unknown path", func(t *testing.T) {
+		v, err := NewVerifier(f, WithoutFullCoverage())
+		require.NoError(t, err)
+		v.Record(&http.Response{StatusCode: http.StatusOK, Request: httptest.NewRequest(http.MethodGet, "/nowhere", nil), Header: http.Header{}})
+
+		failures := v.Failures()
+		require.Len(t, failures, 1)
+		assert.Equal(t, FailureUnknownPath, failures[0].Kind)
+		assert.Equal(t, ErrNotPartOfSpec, failures[0].Sentinel)
+		assert.Equal(t, "/nowhere", failures[0].Path)
+		assert.Equal(t, http.MethodGet, failures[0].Method)
+		assert.Empty(t, failures[0].Pointer)
+		assert.NotContains(t, failures[0].Message, "GET /nowhere:")
+	})
+
+	t.Run("a failure per coordinate that is not covered", func(t *testing.T) {
+		v, err := NewVerifier(f)
+		require.NoError(t, err)
+		v.Record(jsonResponse(httptest.NewRequest(http.MethodGet, "/things/1", nil), http.StatusOK, `{"id": 1}`))
+
+		assert.Equal(t, []ValidationFailure{
+			{
+				Kind:       FailureCoverage,
+				Sentinel:   ErrNotChecked,
+				Path:       "/things",
+				Method:     http.MethodPost,
+				StatusCode: http.StatusCreated,
+				Pointer:    "/paths/~1things/post/responses/201",
+				Message:    "POST 201 is not checked",
+			},
+			{
+				Kind:       FailureCoverage,
+				Sentinel:   ErrNotChecked,
+				Path:       "/things/{id}",
+				Method:     http.MethodGet,
+				StatusCode: http.StatusNotFound,
+				Pointer:    "/paths/~1things~1{id}/get/responses/404",
+				Message:    "GET 404 is not checked",
+			},
+		}, v.Failures())
+	})
+
+	t.Run("recursive schema", func(t *testing.T) {
+		tree, err := os.ReadFile("testdata/tree-spec.yaml")
+		require.NoError(t, err)
+		v, err := NewVerifier(tree)
+		require.NoError(t, err)
+		v.Record(jsonResponse(httptest.NewRequest(http.MethodGet, "/tree", nil), http.StatusOK, `{"name": "root", "children": [{"name": 1}]}`))
+
+		failures := v.Failures()
+		require.Len(t, failures, 1)
+		assert.Equal(t, "/components/schemas/Node/properties/name/type", failures[0].Pointer)
+		assert.Equal(t, "/children/0/name", failures[0].Location)
+		assert.Equal(t, json.Number("1"), failures[0].Value)
+	})
+
+	t.Run("restored state", func(t *testing.T) {
+		v, err := NewVerifier(f, WithoutFullCoverage(), WithRequestValidation())
+		require.NoError(t, err)
+		v.Record(jsonResponse(httptest.NewRequest(http.MethodGet, "/things/1", nil), http.StatusOK, `{"id": "one"}`))
+		b, err := json.Marshal(v)
+		require.NoError(t, err)
+
+		restored, err := NewVerifier(f, WithoutFullCoverage())
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(b, restored))
+		failures := restored.Failures()
+		require.Len(t, failures, 1)
+		assert.Equal(t, FailureResponse, failures[0].Kind)
+		assert.Equal(t, "/things/{id}", failures[0].Path)
+		assert.Equal(t, http.StatusOK, failures[0].StatusCode)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		b, err := json.Marshal(ValidationFailure{
+			Kind:       FailureResponse,
+			Sentinel:   ErrResponseInvalid,
+			Path:       "/things/{id}",
+			Method:     http.MethodGet,
+			StatusCode: http.StatusOK,
+			Value:      "one",
+			Message:    "got string, want integer",
+		})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"sentinel": "response invalid",
+			"kind": "response",
+			"path": "/things/{id}",
+			"method": "GET",
+			"statusCode": 200,
+			"value": "one",
+			"message": "got string, want integer"
+		}`, string(b))
+	})
+}
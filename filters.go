@@ -0,0 +1,201 @@
+package copper
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+// WithIncludeTags is a functional Option for only verifying the operations that have at least one of the tags, so that
+// a large spec can be verified partially. The other operations are left out like the ones that are excluded.
+func WithIncludeTags(tags ...string) Option {
+	return func(c *config) {
+		c.filters.includeTags = append(c.filters.includeTags, tags...)
+	}
+}
+
+// WithExcludeTags is a functional Option for leaving out the operations that have any of the tags. The coordinates of
+// excluded operations are waived rather than required for full coverage, and requests to them are ignored rather than
+// validated or reported as not part of the spec.
+func WithExcludeTags(tags ...string) Option {
+	return func(c *config) {
+		c.filters.excludeTags = append(c.filters.excludeTags, tags...)
+	}
+}
+
+// WithIncludePaths is a functional Option for only verifying the operations of the paths that match one of the
+// patterns. The patterns are matched against the paths of the spec, like /things/{id}, with path.Match, except that a
+// pattern that ends with /* also matches every path below it.
+func WithIncludePaths(patterns ...string) Option {
+	return func(c *config) {
+		c.filters.includePaths = append(c.filters.includePaths, patterns...)
+	}
+}
+
+// WithExcludePaths is a functional Option for leaving out the operations of the paths that match any of the patterns,
+// like /internal/*. The patterns are matched like the ones of WithIncludePaths.
+func WithExcludePaths(patterns ...string) Option {
+	return func(c *config) {
+		c.filters.excludePaths = append(c.filters.excludePaths, patterns...)
+	}
+}
+
+// WithExcludeOperations is a functional Option for leaving out single operations, given either as the method and a
+// path pattern, like "GET /health", or as the operation id.
+func WithExcludeOperations(operations ...string) Option {
+	return func(c *config) {
+		c.filters.excludeOperations = append(c.filters.excludeOperations, operations...)
+	}
+}
+
+// filters select the operations of the spec that are verified, and the ones that are verified but waived from the
+// coverage. Exclusions take precedence over inclusions.
+type filters struct {
+	includeTags       []string
+	excludeTags       []string
+	includePaths      []string
+	excludePaths      []string
+	excludeOperations []string
+	ignoredOperations []string
+	ignoredResponses  []ignoredResponses
+}
+
+func (f filters) equal(other filters) bool {
+	return slices.Equal(f.includeTags, other.includeTags) &&
+		slices.Equal(f.excludeTags, other.excludeTags) &&
+		slices.Equal(f.includePaths, other.includePaths) &&
+		slices.Equal(f.excludePaths, other.excludePaths) &&
+		slices.Equal(f.excludeOperations, other.excludeOperations) &&
+		slices.Equal(f.ignoredOperations, other.ignoredOperations) &&
+		slices.EqualFunc(f.ignoredResponses, other.ignoredResponses, ignoredResponses.equal)
+}
+
+// validate returns an error for every pattern that path.Match can not use.
+func (f filters) validate() []error {
+	var errs []error
+	check := func(option, given, pattern string) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			errs = append(errs, fmt.Errorf("%s is given %q, but it is not a valid pattern: %w", option, given, err))
+		}
+	}
+	for _, pattern := range f.includePaths {
+		check("WithIncludePaths", pattern, pattern)
+	}
+	for _, pattern := range f.excludePaths {
+		check("WithExcludePaths", pattern, pattern)
+	}
+	for _, operation := range f.excludeOperations {
+		if _, pattern, ok := strings.Cut(operation, " "); ok {
+			check("WithExcludeOperations", operation, pattern)
+		}
+	}
+	for _, operation := range f.ignoredOperations {
+		if _, pattern, ok := strings.Cut(operation, " "); ok {
+			check("WithIgnoredOperations", operation, pattern)
+		}
+	}
+	for _, ignored := range f.ignoredResponses {
+		errs = append(errs, ignored.validate()...)
+	}
+	return errs
+}
+
+// excluded returns whether the operation at the path of the spec is left out by the filters, and the reason why.
+func (f filters) excluded(specPath, method string, op *v3.Operation) (string, bool) {
+	var tags []string
+	var operationID string
+	if op != nil {
+		tags, operationID = op.Tags, op.OperationId
+	}
+
+	for _, tag := range f.excludeTags {
+		if slices.Contains(tags, tag) {
+			return fmt.Sprintf("excluded by WithExcludeTags(%q)", tag), true
+		}
+	}
+	for _, pattern := range f.excludePaths {
+		if matchPathPattern(pattern, specPath) {
+			return fmt.Sprintf("excluded by WithExcludePaths(%q)", pattern), true
+		}
+	}
+	for _, operation := range f.excludeOperations {
+		if matchOperation(operation, specPath, method, operationID) {
+			return fmt.Sprintf("excluded by WithExcludeOperations(%q)", operation), true
+		}
+	}
+	if len(f.includeTags) > 0 && !slices.ContainsFunc(f.includeTags, func(tag string) bool {
+		return slices.Contains(tags, tag)
+	}) {
+		return "not included by WithIncludeTags(" + quoteAll(f.includeTags) + ")", true
+	}
+	if len(f.includePaths) > 0 && !slices.ContainsFunc(f.includePaths, func(pattern string) bool {
+		return matchPathPattern(pattern, specPath)
+	}) {
+		return "not included by WithIncludePaths(" + quoteAll(f.includePaths) + ")", true
+	}
+	return "", false
+}
+
+// describe returns the options that the filters are made of, like they are given in code.
+func (f filters) describe() []string {
+	var opts []string
+	add := func(option string, values []string) {
+		if len(values) > 0 {
+			opts = append(opts, option+"("+quoteAll(values)+")")
+		}
+	}
+	add("WithIncludeTags", f.includeTags)
+	add("WithExcludeTags", f.excludeTags)
+	add("WithIncludePaths", f.includePaths)
+	add("WithExcludePaths", f.excludePaths)
+	add("WithExcludeOperations", f.excludeOperations)
+	add("WithIgnoredOperations", f.ignoredOperations)
+	for _, ignored := range f.ignoredResponses {
+		opts = append(opts, ignored.String())
+	}
+	return opts
+}
+
+// quoteAll quotes the values as Go strings, separated by commas.
+func quoteAll(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, v := range values {
+		quoted = append(quoted, strconv.Quote(v))
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// matchOperation matches an operation with the method and a path pattern, like "GET /health", or with the id of the
+// operation.
+func matchOperation(operation, specPath, method, operationID string) bool {
+	m, pattern, ok := strings.Cut(operation, " ")
+	if ok {
+		return strings.EqualFold(m, method) && matchPathPattern(pattern, specPath)
+	}
+	return operationID != "" && operation == operationID
+}
+
+// matchPathPattern matches a path of the spec with path.Match, except that a pattern that ends with /* also matches
+// every path below what the rest of the pattern matches.
+func matchPathPattern(pattern, specPath string) bool {
+	if ok, _ := path.Match(pattern, specPath); ok {
+		return true
+	}
+	prefix, ok := strings.CutSuffix(pattern, "/*")
+	if !ok {
+		return false
+	}
+	for i := 1; i < len(specPath); i++ {
+		if specPath[i] != '/' {
+			continue
+		}
+		if ok, _ := path.Match(prefix, specPath[:i]); ok {
+			return true
+		}
+	}
+	return false
+}
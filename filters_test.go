@@ -0,0 +1,150 @@
+package copper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilters(t *testing.T) {
+	f, err := os.ReadFile("testdata/filters-spec.yaml")
+	require.NoError(t, err)
+
+	coordinates := func(ends []Endpoint) []string {
+		var c []string
+		for _, e := range ends {
+			c = append(c, e.Method+" "+e.Path+" "+e.ResponseCode)
+		}
+		return c
+	}
+
+	tt := []struct {
+		name     string
+		opts     []Option
+		verified []string
+	}{
+		{
+			name: "include tags",
+			opts: []Option{WithIncludeTags("public")},
+			verified: []string{
+				"GET /things 204",
+				"GET /things/{id} 204",
+				"GET /things/{id} 404",
+			},
+		},
+		{
+			name: "exclude tags",
+			opts: []Option{WithExcludeTags("public", "internal")},
+			verified: []string{
+				"GET /health 204",
+				"DELETE /internal/jobs/{id} 204",
+			},
+		},
+		{
+			name: "include paths",
+			opts: []Option{WithIncludePaths("/things/*")},
+			verified: []string{
+				"GET /things/{id} 204",
+				"GET /things/{id} 404",
+			},
+		},
+		{
+			name: "exclude paths below a prefix",
+			opts: []Option{WithExcludePaths("/internal/*")},
+			verified: []string{
+				"GET /health 204",
+				"GET /things 204",
+				"GET /things/{id} 204",
+				"GET /things/{id} 404",
+			},
+		},
+		{
+			name: "exclude operations by method and path, and by id",
+			opts: []Option{WithExcludeOperations("GET /health", "metrics", "delete /internal/jobs/*")},
+			verified: []string{
+				"GET /things 204",
+				"GET /things/{id} 204",
+				"GET /things/{id} 404",
+			},
+		},
+		{
+			name: "exclusions take precedence",
+			opts: []Option{WithIncludePaths("/things", "/things/*"), WithExcludeOperations("getThing")},
+			verified: []string{
+				"GET /things 204",
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			v, err := NewVerifier(f, tc.opts...)
+			require.NoError(t, err)
+
+			var all []Endpoint
+			for _, e := range v.Endpoints() {
+				all = append(all, e.Endpoint)
+			}
+			assert.ElementsMatch(t, tc.verified, coordinates(all))
+			for _, w := range v.Waived() {
+				assert.NotContains(t, tc.verified, w.Method+" "+w.Path+" "+w.ResponseCode)
+			}
+			assert.Len(t, v.Waived(), 6-len(tc.verified))
+		})
+	}
+
+	t.Run("excluded requests are ignored", func(t *testing.T) {
+		v, err := NewVerifier(f, WithExcludePaths("/internal/*"), WithExcludeOperations("GET /health"))
+		require.NoError(t, err)
+
+		for _, target := range []string{"/things", "/things/a", "/internal/metrics", "/health"} {
+			v.Record(&http.Response{StatusCode: 204, Request: httptest.NewRequest(http.MethodGet, target, nil)})
+		}
+		// An undocumented response would be a violation, if the operation was verified.
+		v.Record(&http.Response{StatusCode: 500, Request: httptest.NewRequest(http.MethodDelete, "/internal/jobs/a", nil)})
+		v.Record(&http.Response{StatusCode: 404, Request: httptest.NewRequest(http.MethodGet, "/things/b", nil)})
+
+		assert.NoError(t, v.CurrentError(), "the admin scheme is only used by excluded operations")
+		for _, w := range v.Waived() {
+			assert.Contains(t, w.Reason, "excluded by With")
+		}
+	})
+
+	t.Run("undocumented requests are still reported", func(t *testing.T) {
+		v, err := NewVerifier(f, WithExcludePaths("/internal/*"), WithoutFullCoverage())
+		require.NoError(t, err)
+
+		v.Record(&http.Response{StatusCode: 204, Request: httptest.NewRequest(http.MethodGet, "/others", nil)})
+		assert.ErrorIs(t, v.CurrentError(), ErrNotPartOfSpec)
+	})
+
+	t.Run("reasons", func(t *testing.T) {
+		v, err := NewVerifier(f, WithIncludeTags("public", "other"))
+		require.NoError(t, err)
+
+		assert.Contains(t, v.Waived(), WaivedEndpoint{
+			Endpoint: Endpoint{Path: "/health", Method: "GET", ResponseCode: "204", OperationID: "health"},
+			Reason:   `not included by WithIncludeTags("public", "other")`,
+		})
+	})
+
+	t.Run("invalid patterns", func(t *testing.T) {
+		_, err := NewVerifier(f, WithExcludePaths("/internal/["), WithExcludeOperations("GET /[", "health"))
+		assert.ErrorIs(t, err, ErrInvalidOptions)
+		assert.ErrorContains(t, err, `WithExcludePaths is given "/internal/[", but it is not a valid pattern`)
+		assert.ErrorContains(t, err, `WithExcludeOperations is given "GET /[", but it is not a valid pattern`)
+	})
+
+	t.Run("filters can not be changed", func(t *testing.T) {
+		v, err := NewVerifier(f, WithExcludeTags("internal"))
+		require.NoError(t, err)
+
+		assert.ErrorIs(t, v.SetOptions(WithExcludeTags("public")), ErrInvalidOptions)
+		assert.NoError(t, v.SetOptions(WithoutFullCoverage()))
+		assert.Panics(t, func() { v.With(WithIncludePaths("/things")) })
+	})
+}
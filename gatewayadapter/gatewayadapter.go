@@ -0,0 +1,46 @@
+// Package gatewayadapter records the requests that a grpc-gateway ServeMux transcodes into a copper.Verifier, so that
+// the JSON/HTTP surface of a gRPC service can be checked against the OpenAPI spec that is published for it, like the
+// one generated by protoc-gen-openapiv2. The path template of the HTTP rule that matched is used to find the path in
+// the spec.
+package gatewayadapter
+
+import (
+	"net/http"
+	"regexp"
+
+	"github.com/callebjorkell/copper"
+	"github.com/callebjorkell/copper/internal/capture"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+)
+
+// Middleware returns grpc-gateway middleware that records every request and the response to it into the verifier.
+// Pass it to runtime.NewServeMux with runtime.WithMiddlewares. The ServeMux only runs middleware for requests that
+// match an HTTP rule, so requests that it answers with 404 or 405 are not recorded.
+func Middleware(v *copper.Verifier) runtime.Middleware {
+	return func(next runtime.HandlerFunc) runtime.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+			if err := capture.BufferRequest(r); err != nil {
+				http.Error(w, "could not read request body", http.StatusBadRequest)
+				return
+			}
+
+			cw := capture.NewWriter(w)
+			next(cw, r, pathParams)
+
+			var route string
+			if pattern, ok := runtime.HTTPPattern(r.Context()); ok {
+				route = template(pattern.String())
+			}
+			v.RecordRoute(cw.Response(r), route)
+		}
+	}
+}
+
+var singleSegmentVariable = regexp.MustCompile(`\{([^}=]+)=\*\}`)
+
+// template converts the path template of an HTTP rule, like /v1/things/{id=*}, into a path template like
+// /v1/things/{id}. Variables that span several segments have no counterpart in a path template, and are left as they
+// are.
+func template(pattern string) string {
+	return singleSegmentVariable.ReplaceAllString(pattern, "{$1}")
+}
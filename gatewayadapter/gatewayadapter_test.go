@@ -0,0 +1,50 @@
+package gatewayadapter
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/callebjorkell/copper"
+	"github.com/callebjorkell/copper/internal/routertest"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMux(t *testing.T, v *copper.Verifier) http.Handler {
+	mux := runtime.NewServeMux(runtime.WithMiddlewares(Middleware(v)))
+	handle := func(method, pattern string, h http.HandlerFunc) {
+		require.NoError(t, mux.HandlePath(method, pattern, func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+			h(w, r)
+		}))
+	}
+
+	// The rules that are registered last take precedence, which is how generated gateways order overlapping rules.
+	handle(http.MethodGet, "/api/things/{thingID}", routertest.GetThing)
+	handle(http.MethodPut, "/api/things/{thingID}", routertest.PutThing)
+	handle(http.MethodDelete, "/api/things/{thingID}", routertest.DeleteThing)
+	handle(http.MethodGet, "/api/things/export", routertest.Export)
+	return mux
+}
+
+func newRouter(t *testing.T, spec []byte, opts ...copper.Option) (http.Handler, *copper.Verifier) {
+	v, err := copper.NewVerifier(spec, opts...)
+	require.NoError(t, err)
+	return newMux(t, v), v
+}
+
+func TestMiddleware(t *testing.T) {
+	routertest.Test(t, newRouter, http.StatusNoContent)
+
+	t.Run("the rule that is registered last is matched", func(t *testing.T) {
+		assert.Equal(t, []string{"/things/export"}, routertest.Matched(t, newRouter, http.MethodGet, "/api/things/export"))
+		assert.Equal(t, []string{"/things/{id}"}, routertest.Matched(t, newRouter, http.MethodGet, "/api/things/abc"))
+	})
+}
+
+func TestTemplate(t *testing.T) {
+	assert.Equal(t, "/v1/things/{id}", template("/v1/things/{id=*}"))
+	assert.Equal(t, "/v1/things/{id}:cancel", template("/v1/things/{id=*}:cancel"))
+	assert.Equal(t, "/v1/{name=things/*}", template("/v1/{name=things/*}"))
+	assert.Equal(t, "/v1/things", template("/v1/things"))
+}
@@ -0,0 +1,63 @@
+// Package ginadapter records the requests that a gin engine serves into a copper.Verifier, using the route that gin
+// matched to find the path in the spec.
+package ginadapter
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+
+	"github.com/callebjorkell/copper"
+	"github.com/callebjorkell/copper/internal/capture"
+	"github.com/gin-gonic/gin"
+)
+
+// Middleware returns a gin.HandlerFunc that records every request and the response to it into the verifier. Add it
+// with the Use method of the engine, before the handlers that should be recorded.
+func Middleware(v *copper.Verifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := capture.BufferRequest(c.Request); err != nil {
+			c.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		w := &writer{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+
+		// Gin holds back the status until the body is written, so the headers are taken as they are once the handler
+		// is done rather than when the status was set.
+		res := capture.NewResponse(c.Request, w.Status(), w.Header().Clone(), w.Header(), w.body.Bytes())
+		v.RecordRoute(res, route(c.FullPath()))
+	}
+}
+
+// writer keeps a copy of the body written through the gin.ResponseWriter that it wraps.
+type writer struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *writer) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	w.body.Write(b[:n])
+	return n, err
+}
+
+func (w *writer) WriteString(s string) (int, error) {
+	n, err := w.ResponseWriter.WriteString(s)
+	w.body.WriteString(s[:n])
+	return n, err
+}
+
+// route converts a gin route like /things/:id into a path template like /things/{id}. Catch-all parameters have no
+// counterpart in a path template, and are left as they are.
+func route(fullPath string) string {
+	segs := strings.Split(fullPath, "/")
+	for i, seg := range segs {
+		if name, ok := strings.CutPrefix(seg, ":"); ok {
+			segs[i] = "{" + name + "}"
+		}
+	}
+	return strings.Join(segs, "/")
+}
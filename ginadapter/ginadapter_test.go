@@ -0,0 +1,39 @@
+package ginadapter
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/callebjorkell/copper"
+	"github.com/callebjorkell/copper/internal/routertest"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newEngine(v *copper.Verifier) http.Handler {
+	gin.SetMode(gin.TestMode)
+
+	e := gin.New()
+	e.Use(Middleware(v))
+	things := e.Group("/api/things")
+	things.GET("/export", gin.WrapF(routertest.Export))
+	things.GET("/:thingID", gin.WrapF(routertest.GetThing))
+	things.PUT("/:thingID", gin.WrapF(routertest.PutThing))
+	return e
+}
+
+func TestMiddleware(t *testing.T) {
+	routertest.Test(t, routertest.Middleware(newEngine), http.StatusNotFound)
+
+	t.Run("the full path of a group route is matched", func(t *testing.T) {
+		router := routertest.Middleware(newEngine)
+		assert.Equal(t, []string{"/things/export"}, routertest.Matched(t, router, http.MethodGet, "/api/things/export"))
+		assert.Equal(t, []string{"/things/{id}"}, routertest.Matched(t, router, http.MethodGet, "/api/things/abc"))
+	})
+}
+
+func TestRoute(t *testing.T) {
+	assert.Equal(t, "/api/things/{id}", route("/api/things/:id"))
+	assert.Equal(t, "/files/*path", route("/files/*path"))
+	assert.Equal(t, "", route(""))
+}
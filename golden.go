@@ -0,0 +1,176 @@
+package copper
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// redactedHeaders are the headers whose values are not written to golden files, since they carry credentials.
+var redactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// unsafeFileChars are the characters that are replaced in the names of golden files.
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// WithGoldenFiles is a functional Option for writing every recorded request and response to a golden file in the
+// directory, which ReplayGolden validates again on later runs. This turns captured traffic into fast and deterministic
+// regression tests that follow the spec as it changes. The files are named by the coordinate and a hash of the
+// exchange, so the same exchange is only written once. The values of the Authorization, Proxy-Authorization, Cookie
+// and Set-Cookie headers are redacted, but the scheme of an Authorization header is kept, so that the security of the
+// spec can still be checked for presence. Files that can not be written are logged to the logger of
+// WithRequestLogging, if there is one.
+func WithGoldenFiles(dir string) Option {
+	return func(c *config) {
+		c.goldenDir = dir
+	}
+}
+
+// goldenExchange is a request and response as they are written to a golden file. Bodies that are not valid UTF-8 are
+// written as base64.
+type goldenExchange struct {
+	Request  goldenMessage `json:"request"`
+	Response goldenMessage `json:"response"`
+}
+
+type goldenMessage struct {
+	// Method and URL are only set for the request, and Status only for the response.
+	Method     string      `json:"method,omitempty"`
+	URL        string      `json:"url,omitempty"`
+	Status     int         `json:"status,omitempty"`
+	Header     http.Header `json:"header"`
+	Body       string      `json:"body,omitempty"`
+	BodyBase64 []byte      `json:"bodyBase64,omitempty"`
+}
+
+// writeGolden writes the request and response, with their bodies as they were before they were checked, to a golden
+// file in the directory. The path is the one of the spec that they were matched with, if any.
+func writeGolden(dir string, req *http.Request, res *http.Response, reqBody, resBody []byte, path string,
+	logger RequestLogger) {
+	exchange := goldenExchange{
+		Request:  goldenMessage{Method: req.Method, URL: req.URL.String(), Header: redact(req.Header)},
+		Response: goldenMessage{Status: res.StatusCode, Header: redact(res.Header)},
+	}
+	exchange.Request.setBody(reqBody)
+	exchange.Response.setBody(resBody)
+
+	data, err := json.MarshalIndent(exchange, "", "  ")
+	if err == nil {
+		if path == "" {
+			path = req.URL.Path
+		}
+		hash := sha256.Sum256(data)
+		name := fmt.Sprintf("%s%s-%d-%s.json", req.Method, path, res.StatusCode, hex.EncodeToString(hash[:4]))
+		name = unsafeFileChars.ReplaceAllString(name, "_")
+		err = os.WriteFile(filepath.Join(dir, name), append(data, '\n'), 0o644)
+	}
+	if err != nil && logger != nil {
+		logger.Logf("copper: could not write golden file: %v", err)
+	}
+}
+
+func (m *goldenMessage) setBody(body []byte) {
+	switch {
+	case len(body) == 0:
+	case utf8.Valid(body):
+		m.Body = string(body)
+	default:
+		m.BodyBase64 = body
+	}
+}
+
+func (m goldenMessage) body() []byte {
+	if m.BodyBase64 != nil {
+		return m.BodyBase64
+	}
+	return []byte(m.Body)
+}
+
+// redact returns a copy of the header without the values of the headers that carry credentials.
+func redact(h http.Header) http.Header {
+	h = h.Clone()
+	for _, name := range redactedHeaders {
+		for i, value := range h.Values(name) {
+			scheme, _, found := strings.Cut(value, " ")
+			if found && strings.HasSuffix(name, "Authorization") {
+				h[name][i] = scheme + " REDACTED"
+			} else {
+				h[name][i] = "REDACTED"
+			}
+		}
+	}
+	return h
+}
+
+// ReplayGolden records the exchanges of the golden files in the directory with the Verifier, in the order of their
+// names, and fails the test if they break the spec, like AssertNoErrors. The coverage is not checked, since the golden
+// files are not expected to cover the whole spec. It returns true if every golden file could be read and there were no
+// errors.
+func ReplayGolden(t testing.TB, v *Verifier, dir string) bool {
+	t.Helper()
+
+	names, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil || len(names) == 0 {
+		t.Errorf("copper: no golden files found in %s", dir)
+		return false
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		res, err := readGolden(name)
+		if err != nil {
+			t.Errorf("copper: could not read golden file %s: %v", name, err)
+			return false
+		}
+		v.Record(res)
+	}
+	return AssertNoErrors(t, v)
+}
+
+// readGolden reads the request and response of a golden file.
+func readGolden(name string) (*http.Response, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	var exchange goldenExchange
+	if err := json.Unmarshal(data, &exchange); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(exchange.Request.Method, exchange.Request.URL, bytes.NewReader(exchange.Request.body()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header = exchange.Request.Header
+	if req.Header == nil {
+		req.Header = make(http.Header)
+	}
+
+	resBody := exchange.Response.body()
+	header := exchange.Response.Header
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{
+		Status:        http.StatusText(exchange.Response.Status),
+		StatusCode:    exchange.Response.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(resBody)),
+		ContentLength: int64(len(resBody)),
+		Request:       req,
+	}, nil
+}
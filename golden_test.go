@@ -0,0 +1,74 @@
+package copper
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoldenFiles(t *testing.T) {
+	f, err := os.ReadFile("testdata/request-body-spec.yaml")
+	require.NoError(t, err)
+
+	s := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}),
+	)
+	defer s.Close()
+
+	dir := t.TempDir()
+	c := MustWrapClient(http.DefaultClient, bytes.NewReader(f), WithGoldenFiles(dir), WithRequestValidation())
+	for range 2 {
+		req, err := http.NewRequest(http.MethodPost, s.URL+"/req", strings.NewReader(`{"input":"pem"}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer secret-token")
+		_, err = c.Do(req)
+		require.NoError(t, err)
+	}
+	c.Verify(t)
+
+	names, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	require.NoError(t, err)
+	require.Len(t, names, 1, "the same exchange is only written once")
+	assert.Regexp(t, `POST_req-204-[0-9a-f]{8}\.json$`, names[0])
+
+	data, err := os.ReadFile(names[0])
+	require.NoError(t, err)
+	var exchange goldenExchange
+	require.NoError(t, json.Unmarshal(data, &exchange))
+	assert.Equal(t, "Bearer REDACTED", exchange.Request.Header.Get("Authorization"))
+	assert.Equal(t, `{"input":"pem"}`, exchange.Request.Body)
+
+	t.Run("replay", func(t *testing.T) {
+		v := MustNewVerifier(f, WithRequestValidation())
+		assert.True(t, ReplayGolden(t, v, dir))
+		v.Verify(t)
+	})
+
+	t.Run("replay against a changed spec", func(t *testing.T) {
+		changed := strings.Replace(string(f), "input:\n                  type: string", "input:\n                  type: integer", 1)
+		v := MustNewVerifier([]byte(changed), WithRequestValidation())
+
+		rt := &recordingT{TB: t}
+		assert.False(t, ReplayGolden(rt, v, dir))
+		if assert.Len(t, rt.failures, 1) {
+			assert.Contains(t, rt.failures[0], "request invalid: POST /req")
+		}
+	})
+
+	t.Run("no golden files", func(t *testing.T) {
+		rt := &recordingT{TB: t}
+		assert.False(t, ReplayGolden(rt, MustNewVerifier(f), t.TempDir()))
+		assert.Len(t, rt.failures, 1)
+	})
+}
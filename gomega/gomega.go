@@ -0,0 +1,106 @@
+// Package gomega has Gomega matchers for a copper.Verifier, or the copper.ValidatingClient that embeds one, so that
+// Ginkgo suites can check the contract with Expect rather than bridging through a testing.T. The package is best
+// imported as coppergomega, to keep it apart from Gomega itself:
+//
+//	Expect(client).To(coppergomega.HaveNoContractViolations())
+//	Expect(client).To(coppergomega.HaveFullCoverage())
+//
+// Since the coverage is only complete once every spec has run, it is best checked in a ReportAfterSuite node of Ginkgo
+// v2, with a Verifier that the whole suite shares:
+//
+//	var _ = ReportAfterSuite("copper", func(report Report) {
+//		Expect(client).To(coppergomega.SucceedVerification())
+//	})
+package gomega
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/callebjorkell/copper"
+	"github.com/onsi/gomega/types"
+)
+
+// verifier is what the matchers need from a copper.Verifier, which a copper.ValidatingClient has as well.
+type verifier interface {
+	CurrentError() error
+	CurrentErrors() []error
+	UncheckedByPath() map[string][]copper.MethodStatus
+}
+
+// SucceedVerification succeeds if the Verifier has no errors, like copper.Verifier.Verify: nothing recorded broke the
+// spec, and everything has been covered unless full coverage is not required.
+func SucceedVerification() types.GomegaMatcher {
+	return &matcher{
+		name: "succeed verification",
+		problems: func(v verifier) []string {
+			if err := v.CurrentError(); err != nil {
+				return []string{err.Error()}
+			}
+			return nil
+		},
+	}
+}
+
+// HaveFullCoverage succeeds if every coordinate of the spec has been checked, like copper.AssertFullCoverage. The
+// coverage is checked even if full coverage is not required, and other errors are ignored.
+func HaveFullCoverage() types.GomegaMatcher {
+	return &matcher{
+		name: "have full coverage",
+		problems: func(v verifier) []string {
+			if byPath := v.UncheckedByPath(); len(byPath) > 0 {
+				return []string{(&copper.CoverageError{Missing: byPath}).Error()}
+			}
+			return nil
+		},
+	}
+}
+
+// HaveNoContractViolations succeeds if nothing that has been recorded broke the spec. Coordinates that have not been
+// checked are left out, so that it can be used in the middle of a suite, before everything has been recorded.
+func HaveNoContractViolations() types.GomegaMatcher {
+	return &matcher{
+		name: "have no contract violations",
+		problems: func(v verifier) []string {
+			var problems []string
+			for _, err := range v.CurrentErrors() {
+				if !errors.Is(err, copper.ErrNotChecked) {
+					problems = append(problems, err.Error())
+				}
+			}
+			return problems
+		},
+	}
+}
+
+// matcher is a Gomega matcher for the problems that a Verifier has, which it succeeds without.
+type matcher struct {
+	name     string
+	problems func(v verifier) []string
+	// found are the problems of the last match, for the failure message.
+	found []string
+}
+
+var _ types.GomegaMatcher = (*matcher)(nil)
+
+func (m *matcher) Match(actual any) (bool, error) {
+	v, ok := actual.(verifier)
+	if !ok {
+		return false, fmt.Errorf("%s expects a *copper.Verifier or *copper.ValidatingClient, but got %T", m.name, actual)
+	}
+	m.found = m.problems(v)
+	return len(m.found) == 0, nil
+}
+
+func (m *matcher) FailureMessage(any) string {
+	lines := make([]string, 0, len(m.found))
+	for _, p := range m.found {
+		lines = append(lines, "  "+strings.ReplaceAll(p, "\n", "\n  "))
+	}
+	return fmt.Sprintf("Expected the verifier to %s, but found:\n%s", m.name, strings.Join(lines, "\n"))
+}
+
+func (m *matcher) NegatedFailureMessage(any) string {
+	return fmt.Sprintf("Expected the verifier not to %s, but it does", m.name)
+}
@@ -0,0 +1,56 @@
+package gomega
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/callebjorkell/copper"
+	"github.com/onsi/gomega"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchers(t *testing.T) {
+	f, err := os.ReadFile("../testdata/minimal-spec.yaml")
+	require.NoError(t, err)
+
+	s := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}),
+	)
+	defer s.Close()
+
+	c := copper.MustWrapClient(http.DefaultClient, bytes.NewReader(f))
+
+	g := gomega.NewWithT(t)
+	g.Expect(c).To(HaveNoContractViolations())
+	g.Expect(c).NotTo(HaveFullCoverage())
+	g.Expect(c).NotTo(SucceedVerification())
+
+	m := HaveFullCoverage()
+	ok, err := m.Match(c)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, "Expected the verifier to have full coverage, but found:\n  1 coordinate on 1 path\n    /ping: GET 204",
+		m.FailureMessage(c))
+
+	_, err = c.Get(s.URL + "/ping")
+	require.NoError(t, err)
+	g.Expect(c).To(HaveFullCoverage())
+	g.Expect(c.Verifier).To(SucceedVerification())
+
+	_, err = c.Get(s.URL + "/missing")
+	require.NoError(t, err)
+	m = HaveNoContractViolations()
+	ok, err = m.Match(c)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Contains(t, m.FailureMessage(c), "not part of spec: GET /missing")
+
+	_, err = HaveFullCoverage().Match("not a verifier")
+	assert.EqualError(t, err, "have full coverage expects a *copper.Verifier or *copper.ValidatingClient, but got string")
+}
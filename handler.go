@@ -0,0 +1,64 @@
+package copper
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/callebjorkell/copper/internal/capture"
+)
+
+// ValidatingHandler provides an http.Handler that serves the requests with the handler that it wraps, recording every
+// request and the response to it. It is the server side counterpart of ValidatingClient, for services that are tested
+// in-process or with httptest.NewServer, and like it, it is safe for concurrent use.
+type ValidatingHandler struct {
+	h http.Handler
+	*Verifier
+}
+
+var _ http.Handler = (*ValidatingHandler)(nil)
+
+// WrapHandler takes an HTTP handler and io.Reader for the OpenAPI spec. The spec is parsed, and wraps the handler so
+// that the inbound requests are recorded when served. The options are the same as for WrapClient, so the requests are
+// only validated with WithRequestValidation, unlike with NewServerVerifier. The path in the spec is worked out from
+// the URL, since the handler does not know which route was matched; the adapters for routers avoid that.
+func WrapHandler(h http.Handler, spec io.Reader, opts ...Option) (*ValidatingHandler, error) {
+	s, err := io.ReadAll(spec)
+	if err != nil {
+		return nil, fmt.Errorf("could not read spec: %w", err)
+	}
+
+	verifier, err := NewVerifier(s, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("could not create verifier: %w", err)
+	}
+
+	return &ValidatingHandler{
+		h:        h,
+		Verifier: verifier,
+	}, nil
+}
+
+// MustWrapHandler is like WrapHandler, but panics if the spec can not be read or the Verifier can not be created. It
+// is meant for test helpers and examples, where a spec that can not be used is a bug in the test.
+func MustWrapHandler(h http.Handler, spec io.Reader, opts ...Option) *ValidatingHandler {
+	handler, err := WrapHandler(h, spec, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return handler
+}
+
+// ServeHTTP serves the request with the wrapped handler, and then records it with the response that was written. The
+// body of the request is buffered first, so that it can be validated after the handler has read it.
+func (v *ValidatingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := capture.BufferRequest(r); err != nil {
+		http.Error(w, "could not read request body", http.StatusBadRequest)
+		return
+	}
+
+	cw := capture.NewWriter(w)
+	v.h.ServeHTTP(cw, r)
+
+	v.Record(cw.Response(r))
+}
@@ -0,0 +1,43 @@
+package copper_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/callebjorkell/copper"
+	"github.com/callebjorkell/copper/internal/routertest"
+	"github.com/stretchr/testify/assert"
+)
+
+// The tests of WrapHandler are in their own package, since they share the fixture of the router adapters, which imports
+// copper.
+
+func TestWrapHandler(t *testing.T) {
+	router := func(t *testing.T, spec []byte, opts ...copper.Option) (http.Handler, *copper.Verifier) {
+		h := copper.MustWrapHandler(routertest.Handler(), bytes.NewReader(spec), opts...)
+		return h, h.Verifier
+	}
+	routertest.Test(t, router, http.StatusMethodNotAllowed)
+
+	t.Run("the path is worked out from the URL", func(t *testing.T) {
+		assert.Equal(t, []string{"/things/export"}, routertest.Matched(t, router, http.MethodGet, "/api/things/export"))
+		assert.Equal(t, []string{"/things/{id}"}, routertest.Matched(t, router, http.MethodGet, "/api/things/abc"))
+	})
+
+	t.Run("undocumented response", func(t *testing.T) {
+		h, v := router(t, routertest.Spec(t), copper.WithoutFullCoverage())
+
+		assert.Equal(t, http.StatusBadRequest, routertest.Serve(h, http.MethodPut, "/api/things/abc", "").Code)
+		errs := v.CurrentErrors()
+		if assert.Len(t, errs, 1) {
+			assert.True(t, errors.Is(errs[0], copper.ErrResponseInvalid))
+		}
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		_, err := copper.WrapHandler(routertest.Handler(), bytes.NewReader(routertest.Spec(t)), copper.WithSampling(2))
+		assert.ErrorIs(t, err, copper.ErrInvalidOptions)
+	})
+}
@@ -0,0 +1,162 @@
+package copper
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+// extTrailer marks a documented response header as a trailer, which is sent after the body rather than before it.
+const extTrailer = "x-copper-trailer"
+
+// checkResponseHeaders checks that all headers that are required by the documented response are present. Content-Type
+// is described by the content of the response, and is ignored if documented as a header according to the spec.
+// Headers documented as trailers are not expected among the headers.
+func checkResponseHeaders(response *v3.Response, h http.Header) error {
+	if response == nil || response.Headers == nil {
+		return nil
+	}
+
+	var errs []error
+	for name, header := range response.Headers.FromOldest() {
+		if strings.EqualFold(name, "Content-Type") || extensionBool(header.Extensions, extTrailer) {
+			continue
+		}
+		if header.Required && len(h.Values(name)) == 0 {
+			errs = append(errs, fmt.Errorf("required header %s is missing", name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkResponseTrailers checks that all headers that are documented as required trailers are present among the
+// trailers of the response. Trailers are only available once the body has been read completely.
+func checkResponseTrailers(response *v3.Response, trailer http.Header) error {
+	if response == nil || response.Headers == nil {
+		return nil
+	}
+
+	var errs []error
+	for name, header := range response.Headers.FromOldest() {
+		if !extensionBool(header.Extensions, extTrailer) {
+			continue
+		}
+		if header.Required && len(trailer.Values(name)) == 0 {
+			errs = append(errs, fmt.Errorf("required trailer %s is missing", name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// StatusHeaders maps status codes to the headers that every response with that status code must have.
+type StatusHeaders map[int][]string
+
+// DefaultStatusHeaders returns the headers that the status codes structurally require, and that clients rely on even
+// when a spec does not document them: Location on 201 Created and on redirects, WWW-Authenticate on 401 Unauthorized,
+// and Allow on 405 Method Not Allowed. A new map is returned every time, so that it can be changed before it is given
+// to WithStatusHeaders.
+func DefaultStatusHeaders() StatusHeaders {
+	return StatusHeaders{
+		http.StatusCreated:           {"Location"},
+		http.StatusMovedPermanently:  {"Location"},
+		http.StatusFound:             {"Location"},
+		http.StatusSeeOther:          {"Location"},
+		http.StatusTemporaryRedirect: {"Location"},
+		http.StatusPermanentRedirect: {"Location"},
+		http.StatusUnauthorized:      {"WWW-Authenticate"},
+		http.StatusMethodNotAllowed:  {"Allow"},
+	}
+}
+
+// WithStatusHeaders is a functional Option for requiring headers on responses by their status code, regardless of what
+// the spec documents. Use DefaultStatusHeaders for the headers that the status codes structurally require.
+func WithStatusHeaders(required StatusHeaders) Option {
+	return func(c *config) {
+		c.statusHeaders = required
+	}
+}
+
+// checkStatusHeaders checks that the response has the headers that are required for its status code.
+func checkStatusHeaders(res *http.Response, required StatusHeaders) error {
+	var errs []error
+	for _, name := range required[res.StatusCode] {
+		if len(res.Header.Values(name)) == 0 {
+			errs = append(errs, fmt.Errorf("%d response is missing the %s header", res.StatusCode, name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// HeaderValues decides how a header that is given several times is validated.
+type HeaderValues int
+
+const (
+	// FirstHeaderValue validates only the first value of a header that is given several times. This is the default.
+	FirstHeaderValue HeaderValues = iota
+	// JoinedHeaderValues joins all values of a header that is given several times with commas, the way that RFC 9110
+	// allows a recipient to combine them, and validates the result. Set-Cookie is never joined, since its values can
+	// contain commas themselves.
+	JoinedHeaderValues
+)
+
+// normalizeHeaders returns a copy of the headers where every name is in the canonical form, so that headers are matched
+// regardless of their case even when set directly in the map, and where the values of each header are combined
+// according to the mode.
+func normalizeHeaders(h http.Header, mode HeaderValues) http.Header {
+	if h == nil {
+		return nil
+	}
+
+	// The names are sorted to get the same order of values regardless of the iteration order of the map.
+	normalized := make(http.Header, len(h))
+	for _, name := range slices.Sorted(maps.Keys(h)) {
+		key := http.CanonicalHeaderKey(name)
+		normalized[key] = append(normalized[key], h[name]...)
+	}
+
+	if mode == JoinedHeaderValues {
+		for name, values := range normalized {
+			if len(values) > 1 && name != "Set-Cookie" {
+				normalized[name] = []string{strings.Join(values, ",")}
+			}
+		}
+	}
+	return normalized
+}
+
+// headerValues returns all values of the named header, matching the name regardless of case.
+func headerValues(h http.Header, name string) []string {
+	var values []string
+	for key, v := range h {
+		if strings.EqualFold(key, name) {
+			values = append(values, v...)
+		}
+	}
+	return values
+}
+
+// normalizeRequest returns a copy of the request with normalized headers, and a body of its own so that reading it
+// does not affect the original request.
+func normalizeRequest(req *http.Request, body []byte, mode HeaderValues) *http.Request {
+	normalized := req.Clone(req.Context())
+	normalized.Header = normalizeHeaders(req.Header, mode)
+	normalized.Body = io.NopCloser(bytes.NewReader(body))
+	return normalized
+}
+
+// normalizeResponse returns a copy of the response with normalized headers and trailers, and a body of its own so that
+// reading it does not affect the original response.
+func normalizeResponse(res *http.Response, body []byte, mode HeaderValues) *http.Response {
+	normalized := *res
+	normalized.Header = normalizeHeaders(res.Header, mode)
+	normalized.Trailer = normalizeHeaders(res.Trailer, mode)
+	normalized.Body = io.NopCloser(bytes.NewReader(body))
+	return &normalized
+}
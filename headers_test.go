@@ -0,0 +1,185 @@
+package copper
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseTrailers(t *testing.T) {
+	f, err := os.ReadFile("testdata/trailer-spec.yaml")
+	require.NoError(t, err)
+
+	tt := []struct {
+		name       string
+		trailer    string
+		undeclared bool
+		valid      bool
+	}{
+		{"trailer is sent", "abc123", false, true},
+		{"trailer is missing", "", false, false},
+		{"undeclared trailer is sent", "abc123", true, true},
+		{"undeclared trailer is missing", "", true, false},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			s := httptest.NewServer(http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Content-Type", "text/plain")
+					if !tc.undeclared {
+						w.Header().Set("Trailer", "Checksum")
+					}
+					w.WriteHeader(http.StatusOK)
+					_, _ = w.Write([]byte("file content"))
+					// Flushing sends the body in chunks, which the trailers follow.
+					w.(http.Flusher).Flush()
+					switch {
+					case tc.trailer == "":
+					case tc.undeclared:
+						// Trailers that are not announced up front are only known to the client once the body is read.
+						w.Header().Set(http.TrailerPrefix+"Checksum", tc.trailer)
+					default:
+						w.Header().Set("Checksum", tc.trailer)
+					}
+				}),
+			)
+			defer s.Close()
+
+			c, err := WrapClient(http.DefaultClient, bytes.NewReader(f))
+			require.NoError(t, err)
+
+			_, err = c.Get(s.URL + "/download")
+			require.NoError(t, err)
+
+			if tc.valid {
+				assert.NoError(t, c.CurrentError())
+			} else {
+				assert.ErrorIs(t, c.CurrentError(), ErrResponseInvalid)
+			}
+		})
+	}
+
+	t.Run("trailer is not required among the headers", func(t *testing.T) {
+		v, err := NewVerifier(f)
+		require.NoError(t, err)
+
+		response, _ := documentedResponse(v.model.Paths.PathItems.GetOrZero("/download").Get, http.StatusOK)
+		assert.NoError(t, checkResponseHeaders(response, http.Header{}))
+		assert.Error(t, checkResponseTrailers(response, http.Header{}))
+	})
+}
+
+func TestHeaderValues(t *testing.T) {
+	f, err := os.ReadFile("testdata/header-spec.yaml")
+	require.NoError(t, err)
+
+	tt := []struct {
+		name   string
+		header http.Header
+		first  bool
+		joined bool
+	}{
+		{"canonical name", http.Header{"X-Limit": {"5"}}, true, true},
+		{"lowercase name", http.Header{"x-limit": {"5"}}, true, true},
+		{"invalid value with lowercase name", http.Header{"x-limit": {"five"}}, false, false},
+		{"required header missing", http.Header{"X-Other": {"5"}}, false, false},
+		{"repeated header", http.Header{"X-Limit": {"5", "five"}}, true, false},
+		{"repeated header in different case", http.Header{"X-Limit": {"5"}, "x-limit": {"five"}}, true, false},
+		{"repeated array header", http.Header{"X-Limit": {"5"}, "X-Ids": {"1", "2"}}, true, true},
+		{"repeated array header with invalid value", http.Header{"X-Limit": {"5"}, "X-Ids": {"1", "x"}}, true, false},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, mode := range []HeaderValues{FirstHeaderValue, JoinedHeaderValues} {
+				v, err := NewVerifier(f, WithRequestValidation(), WithoutFullCoverage(), WithHeaderValues(mode))
+				require.NoError(t, err)
+
+				req := httptest.NewRequest(http.MethodGet, "/items", nil)
+				req.Header = tc.header
+				v.Record(&http.Response{
+					StatusCode: http.StatusOK,
+					Header:     http.Header{"content-type": {"application/json"}},
+					Body:       io.NopCloser(strings.NewReader(`{"count": 1}`)),
+					Request:    req,
+				})
+
+				valid := tc.first
+				if mode == JoinedHeaderValues {
+					valid = tc.joined
+				}
+				if valid {
+					assert.NoError(t, v.CurrentError(), "mode: %v", mode)
+				} else {
+					assert.ErrorIs(t, v.CurrentError(), ErrRequestInvalid, "mode: %v", mode)
+				}
+			}
+		})
+	}
+}
+
+func TestStatusHeaders(t *testing.T) {
+	f, err := os.ReadFile("testdata/status-headers-spec.yaml")
+	require.NoError(t, err)
+
+	tt := []struct {
+		name   string
+		method string
+		status int
+		header http.Header
+		err    string
+	}{
+		{"201 with Location", http.MethodPost, http.StatusCreated, http.Header{"Location": {"/things/1"}}, ""},
+		{"201 without Location", http.MethodPost, http.StatusCreated, nil, "201 response is missing the Location header"},
+		{"302 without Location", http.MethodGet, http.StatusFound, nil, "302 response is missing the Location header"},
+		{"401 without WWW-Authenticate", http.MethodPost, http.StatusUnauthorized, nil, "401 response is missing the WWW-Authenticate header"},
+		{"405 with Allow", http.MethodPost, http.StatusMethodNotAllowed, http.Header{"Allow": {"GET"}}, ""},
+		{"405 without Allow", http.MethodPost, http.StatusMethodNotAllowed, nil, "405 response is missing the Allow header"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			v, err := NewVerifier(f, WithStatusHeaders(DefaultStatusHeaders()), WithoutFullCoverage())
+			require.NoError(t, err)
+
+			v.Record(&http.Response{
+				StatusCode: tc.status,
+				Request:    httptest.NewRequest(tc.method, "/things", nil),
+				Header:     tc.header,
+			})
+			if tc.err == "" {
+				assert.NoError(t, v.CurrentError())
+			} else {
+				assert.ErrorIs(t, v.CurrentError(), ErrResponseInvalid)
+				assert.ErrorContains(t, v.CurrentError(), tc.err)
+			}
+		})
+	}
+
+	t.Run("not required by default", func(t *testing.T) {
+		v, err := NewVerifier(f, WithoutFullCoverage())
+		require.NoError(t, err)
+
+		v.Record(&http.Response{StatusCode: http.StatusCreated, Request: httptest.NewRequest(http.MethodPost, "/things", nil)})
+		assert.NoError(t, v.CurrentError())
+	})
+}
+
+func TestNormalizeHeaders(t *testing.T) {
+	h := http.Header{
+		"X-A":        {"1"},
+		"x-a":        {"2"},
+		"Set-Cookie": {"a=1", "b=2"},
+	}
+
+	assert.Equal(t, http.Header{"X-A": {"1", "2"}, "Set-Cookie": {"a=1", "b=2"}}, normalizeHeaders(h, FirstHeaderValue))
+	assert.Equal(t, http.Header{"X-A": {"1,2"}, "Set-Cookie": {"a=1", "b=2"}}, normalizeHeaders(h, JoinedHeaderValues))
+}
@@ -0,0 +1,132 @@
+// Package history keeps track of the coverage and violations of verification runs over time, in an append-only file
+// with one JSON object per line, and can show the trend of them. This is useful for teams that drive the coverage of
+// an API up over a longer period of time.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/callebjorkell/copper"
+)
+
+// Run is the result of a single verification run.
+type Run struct {
+	Time time.Time `json:"time"`
+	// Coverage is the percentage of the coordinates in the spec that were checked.
+	Coverage  float64 `json:"coverage"`
+	Checked   int     `json:"checked"`
+	Endpoints int     `json:"endpoints"`
+	// Violations is the number of errors other than coordinates that were not checked.
+	Violations int `json:"violations"`
+}
+
+// NewRun returns the run for a summary of the verification results, at the given time.
+func NewRun(s copper.Summary, t time.Time) Run {
+	return Run{
+		Time:       t.UTC(),
+		Coverage:   s.Coverage(),
+		Checked:    s.Checked,
+		Endpoints:  s.Endpoints,
+		Violations: s.Violations,
+	}
+}
+
+// Store is an append-only file of runs.
+type Store struct {
+	path string
+}
+
+// Open returns a store for the file at the path. The file is created when the first run is appended.
+func Open(path string) *Store {
+	return &Store{path: path}
+}
+
+// Record appends a run for the current results of the verifier.
+func (s *Store) Record(v *copper.Verifier) error {
+	return s.Append(NewRun(v.Summary(), time.Now()))
+}
+
+// Append appends a run to the file.
+func (s *Store) Append(r Run) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("could not open history: %w", err)
+	}
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("could not write history: %w", err)
+	}
+	return f.Close()
+}
+
+// Runs returns the runs in the file, in the order that they were appended. A file that does not exist yet has no runs.
+func (s *Store) Runs() ([]Run, error) {
+	f, err := os.Open(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not open history: %w", err)
+	}
+	defer f.Close()
+
+	var runs []Run
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var r Run
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			return nil, fmt.Errorf("could not read history at line %d: %w", line, err)
+		}
+		runs = append(runs, r)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read history: %w", err)
+	}
+	return runs, nil
+}
+
+// trendWidth is the width of the bar that shows the coverage of a run.
+const trendWidth = 20
+
+// WriteTrend writes a table of the runs, with a bar for the coverage of each run and the change in coverage and
+// violations from the run before it.
+func WriteTrend(w io.Writer, runs []Run) error {
+	var b strings.Builder
+	line := func(format string, args ...any) {
+		b.WriteString(strings.TrimRight(fmt.Sprintf(format, args...), " "))
+		b.WriteString("\n")
+	}
+
+	line("%-20s  %-*s  %8s  %8s  %10s  %6s", "TIME", trendWidth, "COVERAGE", "", "CHANGE", "VIOLATIONS", "CHANGE")
+	for i, r := range runs {
+		filled := int(r.Coverage / 100 * trendWidth)
+		bar := strings.Repeat("#", filled) + strings.Repeat(".", trendWidth-filled)
+
+		coverageChange, violationsChange := "", ""
+		if i > 0 {
+			coverageChange = fmt.Sprintf("%+.1f", r.Coverage-runs[i-1].Coverage)
+			violationsChange = fmt.Sprintf("%+d", r.Violations-runs[i-1].Violations)
+		}
+		line("%-20s  %s  %7.1f%%  %8s  %10d  %6s",
+			r.Time.Format(time.RFC3339), bar, r.Coverage, coverageChange, r.Violations, violationsChange)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,81 @@
+package history
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/callebjorkell/copper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	s := Open(filepath.Join(t.TempDir(), "history.jsonl"))
+
+	runs, err := s.Runs()
+	require.NoError(t, err)
+	assert.Empty(t, runs)
+
+	first := Run{Time: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), Coverage: 50, Checked: 1, Endpoints: 2, Violations: 3}
+	second := Run{Time: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), Coverage: 100, Checked: 2, Endpoints: 2}
+	require.NoError(t, s.Append(first))
+	require.NoError(t, s.Append(second))
+
+	runs, err = s.Runs()
+	require.NoError(t, err)
+	assert.Equal(t, []Run{first, second}, runs)
+}
+
+func TestRecord(t *testing.T) {
+	f, err := os.ReadFile("../testdata/thing-spec.yaml")
+	require.NoError(t, err)
+
+	v, err := copper.NewVerifier(f)
+	require.NoError(t, err)
+	v.Record(&http.Response{
+		StatusCode: http.StatusOK,
+		Request:    httptest.NewRequest(http.MethodGet, "/ping", nil),
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(`{"message": 1}`)),
+	})
+
+	s := Open(filepath.Join(t.TempDir(), "history.jsonl"))
+	require.NoError(t, s.Record(v))
+
+	runs, err := s.Runs()
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, 50.0, runs[0].Coverage)
+	assert.Equal(t, 1, runs[0].Violations)
+	assert.WithinDuration(t, time.Now(), runs[0].Time, time.Minute)
+}
+
+func TestCorruptHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte("{\"coverage\": 50}\nnot json\n"), 0o644))
+
+	_, err := Open(path).Runs()
+	assert.ErrorContains(t, err, "line 2")
+}
+
+func TestWriteTrend(t *testing.T) {
+	runs := []Run{
+		{Time: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), Coverage: 50, Violations: 3},
+		{Time: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), Coverage: 75, Violations: 1},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteTrend(&buf, runs))
+	assert.Equal(t, ""+
+		"TIME                  COVERAGE                          CHANGE  VIOLATIONS  CHANGE\n"+
+		"2026-01-01T00:00:00Z  ##########..........     50.0%                     3\n"+
+		"2026-02-01T00:00:00Z  ###############.....     75.0%     +25.0           1      -2\n",
+		buf.String())
+}
@@ -0,0 +1,146 @@
+package copper
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+// WithIgnoredOperations is a functional Option for acknowledging operations that can not be tested, given like the
+// ones of WithExcludeOperations. Their coordinates are waived from the coverage with the reason, so that full coverage
+// can still be required for the rest of the spec, but unlike excluded operations, the requests to them that are
+// recorded are still verified.
+func WithIgnoredOperations(operations ...string) Option {
+	return func(c *config) {
+		c.filters.ignoredOperations = append(c.filters.ignoredOperations, operations...)
+	}
+}
+
+// WithIgnoredResponses is a functional Option for acknowledging known gaps in the responses of an operation, given
+// like the ones of WithExcludeOperations, such as WithIgnoredResponses("GET /legacy", "500", "418"). The response codes
+// can also be ranges like 4XX. Documented responses are waived from the coverage, and undocumented ones are accepted
+// without being validated, rather than reported as not documented. Both are listed as waived.
+func WithIgnoredResponses(operation string, codes ...string) Option {
+	return func(c *config) {
+		c.filters.ignoredResponses = append(c.filters.ignoredResponses, ignoredResponses{operation: operation, codes: codes})
+	}
+}
+
+// ignoredResponses are the response codes of an operation that WithIgnoredResponses is given.
+type ignoredResponses struct {
+	operation string
+	codes     []string
+}
+
+func (i ignoredResponses) equal(other ignoredResponses) bool {
+	return i.operation == other.operation && slices.Equal(i.codes, other.codes)
+}
+
+// validate returns an error for a pattern that path.Match can not use, and for codes that are not response codes.
+func (i ignoredResponses) validate() []error {
+	var errs []error
+	if _, pattern, ok := strings.Cut(i.operation, " "); ok {
+		if _, err := path.Match(pattern, ""); err != nil {
+			errs = append(errs, fmt.Errorf("WithIgnoredResponses is given %q, but it is not a valid pattern: %w", i.operation, err))
+		}
+	}
+	if len(i.codes) == 0 {
+		errs = append(errs, fmt.Errorf("WithIgnoredResponses is given no response codes for %q", i.operation))
+	}
+	for _, code := range i.codes {
+		if !validResponseCode(code) {
+			errs = append(errs, fmt.Errorf("WithIgnoredResponses is given %q for %q, but it is not a response code", code, i.operation))
+		}
+	}
+	return errs
+}
+
+// String returns the option like it is given in code.
+func (i ignoredResponses) String() string {
+	return "WithIgnoredResponses(" + quoteAll(append([]string{i.operation}, i.codes...)) + ")"
+}
+
+// ignoredOperation returns whether the operation at the path of the spec is ignored, and the reason why.
+func (f filters) ignoredOperation(specPath, method string, op *v3.Operation) (string, bool) {
+	var operationID string
+	if op != nil {
+		operationID = op.OperationId
+	}
+	for _, operation := range f.ignoredOperations {
+		if matchOperation(operation, specPath, method, operationID) {
+			return fmt.Sprintf("ignored by WithIgnoredOperations(%q)", operation), true
+		}
+	}
+	return "", false
+}
+
+// ignoredResponse returns whether the response code of the operation at the path of the spec is ignored, and the
+// reason why. The code is either a status code, or a range as it is documented.
+func (f filters) ignoredResponse(specPath, method string, op *v3.Operation, code string) (string, bool) {
+	var operationID string
+	if op != nil {
+		operationID = op.OperationId
+	}
+	for _, ignored := range f.ignoredResponses {
+		if !matchOperation(ignored.operation, specPath, method, operationID) {
+			continue
+		}
+		if slices.ContainsFunc(ignored.codes, func(c string) bool { return matchResponseCode(c, code) }) {
+			return "ignored by " + ignored.String(), true
+		}
+	}
+	return "", false
+}
+
+// undocumentedIgnoredResponses returns the response codes that are ignored for the operation at the path of the spec,
+// but that it does not document.
+func (f filters) undocumentedIgnoredResponses(specPath, method string, op *v3.Operation) []string {
+	var codes []string
+	for _, ignored := range f.ignoredResponses {
+		if !matchOperation(ignored.operation, specPath, method, op.OperationId) {
+			continue
+		}
+		for _, code := range ignored.codes {
+			if (op.Responses == nil || op.Responses.Codes.GetOrZero(code) == nil) && !slices.Contains(codes, code) {
+				codes = append(codes, code)
+			}
+		}
+	}
+	return codes
+}
+
+// undocumentedIgnoredResponse returns true if the status code is not documented for the operation at the path of the
+// spec, but ignored by WithIgnoredResponses.
+func (v *Verifier) undocumentedIgnoredResponse(specPath, method string, op *v3.Operation, status int) bool {
+	if op == nil || len(v.conf.filters.ignoredResponses) == 0 {
+		return false
+	}
+	if response, _ := documentedResponse(op, status); response != nil {
+		return false
+	}
+	_, ignored := v.conf.filters.ignoredResponse(specPath, method, op, strconv.Itoa(status))
+	return ignored
+}
+
+// matchResponseCode matches a response code with one given to WithIgnoredResponses, where a range like 4XX matches
+// every code in it.
+func matchResponseCode(ignored, code string) bool {
+	if strings.EqualFold(ignored, code) {
+		return true
+	}
+	return len(ignored) == 3 && strings.EqualFold(ignored[1:], "XX") && len(code) == 3 && code[0] == ignored[0] &&
+		code[1] >= '0' && code[1] <= '9'
+}
+
+// validResponseCode returns true for the status codes and ranges that responses can be documented with.
+func validResponseCode(code string) bool {
+	if len(code) == 3 && code[0] >= '1' && code[0] <= '5' && strings.EqualFold(code[1:], "XX") {
+		return true
+	}
+	n, err := strconv.Atoi(code)
+	return err == nil && len(code) == 3 && n >= 100 && n <= 599
+}
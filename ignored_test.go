@@ -0,0 +1,112 @@
+package copper
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIgnored(t *testing.T) {
+	f, err := os.ReadFile("testdata/ignored-spec.yaml")
+	require.NoError(t, err)
+
+	record := func(v *Verifier, method, target string, status int, body string) {
+		res := &http.Response{StatusCode: status, Request: httptest.NewRequest(method, target, nil), Header: http.Header{}}
+		if body != "" {
+			res.Header.Set("Content-Type", "application/json")
+			res.Body = io.NopCloser(strings.NewReader(body))
+		}
+		v.Record(res)
+	}
+	waived := func(v *Verifier) map[string]string {
+		w := make(map[string]string)
+		for _, e := range v.Waived() {
+			w[e.Method+" "+e.Path+" "+e.ResponseCode] = e.Reason
+		}
+		return w
+	}
+
+	t.Run("full coverage of the rest", func(t *testing.T) {
+		v, err := NewVerifier(f,
+			WithIgnoredResponses("GET /legacy", "503", "418"),
+			WithIgnoredResponses("listThings", "4XX"),
+			WithIgnoredOperations("POST /untestable"),
+		)
+		require.NoError(t, err)
+
+		record(v, http.MethodGet, "/legacy", http.StatusOK, `{"id": 1}`)
+		record(v, http.MethodGet, "/things", http.StatusOK, "")
+		assert.NoError(t, v.CurrentError())
+
+		reason := `ignored by WithIgnoredResponses("GET /legacy", "503", "418")`
+		assert.Equal(t, map[string]string{
+			"GET /legacy 503":      reason,
+			"GET /legacy 418":      reason,
+			"GET /things 404":      `ignored by WithIgnoredResponses("listThings", "4XX")`,
+			"GET /things 4XX":      `ignored by WithIgnoredResponses("listThings", "4XX")`,
+			"POST /untestable 201": `ignored by WithIgnoredOperations("POST /untestable")`,
+			"POST /untestable 409": `ignored by WithIgnoredOperations("POST /untestable")`,
+		}, waived(v))
+	})
+
+	t.Run("undocumented responses are accepted", func(t *testing.T) {
+		v, err := NewVerifier(f, WithIgnoredResponses("GET /legacy", "418"), WithoutFullCoverage())
+		require.NoError(t, err)
+
+		record(v, http.MethodGet, "/legacy", http.StatusTeapot, `not json`)
+		assert.NoError(t, v.CurrentError())
+
+		record(v, http.MethodGet, "/things", http.StatusTeapot, "")
+		assert.ErrorIs(t, v.CurrentError(), ErrResponseInvalid, "only the responses of the operation are ignored")
+	})
+
+	t.Run("ignored operations are still verified", func(t *testing.T) {
+		v, err := NewVerifier(f, WithIgnoredOperations("POST /untestable"))
+		require.NoError(t, err)
+
+		record(v, http.MethodPost, "/untestable", http.StatusCreated, `{}`)
+		errs := v.CurrentErrors()
+		require.NotEmpty(t, errs)
+		assert.ErrorIs(t, errs[0], ErrResponseInvalid)
+	})
+
+	t.Run("waived in the report", func(t *testing.T) {
+		v, err := NewVerifier(f, WithIgnoredResponses("GET /legacy", "503"))
+		require.NoError(t, err)
+
+		assert.Contains(t, v.DebugString(), `WithIgnoredResponses("GET /legacy", "503")`)
+		assert.Contains(t, v.Waived(), WaivedEndpoint{
+			Endpoint: Endpoint{Path: "/legacy", Method: http.MethodGet, ResponseCode: "503"},
+			Reason:   `ignored by WithIgnoredResponses("GET /legacy", "503")`,
+		})
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		_, err := NewVerifier(f,
+			WithIgnoredResponses("GET /["),
+			WithIgnoredResponses("GET /legacy", "600", "4xx", "default"),
+			WithIgnoredOperations("GET /["),
+		)
+		assert.ErrorIs(t, err, ErrInvalidOptions)
+		assert.ErrorContains(t, err, `WithIgnoredResponses is given "GET /[", but it is not a valid pattern`)
+		assert.ErrorContains(t, err, `WithIgnoredResponses is given no response codes for "GET /["`)
+		assert.ErrorContains(t, err, `WithIgnoredResponses is given "600" for "GET /legacy", but it is not a response code`)
+		assert.ErrorContains(t, err, `WithIgnoredResponses is given "default" for "GET /legacy", but it is not a response code`)
+		assert.NotContains(t, err.Error(), `"4xx"`)
+		assert.ErrorContains(t, err, `WithIgnoredOperations is given "GET /[", but it is not a valid pattern`)
+	})
+
+	t.Run("ignored responses can not be changed", func(t *testing.T) {
+		v, err := NewVerifier(f, WithIgnoredResponses("GET /legacy", "503"))
+		require.NoError(t, err)
+
+		assert.ErrorIs(t, v.SetOptions(WithIgnoredResponses("GET /legacy", "418")), ErrInvalidOptions)
+		assert.Panics(t, func() { v.With(WithIgnoredOperations("GET /legacy")) })
+	})
+}
@@ -0,0 +1,122 @@
+// Package capture keeps copies of what server handlers receive and respond, so that the exchanges can be recorded by a
+// copper.Verifier once the handler is done.
+package capture
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Writer is a http.ResponseWriter that passes everything on to the writer that it wraps, while keeping a copy of the
+// status, headers and body of the response.
+type Writer struct {
+	http.ResponseWriter
+	status int
+	header http.Header
+	body   bytes.Buffer
+}
+
+// NewWriter returns a Writer that wraps the given writer.
+func NewWriter(w http.ResponseWriter) *Writer {
+	return &Writer{ResponseWriter: w}
+}
+
+// WriteHeader keeps the status code and the headers as they are when it is written. Informational (1xx) responses are
+// passed on, but not kept since they are not the final response.
+func (w *Writer) WriteHeader(code int) {
+	if w.status == 0 && (code >= 200 || code == http.StatusSwitchingProtocols) {
+		w.status = code
+		w.header = w.Header().Clone()
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write keeps a copy of the body. As for any http.ResponseWriter, the status is 200 if it has not been written.
+func (w *Writer) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush flushes the wrapped writer, if it supports it.
+func (w *Writer) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the wrapped writer, which lets http.ResponseController reach it.
+func (w *Writer) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Response returns the response that has been written so far, as it would have been received by a client.
+func (w *Writer) Response(req *http.Request) *http.Response {
+	status, header := w.status, w.header
+	if status == 0 {
+		// Nothing was written, which the server sends as an empty 200 response.
+		status, header = http.StatusOK, w.Header().Clone()
+	}
+	return NewResponse(req, status, header, w.Header(), w.body.Bytes())
+}
+
+// NewResponse returns a response as it would have been received by a client. The header is the one that was sent with
+// the status, and current is the header map of the writer once the handler is done, which the trailers are taken from.
+func NewResponse(req *http.Request, status int, header, current http.Header, body []byte) *http.Response {
+	return &http.Response{
+		Status:        http.StatusText(status),
+		StatusCode:    status,
+		Proto:         req.Proto,
+		ProtoMajor:    req.ProtoMajor,
+		ProtoMinor:    req.ProtoMinor,
+		Header:        header,
+		Trailer:       trailer(header, current),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// trailer returns the trailers that have been set, either declared in the Trailer header before the status was
+// written, or with the http.TrailerPrefix after it.
+func trailer(header, current http.Header) http.Header {
+	trailer := make(http.Header)
+	for _, names := range header.Values("Trailer") {
+		for _, name := range strings.Split(names, ",") {
+			name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+			if values := current.Values(name); len(values) > 0 {
+				trailer[name] = values
+			}
+		}
+	}
+	for name, values := range current {
+		if after, ok := strings.CutPrefix(name, http.TrailerPrefix); ok {
+			trailer[http.CanonicalHeaderKey(after)] = values
+		}
+	}
+
+	if len(trailer) == 0 {
+		return nil
+	}
+	return trailer
+}
+
+// BufferRequest reads the body of the request and replaces it, so that it can be read by the handler and then again
+// when the exchange is recorded.
+func BufferRequest(req *http.Request) error {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	return err
+}
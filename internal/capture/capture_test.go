@@ -0,0 +1,105 @@
+package capture
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriter(t *testing.T) {
+	tt := []struct {
+		name    string
+		handler http.HandlerFunc
+		status  int
+		header  http.Header
+		trailer http.Header
+		body    string
+	}{
+		{
+			name:    "nothing written",
+			handler: func(w http.ResponseWriter, r *http.Request) {},
+			status:  http.StatusOK,
+			header:  http.Header{},
+		},
+		{
+			name: "body without status",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/plain")
+				_, _ = w.Write([]byte("hello"))
+			},
+			status: http.StatusOK,
+			header: http.Header{"Content-Type": {"text/plain"}},
+			body:   "hello",
+		},
+		{
+			name: "headers set after the status are not kept",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+				w.Header().Set("X-Late", "yes")
+			},
+			status: http.StatusNoContent,
+			header: http.Header{},
+		},
+		{
+			name: "informational responses are skipped",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Link", "</style.css>; rel=preload")
+				w.WriteHeader(http.StatusEarlyHints)
+				w.WriteHeader(http.StatusCreated)
+			},
+			status: http.StatusCreated,
+			header: http.Header{"Link": {"</style.css>; rel=preload"}},
+		},
+		{
+			name: "trailers",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Trailer", "Checksum")
+				_, _ = w.Write([]byte("data"))
+				w.Header().Set("Checksum", "abc")
+				w.Header().Set(http.TrailerPrefix+"Other", "def")
+			},
+			status:  http.StatusOK,
+			header:  http.Header{"Trailer": {"Checksum"}},
+			trailer: http.Header{"Checksum": {"abc"}, "Other": {"def"}},
+			body:    "data",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := NewWriter(httptest.NewRecorder())
+			tc.handler(w, req)
+
+			res := w.Response(req)
+			assert.Equal(t, tc.status, res.StatusCode)
+			assert.Equal(t, tc.header, res.Header)
+			assert.Equal(t, tc.trailer, res.Trailer)
+			assert.Same(t, req, res.Request)
+
+			body, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			assert.Equal(t, tc.body, string(body))
+		})
+	}
+}
+
+func TestBufferRequest(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("content"))
+	require.NoError(t, BufferRequest(req))
+
+	body, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "content", string(body))
+
+	again, err := req.GetBody()
+	require.NoError(t, err)
+	body, err = io.ReadAll(again)
+	require.NoError(t, err)
+	assert.Equal(t, "content", string(body))
+}
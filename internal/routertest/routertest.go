@@ -0,0 +1,130 @@
+// Package routertest has the fixture that the tests of the router adapters share: the handlers for the paths of
+// testdata/route-spec.yaml, served below /api, and the tests that every adapter has to pass with them. The tests of an
+// adapter only register the handlers on its router, and test what is specific to the router.
+package routertest
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/callebjorkell/copper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Spec reads testdata/route-spec.yaml of the root module, wherever the tests of the adapter are run from.
+func Spec(t testing.TB) []byte {
+	_, file, _, _ := runtime.Caller(0)
+	b, err := os.ReadFile(filepath.Join(filepath.Dir(file), "..", "..", "testdata", "route-spec.yaml"))
+	require.NoError(t, err)
+	return b
+}
+
+// Export serves GET /things/export.
+func Export(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/csv")
+	_, _ = w.Write([]byte("id\n1\n"))
+}
+
+// GetThing serves GET /things/{id}, with the last segment of the path as the id.
+func GetThing(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write([]byte(`{"id": "` + path.Base(r.URL.Path) + `"}`))
+}
+
+// PutThing serves PUT /things/{id}. It reads the body, which the adapters must leave readable, and responds with 400 if
+// it is empty.
+func PutThing(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	if len(body) == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeleteThing serves DELETE /things/{id}, which is not in the spec, for routers that have to route it to record it.
+func DeleteThing(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Handler serves the handlers on an http.ServeMux, for wrappers that work out the path from the URL.
+func Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/things/export", Export)
+	mux.HandleFunc("GET /api/things/{thingID}", GetThing)
+	mux.HandleFunc("PUT /api/things/{thingID}", PutThing)
+	return mux
+}
+
+// Router returns the router under test for the spec and the options, along with the Verifier that it records into.
+type Router func(t *testing.T, spec []byte, opts ...copper.Option) (http.Handler, *copper.Verifier)
+
+// Middleware returns a Router for an adapter that records into the Verifier that it is given.
+func Middleware(newRouter func(v *copper.Verifier) http.Handler) Router {
+	return func(t *testing.T, spec []byte, opts ...copper.Option) (http.Handler, *copper.Verifier) {
+		v, err := copper.NewVerifier(spec, opts...)
+		require.NoError(t, err)
+		return newRouter(v), v
+	}
+}
+
+// Serve serves a request with the body as JSON to the handler.
+func Serve(h http.Handler, method, url, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, url, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+// Test runs the tests that every adapter has to pass. Unrouted is the status that the router responds to
+// DELETE /api/things/abc with, which is not in the spec.
+func Test(t *testing.T, router Router, unrouted int) {
+	spec := Spec(t)
+
+	t.Run("routes are covered", func(t *testing.T) {
+		h, v := router(t, spec, copper.WithRequestValidation())
+
+		assert.Equal(t, http.StatusOK, Serve(h, http.MethodGet, "/api/things/export", "").Code)
+		assert.Equal(t, http.StatusOK, Serve(h, http.MethodGet, "/api/things/abc", "").Code)
+		assert.Equal(t, http.StatusNoContent, Serve(h, http.MethodPut, "/api/things/abc", `{"name": "thing"}`).Code)
+
+		assert.NoError(t, v.CurrentError())
+	})
+
+	t.Run("invalid requests are reported", func(t *testing.T) {
+		h, v := router(t, spec, copper.WithRequestValidation(), copper.WithoutFullCoverage())
+
+		assert.Equal(t, http.StatusNoContent, Serve(h, http.MethodPut, "/api/things/abc", `{"title": "thing"}`).Code)
+		assert.ErrorIs(t, v.CurrentError(), copper.ErrRequestInvalid)
+	})
+
+	t.Run("routes that are not in the spec are reported", func(t *testing.T) {
+		h, v := router(t, spec, copper.WithoutFullCoverage())
+
+		assert.Equal(t, unrouted, Serve(h, http.MethodDelete, "/api/things/abc", "").Code)
+		assert.ErrorIs(t, v.CurrentError(), copper.ErrNotPartOfSpec)
+	})
+}
+
+// Matched serves a single request to a new router, and returns the paths of the spec that it was recorded for.
+func Matched(t *testing.T, router Router, method, url string) []string {
+	h, v := router(t, Spec(t), copper.WithoutFullCoverage())
+	Serve(h, method, url, "")
+
+	var matched []string
+	for _, e := range v.Endpoints() {
+		if e.Hits > 0 {
+			matched = append(matched, e.Path)
+		}
+	}
+	return matched
+}
@@ -0,0 +1,140 @@
+//go:build !copper_lite
+
+package copper
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteJUnit writes the verification results as JUnit XML, which CI systems show in their test result views. Every
+// coordinate of the spec is a test case, in a test suite named by the spec, like the test points of WriteTAP: a test
+// case fails with the errors found for it, or if it has not been checked while full coverage is required, or the
+// coverage is below the threshold of WithCoverageThreshold. Otherwise, coordinates that have not been checked are
+// skipped, and so are the waived ones, with the reason. Errors that do not belong to a coordinate, like requests to paths
+// that are not part of the spec, are added as failing test cases at the end.
+func (v *Verifier) WriteJUnit(w io.Writer) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	ends := v.endpoints.All()
+	attributed := make(map[error]bool)
+	for _, e := range ends {
+		for _, err := range v.endpoints.Failures(e) {
+			attributed[err] = true
+		}
+	}
+
+	suite := junitSuite{Name: strings.TrimSpace(v.model.Info.Title + " " + v.model.Info.Version)}
+	for _, e := range ends {
+		tc := junitCase{Name: tapDescription(e), ClassName: e.Path}
+		failures := v.endpoints.Failures(e)
+		switch {
+		case len(failures) > 0:
+			tc.Failure = junitFailure(failures)
+		case v.endpoints.IsChecked(e.Path, e.Method, e.ResponseCode):
+		case !v.requiresCoverage():
+			tc.Skipped = &junitSkipped{Message: "not checked"}
+		default:
+			tc.Failure = junitFailure([]error{ErrNotChecked})
+		}
+		suite.add(tc)
+	}
+	for _, e := range v.endpoints.Waived() {
+		suite.add(junitCase{
+			Name:      tapDescription(e.Endpoint),
+			ClassName: e.Path,
+			Skipped:   &junitSkipped{Message: "waived: " + e.Reason},
+		})
+	}
+	for _, err := range v.currentErrors() {
+		if attributed[err] || errors.Is(err, ErrNotChecked) {
+			continue
+		}
+		name := "error"
+		var verr *VerificationError
+		if errors.As(err, &verr) {
+			name = verr.Sentinel().Error()
+		}
+		suite.add(junitCase{Name: name, ClassName: "copper", Failure: junitFailure([]error{err})})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("could not write JUnit XML: %w", err)
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(junitSuites{Suites: []junitSuite{suite}}); err != nil {
+		return fmt.Errorf("could not write JUnit XML: %w", err)
+	}
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return fmt.Errorf("could not write JUnit XML: %w", err)
+	}
+	return nil
+}
+
+type junitSuites struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Suites  []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Skipped  int         `xml:"skipped,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+// add adds the test case to the suite, and counts it.
+func (s *junitSuite) add(tc junitCase) {
+	s.Tests++
+	if tc.Failure != nil {
+		s.Failures++
+	}
+	if tc.Skipped != nil {
+		s.Skipped++
+	}
+	s.Cases = append(s.Cases, tc)
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFail    `xml:"failure"`
+	Skipped   *junitSkipped `xml:"skipped"`
+}
+
+type junitFail struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// junitFailure returns the failure of a test case with the errors, where the message is the first line of the first
+// error, the type its sentinel, and the text every error in full.
+func junitFailure(errs []error) *junitFail {
+	lines := make([]string, 0, len(errs))
+	for _, err := range errs {
+		lines = append(lines, err.Error())
+	}
+	message, _, _ := strings.Cut(lines[0], "\n")
+
+	f := &junitFail{Message: message, Type: "error", Text: strings.Join(lines, "\n")}
+	var verr *VerificationError
+	var sentinel SentinelError
+	switch {
+	case errors.As(errs[0], &verr):
+		f.Type = verr.Sentinel().Error()
+	case errors.As(errs[0], &sentinel):
+		f.Type = sentinel.Error()
+	}
+	return f
+}
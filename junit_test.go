@@ -0,0 +1,82 @@
+//go:build !copper_lite
+
+package copper
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteJUnit(t *testing.T) {
+	f, err := os.ReadFile("testdata/thing-spec.yaml")
+	require.NoError(t, err)
+
+	record := func(v *Verifier, path, body string) {
+		v.Record(&http.Response{
+			StatusCode: 200,
+			Request:    httptest.NewRequest(http.MethodGet, path, nil),
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+		})
+	}
+
+	t.Run("all passing", func(t *testing.T) {
+		v := MustNewVerifier(f)
+		record(v, "/ping", `{"message": "pong"}`)
+		record(v, "/other", `{"thing": "thing"}`)
+
+		var buf bytes.Buffer
+		require.NoError(t, v.WriteJUnit(&buf))
+		assert.True(t, strings.HasPrefix(buf.String(), xml.Header))
+		assert.Contains(t, buf.String(), `<testsuite name="thing test 1.0" tests="2" failures="0" skipped="0">`)
+		assert.Contains(t, buf.String(), `<testcase name="GET /ping 200" classname="/ping"></testcase>`)
+	})
+
+	t.Run("failures", func(t *testing.T) {
+		v := MustNewVerifier(f)
+		record(v, "/ping", `{"message": 1}`)
+		record(v, "/missing", `{}`)
+
+		var buf bytes.Buffer
+		require.NoError(t, v.WriteJUnit(&buf))
+
+		var suites junitSuites
+		require.NoError(t, xml.Unmarshal(buf.Bytes(), &suites))
+		require.Len(t, suites.Suites, 1)
+		suite := suites.Suites[0]
+		assert.Equal(t, 3, suite.Tests)
+		assert.Equal(t, 3, suite.Failures)
+		require.Len(t, suite.Cases, 3)
+
+		assert.Equal(t, "GET /other 200", suite.Cases[0].Name)
+		assert.Equal(t, &junitFail{Message: "not checked", Type: "not checked", Text: "not checked"}, suite.Cases[0].Failure)
+
+		assert.Equal(t, "GET /ping 200", suite.Cases[1].Name)
+		if assert.NotNil(t, suite.Cases[1].Failure) {
+			assert.Equal(t, "response invalid", suite.Cases[1].Failure.Type)
+			assert.Contains(t, suite.Cases[1].Failure.Text, "GET /ping")
+		}
+
+		assert.Equal(t, "not part of spec", suite.Cases[2].Name)
+		assert.Equal(t, "copper", suite.Cases[2].ClassName)
+	})
+
+	t.Run("skipped without full coverage", func(t *testing.T) {
+		v := MustNewVerifier(f, WithoutFullCoverage())
+		record(v, "/ping", `{"message": "pong"}`)
+
+		var buf bytes.Buffer
+		require.NoError(t, v.WriteJUnit(&buf))
+		assert.Contains(t, buf.String(), `tests="2" failures="0" skipped="1"`)
+		assert.Contains(t, buf.String(), `<skipped message="not checked"></skipped>`)
+	})
+}
@@ -0,0 +1,203 @@
+package copper
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// extJWTClaims is a JSON schema for the claims of the tokens of a bearer security scheme with the JWT format.
+const extJWTClaims = "x-jwt-claims"
+
+// JWTClaimsCheck checks the claims of a JWT that a request presents for the named security scheme, for anything that
+// the x-jwt-claims extension can not express. The claims are decoded from JSON as is.
+type JWTClaimsCheck func(req *http.Request, scheme string, claims map[string]any) error
+
+// WithJWTClaims is a functional Option for checking the claims of the JWTs that requests present for bearer security
+// schemes with the JWT format. The tokens are decoded without verifying the signature, since that is the job of the
+// server, and the claims are validated against the JSON schema given by the x-jwt-claims extension of the scheme, if
+// any. Scopes listed by the security requirement of the operation must be in the scope or scp claim. The checks are
+// then called with the claims, so that contract tests can catch tokens that are missing a required audience or
+// similar. Only has an effect together with WithRequestValidation or WithSecurityValidation.
+func WithJWTClaims(checks ...JWTClaimsCheck) Option {
+	return func(c *config) {
+		c.jwtClaims = true
+		c.jwtChecks = append(c.jwtChecks, checks...)
+	}
+}
+
+// jwtSchemes holds the bearer security schemes with the JWT format, with the compiled x-jwt-claims schema of each, or
+// nil for the ones without one.
+type jwtSchemes map[string]*jsonschema.Schema
+
+// newJWTSchemes finds the bearer schemes with the JWT format, and compiles their claims schemas. An invalid claims
+// schema is reported up front, rather than for every request.
+func newJWTSchemes(doc *v3.Document) (jwtSchemes, error) {
+	schemes := make(jwtSchemes)
+	if doc.Components == nil {
+		return schemes, nil
+	}
+
+	var errs []error
+	for name, scheme := range doc.Components.SecuritySchemes.FromOldest() {
+		if !strings.EqualFold(scheme.Type, "http") || !strings.EqualFold(scheme.Scheme, "bearer") ||
+			!strings.EqualFold(scheme.BearerFormat, "JWT") {
+			continue
+		}
+		schemes[name] = nil
+
+		node := scheme.Extensions.GetOrZero(extJWTClaims)
+		if node == nil {
+			continue
+		}
+		schema, err := compileClaimsSchema(name, node.Decode)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s of security scheme %s is not a valid schema: %w", extJWTClaims, name, err))
+			continue
+		}
+		schemes[name] = schema
+	}
+	return schemes, errors.Join(errs...)
+}
+
+func compileClaimsSchema(name string, decode func(any) error) (*jsonschema.Schema, error) {
+	var raw any
+	if err := decode(&raw); err != nil {
+		return nil, err
+	}
+	// The YAML is turned into JSON, since the JSON schema library only knows the types of decoded JSON.
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+
+	c := jsonschema.NewCompiler()
+	url := "copper://claims/" + name + ".json"
+	if err := c.AddResource(url, doc); err != nil {
+		return nil, err
+	}
+	return c.Compile(url)
+}
+
+// checkJWTClaims checks the claims of the JWT in the request against each JWT scheme of the operation. Requests without
+// a bearer token are left for the security validation of the validator library.
+func (v *Verifier) checkJWTClaims(req *http.Request, op *v3.Operation) error {
+	token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if op == nil || !ok {
+		return nil
+	}
+
+	// The scopes of every requirement that uses the scheme, where any one of them is enough.
+	scopes := make(map[string][][]string)
+	var names []string
+	for _, requirement := range operationSecurity(v.model, op) {
+		for name, required := range requirement.Requirements.FromOldest() {
+			if _, ok := v.jwtSchemes[name]; !ok {
+				continue
+			}
+			if _, ok := scopes[name]; !ok {
+				names = append(names, name)
+			}
+			scopes[name] = append(scopes[name], required)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	claims, err := decodeJWT(strings.TrimSpace(token))
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, name := range names {
+		if schema := v.jwtSchemes[name]; schema != nil {
+			if err := schema.Validate(claims); err != nil {
+				errs = append(errs, fmt.Errorf("claims of the JWT for security scheme %s are invalid: %w", name, err))
+			}
+		}
+		if missing := missingScopes(claims, scopes[name]); len(missing) > 0 {
+			errs = append(errs, fmt.Errorf("JWT for security scheme %s is missing the scopes %s", name, strings.Join(missing, ", ")))
+		}
+		if claimsMap, ok := claims.(map[string]any); ok {
+			for _, check := range v.conf.jwtChecks {
+				if err := check(req, name, claimsMap); err != nil {
+					errs = append(errs, fmt.Errorf("claims of the JWT for security scheme %s: %w", name, err))
+				}
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// decodeJWT decodes the claims of a JWT, without verifying its signature.
+func decodeJWT(token string) (any, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("bearer token is not a JWT")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, fmt.Errorf("claims of the JWT can not be decoded: %w", err)
+	}
+	claims, err := jsonschema.UnmarshalJSON(bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("claims of the JWT can not be decoded: %w", err)
+	}
+	if _, ok := claims.(map[string]any); !ok {
+		return nil, errors.New("claims of the JWT are not a JSON object")
+	}
+	return claims, nil
+}
+
+// missingScopes returns the scopes that the token is missing for the requirement that it comes closest to satisfying,
+// or nothing if it satisfies any of them. The scopes of the token are taken from the scope claim, which is a space
+// separated string, or the scp claim, which is either that or an array.
+func missingScopes(claims any, requirements [][]string) []string {
+	var granted []string
+	if m, ok := claims.(map[string]any); ok {
+		for _, claim := range []string{"scope", "scp"} {
+			switch s := m[claim].(type) {
+			case string:
+				granted = append(granted, strings.Fields(s)...)
+			case []any:
+				for _, scope := range s {
+					if str, ok := scope.(string); ok {
+						granted = append(granted, str)
+					}
+				}
+			}
+		}
+	}
+
+	var closest []string
+	for i, required := range requirements {
+		var missing []string
+		for _, scope := range required {
+			if !slices.Contains(granted, scope) {
+				missing = append(missing, scope)
+			}
+		}
+		if len(missing) == 0 {
+			return nil
+		}
+		if i == 0 || len(missing) < len(closest) {
+			closest = missing
+		}
+	}
+	return closest
+}
@@ -0,0 +1,89 @@
+package copper
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// jwt returns an unsigned token with the given claims, which is all that copper looks at.
+func jwt(claims string) string {
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString([]byte(`{"alg":"none"}`)) + "." + enc.EncodeToString([]byte(claims)) + "."
+}
+
+func TestJWTClaims(t *testing.T) {
+	f, err := os.ReadFile("testdata/jwt-spec.yaml")
+	require.NoError(t, err)
+
+	tt := []struct {
+		name   string
+		method string
+		token  string
+		err    string
+	}{
+		{"valid claims", http.MethodGet, jwt(`{"sub": "me", "aud": "things-api"}`), ""},
+		{"wrong audience", http.MethodGet, jwt(`{"sub": "me", "aud": "other-api"}`), "claims of the JWT for security scheme Token are invalid"},
+		{"missing subject", http.MethodGet, jwt(`{"aud": "things-api"}`), "missing property 'sub'"},
+		{"not a JWT", http.MethodGet, "opaque", "bearer token is not a JWT"},
+		{"scope claim", http.MethodDelete, jwt(`{"sub": "me", "aud": "things-api", "scope": "things:read things:delete"}`), ""},
+		{"scp claim of another requirement", http.MethodDelete, jwt(`{"sub": "me", "aud": "things-api", "scp": ["admin"]}`), ""},
+		{"missing scope", http.MethodDelete, jwt(`{"sub": "me", "aud": "things-api", "scope": "things:read"}`), "JWT for security scheme Token is missing the scopes things:delete"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			v, err := NewVerifier(f, WithRequestValidation(), WithJWTClaims(), WithoutFullCoverage())
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(tc.method, "/things", nil)
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+			v.Record(&http.Response{StatusCode: http.StatusNoContent, Request: req})
+
+			if tc.err == "" {
+				assert.NoError(t, v.CurrentError())
+			} else {
+				assert.ErrorIs(t, v.CurrentError(), ErrSecurityViolation)
+				assert.ErrorContains(t, v.CurrentError(), tc.err)
+			}
+		})
+	}
+
+	t.Run("checks", func(t *testing.T) {
+		check := func(_ *http.Request, scheme string, claims map[string]any) error {
+			if claims["tenant"] == nil {
+				return errors.New("tenant is missing")
+			}
+			return nil
+		}
+		v, err := NewVerifier(f, WithRequestValidation(), WithJWTClaims(check), WithoutFullCoverage())
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/things", nil)
+		req.Header.Set("Authorization", "Bearer "+jwt(`{"sub": "me", "aud": "things-api"}`))
+		v.Record(&http.Response{StatusCode: http.StatusNoContent, Request: req})
+		assert.ErrorContains(t, v.CurrentError(), "claims of the JWT for security scheme Token: tenant is missing")
+	})
+
+	t.Run("without request validation", func(t *testing.T) {
+		_, err := NewVerifier(f, WithJWTClaims())
+		assert.ErrorIs(t, err, ErrInvalidOptions)
+	})
+
+	t.Run("with security validation", func(t *testing.T) {
+		v, err := NewVerifier(f, WithSecurityValidation(), WithJWTClaims(), WithoutFullCoverage())
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/things", nil)
+		req.Header.Set("Authorization", "Bearer "+jwt(`{"sub": "me"}`))
+		v.Record(&http.Response{StatusCode: http.StatusNoContent, Request: req})
+		assert.ErrorIs(t, v.CurrentError(), ErrSecurityViolation)
+		assert.ErrorContains(t, v.CurrentError(), "claims of the JWT for security scheme Token")
+	})
+}
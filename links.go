@@ -0,0 +1,348 @@
+package copper
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+// maxPendingLinks is how many expected follow-up requests are kept for a link that has not been followed yet. The oldest
+// ones are dropped beyond that, so that a link that is never followed does not grow without bound in a long run.
+const maxPendingLinks = 1000
+
+// linkKey identifies a link documented on a response.
+type linkKey struct {
+	path, method, code, name string
+}
+
+// linkState tracks a single documented link. Every time the response with the link is recorded, the parameter values
+// for the follow-up request are evaluated and added to the pending expectations. The link is followed once a request to
+// the target operation matches one of them. Only the latest maxPendingLinks distinct expectations are kept.
+type linkState struct {
+	target   operationRef
+	pending  []map[string]string
+	followed bool
+}
+
+// operationRef points out a single operation in the spec.
+type operationRef struct {
+	path, method string
+}
+
+// links tracks the links of all responses in the spec.
+type links struct {
+	model  *v3.Document
+	states map[linkKey]*linkState
+}
+
+func newLinks(model *v3.Document) *links {
+	return &links{
+		model:  model,
+		states: make(map[linkKey]*linkState),
+	}
+}
+
+// follow marks any link that the request satisfies as followed. This needs to happen before the links of the response
+// to the same request are added, so that a link can not be followed by the request that produced it.
+func (l *links) follow(req *http.Request, foundPath string) {
+	target := operationRef{path: foundPath, method: req.Method}
+	for _, state := range l.states {
+		if state.followed || state.target != target {
+			continue
+		}
+
+		for _, expected := range state.pending {
+			if l.matches(req, foundPath, expected) {
+				state.followed = true
+				state.pending = nil
+				break
+			}
+		}
+	}
+}
+
+// add evaluates the links documented for the response, and adds the expected follow-up requests for them.
+func (l *links) add(e exchange, response *v3.Response, code string) {
+	if response == nil || response.Links == nil {
+		return
+	}
+
+	for name, link := range response.Links.FromOldest() {
+		key := linkKey{path: e.foundPath, method: e.req.Method, code: code, name: name}
+		state, ok := l.states[key]
+		if !ok {
+			target, found := l.resolve(link)
+			if !found {
+				continue
+			}
+			state = &linkState{target: target}
+			l.states[key] = state
+		}
+		if state.followed {
+			continue
+		}
+
+		expected := make(map[string]string)
+		if link.Parameters != nil {
+			for param, expr := range link.Parameters.FromOldest() {
+				if value, ok := e.evaluate(expr); ok {
+					expected[param] = value
+				}
+			}
+		}
+		if slices.ContainsFunc(state.pending, func(p map[string]string) bool { return maps.Equal(p, expected) }) {
+			continue
+		}
+		if len(state.pending) >= maxPendingLinks {
+			state.pending = slices.Delete(state.pending, 0, len(state.pending)-maxPendingLinks+1)
+		}
+		state.pending = append(state.pending, expected)
+	}
+}
+
+// unfollowed returns errors for every link that was made available by a recorded response, but never followed.
+func (l *links) unfollowed() []*VerificationError {
+	var keys []linkKey
+	for key, state := range l.states {
+		if !state.followed {
+			keys = append(keys, key)
+		}
+	}
+	slices.SortFunc(keys, func(a, b linkKey) int {
+		return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+	})
+
+	var errs []*VerificationError
+	for _, key := range keys {
+		target := l.states[key].target
+		err := fmt.Errorf("%s %s: %s link %q to %s %s was never followed",
+			key.method, key.path, key.code, key.name, target.method, target.path)
+		errs = append(errs, joinError(ErrNotChecked, err))
+	}
+	return errs
+}
+
+// resolve finds the operation that the link points to, either by operationId or by operationRef.
+func (l *links) resolve(link *v3.Link) (operationRef, bool) {
+	if l.model.Paths == nil {
+		return operationRef{}, false
+	}
+	for path, pathItem := range l.model.Paths.PathItems.FromOldest() {
+		for method, op := range pathItem.GetOperations().FromOldest() {
+			ref := operationRef{path: path, method: strings.ToUpper(method)}
+			if link.OperationId != "" && op.OperationId == link.OperationId {
+				return ref, true
+			}
+			if link.OperationRef != "" && strings.HasSuffix(link.OperationRef, "#"+pointer("paths", path, method)) {
+				return ref, true
+			}
+		}
+	}
+	return operationRef{}, false
+}
+
+// matches checks if the request has all the expected parameter values. Parameter names can be qualified with their
+// location (path.id), and otherwise the location documented for the parameter by the target operation is used.
+func (l *links) matches(req *http.Request, foundPath string, expected map[string]string) bool {
+	for name, value := range expected {
+		in, param, qualified := strings.Cut(name, ".")
+		if !qualified {
+			in, param = l.parameterLocation(foundPath, req.Method, name), name
+		}
+
+		actual, ok := requestParam(req, foundPath, in, param)
+		if !ok || actual != value {
+			return false
+		}
+	}
+	return true
+}
+
+func (l *links) parameterLocation(path, method, name string) string {
+	if l.model.Paths == nil {
+		return "path"
+	}
+	pathItem := l.model.Paths.PathItems.GetOrZero(path)
+	if pathItem == nil {
+		return "path"
+	}
+
+	params := slices.Clone(pathItem.Parameters)
+	if op := pathItem.GetOperations().GetOrZero(strings.ToLower(method)); op != nil {
+		params = append(params, op.Parameters...)
+	}
+	for _, p := range params {
+		if p.Name == name {
+			return p.In
+		}
+	}
+	return "path"
+}
+
+// requestParam returns the value of the named parameter in the given location of the request.
+func requestParam(req *http.Request, template, in, name string) (string, bool) {
+	switch in {
+	case "path":
+		value, ok := pathParams(template, req.URL.Path)[name]
+		return value, ok
+	case "query":
+		if !req.URL.Query().Has(name) {
+			return "", false
+		}
+		return req.URL.Query().Get(name), true
+	case "header":
+		values := headerValues(req.Header, name)
+		if len(values) == 0 {
+			return "", false
+		}
+		return values[0], true
+	case "cookie":
+		c, err := req.Cookie(name)
+		if err != nil {
+			return "", false
+		}
+		return c.Value, true
+	}
+	return "", false
+}
+
+// pathParams extracts the values of the path parameters in the template from the request path. The request path might
+// contain a base path, so the segments are aligned from the end.
+func pathParams(template, path string) map[string]string {
+	tSegs := strings.Split(strings.Trim(template, "/"), "/")
+	pSegs := strings.Split(strings.Trim(path, "/"), "/")
+	if len(pSegs) < len(tSegs) {
+		return nil
+	}
+	pSegs = pSegs[len(pSegs)-len(tSegs):]
+
+	params := make(map[string]string)
+	for i, seg := range tSegs {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			value, err := url.PathUnescape(pSegs[i])
+			if err != nil {
+				value = pSegs[i]
+			}
+			params[strings.Trim(seg, "{}")] = value
+		}
+	}
+	return params
+}
+
+// exchange is a recorded request and response pair, which runtime expressions are evaluated against.
+type exchange struct {
+	req       *http.Request
+	reqBody   []byte
+	res       *http.Response
+	resBody   []byte
+	foundPath string
+}
+
+// evaluate returns the value of a runtime expression, as described by the OpenAPI specification. Expressions can
+// also be embedded in a string using braces, like "id-{$response.body#/id}". Anything else is a constant.
+func (e exchange) evaluate(expr string) (string, bool) {
+	if strings.HasPrefix(expr, "$") {
+		return e.evaluateExpression(expr)
+	}
+
+	var s strings.Builder
+	for {
+		start := strings.Index(expr, "{$")
+		if start < 0 {
+			s.WriteString(expr)
+			return s.String(), true
+		}
+		end := strings.Index(expr[start:], "}")
+		if end < 0 {
+			s.WriteString(expr)
+			return s.String(), true
+		}
+
+		value, ok := e.evaluateExpression(expr[start+1 : start+end])
+		if !ok {
+			return "", false
+		}
+		s.WriteString(expr[:start])
+		s.WriteString(value)
+		expr = expr[start+end+1:]
+	}
+}
+
+func (e exchange) evaluateExpression(expr string) (string, bool) {
+	switch expr {
+	case "$url":
+		return e.req.URL.String(), true
+	case "$method":
+		return e.req.Method, true
+	case "$statusCode":
+		return strconv.Itoa(e.res.StatusCode), true
+	}
+
+	source, rest, ok := strings.Cut(expr, ".")
+	if !ok {
+		return "", false
+	}
+
+	switch source {
+	case "$request":
+		if ptr, ok := strings.CutPrefix(rest, "body#"); ok {
+			return bodyValue(e.reqBody, ptr)
+		}
+		in, name, _ := strings.Cut(rest, ".")
+		return requestParam(e.req, e.foundPath, in, name)
+	case "$response":
+		if ptr, ok := strings.CutPrefix(rest, "body#"); ok {
+			return bodyValue(e.resBody, ptr)
+		}
+		if name, ok := strings.CutPrefix(rest, "header."); ok {
+			values := headerValues(e.res.Header, name)
+			if len(values) == 0 {
+				return "", false
+			}
+			return values[0], true
+		}
+	}
+	return "", false
+}
+
+// bodyValue returns the value found at the JSON pointer in the body, formatted the way that it would be as a
+// parameter.
+func bodyValue(body []byte, ptr string) (string, bool) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return "", false
+	}
+
+	tokens, ok := pointerTokens(ptr)
+	if !ok {
+		return "", false
+	}
+	if value, ok = valueAt(value, tokens); !ok {
+		return "", false
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return strconv.FormatBool(v), true
+	case nil:
+		return "", false
+	}
+
+	b, err := json.Marshal(value)
+	return string(b), err == nil
+}
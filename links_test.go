@@ -0,0 +1,141 @@
+package copper
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithLinks(t *testing.T) {
+	f, err := os.ReadFile("testdata/links-spec.yaml")
+	require.NoError(t, err)
+
+	create := func(v *Verifier) {
+		req := httptest.NewRequest(http.MethodPost, "/things", nil)
+		v.Record(&http.Response{
+			StatusCode: http.StatusCreated,
+			Request:    req,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{"id": 42}`)),
+		})
+	}
+	get := func(v *Verifier, id string) {
+		req := httptest.NewRequest(http.MethodGet, "/things/"+id, nil)
+		v.Record(&http.Response{StatusCode: http.StatusOK, Request: req})
+	}
+
+	t.Run("followed link", func(t *testing.T) {
+		v, err := NewVerifier(f, WithLinks())
+		require.NoError(t, err)
+
+		create(v)
+		get(v, "42")
+		assert.NoError(t, v.CurrentError())
+	})
+
+	t.Run("link followed with other parameters", func(t *testing.T) {
+		v, err := NewVerifier(f, WithLinks(), WithoutFullCoverage())
+		require.NoError(t, err)
+
+		create(v)
+		get(v, "43")
+		assert.ErrorIs(t, v.CurrentError(), ErrNotChecked)
+		assert.ErrorContains(t, v.CurrentError(), `link "GetThing"`)
+	})
+
+	t.Run("link followed before it was available", func(t *testing.T) {
+		v, err := NewVerifier(f, WithLinks(), WithoutFullCoverage())
+		require.NoError(t, err)
+
+		get(v, "42")
+		create(v)
+		assert.ErrorIs(t, v.CurrentError(), ErrNotChecked)
+	})
+
+	t.Run("pending expectations are bounded", func(t *testing.T) {
+		v, err := NewVerifier(f, WithLinks(), WithoutFullCoverage())
+		require.NoError(t, err)
+
+		createID := func(id int) {
+			req := httptest.NewRequest(http.MethodPost, "/things", nil)
+			v.Record(&http.Response{
+				StatusCode: http.StatusCreated,
+				Request:    req,
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(fmt.Sprintf(`{"id": %d}`, id))),
+			})
+		}
+		for id := range maxPendingLinks + 1 {
+			createID(id)
+		}
+		// The same expectation is only kept once.
+		createID(maxPendingLinks)
+		for _, state := range v.links.states {
+			assert.Len(t, state.pending, maxPendingLinks)
+		}
+
+		// The oldest expectation was dropped, but the latest ones can still be followed.
+		get(v, "0")
+		assert.ErrorIs(t, v.CurrentError(), ErrNotChecked)
+		get(v, strconv.Itoa(maxPendingLinks))
+		assert.NoError(t, v.CurrentError())
+	})
+
+	t.Run("links are not checked by default", func(t *testing.T) {
+		v, err := NewVerifier(f, WithoutFullCoverage())
+		require.NoError(t, err)
+
+		create(v)
+		assert.NoError(t, v.CurrentError())
+	})
+}
+
+func TestRuntimeExpressions(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/base/things/7?filter=new", nil)
+	req.Header.Set("X-Tenant", "acme")
+	e := exchange{
+		req:       req,
+		reqBody:   []byte(`{"name": "thing"}`),
+		res:       &http.Response{StatusCode: 201, Header: http.Header{"Location": []string{"/things/42"}}},
+		resBody:   []byte(`{"id": 42, "tags": ["a", "b"], "owner": {"a/b": true}}`),
+		foundPath: "/things/{id}",
+	}
+
+	tt := []struct {
+		expr     string
+		expected string
+		ok       bool
+	}{
+		{"$method", "POST", true},
+		{"$statusCode", "201", true},
+		{"$request.path.id", "7", true},
+		{"$request.query.filter", "new", true},
+		{"$request.header.X-Tenant", "acme", true},
+		{"$request.body#/name", "thing", true},
+		{"$response.header.Location", "/things/42", true},
+		{"$response.body#/id", "42", true},
+		{"$response.body#/tags/1", "b", true},
+		{"$response.body#/owner/a~1b", "true", true},
+		{"$response.body#/tags", `["a","b"]`, true},
+		{"thing-{$response.body#/id}", "thing-42", true},
+		{"constant", "constant", true},
+		{"$response.body#/missing", "", false},
+		{"$request.query.missing", "", false},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.expr, func(t *testing.T) {
+			value, ok := e.evaluate(tc.expr)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.expected, value)
+		})
+	}
+}
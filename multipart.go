@@ -0,0 +1,142 @@
+package copper
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/pb33f/libopenapi-validator/schema_validation"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+// checkMultipart checks a multipart request body against the schema and the encoding of its documented content, which
+// the validator library does not validate at all. Every part must be a documented property, required properties must
+// have a part, and a part must have the content type and the headers that the encoding of its property declares.
+// Parts with JSON content are validated against the schema of their property.
+func checkMultipart(req *http.Request, body []byte, op *v3.Operation) error {
+	if op == nil || op.RequestBody == nil {
+		return nil
+	}
+	mediaType, params, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil || !strings.HasPrefix(mediaType, "multipart/") {
+		return nil
+	}
+	content := op.RequestBody.Content.GetOrZero(mediaType)
+	if content == nil {
+		return nil
+	}
+	if params["boundary"] == "" {
+		return fmt.Errorf("%s body has no boundary", mediaType)
+	}
+
+	var schema *base.Schema
+	if content.Schema != nil {
+		schema = content.Schema.Schema()
+	}
+
+	var errs []error
+	var names []string
+	r := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+	for i := 0; ; i++ {
+		part, err := r.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("%s body can not be read: %w", mediaType, err)
+		}
+
+		name := part.FormName()
+		if name == "" {
+			// Parts of multipart/mixed and similar have no names to tie them to properties.
+			continue
+		}
+		names = append(names, name)
+
+		data, err := io.ReadAll(part)
+		if err != nil {
+			return fmt.Errorf("%s body can not be read: %w", mediaType, err)
+		}
+		errs = append(errs, checkPart(name, part.Header, data, schema, content.Encoding.GetOrZero(name)))
+	}
+
+	if schema != nil {
+		for _, name := range schema.Required {
+			if !slices.Contains(names, name) {
+				errs = append(errs, fmt.Errorf("part %s is required but missing", name))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func checkPart(name string, header map[string][]string, data []byte, schema *base.Schema, encoding *v3.Encoding) error {
+	var property *base.Schema
+	if schema != nil && schema.Properties != nil {
+		if proxy := schema.Properties.GetOrZero(name); proxy != nil {
+			property = proxy.Schema()
+		}
+	}
+	if property == nil && schema != nil && schema.Properties != nil && schema.AdditionalProperties == nil {
+		return fmt.Errorf("part %s is not a documented property", name)
+	}
+
+	h := http.Header(header)
+	contentType := h.Get("Content-Type")
+	if contentType == "" {
+		// A part without a Content-Type is plain text, according to RFC 7578.
+		contentType = "text/plain"
+	}
+	partType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("part %s has an invalid Content-Type %q", name, contentType)
+	}
+
+	var errs []error
+	if encoding != nil {
+		if encoding.ContentType != "" && !matchesMediaTypes(partType, encoding.ContentType) {
+			errs = append(errs, fmt.Errorf("part %s has Content-Type %s, but the encoding declares %s", name, partType, encoding.ContentType))
+		}
+		for headerName, documented := range encoding.Headers.FromOldest() {
+			// The Content-Type of a part is described by contentType, and is ignored as a header.
+			if strings.EqualFold(headerName, "Content-Type") {
+				continue
+			}
+			if documented.Required && len(h.Values(headerName)) == 0 {
+				errs = append(errs, fmt.Errorf("part %s is missing the required header %s", name, headerName))
+			}
+		}
+	}
+
+	if property != nil && (partType == "application/json" || strings.HasSuffix(partType, "+json")) {
+		if ok, validationErrs := schema_validation.NewSchemaValidator().ValidateSchemaBytes(property, data); !ok {
+			errs = append(errs, fmt.Errorf("part %s is invalid: %w", name, toError(validationErrs)))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// matchesMediaTypes checks the media type against a comma separated list of media types, which may have wildcards like
+// image/* or */*.
+func matchesMediaTypes(mediaType, list string) bool {
+	for _, candidate := range strings.Split(list, ",") {
+		candidate, _, err := mime.ParseMediaType(strings.TrimSpace(candidate))
+		if err != nil {
+			continue
+		}
+		if candidate == "*/*" || candidate == mediaType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(candidate, "/*"); ok && strings.HasPrefix(mediaType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package copper
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testPart struct {
+	name        string
+	contentType string
+	checksum    string
+	data        string
+}
+
+func multipartRequest(t *testing.T, parts ...testPart) *http.Request {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for _, p := range parts {
+		h := textproto.MIMEHeader{}
+		h.Set("Content-Disposition", `form-data; name="`+p.name+`"`)
+		if p.contentType != "" {
+			h.Set("Content-Type", p.contentType)
+		}
+		if p.checksum != "" {
+			h.Set("X-Checksum", p.checksum)
+		}
+		pw, err := w.CreatePart(h)
+		require.NoError(t, err)
+		_, err = pw.Write([]byte(p.data))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/uploads", &body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	return req
+}
+
+func TestMultipartEncoding(t *testing.T) {
+	f, err := os.ReadFile("testdata/multipart-spec.yaml")
+	require.NoError(t, err)
+
+	file := testPart{name: "file", contentType: "image/png", checksum: "abc", data: "\x89PNG"}
+	meta := testPart{name: "meta", contentType: "application/json", data: `{"name": "cat.png"}`}
+
+	tt := []struct {
+		name  string
+		parts []testPart
+		err   string
+	}{
+		{"valid", []testPart{file, meta, {name: "description", data: "a cat"}}, ""},
+		{"other declared content type", []testPart{{name: "file", contentType: "image/jpeg", checksum: "abc"}, meta}, ""},
+		{"wrong content type", []testPart{{name: "file", contentType: "application/pdf", checksum: "abc"}, meta}, "part file has Content-Type application/pdf, but the encoding declares image/png, image/jpeg"},
+		{"missing header", []testPart{{name: "file", contentType: "image/png"}, meta}, "part file is missing the required header X-Checksum"},
+		{"invalid JSON part", []testPart{file, {name: "meta", contentType: "application/json", data: `{}`}}, "part meta is invalid"},
+		{"JSON part as text", []testPart{file, {name: "meta", data: `{"name": "cat.png"}`}}, "part meta has Content-Type text/plain"},
+		{"missing part", []testPart{file}, "part meta is required but missing"},
+		{"undocumented part", []testPart{file, meta, {name: "extra", data: "x"}}, "part extra is not a documented property"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			v, err := NewVerifier(f, WithRequestValidation(), WithoutFullCoverage())
+			require.NoError(t, err)
+
+			v.Record(&http.Response{StatusCode: http.StatusNoContent, Request: multipartRequest(t, tc.parts...)})
+			if tc.err == "" {
+				assert.NoError(t, v.CurrentError())
+			} else {
+				assert.ErrorIs(t, v.CurrentError(), ErrRequestInvalid)
+				assert.ErrorContains(t, v.CurrentError(), tc.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+// Package muxadapter records the requests that a gorilla/mux router serves into a copper.Verifier, using the path
+// template of the route that the router matched to find the path in the spec.
+package muxadapter
+
+import (
+	"net/http"
+
+	"github.com/callebjorkell/copper"
+	"github.com/callebjorkell/copper/internal/capture"
+	"github.com/gorilla/mux"
+)
+
+// Middleware returns gorilla/mux middleware that records every request and the response to it into the verifier. Use
+// it with the Use method of the router, so that the matched route is known when the request is recorded. The router
+// only runs middleware for requests that match a route, so requests that it answers with 404 or 405 are not recorded.
+func Middleware(v *copper.Verifier) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := capture.BufferRequest(r); err != nil {
+				http.Error(w, "could not read request body", http.StatusBadRequest)
+				return
+			}
+
+			cw := capture.NewWriter(w)
+			next.ServeHTTP(cw, r)
+
+			var route string
+			if cr := mux.CurrentRoute(r); cr != nil {
+				// Routes that are only matched on something else than the path, like the host, have no template.
+				route, _ = cr.GetPathTemplate()
+			}
+			v.RecordRoute(cw.Response(r), route)
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package muxadapter
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/callebjorkell/copper"
+	"github.com/callebjorkell/copper/internal/routertest"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRouter(v *copper.Verifier) http.Handler {
+	r := mux.NewRouter()
+	r.Use(Middleware(v))
+	things := r.PathPrefix("/api/things").Subrouter()
+	things.HandleFunc("/export", routertest.Export).Methods(http.MethodGet)
+	things.HandleFunc("/{thingID:[a-z0-9]+}", routertest.GetThing).Methods(http.MethodGet)
+	things.HandleFunc("/{thingID}", routertest.PutThing).Methods(http.MethodPut)
+	things.HandleFunc("/{thingID}", routertest.DeleteThing).Methods(http.MethodDelete)
+	return r
+}
+
+func TestMiddleware(t *testing.T) {
+	routertest.Test(t, routertest.Middleware(newRouter), http.StatusNoContent)
+
+	t.Run("the template is matched without the pattern of the variable", func(t *testing.T) {
+		// The pattern of {thingID} also matches export, so the route that is registered first has to be the one used.
+		router := routertest.Middleware(newRouter)
+		assert.Equal(t, []string{"/things/export"}, routertest.Matched(t, router, http.MethodGet, "/api/things/export"))
+		assert.Equal(t, []string{"/things/{id}"}, routertest.Matched(t, router, http.MethodGet, "/api/things/abc"))
+	})
+}
@@ -0,0 +1,95 @@
+package copper
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+// Negotiate sends the request once for every media type that the responses of its operation document, with the media
+// type as the Accept header, and records every response like Do. This makes sure that each representation is actually
+// exercised and validated, rather than only the one that the server prefers. A response with another Content-Type than
+// the one that was asked for is reported with ErrResponseInvalid, if the spec documents the media type for its status.
+// Wildcard media types like */* are not sent. The responses are returned in the order of the media types in the spec,
+// and have to be closed by the caller.
+func (v *ValidatingClient) Negotiate(r *http.Request) ([]*http.Response, error) {
+	r, err := replayable(r)
+	if err != nil {
+		return nil, err
+	}
+
+	lookup := resolveRelative(r.Clone(r.Context()), v.clientOptions().base)
+	pathItem, errs, _ := v.findPath(lookup)
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("could not negotiate %s %s: %w", r.Method, r.URL.Path, toError(errs))
+	}
+	op := pathItem.GetOperations().GetOrZero(strings.ToLower(r.Method))
+	mediaTypes := responseMediaTypes(op)
+	if len(mediaTypes) == 0 {
+		return nil, fmt.Errorf("could not negotiate %s %s: no media types are documented for its responses", r.Method,
+			r.URL.Path)
+	}
+
+	responses := make([]*http.Response, 0, len(mediaTypes))
+	for _, mediaType := range mediaTypes {
+		req := r.Clone(r.Context())
+		if r.GetBody != nil {
+			if req.Body, err = r.GetBody(); err != nil {
+				return responses, fmt.Errorf("could not read body again: %w", err)
+			}
+		}
+		req.Header.Set("Accept", mediaType)
+
+		res, err := v.Do(req)
+		if err != nil {
+			return responses, err
+		}
+		responses = append(responses, res)
+		v.checkNegotiated(res, op, mediaType)
+	}
+	return responses, nil
+}
+
+// checkNegotiated reports a response that has another media type than the one that was asked for, if the spec
+// documents the media type for the status of the response.
+func (v *ValidatingClient) checkNegotiated(res *http.Response, op *v3.Operation, accept string) {
+	response, _ := documentedResponse(op, res.StatusCode)
+	if response == nil || !documentsMediaType(response, accept) {
+		return
+	}
+	mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if mediaType == accept {
+		return
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.appendErr(ErrResponseInvalid, fmt.Errorf("%s %s: %d response has Content-Type %s, but %s was asked for",
+		res.Request.Method, res.Request.URL.Path, res.StatusCode, mediaType, accept))
+}
+
+// responseMediaTypes returns the media types that the responses of the operation document, without wildcards, in the
+// order that they are first found.
+func responseMediaTypes(op *v3.Operation) []string {
+	if op == nil || op.Responses == nil {
+		return nil
+	}
+	responses := slices.Collect(op.Responses.Codes.ValuesFromOldest())
+	if op.Responses.Default != nil {
+		responses = append(responses, op.Responses.Default)
+	}
+
+	var mediaTypes []string
+	for _, response := range responses {
+		for mediaType := range response.Content.KeysFromOldest() {
+			if !strings.Contains(mediaType, "*") && !slices.Contains(mediaTypes, mediaType) {
+				mediaTypes = append(mediaTypes, mediaType)
+			}
+		}
+	}
+	return mediaTypes
+}
@@ -0,0 +1,82 @@
+package copper
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiate(t *testing.T) {
+	f, err := os.ReadFile("testdata/negotiation-spec.yaml")
+	require.NoError(t, err)
+
+	tt := []struct {
+		name    string
+		handler http.HandlerFunc
+		errors  []string
+	}{
+		{
+			name: "every representation",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Accept") == "text/csv" {
+					w.Header().Set("Content-Type", "text/csv")
+					_, _ = w.Write([]byte("total\n1\n"))
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"total":1}`))
+			},
+		},
+		{
+			name: "always json",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"total":1}`))
+			},
+			errors: []string{"response invalid: GET /report: 200 response has Content-Type application/json, but text/csv was asked for"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var accepted []string
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				accepted = append(accepted, r.Header.Get("Accept"))
+				tc.handler(w, r)
+			}))
+			defer s.Close()
+
+			c := MustWrapClient(http.DefaultClient, bytes.NewReader(f), WithoutFullCoverage())
+			req, err := http.NewRequest(http.MethodGet, s.URL+"/report", nil)
+			require.NoError(t, err)
+
+			responses, err := c.Negotiate(req)
+			require.NoError(t, err)
+			assert.Len(t, responses, 2)
+			for _, res := range responses {
+				_ = res.Body.Close()
+			}
+			assert.Equal(t, []string{"application/json", "text/csv"}, accepted)
+
+			var errs []string
+			for _, err := range c.CurrentErrors() {
+				errs = append(errs, err.Error())
+			}
+			assert.Equal(t, tc.errors, errs)
+		})
+	}
+
+	t.Run("no media types", func(t *testing.T) {
+		c := MustWrapClient(http.DefaultClient, bytes.NewReader(f))
+		req, err := http.NewRequest(http.MethodGet, "http://localhost/plain", nil)
+		require.NoError(t, err)
+
+		_, err = c.Negotiate(req)
+		assert.ErrorContains(t, err, "no media types are documented")
+	})
+}
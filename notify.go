@@ -0,0 +1,91 @@
+package copper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Summary sums up the verification results, for notifying about them.
+type Summary struct {
+	// Title and Version are taken from the info of the spec.
+	Title   string `json:"title"`
+	Version string `json:"version"`
+	// Endpoints is the number of coordinates in the spec, and Checked the number of those that have been checked.
+	Endpoints int `json:"endpoints"`
+	Checked   int `json:"checked"`
+	// Violations is the number of errors other than coordinates that have not been checked, including the ones that
+	// WithErrorWindow has dropped from Errors.
+	Violations int `json:"violations"`
+	// Errors holds the messages of the current errors, as returned by CurrentErrors.
+	Errors []string `json:"errors"`
+	// Waived lists the coordinates that are left out of the coverage, with the reason why.
+	Waived []string `json:"waived"`
+}
+
+// Coverage returns the percentage of the coordinates in the spec that have been checked. A spec without any coordinates
+// is fully covered.
+func (s Summary) Coverage() float64 {
+	if s.Endpoints == 0 {
+		return 100
+	}
+	return float64(s.Checked) * 100 / float64(s.Endpoints)
+}
+
+// Failed returns true if there are any errors.
+func (s Summary) Failed() bool {
+	return len(s.Errors) > 0
+}
+
+// Notifier is notified with a summary when verification finishes with failures. The notify package has notifiers for
+// webhooks and Slack.
+type Notifier interface {
+	Notify(ctx context.Context, s Summary) error
+}
+
+// Summary returns a summary of the current verification results.
+func (v *Verifier) Summary() Summary {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	s := Summary{
+		Title:     v.model.Info.Title,
+		Version:   v.model.Info.Version,
+		Endpoints: len(v.endpoints.All()),
+		Errors:    []string{},
+		Waived:    []string{},
+	}
+	s.Checked = s.Endpoints - len(v.endpoints.Unchecked())
+	for _, err := range v.currentErrors() {
+		if !errors.Is(err, ErrNotChecked) {
+			s.Violations++
+		}
+		s.Errors = append(s.Errors, err.Error())
+	}
+	// Errors that the error window has dropped are still violations.
+	for _, n := range v.dropped {
+		s.Violations += n
+	}
+	for _, w := range v.endpoints.Waived() {
+		s.Waived = append(s.Waived, fmt.Sprintf("%s %s %s: %s", w.Method, w.Path, w.ResponseCode, w.Reason))
+	}
+	return s
+}
+
+// NotifyFailures notifies the notifiers with a summary of the verification results, but only if there are any errors,
+// which makes it suitable to call at the end of every run. All notifiers are notified even if some of them fail, and
+// their errors are returned joined.
+func (v *Verifier) NotifyFailures(ctx context.Context, notifiers ...Notifier) error {
+	s := v.Summary()
+	if !s.Failed() {
+		return nil
+	}
+
+	var errs []error
+	for _, n := range notifiers {
+		if err := n.Notify(ctx, s); err != nil {
+			errs = append(errs, fmt.Errorf("could not notify: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
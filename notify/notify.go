@@ -0,0 +1,105 @@
+// Package notify has copper.Notifier implementations that post a summary of failed verifications to a generic webhook
+// or to Slack, so that contract runs can alert the owners of an API directly.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/callebjorkell/copper"
+)
+
+// Webhook posts the summary as JSON to a URL.
+type Webhook struct {
+	URL string
+	// Client is used for posting, or http.DefaultClient if it is nil.
+	Client *http.Client
+}
+
+var _ copper.Notifier = Webhook{}
+
+// Notify posts the summary, and returns an error if the response does not have a 2xx status code.
+func (w Webhook) Notify(ctx context.Context, s copper.Summary) error {
+	return post(ctx, w.Client, w.URL, s)
+}
+
+// defaultMaxErrors is the number of errors that Slack lists, unless MaxErrors is set.
+const defaultMaxErrors = 10
+
+// Slack posts a message with the summary to a Slack incoming webhook.
+type Slack struct {
+	// WebhookURL is the URL of the incoming webhook, which decides the channel that the message is posted to.
+	WebhookURL string
+	// Client is used for posting, or http.DefaultClient if it is nil.
+	Client *http.Client
+	// MaxErrors is the number of errors to list in the message, to keep it readable. Defaults to 10.
+	MaxErrors int
+}
+
+var _ copper.Notifier = Slack{}
+
+// Notify posts the message, and returns an error if the response does not have a 2xx status code.
+func (sl Slack) Notify(ctx context.Context, s copper.Summary) error {
+	return post(ctx, sl.Client, sl.WebhookURL, map[string]string{"text": sl.message(s)})
+}
+
+func (sl Slack) message(s copper.Summary) string {
+	maxErrors := sl.MaxErrors
+	if maxErrors <= 0 {
+		maxErrors = defaultMaxErrors
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, ":x: Contract verification of *%s* %s failed with %d errors, %d of %d endpoints checked",
+		s.Title, s.Version, len(s.Errors), s.Checked, s.Endpoints)
+	if len(s.Waived) > 0 {
+		fmt.Fprintf(&b, " and %d waived", len(s.Waived))
+	}
+	b.WriteString(".")
+	if len(s.Errors) == 0 {
+		return b.String()
+	}
+
+	b.WriteString("\n```\n")
+	for _, err := range s.Errors[:min(len(s.Errors), maxErrors)] {
+		// Backticks would end the code block early.
+		b.WriteString(strings.ReplaceAll(err, "```", "'''"))
+		b.WriteString("\n")
+	}
+	b.WriteString("```")
+	if more := len(s.Errors) - maxErrors; more > 0 {
+		fmt.Fprintf(&b, "\n…and %d more.", more)
+	}
+	return b.String()
+}
+
+func post(ctx context.Context, client *http.Client, url string, body any) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with %s", res.Status)
+	}
+	return nil
+}
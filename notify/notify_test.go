@@ -0,0 +1,99 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/callebjorkell/copper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type receiver struct {
+	status int
+	bodies [][]byte
+}
+
+func (r *receiver) server(t *testing.T) *httptest.Server {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodPost, req.Method)
+		assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+		body, _ := io.ReadAll(req.Body)
+		r.bodies = append(r.bodies, body)
+		w.WriteHeader(r.status)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func failingVerifier(t *testing.T) *copper.Verifier {
+	f, err := os.ReadFile("../testdata/thing-spec.yaml")
+	require.NoError(t, err)
+
+	v, err := copper.NewVerifier(f)
+	require.NoError(t, err)
+	v.Record(&http.Response{
+		StatusCode: http.StatusOK,
+		Request:    httptest.NewRequest(http.MethodGet, "/ping", nil),
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(`{"message": "pong"}`)),
+	})
+	return v
+}
+
+func TestWebhook(t *testing.T) {
+	r := &receiver{status: http.StatusNoContent}
+	s := r.server(t)
+
+	v := failingVerifier(t)
+	require.NoError(t, v.NotifyFailures(context.Background(), Webhook{URL: s.URL}))
+
+	require.Len(t, r.bodies, 1)
+	var sum copper.Summary
+	require.NoError(t, json.Unmarshal(r.bodies[0], &sum))
+	assert.Equal(t, "thing test", sum.Title)
+	assert.Equal(t, 2, sum.Endpoints)
+	assert.Equal(t, 1, sum.Checked)
+	assert.Len(t, sum.Errors, 1)
+}
+
+func TestSlack(t *testing.T) {
+	t.Run("message", func(t *testing.T) {
+		r := &receiver{status: http.StatusOK}
+		s := r.server(t)
+
+		v := failingVerifier(t)
+		require.NoError(t, v.NotifyFailures(context.Background(), Slack{WebhookURL: s.URL}))
+
+		require.Len(t, r.bodies, 1)
+		var msg map[string]string
+		require.NoError(t, json.Unmarshal(r.bodies[0], &msg))
+		assert.Equal(t, ":x: Contract verification of *thing test* 1.0 failed with 1 errors, 1 of 2 endpoints checked.\n"+
+			"```\nnot checked: 1 coordinate on 1 path\n  /other: GET 200\n```", msg["text"])
+	})
+
+	t.Run("errors are limited", func(t *testing.T) {
+		msg := Slack{MaxErrors: 1}.message(copper.Summary{Errors: []string{"one", "two", "three"}})
+		assert.Contains(t, msg, "```\none\n```\n…and 2 more.")
+	})
+
+	t.Run("waived endpoints are counted", func(t *testing.T) {
+		msg := Slack{}.message(copper.Summary{Endpoints: 2, Checked: 1, Errors: []string{"one"}, Waived: []string{"GET /fault 500: not required"}})
+		assert.Contains(t, msg, "1 of 2 endpoints checked and 1 waived.\n")
+	})
+
+	t.Run("failing webhook", func(t *testing.T) {
+		r := &receiver{status: http.StatusForbidden}
+		s := r.server(t)
+
+		err := failingVerifier(t).NotifyFailures(context.Background(), Slack{WebhookURL: s.URL}, Webhook{URL: s.URL})
+		assert.ErrorContains(t, err, "403 Forbidden")
+		assert.Len(t, r.bodies, 2)
+	})
+}
@@ -0,0 +1,74 @@
+package copper
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type notifierFunc func(ctx context.Context, s Summary) error
+
+func (f notifierFunc) Notify(ctx context.Context, s Summary) error {
+	return f(ctx, s)
+}
+
+func TestNotifyFailures(t *testing.T) {
+	f, err := os.ReadFile("testdata/thing-spec.yaml")
+	require.NoError(t, err)
+
+	record := func(v *Verifier, path, body string) {
+		v.Record(&http.Response{
+			StatusCode: 200,
+			Request:    httptest.NewRequest(http.MethodGet, path, nil),
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+		})
+	}
+
+	var notified []Summary
+	n := notifierFunc(func(_ context.Context, s Summary) error {
+		notified = append(notified, s)
+		return nil
+	})
+
+	v, err := NewVerifier(f)
+	require.NoError(t, err)
+	record(v, "/ping", `{"message": "pong"}`)
+
+	require.NoError(t, v.NotifyFailures(context.Background(), n))
+	require.Len(t, notified, 1)
+	assert.Equal(t, Summary{
+		Title:     "thing test",
+		Version:   "1.0",
+		Endpoints: 2,
+		Checked:   1,
+		Errors:    []string{"not checked: 1 coordinate on 1 path\n  /other: GET 200"},
+		Waived:    []string{},
+	}, notified[0])
+	assert.Equal(t, 50.0, notified[0].Coverage())
+
+	record(v, "/other", `{"thing": "thing"}`)
+	require.NoError(t, v.NotifyFailures(context.Background(), n))
+	assert.Len(t, notified, 1, "nothing is notified without failures")
+}
+
+func TestSummaryWaived(t *testing.T) {
+	f, err := os.ReadFile("testdata/server-error-spec.yaml")
+	require.NoError(t, err)
+
+	v, err := NewVerifier(f)
+	require.NoError(t, err)
+
+	s := v.Summary()
+	assert.Equal(t, 0, s.Endpoints)
+	assert.Equal(t, []string{
+		"GET /fault 500: 500 responses are not required without WithInternalServerErrors or x-copper-strict",
+	}, s.Waived)
+}
@@ -0,0 +1,244 @@
+package copper
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/orderedmap"
+)
+
+// Nullability decides which way of allowing null values in a schema is honoured. OpenAPI 3.0 uses nullable: true next
+// to the type, while OpenAPI 3.1 lists "null" as one of the types and leaves nullable without meaning.
+type Nullability int
+
+const (
+	// NullableByVersion honours the way of the version of the spec, which is nullable: true for 3.0 and a type that
+	// includes "null" for 3.1. This is the default.
+	NullableByVersion Nullability = iota
+	// NullableLenient honours nullable: true in 3.1 specs as well, for specs in the middle of a migration from 3.0.
+	// Type arrays are not valid in a 3.0 spec, so those are still rejected when the spec is loaded.
+	NullableLenient
+	// NullableStrict is like NullableByVersion, but a 3.1 spec that uses nullable is rejected when the verifier is
+	// created, rather than having the keyword silently ignored.
+	NullableStrict
+)
+
+// WithNullability is a functional Option for deciding how null values are allowed by the schemas of the spec. The
+// default is NullableByVersion. The schemas are prepared when the verifier is created, so the mode can not be changed
+// with SetOptions or With.
+func WithNullability(mode Nullability) Option {
+	return func(c *config) {
+		c.nullability = mode
+	}
+}
+
+// applyNullability rewrites the schemas that are nullable in the way that the mode honours for the version of the spec
+// into a type that includes "null", which is what the JSON schema validation understands.
+func applyNullability(doc *v3.Document, mode Nullability) error {
+	legacy := strings.HasPrefix(doc.Version, "3.0")
+
+	var errs []error
+	walkSchemas(doc, func(s *base.Schema, location string) {
+		if s.Nullable == nil || !*s.Nullable {
+			return
+		}
+		switch {
+		case !legacy && mode == NullableStrict:
+			errs = append(errs, fmt.Errorf("schema at %s uses nullable, which OpenAPI %s ignores, instead of a type that includes \"null\"", location, doc.Version))
+		case legacy || mode == NullableLenient:
+			if len(s.Type) > 0 && !slices.Contains(s.Type, "null") {
+				s.Type = append(s.Type, "null")
+			}
+		}
+	})
+	return errors.Join(errs...)
+}
+
+// nullableJSON does what applyNullability does for a spec decoded as generic JSON, which is what recursive schemas are
+// compiled from. Any object with nullable set to true is taken to be a schema, which holds since a property or
+// extension with that name would not be a boolean.
+func nullableJSON(doc any, mode Nullability) {
+	root, ok := doc.(map[string]any)
+	if !ok {
+		return
+	}
+	version, _ := root["openapi"].(string)
+	if !strings.HasPrefix(version, "3.0") && mode != NullableLenient {
+		return
+	}
+
+	var walk func(any)
+	walk = func(value any) {
+		switch v := value.(type) {
+		case map[string]any:
+			if nullable, _ := v["nullable"].(bool); nullable {
+				switch t := v["type"].(type) {
+				case string:
+					if t != "null" {
+						v["type"] = []any{t, "null"}
+					}
+				case []any:
+					if !slices.Contains(t, any("null")) {
+						v["type"] = append(t, "null")
+					}
+				}
+			}
+			for _, child := range v {
+				walk(child)
+			}
+		case []any:
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+	walk(root)
+}
+
+// walkSchemas calls fn once for every schema in the document, along with the JSON pointer of where it was first found.
+func walkSchemas(doc *v3.Document, fn func(s *base.Schema, location string)) {
+	w := schemaWalker{fn: fn, seen: make(map[*base.Schema]bool), bodies: true}
+	w.document(doc)
+}
+
+// walkParameterSchemas is like walkSchemas, but only for the schemas of parameters and headers, which are validated
+// one value at a time rather than as a body.
+func walkParameterSchemas(doc *v3.Document, fn func(s *base.Schema, location string)) {
+	w := schemaWalker{fn: fn, seen: make(map[*base.Schema]bool)}
+	w.document(doc)
+}
+
+type schemaWalker struct {
+	fn   func(s *base.Schema, location string)
+	seen map[*base.Schema]bool
+	// bodies is set to also walk the schemas of request and response bodies.
+	bodies bool
+}
+
+func (w *schemaWalker) document(doc *v3.Document) {
+	if c := doc.Components; c != nil {
+		if w.bodies {
+			// The schemas that parameters and headers reference are walked from where they are referenced.
+			for name, proxy := range c.Schemas.FromOldest() {
+				w.proxy(proxy, pointer("components", "schemas", name))
+			}
+		}
+		for name, p := range c.Parameters.FromOldest() {
+			w.parameter(p, pointer("components", "parameters", name))
+		}
+		for name, h := range c.Headers.FromOldest() {
+			w.header(h, pointer("components", "headers", name))
+		}
+		for name, b := range c.RequestBodies.FromOldest() {
+			if b != nil {
+				w.body(b.Content, pointer("components", "requestBodies", name))
+			}
+		}
+		for name, r := range c.Responses.FromOldest() {
+			w.response(r, pointer("components", "responses", name))
+		}
+	}
+
+	if doc.Paths == nil {
+		return
+	}
+	for path, item := range doc.Paths.PathItems.FromOldest() {
+		for i, p := range item.Parameters {
+			w.parameter(p, pointer("paths", path, "parameters", fmt.Sprint(i)))
+		}
+		for method, op := range item.GetOperations().FromOldest() {
+			location := pointer("paths", path, method)
+			for i, p := range op.Parameters {
+				w.parameter(p, location+pointer("parameters", fmt.Sprint(i)))
+			}
+			if op.RequestBody != nil {
+				w.body(op.RequestBody.Content, location+pointer("requestBody"))
+			}
+			if op.Responses == nil {
+				continue
+			}
+			w.response(op.Responses.Default, location+pointer("responses", "default"))
+			for code, r := range op.Responses.Codes.FromOldest() {
+				w.response(r, location+pointer("responses", code))
+			}
+		}
+	}
+}
+
+func (w *schemaWalker) parameter(p *v3.Parameter, location string) {
+	if p == nil {
+		return
+	}
+	w.proxy(p.Schema, location+pointer("schema"))
+	w.content(p.Content, location)
+}
+
+func (w *schemaWalker) header(h *v3.Header, location string) {
+	if h == nil {
+		return
+	}
+	w.proxy(h.Schema, location+pointer("schema"))
+	w.content(h.Content, location)
+}
+
+func (w *schemaWalker) response(r *v3.Response, location string) {
+	if r == nil {
+		return
+	}
+	for name, h := range r.Headers.FromOldest() {
+		w.header(h, location+pointer("headers", name))
+	}
+	w.body(r.Content, location)
+}
+
+func (w *schemaWalker) body(content *orderedmap.Map[string, *v3.MediaType], location string) {
+	if w.bodies {
+		w.content(content, location)
+	}
+}
+
+func (w *schemaWalker) content(content *orderedmap.Map[string, *v3.MediaType], location string) {
+	for name, mt := range content.FromOldest() {
+		if mt != nil {
+			w.proxy(mt.Schema, location+pointer("content", name, "schema"))
+		}
+	}
+}
+
+func (w *schemaWalker) proxy(proxy *base.SchemaProxy, location string) {
+	if proxy == nil {
+		return
+	}
+	s := proxy.Schema()
+	if s == nil || w.seen[s] {
+		return
+	}
+	w.seen[s] = true
+	w.fn(s, location)
+
+	for _, group := range []struct {
+		keyword string
+		proxies []*base.SchemaProxy
+	}{{"allOf", s.AllOf}, {"anyOf", s.AnyOf}, {"oneOf", s.OneOf}, {"prefixItems", s.PrefixItems}} {
+		for i, p := range group.proxies {
+			w.proxy(p, location+pointer(group.keyword, fmt.Sprint(i)))
+		}
+	}
+	for name, p := range s.Properties.FromOldest() {
+		w.proxy(p, location+pointer("properties", name))
+	}
+	for name, p := range s.PatternProperties.FromOldest() {
+		w.proxy(p, location+pointer("patternProperties", name))
+	}
+	if s.Items != nil && s.Items.IsA() {
+		w.proxy(s.Items.A, location+pointer("items"))
+	}
+	if s.AdditionalProperties != nil && s.AdditionalProperties.IsA() {
+		w.proxy(s.AdditionalProperties.A, location+pointer("additionalProperties"))
+	}
+	w.proxy(s.Not, location+pointer("not"))
+}
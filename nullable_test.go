@@ -0,0 +1,115 @@
+package copper
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// nullableSpec returns a spec of the given version where the name is nullable in the given way, both inline and
+// through a reference, and in a recursive schema.
+func nullableSpec(version, name string) []byte {
+	return []byte(fmt.Sprintf(`openapi: %s
+info:
+  title: nullable
+  version: '1.0'
+paths:
+  /things:
+    get:
+      responses:
+        "200":
+          description: a thing
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  name: %s
+                  ref:
+                    $ref: '#/components/schemas/Name'
+  /tree:
+    get:
+      responses:
+        "200":
+          description: a tree
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Node'
+components:
+  schemas:
+    Name: %s
+    Node:
+      type: object
+      properties:
+        name: %s
+        children:
+          type: array
+          items:
+            $ref: '#/components/schemas/Node'
+`, version, name, name, name))
+}
+
+func TestNullability(t *testing.T) {
+	const (
+		nullable  = "{type: string, nullable: true}"
+		typeArray = "{type: [string, 'null']}"
+	)
+
+	tt := []struct {
+		name    string
+		version string
+		schema  string
+		mode    Nullability
+		valid   bool
+	}{
+		{"nullable in 3.0", "3.0.3", nullable, NullableByVersion, true},
+		{"not nullable in 3.0", "3.0.3", "{type: string}", NullableByVersion, false},
+		{"type array in 3.1", "3.1.0", typeArray, NullableByVersion, true},
+		{"nullable in 3.1", "3.1.0", nullable, NullableByVersion, false},
+		{"lenient nullable in 3.1", "3.1.0", nullable, NullableLenient, true},
+		{"lenient type array in 3.1", "3.1.0", typeArray, NullableLenient, true},
+		{"strict nullable in 3.0", "3.0.3", nullable, NullableStrict, true},
+		{"strict type array in 3.1", "3.1.0", typeArray, NullableStrict, true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			v, err := NewVerifier(nullableSpec(tc.version, tc.schema), WithNullability(tc.mode), WithoutFullCoverage())
+			require.NoError(t, err)
+
+			for path, body := range map[string]string{
+				"/things": `{"name": null, "ref": null}`,
+				"/tree":   `{"name": "root", "children": [{"name": null}]}`,
+			} {
+				v.Record(&http.Response{
+					StatusCode: http.StatusOK,
+					Request:    httptest.NewRequest(http.MethodGet, path, nil),
+					Header:     http.Header{"Content-Type": []string{"application/json"}},
+					Body:       io.NopCloser(strings.NewReader(body)),
+				})
+			}
+
+			if tc.valid {
+				assert.NoError(t, v.CurrentError())
+			} else {
+				// Both paths fail, the inline and referenced schema through the validator library, and the recursive
+				// schema through the JSON schema library.
+				assert.ErrorIs(t, v.CurrentError(), ErrResponseInvalid)
+				assert.Len(t, v.CurrentErrors(), 2)
+			}
+		})
+	}
+
+	t.Run("strict nullable in 3.1", func(t *testing.T) {
+		_, err := NewVerifier(nullableSpec("3.1.0", nullable), WithNullability(NullableStrict))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "schema at /components/schemas/Name uses nullable")
+	})
+}
@@ -1,17 +1,68 @@
 package copper
 
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+)
+
 type Option func(c *config)
 
 type config struct {
-	serverBase                string
-	checkInternalServerErrors bool
-	checkRequest              bool
-	requestLogger             RequestLogger
-	disableFullCoverage       bool
+	serverBase                   string
+	checkInternalServerErrors    bool
+	checkRequest                 bool
+	requestLogger                RequestLogger
+	disableFullCoverage          bool
+	maxDepth                     int
+	headFromGet                  bool
+	headCoverageFromGet          bool
+	problemDetails               bool
+	rateLimitHeaders             bool
+	links                        bool
+	disableResponseValidation    bool
+	strictQueryEncoding          bool
+	headerValues                 HeaderValues
+	strictFormats                bool
+	strictResponseProperties     bool
+	sampling                     float64
+	verbosity                    Verbosity
+	nullability                  Nullability
+	ecmaPatterns                 bool
+	contentSniffing              bool
+	jwtClaims                    bool
+	jwtChecks                    []JWTClaimsCheck
+	statusHeaders                StatusHeaders
+	errorTemplates               ErrorTemplates
+	ignoreUnsupportedBodyFormats bool
+	violationHandler             func(*VerificationError)
+	baseURL                      string
+	requestIDs                   bool
+	tlsConfig                    *tls.Config
+	rateLimit                    float64
+	errorWindow                  int
+	snapshotPath                 string
+	snapshotInterval             time.Duration
+	goldenDir                    string
+	requestMediaTypeCoverage     bool
+	swagger2                     bool
+	filters                      filters
+	coverageThreshold            float64
+	securityValidation           bool
+	authErrorCoverage            bool
+	exampleValidation            bool
+	// conflicts are found while the options are applied, and reported by validate.
+	conflicts []error
 }
 
 func getConfig(opts ...Option) config {
-	c := &config{}
+	c := &config{
+		maxDepth: defaultMaxDepth,
+		sampling: 1,
+	}
 	for _, opt := range opts {
 		opt(c)
 	}
@@ -19,15 +70,88 @@ func getConfig(opts ...Option) config {
 	return *c
 }
 
+// ErrInvalidOptions is returned when creating a verifier with options that contradict each other, or have values that
+// can not be used.
+var ErrInvalidOptions = errors.New("invalid options")
+
+// validate checks that the options make sense together, rather than silently letting one of them win.
+func (c config) validate() error {
+	errs := slices.Clone(c.conflicts)
+	errs = append(errs, c.filters.validate()...)
+
+	if c.serverBase != "" {
+		if _, err := url.Parse(c.serverBase); err != nil {
+			errs = append(errs, fmt.Errorf("WithServer is given an invalid URL: %w", err))
+		}
+	}
+	if c.baseURL != "" {
+		if u, err := url.Parse(c.baseURL); err != nil {
+			errs = append(errs, fmt.Errorf("WithBaseURL is given an invalid URL: %w", err))
+		} else if !u.IsAbs() || u.Host == "" {
+			errs = append(errs, fmt.Errorf("WithBaseURL is given %q, which is not an absolute URL", c.baseURL))
+		}
+	}
+	if c.disableResponseValidation && c.strictResponseProperties {
+		errs = append(errs, errors.New("WithStrictResponseProperties has no effect together with WithoutResponseValidation"))
+	}
+	if c.disableResponseValidation && !c.checkRequest && c.strictFormats {
+		errs = append(errs, errors.New("WithStrictFormats has no effect when neither requests nor responses are validated"))
+	}
+	if c.jwtClaims && !c.checkRequest && !c.securityValidation {
+		errs = append(errs, errors.New("WithJWTClaims has no effect without WithRequestValidation or WithSecurityValidation"))
+	}
+	if c.maxDepth < 1 {
+		errs = append(errs, fmt.Errorf("WithMaxDepth is given %d, but the depth must be at least 1", c.maxDepth))
+	}
+	if c.verbosity < LogDumps || c.verbosity > LogProgressAndDumps {
+		errs = append(errs, fmt.Errorf("WithVerbosity is given an unknown level %d", c.verbosity))
+	}
+	if c.verbosity != LogDumps && c.requestLogger == nil {
+		errs = append(errs, errors.New("WithVerbosity has no effect without WithRequestLogging"))
+	}
+	if c.sampling <= 0 || c.sampling > 1 {
+		errs = append(errs, fmt.Errorf("WithSampling is given %v, but the rate must be above 0 and at most 1", c.sampling))
+	}
+	if c.coverageThreshold < 0 || c.coverageThreshold > 100 {
+		errs = append(errs, fmt.Errorf("WithCoverageThreshold is given %v, but the percentage must be between 0 and 100", c.coverageThreshold))
+	}
+	if c.coverageThreshold != 0 && c.disableFullCoverage {
+		errs = append(errs, errors.New("WithCoverageThreshold has no effect together with WithoutFullCoverage"))
+	}
+	if c.nullability < NullableByVersion || c.nullability > NullableStrict {
+		errs = append(errs, fmt.Errorf("WithNullability is given an unknown mode %d", c.nullability))
+	}
+	if c.headerValues != FirstHeaderValue && c.headerValues != JoinedHeaderValues {
+		errs = append(errs, fmt.Errorf("WithHeaderValues is given an unknown mode %d", c.headerValues))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %w", ErrInvalidOptions, errors.Join(errs...))
+	}
+	return nil
+}
+
 // WithServer is a functional Option for setting the base path/host used when correlating the specification to the API
 // calls being recorded. This can be used when the specification doesn't have a server entry for the target of tests,
 // or when conflicts cause the wrong server to be selected for calculating the base path.
 func WithServer(host string) Option {
 	return func(c *config) {
+		if c.serverBase != "" && c.serverBase != host {
+			c.conflicts = append(c.conflicts, fmt.Errorf("WithServer is given both %q and %q", c.serverBase, host))
+		}
 		c.serverBase = host
 	}
 }
 
+// WithBaseURL is a functional Option for the client from WrapClient, which joins the base URL with the paths of requests
+// that have relative URLs, so that tests can call c.Get("/ping") rather than repeat the URL of the server in every call.
+// A base URL with a path, like http://localhost:8080/api, is prefixed to the paths. Absolute URLs are sent as they are.
+func WithBaseURL(base string) Option {
+	return func(c *config) {
+		c.baseURL = base
+	}
+}
+
 // WithInternalServerErrors is a functional Option for also validating server responses. These are skipped by default
 // since a server should not ideally have internal server errors, and even if they are not part of a specification, they
 // considered a possible response from an API.
@@ -47,6 +171,14 @@ func WithRequestValidation() Option {
 	}
 }
 
+// WithoutRequestValidation is a functional Option for turning request validation off again, which is mainly useful
+// together with SetOptions or after a preset like Strict.
+func WithoutRequestValidation() Option {
+	return func(c *config) {
+		c.checkRequest = false
+	}
+}
+
 // WithoutFullCoverage is a functional Option for disabling verification that full coverage of the API has been
 // accomplished. Full coverage is defined as having a test covering all documented response codes for all documented
 // endpoint paths and methods. Using this option will still verify that no undocumented endpoints have been hit, as
@@ -57,6 +189,131 @@ func WithoutFullCoverage() Option {
 	}
 }
 
+// WithoutResponseValidation is a functional Option for only tracking coverage. Responses are not validated against
+// their schemas, headers or any of the other checks, which makes recording a lot cheaper for high-volume suites. Hits
+// on undocumented endpoints and undocumented response codes are still reported.
+func WithoutResponseValidation() Option {
+	return func(c *config) {
+		c.disableResponseValidation = true
+	}
+}
+
+// WithHeadFromGet is a functional Option for validating responses to documented HEAD operations against the GET
+// operation of the same path. A HEAD response has no body, but should carry the same headers as the GET response, so
+// the headers required by the GET response for the same status code are required on the HEAD response as well.
+func WithHeadFromGet() Option {
+	return func(c *config) {
+		c.headFromGet = true
+	}
+}
+
+// WithHeadCoverageFromGet is a functional Option for letting a covered GET response also cover the HEAD response with
+// the same status code for the same path, if the spec documents one. Specs that document both methods otherwise need a
+// test for each, even though the HEAD response is just the GET response without a body.
+func WithHeadCoverageFromGet() Option {
+	return func(c *config) {
+		c.headCoverageFromGet = true
+	}
+}
+
+// WithProblemDetails is a functional Option for requiring that all error (4xx and 5xx) responses use RFC 7807 problem
+// details, meaning that they have the application/problem+json content type. Problem details bodies are always
+// checked for having the standard members with the right types, with or without this option.
+func WithProblemDetails() Option {
+	return func(c *config) {
+		c.problemDetails = true
+	}
+}
+
+// WithRateLimitHeaders is a functional Option for checking rate limiting contracts. A 429 response is required to have
+// a Retry-After header, and Retry-After as well as the RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers
+// are checked for being well-formed on any response where they are present. The headers do not count toward coverage,
+// which is only tracked for the coordinates of the spec.
+func WithRateLimitHeaders() Option {
+	return func(c *config) {
+		c.rateLimitHeaders = true
+	}
+}
+
+// WithLinks is a functional Option for verifying the links documented on responses. Whenever a response with links is
+// recorded, a follow-up request to each linked operation, with the parameter values given by the link, is expected to
+// be recorded later on. Links that are never followed are reported as not checked, which gives coverage of workflows
+// rather than only of isolated endpoints.
+func WithLinks() Option {
+	return func(c *config) {
+		c.links = true
+	}
+}
+
+// WithStrictQueryEncoding is a functional Option for checking that reserved characters in the query values of requests
+// are percent-encoded, unless the parameter has allowReserved set in the spec. Without this option, reserved
+// characters are tolerated in any query value, which avoids false positives for APIs that take URLs or JSON in the
+// query. Only has an effect together with WithRequestValidation.
+func WithStrictQueryEncoding() Option {
+	return func(c *config) {
+		c.strictQueryEncoding = true
+	}
+}
+
+// WithHeaderValues is a functional Option for deciding how headers that are given several times are validated, either by
+// the first value only, or by all values joined with commas. Header names are always matched regardless of case. The
+// default is FirstHeaderValue.
+func WithHeaderValues(mode HeaderValues) Option {
+	return func(c *config) {
+		c.headerValues = mode
+	}
+}
+
+// WithStrictFormats is a functional Option for validating values against the format of their schema, like date-time,
+// uuid or email for strings, and the ranges of int32 and int64 for integers. The format is otherwise only an
+// annotation, and not validated. Formats that are not known are ignored.
+func WithStrictFormats() Option {
+	return func(c *config) {
+		c.strictFormats = true
+	}
+}
+
+// WithStrictResponseProperties is a functional Option for reporting properties in response bodies that are not
+// documented by the schema. A schema that does not say anything about additionalProperties allows any properties,
+// which lets undocumented fields slip into responses unnoticed. Schemas that explicitly set additionalProperties or
+// patternProperties, as well as free-form objects without any properties, are still allowed to have other properties.
+func WithStrictResponseProperties() Option {
+	return func(c *config) {
+		c.strictResponseProperties = true
+	}
+}
+
+// Strict is a functional Option that combines the options for the strictest verification: request validation, coverage
+// of internal server errors, strict formats and strict response properties. Response validation is turned back on if an
+// earlier option turned it off. Options given after it can tweak it further.
+func Strict() Option {
+	return func(c *config) {
+		c.disableResponseValidation = false
+		for _, opt := range []Option{
+			WithRequestValidation(),
+			WithInternalServerErrors(),
+			WithStrictFormats(),
+			WithStrictResponseProperties(),
+		} {
+			opt(c)
+		}
+	}
+}
+
+// Lenient is a functional Option that mostly only tracks coverage. Requests and response bodies are not validated, and
+// any strict checks enabled by earlier options are turned off again, but hits on undocumented endpoints and response
+// codes are still reported. Options given after it can tweak it further.
+func Lenient() Option {
+	return func(c *config) {
+		c.checkRequest = false
+		c.checkInternalServerErrors = false
+		c.strictFormats = false
+		c.strictResponseProperties = false
+		c.strictQueryEncoding = false
+		c.disableResponseValidation = true
+	}
+}
+
 // RequestLogger is a minimal interface that can fit for example a testing.T, allowing tests to easily print logs where
 // needed.
 type RequestLogger interface {
@@ -71,3 +328,55 @@ func WithRequestLogging(l RequestLogger) Option {
 		c.requestLogger = l
 	}
 }
+
+// Verbosity decides what is logged to the logger given with WithRequestLogging.
+type Verbosity int
+
+const (
+	// LogDumps logs a dump of every request and response. This is the default.
+	LogDumps Verbosity = iota
+	// LogProgress logs a single line per request and response, in the logfmt style, with the coordinate, the verdict
+	// and how long it took to verify. This is easy for log scrapers to parse, for example from the output of go test
+	// -json.
+	LogProgress
+	// LogProgressAndDumps logs both the dumps and the single lines.
+	LogProgressAndDumps
+)
+
+// WithVerbosity is a functional Option for deciding what is logged to the logger given with WithRequestLogging. The
+// default is LogDumps.
+func WithVerbosity(level Verbosity) Option {
+	return func(c *config) {
+		c.verbosity = level
+	}
+}
+
+// WithViolationHandler is a functional Option for calling the handler with every violation that is found when a
+// request and response are recorded, before Record returns and in the order that they were found. It is meant for side
+// effects like taking a screenshot, extra logging or failing a test right away for some sentinels, without polling
+// CurrentErrors. Coverage errors are only known at the end, and are not passed to the handler.
+func WithViolationHandler(handler func(*VerificationError)) Option {
+	return func(c *config) {
+		c.violationHandler = handler
+	}
+}
+
+// WithMaxDepth is a functional Option for setting how deeply nested request and response bodies are allowed to be
+// before validation is aborted. Bodies for recursive schemas (trees, linked lists and similar) can be nested
+// arbitrarily deep, and the limit makes sure that validating them stays bounded. Bodies nested deeper than the limit
+// are reported as invalid. Defaults to 128.
+func WithMaxDepth(depth int) Option {
+	return func(c *config) {
+		c.maxDepth = depth
+	}
+}
+
+// WithSampling is a functional Option for only validating a fraction of the recorded requests and responses, which keeps
+// the overhead down when recording load tests or live traffic. The rate is the fraction to validate, above 0 and at
+// most 1, and the validated ones are spread evenly over the recorded ones. Coverage is still tracked for all of them,
+// and responses with undocumented status codes are always reported.
+func WithSampling(rate float64) Option {
+	return func(c *config) {
+		c.sampling = rate
+	}
+}
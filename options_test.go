@@ -0,0 +1,58 @@
+package copper
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptionConflicts(t *testing.T) {
+	f, err := os.ReadFile("testdata/minimal-spec.yaml")
+	require.NoError(t, err)
+
+	tt := []struct {
+		name  string
+		opts  []Option
+		valid bool
+	}{
+		{"no options", nil, true},
+		{"presets", []Option{Strict(), WithoutFullCoverage()}, true},
+		{"preset overridden", []Option{Strict(), Lenient()}, true},
+		{"same server twice", []Option{WithServer("http://localhost:8000"), WithServer("http://localhost:8000")}, true},
+		{"different servers", []Option{WithServer("http://localhost:8000"), WithServer("http://localhost:9000/api")}, false},
+		{"invalid server", []Option{WithServer("http://local host:8000")}, false},
+		{"strict properties without response validation", []Option{WithoutResponseValidation(), WithStrictResponseProperties()}, false},
+		{"strict after lenient", []Option{Lenient(), Strict()}, true},
+		{"lenient response validation after strict", []Option{Strict(), WithoutResponseValidation()}, false},
+		{"strict formats for requests only", []Option{WithoutResponseValidation(), WithRequestValidation(), WithStrictFormats()}, true},
+		{"strict formats without validation", []Option{WithoutResponseValidation(), WithStrictFormats()}, false},
+		{"zero max depth", []Option{WithMaxDepth(0)}, false},
+		{"unknown header mode", []Option{WithHeaderValues(HeaderValues(7))}, false},
+		{"sampling", []Option{WithSampling(0.1)}, true},
+		{"zero sampling", []Option{WithSampling(0)}, false},
+		{"sampling above 1", []Option{WithSampling(1.5)}, false},
+		{"unknown nullability", []Option{WithNullability(Nullability(-1))}, false},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewVerifier(f, tc.opts...)
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidOptions)
+			}
+
+			_, err = WrapClient(http.DefaultClient, bytes.NewReader(f), tc.opts...)
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidOptions)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package copper
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/dlclark/regexp2"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"gopkg.in/yaml.v3"
+)
+
+// ecmaMatchTimeout bounds how long a single match of an ECMAScript pattern may take. Unlike Go regular expressions,
+// these backtrack, and a pattern can take exponential time on some values. A match that times out counts as a value
+// that does not match.
+const ecmaMatchTimeout = time.Second
+
+// WithECMAPatterns is a functional Option for checking the patterns of the spec that Go regular expressions do not
+// support, like lookaheads and backreferences, with an ECMAScript compatible engine, which is what the pattern keyword
+// of OpenAPI is defined by. Without this option, a spec with such patterns is rejected when the verifier is created.
+// Patterns that Go supports are still matched by Go. The ECMAScript patterns are supported in bodies, but not for
+// parameters and headers, where a spec that uses them is still rejected.
+func WithECMAPatterns() Option {
+	return func(c *config) {
+		c.ecmaPatterns = true
+	}
+}
+
+// checkPatterns finds the patterns of the spec that Go regular expressions can not compile, and reports them with the
+// line they are on, unless ECMAScript patterns are allowed. The spec is otherwise rejected by the document validation
+// with an error that does not say what is wrong. When ECMAScript patterns are allowed, the spec is returned with the
+// patterns blanked out for the document validation, which compiles them with Go. If there is nothing to blank out, nil
+// is returned.
+func checkPatterns(specBytes []byte, ecma bool) ([]byte, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(specBytes, &root); err != nil {
+		// The spec is not valid YAML, which parsing it as a document reports.
+		return nil, nil
+	}
+
+	var errs []error
+	var unsupported []*yaml.Node
+	walkPatterns(&root, func(pattern *yaml.Node) {
+		_, err := regexp.Compile(pattern.Value)
+		if err == nil {
+			return
+		}
+		if !ecma {
+			errs = append(errs, fmt.Errorf("pattern %q on line %d is not supported by Go regular expressions, "+
+				"use WithECMAPatterns to check it: %w", pattern.Value, pattern.Line, err))
+			return
+		}
+		if _, err := compileECMA(pattern.Value); err != nil {
+			errs = append(errs, fmt.Errorf("pattern %q on line %d is not a valid ECMAScript regular expression: %w",
+				pattern.Value, pattern.Line, err))
+			return
+		}
+		unsupported = append(unsupported, pattern)
+	})
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	if len(unsupported) == 0 {
+		return nil, nil
+	}
+
+	for _, pattern := range unsupported {
+		pattern.Value = ""
+		pattern.Style = yaml.DoubleQuotedStyle
+	}
+	return yaml.Marshal(&root)
+}
+
+// walkPatterns calls fn for every pattern with a string value in the YAML tree. A property or extension called pattern
+// would have an object as its value, so these are not mistaken for patterns.
+func walkPatterns(node *yaml.Node, fn func(pattern *yaml.Node)) {
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "pattern" && value.Kind == yaml.ScalarNode && value.Tag == "!!str" {
+				fn(value)
+			}
+		}
+	}
+	for _, child := range node.Content {
+		walkPatterns(child, fn)
+	}
+}
+
+// checkParameterPatterns reports the parameters and headers with patterns that Go regular expressions do not support.
+// These are validated by the validator library, which only knows Go regular expressions.
+func checkParameterPatterns(doc *v3.Document) error {
+	var errs []error
+	walkParameterSchemas(doc, func(s *base.Schema, location string) {
+		if _, err := regexp.Compile(s.Pattern); err != nil {
+			errs = append(errs, fmt.Errorf("pattern %q of the schema at %s is only supported in bodies", s.Pattern, location))
+		}
+	})
+	return errors.Join(errs...)
+}
+
+// ecmaRegexp is the regexp engine for schemas when ECMAScript patterns are allowed. Schemas with such patterns do not
+// compile the way the validator library compiles them, so they are validated like recursive schemas are.
+func ecmaRegexp(s string) (jsonschema.Regexp, error) {
+	if re, err := regexp.Compile(s); err == nil {
+		return re, nil
+	}
+	re, err := compileECMA(s)
+	if err != nil {
+		return nil, err
+	}
+	return ecmaPattern{re}, nil
+}
+
+func compileECMA(s string) (*regexp2.Regexp, error) {
+	re, err := regexp2.Compile(s, regexp2.ECMAScript)
+	if err != nil {
+		return nil, err
+	}
+	re.MatchTimeout = ecmaMatchTimeout
+	return re, nil
+}
+
+type ecmaPattern struct {
+	re *regexp2.Regexp
+}
+
+func (p ecmaPattern) MatchString(s string) bool {
+	ok, err := p.re.MatchString(s)
+	return err == nil && ok
+}
+
+func (p ecmaPattern) String() string {
+	return p.re.String()
+}
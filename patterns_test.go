@@ -0,0 +1,70 @@
+package copper
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestECMAPatterns(t *testing.T) {
+	f, err := os.ReadFile("testdata/pattern-spec.yaml")
+	require.NoError(t, err)
+
+	t.Run("rejected without the option", func(t *testing.T) {
+		_, err := NewVerifier(f)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `pattern "^(\\w)\\1$" on line 25 is not supported by Go regular expressions`)
+		assert.Contains(t, err.Error(), `pattern "^(?=.*\\d)(?=.*[a-z]).{8,}$" on line 34`)
+	})
+
+	tt := []struct {
+		name     string
+		password string
+		codes    string
+		valid    bool
+	}{
+		{"valid", "secret123", `["aa", "bb"]`, true},
+		{"lookahead not matched", "secretpassword", `["aa"]`, false},
+		{"backreference not matched", "secret123", `["ab"]`, false},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			v, err := NewVerifier(f, WithECMAPatterns(), WithRequestValidation())
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "/passwords", strings.NewReader(`{"password": "`+tc.password+`"}`))
+			req.Header.Set("Content-Type", "application/json")
+			v.Record(&http.Response{
+				StatusCode: http.StatusCreated,
+				Request:    req,
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(`{"codes": ` + tc.codes + `}`)),
+			})
+
+			if tc.valid {
+				assert.NoError(t, v.CurrentError())
+			} else {
+				assert.ErrorContains(t, v.CurrentError(), "does not match pattern")
+			}
+		})
+	}
+
+	t.Run("invalid ECMAScript pattern", func(t *testing.T) {
+		spec := strings.Replace(string(f), `'^(\w)\1$'`, `'^(?<=a'`, 1)
+		_, err := NewVerifier([]byte(spec), WithECMAPatterns())
+		assert.ErrorContains(t, err, "is not a valid ECMAScript regular expression")
+	})
+
+	t.Run("parameters are rejected", func(t *testing.T) {
+		spec := strings.Replace(string(f), "    post:\n", "    post:\n      parameters:\n        - name: code\n          in: query\n          schema:\n            type: string\n            pattern: '^(?!x)'\n", 1)
+		_, err := NewVerifier([]byte(spec), WithECMAPatterns())
+		assert.ErrorContains(t, err, `pattern "^(?!x)" of the schema at /paths/~1passwords/post/parameters/0/schema is only supported in bodies`)
+	})
+}
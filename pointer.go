@@ -0,0 +1,57 @@
+package copper
+
+import (
+	"strconv"
+	"strings"
+)
+
+// pointer builds a JSON pointer from the given reference tokens, escaping them as needed.
+func pointer(tokens ...string) string {
+	escaper := strings.NewReplacer("~", "~0", "/", "~1")
+
+	s := strings.Builder{}
+	for _, t := range tokens {
+		s.WriteString("/")
+		s.WriteString(escaper.Replace(t))
+	}
+	return s.String()
+}
+
+// valueAt returns the value at the reference tokens of a JSON pointer in the decoded JSON.
+func valueAt(decoded any, tokens []string) (any, bool) {
+	value := decoded
+	for _, t := range tokens {
+		switch v := value.(type) {
+		case map[string]any:
+			var ok bool
+			if value, ok = v[t]; !ok {
+				return nil, false
+			}
+		case []any:
+			i, err := strconv.Atoi(t)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			value = v[i]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// pointerTokens returns the unescaped reference tokens of a JSON pointer.
+func pointerTokens(p string) ([]string, bool) {
+	if p == "" {
+		return nil, true
+	}
+	if !strings.HasPrefix(p, "/") {
+		return nil, false
+	}
+	unescaper := strings.NewReplacer("~1", "/", "~0", "~")
+	tokens := strings.Split(p[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = unescaper.Replace(t)
+	}
+	return tokens, true
+}
@@ -0,0 +1,42 @@
+package copper
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValueAt(t *testing.T) {
+	var decoded any
+	require.NoError(t, json.Unmarshal([]byte(`{"a/b": {"c~d": [1, "two"]}, "": true}`), &decoded))
+
+	tt := []struct {
+		pointer string
+		value   any
+		found   bool
+	}{
+		{"", decoded, true},
+		{"/", true, true},
+		{pointer("a/b", "c~d", "1"), "two", true},
+		{"/a~1b/c~0d/0", float64(1), true},
+		{"/a~1b/c~0d/2", nil, false},
+		{"/a~1b/c~0d/-1", nil, false},
+		{"/a~1b/c~0d/0/deeper", nil, false},
+		{"/missing", nil, false},
+		{"a~1b", nil, false},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.pointer, func(t *testing.T) {
+			tokens, ok := pointerTokens(tc.pointer)
+			if ok {
+				var value any
+				value, ok = valueAt(decoded, tokens)
+				assert.Equal(t, tc.value, value)
+			}
+			assert.Equal(t, tc.found, ok)
+		})
+	}
+}
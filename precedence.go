@@ -0,0 +1,76 @@
+package copper
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	validatorerr "github.com/pb33f/libopenapi-validator/errors"
+	"github.com/pb33f/libopenapi-validator/paths"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/orderedmap"
+)
+
+// byPrecedence returns a shallow copy of the document with the paths in the order that they are matched in. Segment by
+// segment, a static segment takes precedence over a template, so /things/export is matched before /things/{id}, which
+// is what OpenAPI prescribes. Paths that are equal in precedence keep the order of the spec. A document without paths,
+// which OpenAPI 3.1 allows, gets empty ones, since the validator library does not expect them to be missing.
+func byPrecedence(doc *v3.Document) *v3.Document {
+	if doc.Paths == nil {
+		matching := *doc
+		matching.Paths = &v3.Paths{PathItems: orderedmap.New[string, *v3.PathItem]()}
+		return &matching
+	}
+
+	keys := slices.Collect(doc.Paths.PathItems.KeysFromOldest())
+	slices.SortStableFunc(keys, comparePrecedence)
+
+	items := orderedmap.New[string, *v3.PathItem]()
+	for _, key := range keys {
+		items.Set(key, doc.Paths.PathItems.GetOrZero(key))
+	}
+
+	sorted := *doc.Paths
+	sorted.PathItems = items
+	matching := *doc
+	matching.Paths = &sorted
+	return &matching
+}
+
+// comparePrecedence compares two paths by the first segment where one is a template and the other is not. The one
+// with the static segment comes first.
+func comparePrecedence(a, b string) int {
+	aSegs := strings.Split(strings.Trim(a, "/"), "/")
+	bSegs := strings.Split(strings.Trim(b, "/"), "/")
+	for i := range min(len(aSegs), len(bSegs)) {
+		aTemplate, bTemplate := isTemplateSegment(aSegs[i]), isTemplateSegment(bSegs[i])
+		switch {
+		case aTemplate && !bTemplate:
+			return 1
+		case !aTemplate && bTemplate:
+			return -1
+		}
+	}
+	return 0
+}
+
+// isTemplateSegment checks if a segment of a path has a parameter in it, like {id} or {id}.json.
+func isTemplateSegment(seg string) bool {
+	return strings.Contains(seg, "{")
+}
+
+// findPath finds the path in the spec for the request, in the order of precedence. A path that matches the URL, but
+// does not have the method of the request, is passed over for the next one that matches.
+func (v *Verifier) findPath(req *http.Request) (*v3.PathItem, []*validatorerr.ValidationError, string) {
+	return paths.FindPath(req, v.matching)
+}
+
+// MatchPath returns the path in the spec that Record attributes the request to, or an empty string if there is none.
+// It is meant for tests that check how overlapping paths like /things/{id} and /things/export are resolved.
+func (v *Verifier) MatchPath(req *http.Request) string {
+	_, errs, path := v.findPath(req)
+	if len(errs) > 0 {
+		return ""
+	}
+	return path
+}
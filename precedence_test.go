@@ -0,0 +1,65 @@
+package copper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchPath(t *testing.T) {
+	f, err := os.ReadFile("testdata/precedence-spec.yaml")
+	require.NoError(t, err)
+
+	v, err := NewVerifier(f)
+	require.NoError(t, err)
+
+	tt := []struct {
+		name     string
+		method   string
+		url      string
+		expected string
+	}{
+		{"static path beats a template listed before it", http.MethodGet, "/things/export", "/things/export"},
+		{"static first segment beats a template", http.MethodGet, "/stuff/export", "/{kind}/export"},
+		{"template matches other values", http.MethodGet, "/things/1", "/things/{id}"},
+		{"static path without the method falls through", http.MethodPut, "/things/export", "/things/{id}"},
+		{"no path has the method", http.MethodDelete, "/things/1", ""},
+		{"no path matches", http.MethodGet, "/things/1/parts", ""},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, v.MatchPath(httptest.NewRequest(tc.method, tc.url, nil)))
+		})
+	}
+}
+
+func TestPrecedenceCoverage(t *testing.T) {
+	f, err := os.ReadFile("testdata/precedence-spec.yaml")
+	require.NoError(t, err)
+
+	v, err := NewVerifier(f)
+	require.NoError(t, err)
+
+	v.Record(&http.Response{
+		StatusCode: http.StatusOK,
+		Body:       http.NoBody,
+		Request:    httptest.NewRequest(http.MethodGet, "/things/export", nil),
+	})
+
+	assert.True(t, v.endpoints.IsChecked("/things/export", http.MethodGet, "200"))
+	assert.False(t, v.endpoints.IsChecked("/things/{id}", http.MethodGet, "200"))
+	assert.False(t, v.endpoints.IsChecked("/{kind}/export", http.MethodGet, "200"))
+	assert.NotErrorIs(t, v.CurrentError(), ErrNotPartOfSpec)
+}
+
+func TestComparePrecedence(t *testing.T) {
+	assert.Negative(t, comparePrecedence("/things/export", "/things/{id}"))
+	assert.Positive(t, comparePrecedence("/{kind}/export", "/things/{id}"))
+	assert.Positive(t, comparePrecedence("/things/{id}.json", "/things/latest"))
+	assert.Zero(t, comparePrecedence("/things/{id}", "/things/{name}/parts"))
+}
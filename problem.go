@@ -0,0 +1,76 @@
+package copper
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+)
+
+const problemMediaType = "application/problem+json"
+
+// problemMembers lists the members defined for problem details by RFC 7807 in the order of the RFC, along with a check
+// for the JSON type that each of them must have. The order keeps the reported errors stable.
+var problemMembers = []struct {
+	name  string
+	valid func(v any) bool
+}{
+	{"type", isString},
+	{"title", isString},
+	{"status", isInteger},
+	{"detail", isString},
+	{"instance", isString},
+}
+
+// checkProblemDetails checks bodies that are declared as problem details, and if requested through WithProblemDetails,
+// also that all error responses use problem details.
+func (v *Verifier) checkProblemDetails(req *http.Request, res *http.Response, body []byte) error {
+	mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+
+	if mediaType != problemMediaType {
+		if v.conf.problemDetails && res.StatusCode >= 400 && !isBodiless(req, res.StatusCode) {
+			return fmt.Errorf("%d response has content type %q, but error responses must use %s",
+				res.StatusCode, mediaType, problemMediaType)
+		}
+		return nil
+	}
+
+	if len(body) == 0 {
+		return nil
+	}
+	return checkProblem(body, res.StatusCode)
+}
+
+// checkProblem checks that the body is a problem details object according to RFC 7807. All members are optional, but
+// the standard ones must have the right type, and the status must be the same as the status code of the response.
+func checkProblem(body []byte, statusCode int) error {
+	var problem map[string]any
+	if err := json.Unmarshal(body, &problem); err != nil {
+		return fmt.Errorf("problem details must be a JSON object: %w", err)
+	}
+
+	var errs []error
+	for _, member := range problemMembers {
+		value, ok := problem[member.name]
+		if ok && !member.valid(value) {
+			errs = append(errs, fmt.Errorf("problem details member %q has the wrong type", member.name))
+		}
+	}
+
+	if status, ok := problem["status"].(float64); ok && int(status) != statusCode {
+		errs = append(errs, fmt.Errorf("problem details status %v does not match the response status %d", status, statusCode))
+	}
+
+	return errors.Join(errs...)
+}
+
+func isString(v any) bool {
+	_, ok := v.(string)
+	return ok
+}
+
+func isInteger(v any) bool {
+	f, ok := v.(float64)
+	return ok && f == float64(int64(f))
+}
@@ -0,0 +1,91 @@
+package copper
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckProblem(t *testing.T) {
+	tt := []struct {
+		name  string
+		body  string
+		valid bool
+	}{
+		{"all standard members", `{"type":"https://example.com/nope","title":"Nope","status":404,"detail":"No thing","instance":"/thing"}`, true},
+		{"no members", `{}`, true},
+		{"extension members", `{"title":"Nope","balance":30}`, true},
+		{"no type or title", `{"status":404,"detail":"No thing"}`, true},
+		{"not an object", `["nope"]`, false},
+		{"title is not a string", `{"title":5}`, false},
+		{"status is not an integer", `{"status":404.5}`, false},
+		{"status does not match", `{"status":500}`, false},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkProblem([]byte(tc.body), http.StatusNotFound)
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+
+	t.Run("errors are in the order of the members", func(t *testing.T) {
+		err := checkProblem([]byte(`{"instance":1,"detail":2,"status":"404","title":3,"type":4}`), http.StatusNotFound)
+		assert.EqualError(t, err, `problem details member "type" has the wrong type
+problem details member "title" has the wrong type
+problem details member "status" has the wrong type
+problem details member "detail" has the wrong type
+problem details member "instance" has the wrong type`)
+	})
+}
+
+func TestWithProblemDetails(t *testing.T) {
+	f, err := os.ReadFile("testdata/problem-spec.yaml")
+	require.NoError(t, err)
+
+	tt := []struct {
+		name        string
+		opts        []Option
+		statusCode  int
+		contentType string
+		body        string
+		valid       bool
+	}{
+		{"problem details", []Option{WithProblemDetails()}, 404, "application/problem+json", `{"title":"Nope","status":404}`, true},
+		{"plain json error", []Option{WithProblemDetails()}, 404, "application/json", `{"title":"Nope"}`, false},
+		{"plain json error without option", nil, 404, "application/json", `{"title":"Nope"}`, true},
+		{"invalid problem details without option", nil, 404, "application/problem+json", `{"status":"404"}`, false},
+		{"success is not affected", []Option{WithProblemDetails()}, 200, "application/json", `{}`, true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			v, err := NewVerifier(f, append(tc.opts, WithoutFullCoverage())...)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "/thing", nil)
+			v.Record(&http.Response{
+				StatusCode: tc.statusCode,
+				Request:    req,
+				Header:     http.Header{"Content-Type": []string{tc.contentType}},
+				Body:       io.NopCloser(strings.NewReader(tc.body)),
+			})
+
+			if tc.valid {
+				assert.NoError(t, v.CurrentError())
+			} else {
+				assert.ErrorIs(t, v.CurrentError(), ErrResponseInvalid)
+			}
+		})
+	}
+}
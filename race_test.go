@@ -0,0 +1,101 @@
+package copper
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The tests are meant to be run with the race detector, which catches shared state that is changed while recording.
+func TestConcurrentRecording(t *testing.T) {
+	f, err := os.ReadFile("testdata/request-body-spec.yaml")
+	require.NoError(t, err)
+
+	s := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.Copy(io.Discard, r.Body)
+			w.WriteHeader(http.StatusNoContent)
+		}),
+	)
+	defer s.Close()
+
+	t.Run("one client", func(t *testing.T) {
+		c := MustWrapClient(http.DefaultClient, bytes.NewReader(f), WithRequestValidation(), WithRequestLogging(&logStore{}))
+
+		var wg sync.WaitGroup
+		for i := range 50 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				body := `{"input":"pem"}`
+				if i%2 == 1 {
+					body = `{"input":5}`
+				}
+				res, err := c.Post(s.URL+"/req", "application/json", strings.NewReader(body))
+				if assert.NoError(t, err) {
+					_ = res.Body.Close()
+				}
+			}()
+		}
+		wg.Wait()
+
+		assert.Len(t, c.CurrentErrors(), 25)
+		assert.Equal(t, 50, c.Endpoints()[0].Hits)
+	})
+
+	t.Run("one request", func(t *testing.T) {
+		v := MustNewVerifier(f, WithRequestValidation())
+
+		req, err := http.NewRequest(http.MethodPost, "/req", strings.NewReader(`{"input":"pem"}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+
+		var wg sync.WaitGroup
+		for range 50 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				v.Record(&http.Response{StatusCode: http.StatusNoContent, Request: req})
+			}()
+		}
+		wg.Wait()
+
+		assert.NoError(t, v.CurrentError())
+		assert.Equal(t, 50, v.Endpoints()[0].Hits)
+	})
+
+	t.Run("client controls", func(t *testing.T) {
+		c := MustWrapClient(http.DefaultClient, bytes.NewReader(f), WithoutFullCoverage())
+
+		var wg sync.WaitGroup
+		for i := range 50 {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				c.SetTimeout(time.Duration(i+1) * time.Second)
+				c.SetJar(nil)
+				c.SetCheckRedirect(nil)
+			}()
+			go func() {
+				defer wg.Done()
+				res, err := c.Post(s.URL+"/req", "application/json", strings.NewReader(`{"input":"pem"}`))
+				if assert.NoError(t, err) {
+					_ = res.Body.Close()
+				}
+			}()
+		}
+		wg.Wait()
+
+		assert.NoError(t, c.CurrentError())
+		assert.Zero(t, http.DefaultClient.Timeout)
+	})
+}
@@ -0,0 +1,133 @@
+package copper
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// contentRange is a parsed Content-Range header for a byte range. A total of -1 means that the complete length is
+// unknown.
+type contentRange struct {
+	start, end, total int64
+}
+
+// parseContentRange parses a satisfied byte range on the form "bytes 0-499/1234" or "bytes 0-499/*".
+func parseContentRange(s string) (contentRange, error) {
+	unit, rng, ok := strings.Cut(strings.TrimSpace(s), " ")
+	if !ok || unit != "bytes" {
+		return contentRange{}, fmt.Errorf("content range %q is not a byte range", s)
+	}
+
+	span, total, ok := strings.Cut(rng, "/")
+	if !ok {
+		return contentRange{}, fmt.Errorf("content range %q has no complete length", s)
+	}
+
+	first, last, ok := strings.Cut(span, "-")
+	if !ok {
+		return contentRange{}, fmt.Errorf("content range %q has no byte span", s)
+	}
+
+	var (
+		r    = contentRange{total: -1}
+		errs []error
+		err  error
+	)
+	r.start, err = strconv.ParseInt(first, 10, 64)
+	errs = append(errs, err)
+	r.end, err = strconv.ParseInt(last, 10, 64)
+	errs = append(errs, err)
+	if total != "*" {
+		r.total, err = strconv.ParseInt(total, 10, 64)
+		errs = append(errs, err)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return contentRange{}, fmt.Errorf("content range %q is malformed: %w", s, err)
+	}
+
+	if r.start < 0 || r.start > r.end {
+		return contentRange{}, fmt.Errorf("content range %q has an invalid byte span", s)
+	}
+	if r.total >= 0 && r.end >= r.total {
+		return contentRange{}, fmt.Errorf("content range %q ends beyond the complete length", s)
+	}
+	return r, nil
+}
+
+func (r contentRange) length() int64 {
+	return r.end - r.start + 1
+}
+
+// checkPartialContent checks that a 206 response describes the range(s) that it contains, and that the body is
+// consistent with the described range(s).
+func checkPartialContent(h http.Header, body []byte) error {
+	mediaType, params, _ := mime.ParseMediaType(h.Get("Content-Type"))
+	if mediaType == "multipart/byteranges" {
+		return checkByteRanges(params["boundary"], body)
+	}
+
+	header := h.Get("Content-Range")
+	if header == "" {
+		return errors.New("partial content response has no Content-Range header")
+	}
+
+	r, err := parseContentRange(header)
+	if err != nil {
+		return err
+	}
+
+	if int64(len(body)) != r.length() {
+		return fmt.Errorf("content range %q describes %d bytes, but the body has %d", header, r.length(), len(body))
+	}
+	return nil
+}
+
+// checkByteRanges checks each part of a multipart/byteranges body, which all need their own Content-Range.
+func checkByteRanges(boundary string, body []byte) error {
+	if boundary == "" {
+		return errors.New("multipart/byteranges response has no boundary")
+	}
+
+	var errs []error
+	parts := multipart.NewReader(bytes.NewReader(body), boundary)
+	for i := 0; ; i++ {
+		part, err := parts.NextPart()
+		if errors.Is(err, io.EOF) {
+			if i == 0 {
+				return errors.New("multipart/byteranges response has no parts")
+			}
+			return errors.Join(errs...)
+		}
+		if err != nil {
+			return fmt.Errorf("multipart/byteranges response could not be read: %w", err)
+		}
+
+		partBody, err := io.ReadAll(part)
+		if err != nil {
+			return fmt.Errorf("multipart/byteranges part %d could not be read: %w", i, err)
+		}
+
+		header := part.Header.Get("Content-Range")
+		if header == "" {
+			errs = append(errs, fmt.Errorf("multipart/byteranges part %d has no Content-Range header", i))
+			continue
+		}
+
+		r, err := parseContentRange(header)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("multipart/byteranges part %d: %w", i, err))
+			continue
+		}
+		if int64(len(partBody)) != r.length() {
+			errs = append(errs, fmt.Errorf("multipart/byteranges part %d: content range %q describes %d bytes, but the part has %d",
+				i, header, r.length(), len(partBody)))
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package copper
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseContentRange(t *testing.T) {
+	tt := []struct {
+		name     string
+		header   string
+		expected contentRange
+		valid    bool
+	}{
+		{"known length", "bytes 0-499/1234", contentRange{0, 499, 1234}, true},
+		{"unknown length", "bytes 500-999/*", contentRange{500, 999, -1}, true},
+		{"single byte", "bytes 0-0/1", contentRange{0, 0, 1}, true},
+		{"wrong unit", "items 0-499/1234", contentRange{}, false},
+		{"unsatisfied range", "bytes */1234", contentRange{}, false},
+		{"missing length", "bytes 0-499", contentRange{}, false},
+		{"reversed span", "bytes 499-0/1234", contentRange{}, false},
+		{"beyond length", "bytes 0-1234/1234", contentRange{}, false},
+		{"not a number", "bytes a-b/c", contentRange{}, false},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := parseContentRange(tc.header)
+			if tc.valid {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, r)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestPartialContent(t *testing.T) {
+	f, err := os.ReadFile("testdata/range-spec.yaml")
+	require.NoError(t, err)
+
+	multipartBody := "--SEP\r\nContent-Type: video/mp4\r\nContent-Range: bytes 0-3/100\r\n\r\nabcd\r\n" +
+		"--SEP\r\nContent-Type: video/mp4\r\nContent-Range: bytes 10-11/100\r\n\r\nef\r\n--SEP--\r\n"
+
+	tt := []struct {
+		name        string
+		contentType string
+		rangeHeader string
+		body        string
+		valid       bool
+	}{
+		{"consistent range", "video/mp4", "bytes 0-3/100", "abcd", true},
+		{"missing content range", "video/mp4", "", "abcd", false},
+		{"too short body", "video/mp4", "bytes 0-3/100", "abc", false},
+		{"multiple ranges", "multipart/byteranges; boundary=SEP", "", multipartBody, true},
+		{"part with wrong length", "multipart/byteranges; boundary=SEP", "", strings.Replace(multipartBody, "ef", "efg", 1), false},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			v, err := NewVerifier(f)
+			require.NoError(t, err)
+
+			h := http.Header{"Content-Type": []string{tc.contentType}}
+			if tc.rangeHeader != "" {
+				h.Set("Content-Range", tc.rangeHeader)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/video", nil)
+			req.Header.Set("Range", "bytes=0-3")
+			v.Record(&http.Response{
+				StatusCode: http.StatusPartialContent,
+				Request:    req,
+				Header:     h,
+				Body:       io.NopCloser(strings.NewReader(tc.body)),
+			})
+
+			errs := v.CurrentErrors()
+			// The 200 response is never checked, but the 206 always counts toward coverage.
+			assert.ErrorIs(t, errs[len(errs)-1], ErrNotChecked)
+			assert.Contains(t, errs[len(errs)-1].Error(), "200")
+			if tc.valid {
+				assert.Len(t, errs, 1)
+			} else {
+				assert.ErrorIs(t, errs[0], ErrResponseInvalid)
+			}
+		})
+	}
+}
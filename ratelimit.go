@@ -0,0 +1,55 @@
+package copper
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// rateLimitHeaders are the fields from the IETF RateLimit header fields draft that carry a single non-negative integer.
+var rateLimitHeaders = []string{"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset"}
+
+// checkRateLimit checks that a 429 response says when to retry, and that Retry-After and the RateLimit headers are
+// well-formed on any response where they are present.
+func checkRateLimit(res *http.Response) error {
+	var errs []error
+
+	retryAfter := res.Header.Get("Retry-After")
+	switch {
+	case retryAfter != "":
+		if err := checkRetryAfter(retryAfter); err != nil {
+			errs = append(errs, err)
+		}
+	case res.StatusCode == http.StatusTooManyRequests:
+		errs = append(errs, errors.New("429 response has no Retry-After header"))
+	}
+
+	for _, name := range rateLimitHeaders {
+		value := res.Header.Get(name)
+		if value == "" {
+			continue
+		}
+		if _, err := parseNonNegative(value); err != nil {
+			errs = append(errs, fmt.Errorf("header %s is malformed: %w", name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// checkRetryAfter checks that the value is either a number of seconds, or an HTTP date.
+func checkRetryAfter(value string) error {
+	if _, err := parseNonNegative(value); err == nil {
+		return nil
+	}
+	if _, err := http.ParseTime(value); err == nil {
+		return nil
+	}
+	return fmt.Errorf("header Retry-After is malformed: %q is neither a delay in seconds nor an HTTP date", value)
+}
+
+func parseNonNegative(value string) (uint64, error) {
+	return strconv.ParseUint(strings.TrimSpace(value), 10, 64)
+}
@@ -0,0 +1,48 @@
+package copper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithRateLimitHeaders(t *testing.T) {
+	f, err := os.ReadFile("testdata/rate-limit-spec.yaml")
+	require.NoError(t, err)
+
+	tt := []struct {
+		name       string
+		opts       []Option
+		statusCode int
+		header     http.Header
+		valid      bool
+	}{
+		{"retry after seconds", []Option{WithRateLimitHeaders()}, 429, http.Header{"Retry-After": {"120"}}, true},
+		{"retry after date", []Option{WithRateLimitHeaders()}, 429, http.Header{"Retry-After": {"Wed, 21 Oct 2015 07:28:00 GMT"}}, true},
+		{"missing retry after", []Option{WithRateLimitHeaders()}, 429, http.Header{}, false},
+		{"malformed retry after", []Option{WithRateLimitHeaders()}, 429, http.Header{"Retry-After": {"soon"}}, false},
+		{"missing retry after without option", nil, 429, http.Header{}, true},
+		{"well-formed rate limit", []Option{WithRateLimitHeaders()}, 204, http.Header{"Ratelimit-Limit": {"100"}, "Ratelimit-Remaining": {"0"}}, true},
+		{"malformed rate limit", []Option{WithRateLimitHeaders()}, 204, http.Header{"Ratelimit-Remaining": {"-1"}}, false},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			v, err := NewVerifier(f, append(tc.opts, WithoutFullCoverage())...)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+			v.Record(&http.Response{StatusCode: tc.statusCode, Request: req, Header: tc.header})
+
+			if tc.valid {
+				assert.NoError(t, v.CurrentError())
+			} else {
+				assert.ErrorIs(t, v.CurrentError(), ErrResponseInvalid)
+			}
+		})
+	}
+}
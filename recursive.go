@@ -0,0 +1,200 @@
+package copper
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/utils"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// defaultMaxDepth is the maximum nesting depth of bodies that will be validated, unless changed with WithMaxDepth.
+const defaultMaxDepth = 128
+
+const specResource = "copper://spec.json"
+
+// recursiveSchemas validates bodies for schemas that reference themselves, like trees or linked lists. The validator
+// library renders every schema inline before compiling it, which is not possible for a recursive schema, so these are
+// instead compiled straight from the spec document where the JSON schema compiler resolves the references lazily. The
+// same goes for schemas with ECMAScript patterns, which the validator library can not compile.
+type recursiveSchemas struct {
+	mu       sync.Mutex
+	compiler *jsonschema.Compiler
+	loadErr  error
+	schemas  map[*v3.MediaType]*recursiveSchema
+}
+
+type recursiveSchema struct {
+	schema *jsonschema.Schema
+	err    error
+}
+
+func newRecursiveSchemas(specBytes []byte, conf config) *recursiveSchemas {
+	r := &recursiveSchemas{
+		compiler: jsonschema.NewCompiler(),
+		schemas:  make(map[*v3.MediaType]*recursiveSchema),
+	}
+	if conf.ecmaPatterns {
+		r.compiler.UseRegexpEngine(ecmaRegexp)
+	}
+
+	specJSON, err := utils.ConvertYAMLtoJSON(specBytes)
+	if err != nil {
+		r.loadErr = err
+		return r
+	}
+
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(specJSON))
+	if err != nil {
+		r.loadErr = err
+		return r
+	}
+
+	nullableJSON(doc, conf.nullability)
+	r.loadErr = r.compiler.AddResource(specResource, doc)
+	return r
+}
+
+// lookup returns the compiled schema for the given media type if the schema of it is recursive. The location is the
+// JSON pointer of the schema in the spec, and is only used if the schema is not a reference itself. If the schema is
+// not recursive, nil is returned and the validator library should be used instead.
+func (r *recursiveSchemas) lookup(mediaType *v3.MediaType, location string) *recursiveSchema {
+	if mediaType == nil || mediaType.Schema == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if s, ok := r.schemas[mediaType]; ok {
+		return s
+	}
+
+	var s *recursiveSchema
+	if !compilesInline(mediaType) {
+		s = r.compile(mediaType, location)
+	}
+	r.schemas[mediaType] = s
+	return s
+}
+
+// compilesInline checks if the schema can be compiled the way the validator library does it. Recursive references are
+// left as they are when rendering inline, and since they then point to nothing the compilation fails.
+func compilesInline(mediaType *v3.MediaType) bool {
+	rendered, err := mediaType.Schema.Schema().RenderInline()
+	if err != nil {
+		return false
+	}
+	_, err = compileInline(rendered, false)
+	return err == nil
+}
+
+// compileInline compiles a schema that has been rendered inline, with the ECMAScript regular expression engine if
+// asked to.
+func compileInline(rendered []byte, ecma bool) (*jsonschema.Schema, error) {
+	renderedJSON, err := utils.ConvertYAMLtoJSON(rendered)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(renderedJSON))
+	if err != nil {
+		return nil, err
+	}
+
+	c := jsonschema.NewCompiler()
+	if ecma {
+		c.UseRegexpEngine(ecmaRegexp)
+	}
+	if err := c.AddResource("inline.json", doc); err != nil {
+		return nil, err
+	}
+	return c.Compile("inline.json")
+}
+
+func (r *recursiveSchemas) compile(mediaType *v3.MediaType, location string) *recursiveSchema {
+	if r.loadErr != nil {
+		return &recursiveSchema{err: fmt.Errorf("could not load spec for recursive schema: %w", r.loadErr)}
+	}
+
+	if mediaType.Schema.IsReference() {
+		location = strings.TrimPrefix(mediaType.Schema.GetReference(), "#")
+	}
+
+	schema, err := r.compiler.Compile(specResource + "#" + location)
+	if err != nil {
+		return &recursiveSchema{err: fmt.Errorf("could not compile recursive schema at %s: %w", location, err)}
+	}
+	return &recursiveSchema{schema: schema}
+}
+
+// validate checks the given body against the schema. An empty body is not validated.
+func (s *recursiveSchema) validate(body []byte) error {
+	if s.err != nil {
+		return s.err
+	}
+	if len(body) == 0 {
+		return nil
+	}
+
+	decoded, err := jsonschema.UnmarshalJSON(bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("body cannot be decoded: %w", err)
+	}
+
+	err = s.schema.Validate(decoded)
+	if verr, ok := err.(*jsonschema.ValidationError); ok {
+		bestUnionCauses(verr)
+	}
+	return err
+}
+
+var errTooDeep = errors.New("maximum nesting depth exceeded")
+
+// checkDepth walks the JSON tokens of the body without recursion, and returns an error if the nesting depth of the
+// body is larger than the given max. Bodies that are not valid JSON are left for the schema validation to report.
+func checkDepth(body []byte, max int) error {
+	dec := json.NewDecoder(bytes.NewReader(body))
+
+	depth := 0
+	for {
+		t, err := dec.Token()
+		if err != nil {
+			// Either the end of the body, or something that is not JSON, which the schema validation will report.
+			return nil
+		}
+
+		delim, ok := t.(json.Delim)
+		if !ok {
+			continue
+		}
+
+		switch delim {
+		case '{', '[':
+			depth++
+			if depth > max {
+				return fmt.Errorf("%w: body is nested deeper than %d levels", errTooDeep, max)
+			}
+		case '}', ']':
+			depth--
+		}
+	}
+}
+
+// readBody reads the full body, and replaces it with a new reader so that it can be read again.
+func readBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+
+	b, err := io.ReadAll(*body)
+	_ = (*body).Close()
+	*body = io.NopCloser(bytes.NewReader(b))
+	return b, err
+}
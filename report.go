@@ -0,0 +1,161 @@
+//go:build !copper_lite
+
+package copper
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"time"
+)
+
+// Report is a structured report of the coverage of the spec and the errors found, for publishing dashboards from test
+// runs rather than only failing them. It is a snapshot, taken by Verifier.Report.
+type Report struct {
+	// Title and Version are taken from the info of the spec.
+	Title   string `json:"title"`
+	Version string `json:"version"`
+	// Endpoints is the number of coordinates in the spec, and Checked the number of those that have been checked.
+	Endpoints int `json:"endpoints"`
+	Checked   int `json:"checked"`
+	// Coordinates has every coordinate of the spec, sorted by path, method and response code.
+	Coordinates []CoordinateReport `json:"coordinates"`
+	// Waived lists the coordinates that are left out of the coverage, with the reason why.
+	Waived []WaivedEndpoint `json:"waived"`
+	// Errors holds the messages of the errors that do not belong to a coordinate, like requests to paths that are not
+	// part of the spec.
+	Errors []string `json:"errors"`
+}
+
+// CoordinateReport is a coordinate of the spec in a Report, with the messages of the errors that were found for it.
+type CoordinateReport struct {
+	EndpointStatus
+	Errors []string `json:"errors"`
+}
+
+// Coverage returns the percentage of the coordinates in the spec that have been checked. A spec without any coordinates
+// is fully covered.
+func (r Report) Coverage() float64 {
+	if r.Endpoints == 0 {
+		return 100
+	}
+	return float64(r.Checked) * 100 / float64(r.Endpoints)
+}
+
+// Report returns a report of the coverage of every coordinate of the spec, with the errors found for it, and of the
+// errors that do not belong to any coordinate. Errors for coverage are left out, since the coordinates show it.
+func (v *Verifier) Report() Report {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	r := Report{
+		Title:       v.model.Info.Title,
+		Version:     v.model.Info.Version,
+		Coordinates: []CoordinateReport{},
+		Waived:      v.endpoints.Waived(),
+		Errors:      []string{},
+	}
+	if r.Waived == nil {
+		r.Waived = []WaivedEndpoint{}
+	}
+
+	attributed := make(map[error]bool)
+	for _, status := range v.endpointStatuses() {
+		c := CoordinateReport{EndpointStatus: status, Errors: []string{}}
+		for _, err := range v.endpoints.Failures(status.Endpoint) {
+			attributed[err] = true
+			c.Errors = append(c.Errors, err.Error())
+		}
+		r.Endpoints++
+		if status.Checked {
+			r.Checked++
+		}
+		r.Coordinates = append(r.Coordinates, c)
+	}
+	for _, err := range v.currentErrors() {
+		if !attributed[err] && !errors.Is(err, ErrNotChecked) {
+			r.Errors = append(r.Errors, err.Error())
+		}
+	}
+	return r
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(r); err != nil {
+		return fmt.Errorf("could not write report: %w", err)
+	}
+	return nil
+}
+
+// WriteHTML writes the report as a self-contained HTML page, with a table of the coordinates that shows which have been
+// checked and which have errors, for publishing as an artifact of a test run.
+func (r Report) WriteHTML(w io.Writer) error {
+	if err := reportTemplate.Execute(w, r); err != nil {
+		return fmt.Errorf("could not write report: %w", err)
+	}
+	return nil
+}
+
+// seen formats when a coordinate was first or last seen in the HTML report, and leaves it empty if it has not been.
+func seen(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{"seen": seen}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}} {{.Version}} coverage</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; vertical-align: top; }
+tr.checked td.status { background: #d4edda; }
+tr.unchecked td.status { background: #fff3cd; }
+tr.failed td.status { background: #f8d7da; }
+ul { margin: 0; padding-left: 1.2em; }
+</style>
+</head>
+<body>
+<h1>{{.Title}} {{.Version}}</h1>
+<p>{{.Checked}} of {{.Endpoints}} coordinates checked ({{printf "%.1f" .Coverage}}%)</p>
+<table>
+<thead><tr><th>Method</th><th>Path</th><th>Response</th><th>Operation</th><th>Status</th><th>Hits</th><th>First seen</th><th>Last seen</th><th>Errors</th></tr></thead>
+<tbody>
+{{- range .Coordinates}}
+<tr class="{{if .Errors}}failed{{else if .Checked}}checked{{else}}unchecked{{end}}">
+<td>{{.Method}}</td><td>{{.Path}}</td><td>{{.ResponseCode}}</td><td>{{.Operation}}</td>
+<td class="status">{{if .Errors}}failed{{else if .Checked}}checked{{else}}not checked{{end}}</td>
+<td>{{.Hits}}</td><td>{{seen .FirstSeen}}</td><td>{{seen .LastSeen}}</td>
+<td>{{if .Errors}}<ul>{{range .Errors}}<li>{{.}}</li>{{end}}</ul>{{end}}</td>
+</tr>
+{{- end}}
+</tbody>
+</table>
+{{- if .Waived}}
+<h2>Waived</h2>
+<ul>
+{{- range .Waived}}
+<li>{{.Method}} {{.Path}} {{.ResponseCode}}: {{.Reason}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- if .Errors}}
+<h2>Other errors</h2>
+<ul>
+{{- range .Errors}}
+<li>{{.}}</li>
+{{- end}}
+</ul>
+{{- end}}
+</body>
+</html>
+`))
@@ -0,0 +1,93 @@
+//go:build !copper_lite
+
+package copper
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReport(t *testing.T) {
+	f, err := os.ReadFile("testdata/thing-spec.yaml")
+	require.NoError(t, err)
+
+	record := func(v *Verifier, path, body string) {
+		v.Record(&http.Response{
+			StatusCode: 200,
+			Request:    httptest.NewRequest(http.MethodGet, path, nil),
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+		})
+	}
+
+	v, err := NewVerifier(f)
+	require.NoError(t, err)
+	record(v, "/ping", `{"message": 1}`)
+	record(v, "/ping", `{"message": "pong"}`)
+	record(v, "/missing", `{}`)
+
+	r := v.Report()
+	assert.Equal(t, 2, r.Endpoints)
+	assert.Equal(t, 1, r.Checked)
+	assert.Equal(t, 50.0, r.Coverage())
+	if assert.Len(t, r.Coordinates, 2) {
+		assert.Equal(t, "/other", r.Coordinates[0].Path)
+		assert.False(t, r.Coordinates[0].Checked)
+		assert.Empty(t, r.Coordinates[0].Errors)
+
+		assert.Equal(t, "/ping", r.Coordinates[1].Path)
+		assert.True(t, r.Coordinates[1].Checked)
+		assert.Equal(t, 2, r.Coordinates[1].Hits)
+		if assert.Len(t, r.Coordinates[1].Errors, 1) {
+			assert.Contains(t, r.Coordinates[1].Errors[0], "response invalid: GET /ping")
+		}
+	}
+	if assert.Len(t, r.Errors, 1) {
+		assert.Contains(t, r.Errors[0], "not part of spec: GET /missing")
+	}
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, r.WriteJSON(&buf))
+
+		var decoded Report
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+		var again bytes.Buffer
+		require.NoError(t, decoded.WriteJSON(&again))
+		assert.JSONEq(t, buf.String(), again.String())
+		assert.Contains(t, buf.String(), `"responseCode": "200"`)
+	})
+
+	t.Run("html", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, r.WriteHTML(&buf))
+
+		html := buf.String()
+		assert.Contains(t, html, "<p>1 of 2 coordinates checked (50.0%)</p>")
+		assert.Contains(t, html, `<tr class="unchecked">`)
+		assert.Contains(t, html, `<tr class="failed">`)
+		assert.Contains(t, html, "<th>First seen</th><th>Last seen</th>")
+		ping := r.Coordinates[1]
+		assert.Contains(t, html, "<td>2</td><td>"+ping.FirstSeen.UTC().Format(time.RFC3339)+"</td><td>"+
+			ping.LastSeen.UTC().Format(time.RFC3339)+"</td>")
+		assert.Contains(t, html, "<td>0</td><td></td><td></td>")
+		assert.Contains(t, html, "<h2>Other errors</h2>")
+		assert.NotContains(t, html, "<h2>Waived</h2>")
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		r := MustNewVerifier(f).Report()
+		assert.Equal(t, 0.0, r.Coverage())
+		assert.Empty(t, r.Errors)
+	})
+}
@@ -0,0 +1,60 @@
+package copper
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// RequestIDHeader is the header that carries the ID of a request, for correlating the violations of the request with the
+// logs of the server.
+const RequestIDHeader = "X-Request-ID"
+
+// WithRequestIDs is a functional Option for correlating violations with the exact call that caused them. The client from
+// WrapClient sends every request with an X-Request-ID header, and keeps the one that a request already has. The ID of a
+// request, or else the one that the server responds with, is then part of its errors, its log lines and the saved state,
+// so that a violation in the final report can be looked up in the logs of the server. Verifiers that record the traffic
+// of a server pick up the IDs that the clients send.
+func WithRequestIDs() Option {
+	return func(c *config) {
+		c.requestIDs = true
+	}
+}
+
+// withRequestID returns the request with an X-Request-ID header, generated if it does not already have one. The headers
+// are copied before they are changed, so that the request of the caller is left as it is.
+func withRequestID(r *http.Request) *http.Request {
+	if r.Header.Get(RequestIDHeader) != "" {
+		return r
+	}
+
+	r = r.Clone(r.Context())
+	if r.Header == nil {
+		r.Header = make(http.Header)
+	}
+	r.Header.Set(RequestIDHeader, newRequestID())
+	return r
+}
+
+// newRequestID returns a random ID of 32 hex characters.
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// requestID returns the ID of the exchange, from the request, or from the response if the server generated it. It is
+// empty unless WithRequestIDs is given.
+func (v *Verifier) requestID(req *http.Request, res *http.Response) string {
+	v.mu.Lock()
+	enabled := v.conf.requestIDs
+	v.mu.Unlock()
+
+	if !enabled {
+		return ""
+	}
+	if id := req.Header.Get(RequestIDHeader); id != "" {
+		return id
+	}
+	return res.Header.Get(RequestIDHeader)
+}
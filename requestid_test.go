@@ -0,0 +1,104 @@
+package copper
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithRequestIDs(t *testing.T) {
+	f, err := os.ReadFile("testdata/minimal-spec.yaml")
+	require.NoError(t, err)
+
+	var received string
+	s := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			received = r.Header.Get(RequestIDHeader)
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+	defer s.Close()
+
+	t.Run("generated", func(t *testing.T) {
+		store := &logStore{}
+		c, err := WrapClient(http.DefaultClient, bytes.NewReader(f), WithRequestIDs(), WithRequestLogging(store),
+			WithVerbosity(LogProgressAndDumps))
+		require.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodGet, s.URL+"/ping", nil)
+		require.NoError(t, err)
+		_, err = c.Do(req)
+		require.NoError(t, err)
+		assert.Regexp(t, `^[0-9a-f]{32}$`, received)
+		assert.Empty(t, req.Header.Get(RequestIDHeader), "the request of the caller is not changed")
+
+		var verr *VerificationError
+		require.True(t, errors.As(c.CurrentError(), &verr))
+		assert.Equal(t, received, verr.RequestID())
+		assert.Contains(t, verr.Error(), "(request ID "+received+")")
+
+		if assert.Len(t, store.logs, 3) {
+			assert.Contains(t, store.logs[0], "REQUEST  0001 "+received+" ====")
+			if dumpsHeaders {
+				assert.Contains(t, store.logs[0], "X-Request-Id: "+received)
+			}
+			assert.Contains(t, store.logs[2], `request_id="`+received+`"`)
+		}
+
+		// The ID is kept with the state, but does not make the same violation a new one.
+		data, err := json.Marshal(c.Verifier)
+		require.NoError(t, err)
+		restored := MustNewVerifier(f, WithRequestIDs())
+		require.NoError(t, json.Unmarshal(data, restored))
+		require.True(t, errors.As(restored.CurrentError(), &verr))
+		assert.Equal(t, received, verr.RequestID())
+
+		again := MustWrapClient(http.DefaultClient, bytes.NewReader(f), WithRequestIDs())
+		_, err = again.Get(s.URL + "/ping")
+		require.NoError(t, err)
+		assert.Empty(t, DiffStates(restored, again.Verifier).NewViolations)
+	})
+
+	t.Run("propagated", func(t *testing.T) {
+		c, err := WrapClient(http.DefaultClient, bytes.NewReader(f), WithRequestIDs())
+		require.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodGet, s.URL+"/ping", nil)
+		require.NoError(t, err)
+		req.Header.Set(RequestIDHeader, "trace-1")
+		_, err = c.Do(req)
+		require.NoError(t, err)
+		assert.Equal(t, "trace-1", received)
+		assert.ErrorContains(t, c.CurrentError(), "(request ID trace-1)")
+	})
+
+	t.Run("from the response", func(t *testing.T) {
+		v := MustNewVerifier(f, WithRequestIDs())
+		res := &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body:       http.NoBody,
+			Request:    httptest.NewRequest(http.MethodGet, "/ping", nil),
+		}
+		res.Header.Set(RequestIDHeader, "server-1")
+		v.Record(res)
+		assert.ErrorContains(t, v.CurrentError(), "(request ID server-1)")
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		c, err := WrapClient(http.DefaultClient, bytes.NewReader(f))
+		require.NoError(t, err)
+
+		_, err = c.Get(s.URL + "/ping")
+		require.NoError(t, err)
+		assert.Empty(t, received)
+		assert.NotContains(t, c.CurrentError().Error(), "request ID")
+	})
+}
@@ -0,0 +1,115 @@
+package copper
+
+import (
+	"cmp"
+	"fmt"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/orderedmap"
+)
+
+// WithRequestMediaTypeCoverage is a functional Option for requiring every media type of the request bodies of
+// operations that accept several of them, like both JSON and multipart, to be sent at least once. A media type that is
+// never sent is reported with ErrNotChecked, like the coordinates that are not checked, unless WithoutFullCoverage is
+// given. The media types are tracked either way, and listed by UnusedRequestMediaTypes.
+func WithRequestMediaTypeCoverage() Option {
+	return func(c *config) {
+		c.requestMediaTypeCoverage = true
+	}
+}
+
+// RequestMediaType is a media type of the request body of an operation.
+type RequestMediaType struct {
+	Path      string `json:"path"`
+	Method    string `json:"method"`
+	MediaType string `json:"mediaType"`
+}
+
+// UnusedRequestMediaTypes returns the media types of the request bodies of operations that accept several of them, but
+// that no request has been sent with, sorted by path, method and media type.
+func (v *Verifier) UnusedRequestMediaTypes() []RequestMediaType {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	return v.unusedRequestMediaTypes()
+}
+
+func (v *Verifier) unusedRequestMediaTypes() []RequestMediaType {
+	var unused []RequestMediaType
+	for mt, used := range v.mediaTypes {
+		if !used {
+			unused = append(unused, mt)
+		}
+	}
+	sortRequestMediaTypes(unused)
+	return unused
+}
+
+// requestMediaTypes returns the media types of the request bodies of the operations that accept more than one, none of
+// which are used yet. Operations that the filters leave out are not included.
+func requestMediaTypes(doc *v3.Document, f filters) map[RequestMediaType]bool {
+	mediaTypes := make(map[RequestMediaType]bool)
+	if doc.Paths == nil {
+		return mediaTypes
+	}
+	for path, item := range doc.Paths.PathItems.FromOldest() {
+		for method, op := range item.GetOperations().FromOldest() {
+			if op.RequestBody == nil || orderedmap.Len(op.RequestBody.Content) < 2 {
+				continue
+			}
+			if _, excluded := f.excluded(path, strings.ToUpper(method), op); excluded {
+				continue
+			}
+			for mediaType := range op.RequestBody.Content.KeysFromOldest() {
+				mediaTypes[RequestMediaType{Path: path, Method: strings.ToUpper(method), MediaType: mediaType}] = false
+			}
+		}
+	}
+	return mediaTypes
+}
+
+// useRequestMediaType marks the media type of the operation that the request body was sent with as used. A media type
+// that is documented as it is takes precedence over one with a wildcard, like image/*.
+func (v *Verifier) useRequestMediaType(req *http.Request, path string, op *v3.Operation) {
+	if op == nil || op.RequestBody == nil {
+		return
+	}
+	sent, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil {
+		return
+	}
+
+	method := strings.ToUpper(req.Method)
+	if _, ok := v.mediaTypes[RequestMediaType{Path: path, Method: method, MediaType: sent}]; ok {
+		v.mediaTypes[RequestMediaType{Path: path, Method: method, MediaType: sent}] = true
+		return
+	}
+	for mediaType := range op.RequestBody.Content.KeysFromOldest() {
+		mt := RequestMediaType{Path: path, Method: method, MediaType: mediaType}
+		if _, ok := v.mediaTypes[mt]; ok && matchesMediaTypes(sent, mediaType) {
+			v.mediaTypes[mt] = true
+			return
+		}
+	}
+}
+
+// unusedRequestMediaTypeErrors returns an error for each media type that UnusedRequestMediaTypes lists.
+func (v *Verifier) unusedRequestMediaTypeErrors() []error {
+	unused := v.unusedRequestMediaTypes()
+	errs := make([]error, 0, len(unused))
+	for _, mt := range unused {
+		errs = append(errs, v.conf.withTemplate(joinError(ErrNotChecked,
+			fmt.Errorf("%s %s: request body is never sent as %s", mt.Method, mt.Path, mt.MediaType))))
+	}
+	return errs
+}
+
+func sortRequestMediaTypes(mediaTypes []RequestMediaType) {
+	slices.SortFunc(mediaTypes, func(a, b RequestMediaType) int {
+		return cmp.Or(cmp.Compare(a.Path, b.Path), cmp.Compare(a.Method, b.Method), cmp.Compare(a.MediaType, b.MediaType))
+	})
+}
@@ -0,0 +1,88 @@
+package copper
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestMediaTypeCoverage(t *testing.T) {
+	f, err := os.ReadFile("testdata/request-media-types-spec.yaml")
+	require.NoError(t, err)
+
+	s := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}),
+	)
+	defer s.Close()
+
+	post := func(t *testing.T, c *ValidatingClient, path, contentType, body string) {
+		_, err := c.Post(s.URL+path, contentType, strings.NewReader(body))
+		require.NoError(t, err)
+	}
+
+	t.Run("unused media types are reported", func(t *testing.T) {
+		c := MustWrapClient(http.DefaultClient, bytes.NewReader(f), WithRequestMediaTypeCoverage())
+		post(t, c, "/things", "application/json; charset=utf-8", `{}`)
+		post(t, c, "/single", "application/json", `{}`)
+
+		assert.Equal(t, []RequestMediaType{
+			{Path: "/things", Method: "POST", MediaType: "application/x-www-form-urlencoded"},
+			{Path: "/things", Method: "POST", MediaType: "image/*"},
+		}, c.UnusedRequestMediaTypes())
+
+		var notChecked []string
+		for _, err := range c.CurrentErrors() {
+			if errors.Is(err, ErrNotChecked) {
+				notChecked = append(notChecked, err.Error())
+			}
+		}
+		assert.Equal(t, []string{
+			"not checked: POST /things: request body is never sent as application/x-www-form-urlencoded",
+			"not checked: POST /things: request body is never sent as image/*",
+		}, notChecked)
+	})
+
+	t.Run("wildcards", func(t *testing.T) {
+		c := MustWrapClient(http.DefaultClient, bytes.NewReader(f), WithRequestMediaTypeCoverage())
+		post(t, c, "/things", "application/json", `{}`)
+		post(t, c, "/things", "application/x-www-form-urlencoded", `a=b`)
+		post(t, c, "/things", "image/png", "\x89PNG")
+		post(t, c, "/single", "application/json", `{}`)
+
+		assert.Empty(t, c.UnusedRequestMediaTypes())
+		c.Verify(t)
+	})
+
+	t.Run("without the option", func(t *testing.T) {
+		c := MustWrapClient(http.DefaultClient, bytes.NewReader(f), WithoutFullCoverage())
+		post(t, c, "/things", "application/json", `{}`)
+
+		assert.Len(t, c.UnusedRequestMediaTypes(), 2)
+		c.Verify(t)
+	})
+
+	t.Run("state", func(t *testing.T) {
+		c := MustWrapClient(http.DefaultClient, bytes.NewReader(f), WithRequestMediaTypeCoverage())
+		post(t, c, "/things", "application/json", `{}`)
+
+		data, err := json.Marshal(c.Verifier)
+		require.NoError(t, err)
+
+		restored := MustNewVerifier(f)
+		require.NoError(t, json.Unmarshal(data, restored))
+		assert.Equal(t, c.UnusedRequestMediaTypes(), restored.UnusedRequestMediaTypes())
+
+		restored.Reset()
+		assert.Len(t, restored.UnusedRequestMediaTypes(), 3)
+	})
+}
@@ -0,0 +1,199 @@
+package copper
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	validatorerr "github.com/pb33f/libopenapi-validator/errors"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+// WithSecurityValidation is a functional Option for checking the credentials of every request against the security
+// requirements of its operation, without validating the rest of the request like WithRequestValidation does. Requests
+// to protected operations must have the credentials of one of the requirements, like an API key in its header or query
+// parameter, an Authorization header with the bearer or basic scheme, or a bearer token for oauth2. Requests that carry
+// credentials to operations that declare no security are reported as well, since the server most likely ignores them.
+func WithSecurityValidation() Option {
+	return func(c *config) {
+		c.securityValidation = true
+	}
+}
+
+// checkSecurity checks the request against the security of the operation: that it has the credentials of one of the
+// requirements, that apiKey schemes are sent where they are documented, and the JWT claims when they are checked. With
+// WithSecurityValidation, credentials sent to operations without security are checked as well. With
+// WithAuthErrorCoverage, missing credentials are checked by checkAuthResponse instead, and the claims are not checked
+// for the responses that reject the credentials. The failures are reported with ErrSecurityViolation, apart from the
+// schema errors of the request.
+func (v *Verifier) checkSecurity(req *http.Request, op *v3.Operation, status int) error {
+	var errs []error
+	if !v.conf.authErrorCoverage {
+		errs = append(errs, checkCredentials(req, v.model, op))
+	}
+	errs = append(errs, checkAPIKeyLocations(req, v.model, op))
+	if v.conf.securityValidation {
+		errs = append(errs, checkUnexpectedCredentials(req, v.model, op))
+	}
+	if v.conf.jwtClaims && !(v.conf.authErrorCoverage && isAuthError(status)) {
+		errs = append(errs, v.checkJWTClaims(req, op))
+	}
+	return errors.Join(errs...)
+}
+
+// checkCredentials checks that the request has credentials for every scheme of at least one of the security
+// requirements of the operation. Unlike the validator library, it also applies the security of the document to
+// operations without their own.
+func checkCredentials(req *http.Request, doc *v3.Document, op *v3.Operation) error {
+	security := operationSecurity(doc, op)
+	if op == nil || len(security) == 0 {
+		return nil
+	}
+
+	var missing []string
+	for _, requirement := range security {
+		satisfied := true
+		for name := range requirement.Requirements.KeysFromOldest() {
+			var scheme *v3.SecurityScheme
+			if doc.Components != nil {
+				scheme = doc.Components.SecuritySchemes.GetOrZero(name)
+			}
+			if scheme == nil || !hasCredentials(req, scheme) {
+				satisfied = false
+				if !slices.Contains(missing, name) {
+					missing = append(missing, name)
+				}
+			}
+		}
+		if satisfied {
+			return nil
+		}
+	}
+	return fmt.Errorf("credentials are missing for the security schemes %s", strings.Join(missing, ", "))
+}
+
+// checkUnexpectedCredentials checks that the request has no credentials if the operation declares no security, either
+// with none at all or with only empty requirements. Credentials are recognised by the security schemes of the spec, and
+// any Authorization header counts as one.
+func checkUnexpectedCredentials(req *http.Request, doc *v3.Document, op *v3.Operation) error {
+	if op == nil {
+		return nil
+	}
+	for _, requirement := range operationSecurity(doc, op) {
+		if requirement.Requirements.Len() > 0 {
+			return nil
+		}
+	}
+
+	var sent []string
+	if doc.Components != nil {
+		for name, scheme := range doc.Components.SecuritySchemes.FromOldest() {
+			if hasCredentials(req, scheme) {
+				sent = append(sent, name)
+			}
+		}
+	}
+	switch {
+	case len(sent) > 0:
+		return fmt.Errorf("credentials are sent for the security schemes %s, but the operation has no security", strings.Join(sent, ", "))
+	case req.Header.Get("Authorization") != "":
+		return errors.New("an Authorization header is sent, but the operation has no security")
+	}
+	return nil
+}
+
+// withoutSecurityErrors removes the errors of the security validation of the validator library, which checkSecurity
+// does instead.
+func withoutSecurityErrors(errs []*validatorerr.ValidationError) []*validatorerr.ValidationError {
+	return slices.DeleteFunc(errs, func(err *validatorerr.ValidationError) bool {
+		return err.ValidationType == "security"
+	})
+}
+
+// operationSecurity returns the security requirements of the operation, which are those of the document unless the
+// operation has its own.
+func operationSecurity(doc *v3.Document, op *v3.Operation) []*base.SecurityRequirement {
+	if op != nil && op.Security != nil {
+		return op.Security
+	}
+	return doc.Security
+}
+
+// securitySchemes returns the security schemes that the requirements of the document and its operations use, none of
+// which are exercised yet.
+func securitySchemes(doc *v3.Document, f filters) map[string]bool {
+	schemes := make(map[string]bool)
+	add := func(security []*base.SecurityRequirement) {
+		for _, requirement := range security {
+			for name := range requirement.Requirements.KeysFromOldest() {
+				schemes[name] = false
+			}
+		}
+	}
+
+	add(doc.Security)
+	if doc.Paths != nil {
+		for path, item := range doc.Paths.PathItems.FromOldest() {
+			for method, op := range item.GetOperations().FromOldest() {
+				if _, excluded := f.excluded(path, strings.ToUpper(method), op); !excluded {
+					add(op.Security)
+				}
+			}
+		}
+	}
+	return schemes
+}
+
+// exerciseSecurity marks the security schemes of the operation that the request has credentials for as exercised.
+func (v *Verifier) exerciseSecurity(req *http.Request, op *v3.Operation) {
+	if op == nil || v.model.Components == nil {
+		return
+	}
+
+	for _, requirement := range operationSecurity(v.model, op) {
+		for name := range requirement.Requirements.KeysFromOldest() {
+			scheme := v.model.Components.SecuritySchemes.GetOrZero(name)
+			if scheme != nil && hasCredentials(req, scheme) {
+				v.schemes[name] = true
+			}
+		}
+	}
+}
+
+// hasCredentials checks if the request presents credentials for the security scheme, without checking if they are
+// valid.
+func hasCredentials(req *http.Request, scheme *v3.SecurityScheme) bool {
+	authorization := strings.ToLower(req.Header.Get("Authorization"))
+	switch strings.ToLower(scheme.Type) {
+	case "apikey":
+		return hasAPIKey(req, scheme.In, scheme.Name)
+	case "http":
+		return strings.HasPrefix(authorization, strings.ToLower(scheme.Scheme)+" ")
+	case "oauth2", "openidconnect":
+		return strings.HasPrefix(authorization, "bearer ")
+	case "mutualtls":
+		return req.TLS != nil && len(req.TLS.PeerCertificates) > 0
+	}
+	return false
+}
+
+// unexercisedSchemes returns an error for each security scheme that is used by the spec, but that no request has
+// presented credentials for, sorted by name.
+func (v *Verifier) unexercisedSchemes() []error {
+	var names []string
+	for name, exercised := range v.schemes {
+		if !exercised {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	errs := make([]error, 0, len(names))
+	for _, name := range names {
+		errs = append(errs, v.conf.withTemplate(joinError(ErrSecurityViolation, fmt.Errorf("security scheme %s is never exercised", name))))
+	}
+	return errs
+}
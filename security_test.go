@@ -0,0 +1,142 @@
+package copper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecurityViolation(t *testing.T) {
+	f, err := os.ReadFile("testdata/apikey-spec.yaml")
+	require.NoError(t, err)
+
+	t.Run("missing credentials", func(t *testing.T) {
+		v, err := NewVerifier(f, WithRequestValidation(), WithoutFullCoverage())
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/things", nil)
+		v.Record(&http.Response{StatusCode: http.StatusNoContent, Request: req})
+
+		assert.ErrorIs(t, v.CurrentError(), ErrSecurityViolation)
+		assert.NotErrorIs(t, v.CurrentError(), ErrRequestInvalid)
+		assert.ErrorContains(t, v.CurrentError(), "credentials are missing for the security schemes HeaderKey")
+	})
+
+	t.Run("without request validation", func(t *testing.T) {
+		v, err := NewVerifier(f, WithoutFullCoverage())
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/things", nil)
+		v.Record(&http.Response{StatusCode: http.StatusNoContent, Request: req})
+
+		assert.NoError(t, v.CurrentError())
+	})
+
+	tt := []struct {
+		name    string
+		targets []string
+		err     string
+	}{
+		{"all exercised", []string{"/things", "/optional?key=secret"}, ""},
+		{"query key never sent", []string{"/things", "/optional"}, "security violation: security scheme QueryKey is never exercised"},
+		{"key of another operation", []string{"/things?key=secret", "/optional"}, "security violation: security scheme QueryKey is never exercised"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			v, err := NewVerifier(f, WithRequestValidation())
+			require.NoError(t, err)
+
+			for _, target := range tc.targets {
+				req := httptest.NewRequest(http.MethodGet, target, nil)
+				req.Header.Set("X-API-Key", "secret")
+				v.Record(&http.Response{StatusCode: http.StatusNoContent, Request: req})
+			}
+
+			errs := v.CurrentErrors()
+			if tc.err == "" {
+				assert.Empty(t, errs)
+			} else {
+				require.NotEmpty(t, errs)
+				assert.ErrorIs(t, errs[len(errs)-1], ErrSecurityViolation)
+				assert.EqualError(t, errs[len(errs)-1], tc.err)
+			}
+		})
+	}
+}
+
+func TestSecurityValidation(t *testing.T) {
+	f, err := os.ReadFile("testdata/security-spec.yaml")
+	require.NoError(t, err)
+
+	tt := []struct {
+		name    string
+		method  string
+		target  string
+		headers map[string]string
+		err     string
+	}{
+		{"bearer", http.MethodGet, "/bearer", map[string]string{"Authorization": "Bearer abc"}, ""},
+		{"bearer missing", http.MethodGet, "/bearer", nil, "credentials are missing for the security schemes Bearer"},
+		{"basic instead of bearer", http.MethodGet, "/bearer", map[string]string{"Authorization": "Basic YTpi"}, "credentials are missing for the security schemes Bearer"},
+		{"basic of either", http.MethodGet, "/either", map[string]string{"Authorization": "Basic YTpi"}, ""},
+		{"api key of either", http.MethodGet, "/either", map[string]string{"X-API-Key": "secret"}, ""},
+		{"neither", http.MethodGet, "/either", nil, "credentials are missing for the security schemes Basic, HeaderKey"},
+		{"both", http.MethodGet, "/both?key=secret", map[string]string{"Authorization": "Basic YTpi"}, ""},
+		{"only one of both", http.MethodGet, "/both", map[string]string{"Authorization": "Basic YTpi"}, "credentials are missing for the security schemes QueryKey"},
+		{"oauth2", http.MethodPost, "/oauth", map[string]string{"Authorization": "Bearer abc"}, ""},
+		{"oauth2 missing", http.MethodPost, "/oauth", nil, "credentials are missing for the security schemes OAuth"},
+		{"optional without", http.MethodGet, "/optional", nil, ""},
+		{"optional with", http.MethodGet, "/optional", map[string]string{"Authorization": "Bearer abc"}, ""},
+		{"public", http.MethodGet, "/public", nil, ""},
+		{"public with bearer", http.MethodGet, "/public", map[string]string{"Authorization": "Bearer abc"}, "credentials are sent for the security schemes Bearer, OAuth, but the operation has no security"},
+		{"public with api keys", http.MethodGet, "/public?key=secret", map[string]string{"X-API-Key": "secret"}, "credentials are sent for the security schemes HeaderKey, QueryKey, but the operation has no security"},
+		{"public with unknown authorization", http.MethodGet, "/public", map[string]string{"Authorization": "Digest abc"}, "an Authorization header is sent, but the operation has no security"},
+		{"anonymous with bearer", http.MethodGet, "/anonymous", map[string]string{"Authorization": "Bearer abc"}, "credentials are sent for the security schemes Bearer"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			v, err := NewVerifier(f, WithSecurityValidation(), WithoutFullCoverage())
+			require.NoError(t, err)
+
+			// The body is not valid, which is only reported with request validation.
+			req := httptest.NewRequest(tc.method, tc.target, strings.NewReader(`{}`))
+			req.Header.Set("Content-Type", "application/json")
+			for k, val := range tc.headers {
+				req.Header.Set(k, val)
+			}
+			v.Record(&http.Response{StatusCode: http.StatusNoContent, Request: req})
+
+			if tc.err == "" {
+				assert.NoError(t, v.CurrentError())
+				return
+			}
+			assert.ErrorIs(t, v.CurrentError(), ErrSecurityViolation)
+			assert.ErrorContains(t, v.CurrentError(), tc.err)
+			assert.Len(t, v.CurrentErrors(), 1)
+		})
+	}
+
+	t.Run("schemes that are never exercised", func(t *testing.T) {
+		v, err := NewVerifier(f, WithSecurityValidation())
+		require.NoError(t, err)
+		assert.ErrorContains(t, v.CurrentError(), "security scheme Basic is never exercised")
+	})
+
+	t.Run("without security validation", func(t *testing.T) {
+		v, err := NewVerifier(f, WithRequestValidation(), WithoutFullCoverage())
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/public", nil)
+		req.Header.Set("Authorization", "Bearer abc")
+		v.Record(&http.Response{StatusCode: http.StatusNoContent, Request: req})
+
+		assert.NoError(t, v.CurrentError())
+	})
+}
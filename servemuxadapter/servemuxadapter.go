@@ -0,0 +1,41 @@
+// Package servemuxadapter records the requests that an http.ServeMux serves into a copper.Verifier, using the pattern
+// that the ServeMux matched to find the path in the spec.
+package servemuxadapter
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/callebjorkell/copper"
+	"github.com/callebjorkell/copper/internal/capture"
+)
+
+// Wrap returns a handler that serves the requests with the ServeMux, and records every request and the response to it
+// into the verifier. The ServeMux sets the pattern that it matched on the request, which is what the route is taken
+// from, so it has to be the handler that is wrapped rather than one of the handlers registered on it.
+func Wrap(v *copper.Verifier, mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := capture.BufferRequest(r); err != nil {
+			http.Error(w, "could not read request body", http.StatusBadRequest)
+			return
+		}
+
+		cw := capture.NewWriter(w)
+		mux.ServeHTTP(cw, r)
+
+		v.RecordRoute(cw.Response(r), route(r.Pattern))
+	})
+}
+
+// route converts a ServeMux pattern like "GET example.com/things/{id}" into a path template like /things/{id}, by
+// removing the method and the host, the marker for the end of the path, and the dots of wildcards for the rest of it.
+func route(pattern string) string {
+	if _, after, ok := strings.Cut(pattern, " "); ok {
+		pattern = strings.TrimLeft(after, " \t")
+	}
+	if i := strings.Index(pattern, "/"); i > 0 {
+		pattern = pattern[i:]
+	}
+	pattern = strings.TrimSuffix(pattern, "{$}")
+	return strings.ReplaceAll(pattern, "...}", "}")
+}
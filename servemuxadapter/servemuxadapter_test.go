@@ -0,0 +1,44 @@
+package servemuxadapter
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/callebjorkell/copper"
+	"github.com/callebjorkell/copper/internal/routertest"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMux(v *copper.Verifier) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/things/export", routertest.Export)
+	mux.HandleFunc("GET /api/things/{thingID}", routertest.GetThing)
+	mux.HandleFunc("PUT /api/things/{thingID}", routertest.PutThing)
+	return Wrap(v, mux)
+}
+
+func TestWrap(t *testing.T) {
+	routertest.Test(t, routertest.Middleware(newMux), http.StatusMethodNotAllowed)
+
+	t.Run("the most specific pattern is matched", func(t *testing.T) {
+		router := routertest.Middleware(newMux)
+		assert.Equal(t, []string{"/things/export"}, routertest.Matched(t, router, http.MethodGet, "/api/things/export"))
+		assert.Equal(t, []string{"/things/{id}"}, routertest.Matched(t, router, http.MethodGet, "/api/things/abc"))
+	})
+}
+
+func TestRoute(t *testing.T) {
+	tt := map[string]string{
+		"/things/{id}":                "/things/{id}",
+		"GET /things/{id}":            "/things/{id}",
+		"GET example.com/things/{id}": "/things/{id}",
+		"example.com/things/{id}":     "/things/{id}",
+		"GET /things/{$}":             "/things/",
+		"GET /files/{path...}":        "/files/{path}",
+		"":                            "",
+	}
+
+	for pattern, want := range tt {
+		assert.Equal(t, want, route(pattern), pattern)
+	}
+}
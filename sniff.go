@@ -0,0 +1,94 @@
+package copper
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// WithContentSniffing is a functional Option for checking that the body of a response is the kind of content that its
+// Content-Type header says, by sniffing it as JSON, HTML, other text or binary data. This catches the classic error page
+// served as application/json, which the schema validation otherwise reports as a body that can not be decoded, or not
+// at all for content without a schema.
+func WithContentSniffing() Option {
+	return func(c *config) {
+		c.contentSniffing = true
+	}
+}
+
+// contentKind is a rough kind of content, which is what the sniffing compares.
+type contentKind string
+
+const (
+	kindJSON   contentKind = "JSON"
+	kindHTML   contentKind = "HTML"
+	kindText   contentKind = "text"
+	kindBinary contentKind = "binary data"
+)
+
+// checkSniffedContent checks the body of the response against the kind of its Content-Type. Responses without a body
+// or a Content-Type have nothing to compare.
+func checkSniffedContent(res *http.Response, body []byte) error {
+	contentType := res.Header.Get("Content-Type")
+	if len(body) == 0 || contentType == "" {
+		return nil
+	}
+
+	declared, ok := declaredKind(contentType)
+	if !ok {
+		return nil
+	}
+	sniffed := sniffKind(body)
+
+	switch {
+	case declared == sniffed:
+		return nil
+	case declared == kindText && (sniffed == kindJSON || sniffed == kindHTML):
+		// JSON and HTML are text as well.
+		return nil
+	case declared == kindBinary && sniffed != kindHTML:
+		// Binary formats can look like anything, but an HTML page is not one of them.
+		return nil
+	}
+	return fmt.Errorf("Content-Type is %s, but the body looks like %s", contentType, sniffed)
+}
+
+// declaredKind returns the kind of content that the media type is for. Media types that can not be told apart from
+// their content, like multipart or form data, return false.
+func declaredKind(contentType string) (contentKind, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", false
+	}
+
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return kindJSON, true
+	case mediaType == "text/html" || mediaType == "application/xhtml+xml":
+		return kindHTML, true
+	case strings.HasPrefix(mediaType, "text/"):
+		return kindText, true
+	case mediaType == "application/octet-stream" || strings.HasPrefix(mediaType, "image/") ||
+		strings.HasPrefix(mediaType, "audio/") || strings.HasPrefix(mediaType, "video/"):
+		return kindBinary, true
+	}
+	return "", false
+}
+
+// sniffKind returns the kind of content that the body looks like.
+func sniffKind(body []byte) contentKind {
+	if json.Valid(body) {
+		return kindJSON
+	}
+
+	sniffed, _, _ := mime.ParseMediaType(http.DetectContentType(body))
+	switch {
+	case sniffed == "text/html":
+		return kindHTML
+	case strings.HasPrefix(sniffed, "text/"):
+		return kindText
+	}
+	return kindBinary
+}
@@ -0,0 +1,70 @@
+package copper
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContentSniffing(t *testing.T) {
+	f, err := os.ReadFile("testdata/sniff-spec.yaml")
+	require.NoError(t, err)
+
+	const page = "<!DOCTYPE html><html><body>Internal error</body></html>"
+
+	tt := []struct {
+		name        string
+		contentType string
+		body        string
+		err         string
+	}{
+		{"JSON", "application/json", `{"name": "thing"}`, ""},
+		{"HTML", "text/html; charset=utf-8", page, ""},
+		{"JSON as text", "text/plain", `{"name": "thing"}`, ""},
+		{"binary", "application/octet-stream", "\x00\x01\x02", ""},
+		{"HTML as JSON", "application/json", page, "Content-Type is application/json, but the body looks like HTML"},
+		{"JSON as HTML", "text/html", `{"name": "thing"}`, "Content-Type is text/html, but the body looks like JSON"},
+		{"binary as text", "text/plain", "\x00\x01\x02", "Content-Type is text/plain, but the body looks like binary data"},
+		{"HTML as binary", "application/octet-stream", page, "the body looks like HTML"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			v, err := NewVerifier(f, WithContentSniffing(), WithoutFullCoverage())
+			require.NoError(t, err)
+
+			v.Record(&http.Response{
+				StatusCode: http.StatusOK,
+				Request:    httptest.NewRequest(http.MethodGet, "/thing", nil),
+				Header:     http.Header{"Content-Type": []string{tc.contentType}},
+				Body:       io.NopCloser(strings.NewReader(tc.body)),
+			})
+
+			if tc.err == "" {
+				assert.NoError(t, v.CurrentError())
+			} else {
+				assert.ErrorIs(t, v.CurrentError(), ErrResponseInvalid)
+				assert.ErrorContains(t, v.CurrentError(), tc.err)
+			}
+		})
+	}
+
+	t.Run("off by default", func(t *testing.T) {
+		v, err := NewVerifier(f, WithoutFullCoverage())
+		require.NoError(t, err)
+
+		v.Record(&http.Response{
+			StatusCode: http.StatusOK,
+			Request:    httptest.NewRequest(http.MethodGet, "/thing", nil),
+			Header:     http.Header{"Content-Type": []string{"text/html"}},
+			Body:       io.NopCloser(strings.NewReader(`{"name": "thing"}`)),
+		})
+		assert.NoError(t, v.CurrentError())
+	})
+}
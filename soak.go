@@ -0,0 +1,130 @@
+package copper
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// WithErrorWindow is a functional Option for keeping only the size most recent errors, for Verifiers that validate
+// traffic for days, like in a proxy or a monitor, where the errors would otherwise grow without bounds. Older errors are
+// dropped, but still counted by their sentinel, which ErrorCounts and the Summary include. The errors that are kept for
+// every coordinate are limited to the same size, while EndpointStatus still counts all of them.
+func WithErrorWindow(size int) Option {
+	return func(c *config) {
+		if size < 1 {
+			c.conflicts = append(c.conflicts, fmt.Errorf("WithErrorWindow is given %d, but the size must be at least 1", size))
+		}
+		c.errorWindow = size
+	}
+}
+
+// WithSnapshots is a functional Option for writing the state of the Verifier, as MarshalJSON has it, to the file at the
+// path whenever a response is recorded and the last snapshot is older than the interval. No goroutine is started, so a
+// Verifier that does not record anything does not write anything either. The file is replaced atomically, so that it
+// can always be read, and restored with json.Unmarshal after a restart. Snapshots that can not be written are logged to
+// the logger of WithRequestLogging, if there is one, and tried again with the next response.
+func WithSnapshots(path string, interval time.Duration) Option {
+	return func(c *config) {
+		if path == "" {
+			c.conflicts = append(c.conflicts, errors.New("WithSnapshots is given an empty path"))
+		}
+		if interval <= 0 {
+			c.conflicts = append(c.conflicts, fmt.Errorf("WithSnapshots is given %v, but the interval must be above 0", interval))
+		}
+		c.snapshotPath = path
+		c.snapshotInterval = interval
+	}
+}
+
+// ErrorCounts returns how many errors have been found of each sentinel, including the ones that WithErrorWindow has
+// dropped, but not the errors for coverage, which follow from the state.
+func (v *Verifier) ErrorCounts() map[SentinelError]int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	counts := make(map[SentinelError]int)
+	for sentinel, n := range v.dropped {
+		counts[sentinel] += n
+	}
+	for _, err := range v.errors {
+		var verr *VerificationError
+		if errors.As(err, &verr) {
+			counts[verr.sentinel]++
+		}
+	}
+	return counts
+}
+
+// Snapshot writes the state of the Verifier to the file at the path, as MarshalJSON has it. The file is written next to
+// the path first and then renamed, so that a reader never sees half of it.
+func (v *Verifier) Snapshot(path string) error {
+	data, err := v.MarshalJSON()
+	if err != nil {
+		return fmt.Errorf("could not write snapshot: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("could not write snapshot: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("could not write snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("could not write snapshot: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("could not write snapshot: %w", err)
+	}
+	return nil
+}
+
+// snapshotIfDue writes a snapshot if WithSnapshots is given and the interval has passed since the last one.
+func (v *Verifier) snapshotIfDue(now time.Time) {
+	v.mu.Lock()
+	path, logger := v.conf.snapshotPath, v.conf.requestLogger
+	due := path != "" && now.Sub(v.lastSnapshot) >= v.conf.snapshotInterval
+	if due {
+		v.lastSnapshot = now
+	}
+	v.mu.Unlock()
+
+	if !due {
+		return
+	}
+	if err := v.Snapshot(path); err != nil {
+		// The snapshot is tried again with the next response, rather than after another interval.
+		v.mu.Lock()
+		v.lastSnapshot = time.Time{}
+		v.mu.Unlock()
+		if logger != nil {
+			logger.Logf("copper: %v", err)
+		}
+	}
+}
+
+// trimErrors drops the oldest errors beyond the window, and counts them by their sentinel.
+func (v *Verifier) trimErrors() {
+	excess := len(v.errors) - v.conf.errorWindow
+	if v.conf.errorWindow == 0 || excess <= 0 {
+		return
+	}
+
+	if v.dropped == nil {
+		v.dropped = make(map[SentinelError]int)
+	}
+	for _, err := range v.errors[:excess] {
+		var verr *VerificationError
+		if errors.As(err, &verr) {
+			v.dropped[verr.sentinel]++
+		}
+	}
+	clear(v.errors[:excess])
+	v.errors = v.errors[excess:]
+}
@@ -0,0 +1,98 @@
+package copper
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithErrorWindow(t *testing.T) {
+	f, err := os.ReadFile("testdata/minimal-spec.yaml")
+	require.NoError(t, err)
+
+	var handled []*VerificationError
+	v := MustNewVerifier(f, WithErrorWindow(2), WithoutFullCoverage(),
+		WithViolationHandler(func(err *VerificationError) { handled = append(handled, err) }))
+	record := func(path string, code int) {
+		v.Record(&http.Response{
+			StatusCode: code,
+			Header:     http.Header{},
+			Body:       http.NoBody,
+			Request:    httptest.NewRequest(http.MethodGet, path, nil),
+		})
+	}
+
+	record("/missing", http.StatusNoContent)
+	for range 3 {
+		record("/ping", http.StatusOK)
+	}
+	assert.Len(t, handled, 4, "every error is handled, even the ones that are dropped later")
+
+	errs := v.CurrentErrors()
+	if assert.Len(t, errs, 2) {
+		assert.ErrorIs(t, errs[0], ErrResponseInvalid)
+		assert.ErrorIs(t, errs[1], ErrResponseInvalid)
+	}
+	assert.Equal(t, map[SentinelError]int{ErrNotPartOfSpec: 1, ErrResponseInvalid: 3}, v.ErrorCounts())
+	assert.Equal(t, 4, v.Summary().Violations)
+
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	restored := MustNewVerifier(f, WithErrorWindow(2), WithoutFullCoverage())
+	require.NoError(t, json.Unmarshal(data, restored))
+	assert.Equal(t, v.ErrorCounts(), restored.ErrorCounts())
+
+	v.Reset()
+	assert.Empty(t, v.ErrorCounts())
+
+	_, err = NewVerifier(f, WithErrorWindow(0))
+	assert.ErrorIs(t, err, ErrInvalidOptions)
+}
+
+func TestWithSnapshots(t *testing.T) {
+	f, err := os.ReadFile("testdata/minimal-spec.yaml")
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "state.json")
+	v := MustNewVerifier(f, WithSnapshots(path, time.Hour))
+	record := func() {
+		v.Record(&http.Response{
+			StatusCode: http.StatusNoContent,
+			Header:     http.Header{},
+			Body:       http.NoBody,
+			Request:    httptest.NewRequest(http.MethodGet, "/ping", nil),
+		})
+	}
+
+	_, err = os.Stat(path)
+	assert.ErrorIs(t, err, os.ErrNotExist, "nothing is written before anything is recorded")
+
+	record()
+	first, err := os.ReadFile(path)
+	require.NoError(t, err)
+	restored := MustNewVerifier(f)
+	require.NoError(t, json.Unmarshal(first, restored))
+	assert.NoError(t, restored.CurrentError())
+
+	// The next snapshot is not due until the interval has passed.
+	record()
+	second, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temporary files are left behind")
+
+	for _, opt := range []Option{WithSnapshots("", time.Minute), WithSnapshots(path, 0)} {
+		_, err := NewVerifier(f, opt)
+		assert.ErrorIs(t, err, ErrInvalidOptions)
+	}
+}
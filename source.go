@@ -0,0 +1,119 @@
+//go:build !copper_lite
+
+package copper
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/pb33f/libopenapi/bundler"
+	"github.com/pb33f/libopenapi/datamodel"
+)
+
+// NewVerifierFromFile is like NewVerifier, but reads the spec from a file. References to other files are resolved
+// relative to the directory of the spec, so a spec that is split into several files can be used as it is, without
+// bundling it first.
+func NewVerifierFromFile(path string, opts ...Option) (*Verifier, error) {
+	specBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read spec: %w", err)
+	}
+
+	docConf := datamodel.NewDocumentConfiguration()
+	docConf.BasePath = filepath.Dir(path)
+	docConf.SpecFilePath = filepath.Base(path)
+	docConf.AllowFileReferences = true
+	return newVerifierWithReferences(specBytes, docConf, opts...)
+}
+
+// NewVerifierFromURL is like NewVerifier, but downloads the spec from a URL, like the /openapi.json of a running
+// service. References to other documents are resolved relative to the URL, and downloaded the same way. The spec is
+// downloaded with the TLS configuration of WithTLSConfig or WithInsecureTLS, if one is given, so that it can be served
+// by the service under test.
+func NewVerifierFromURL(specURL string, opts ...Option) (*Verifier, error) {
+	conf := getConfig(opts...)
+	base, err := url.Parse(specURL)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse spec URL: %w", err)
+	}
+	client, err := withTLSConfig(http.DefaultClient, conf.tlsConfig)
+	if err != nil {
+		return nil, fmt.Errorf("could not create client for spec: %w", err)
+	}
+	get := func(u string) (*http.Response, error) {
+		res, err := client.Get(u)
+		if err == nil && res.StatusCode != http.StatusOK {
+			res.Body.Close()
+			return nil, fmt.Errorf("could not download %s: %s", u, res.Status)
+		}
+		return res, err
+	}
+
+	res, err := get(specURL)
+	if err != nil {
+		return nil, fmt.Errorf("could not read spec: %w", err)
+	}
+	defer res.Body.Close()
+	specBytes, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("could not read spec: %w", err)
+	}
+
+	docConf := datamodel.NewDocumentConfiguration()
+	docConf.BaseURL = base.ResolveReference(&url.URL{Path: "."})
+	if docConf.BaseURL.Path == "/" {
+		// The trailing slash is trimmed from the base URL when references are resolved, which leaves an empty path for
+		// a spec at the root that references are then resolved against the working directory for.
+		docConf.BaseURL.Path = "/."
+	}
+	docConf.AllowRemoteReferences = true
+	docConf.RemoteURLHandler = get
+	return newVerifierWithReferences(specBytes, docConf, opts...)
+}
+
+// WrapClientFromFile is like WrapClient, but reads the spec from a file like NewVerifierFromFile.
+func WrapClientFromFile(c *http.Client, path string, opts ...Option) (*ValidatingClient, error) {
+	verifier, err := NewVerifierFromFile(path, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("could not create verifier: %w", err)
+	}
+	return newValidatingClient(c, verifier)
+}
+
+// WrapClientFromURL is like WrapClient, but downloads the spec from a URL like NewVerifierFromURL.
+func WrapClientFromURL(c *http.Client, specURL string, opts ...Option) (*ValidatingClient, error) {
+	verifier, err := NewVerifierFromURL(specURL, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("could not create verifier: %w", err)
+	}
+	return newValidatingClient(c, verifier)
+}
+
+// newVerifierWithReferences returns a new Verifier for the spec, with the references to other documents inlined the
+// way that Bundle does. A Swagger 2.0 spec is converted first, but the documents that it references are not.
+func newVerifierWithReferences(specBytes []byte, docConf *datamodel.DocumentConfiguration, opts ...Option) (
+	*Verifier, error,
+) {
+	conf := getConfig(opts...)
+	if err := conf.validate(); err != nil {
+		return nil, err
+	}
+
+	specBytes, err := convertSwagger2(specBytes, conf.swagger2)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse spec data: %w", err)
+	}
+	docConf.ExtractRefsSequentially = true
+	// The references that can not be resolved are returned as errors, and logged to stdout otherwise.
+	docConf.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	bundled, err := bundler.BundleBytes(specBytes, docConf)
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve references of spec: %w", err)
+	}
+	return newVerifier(bundled, conf)
+}
@@ -0,0 +1,121 @@
+//go:build !copper_lite
+
+package copper
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewVerifierFromSource(t *testing.T) {
+	pong := func(body string) *http.Response {
+		return &http.Response{
+			StatusCode: 200,
+			Request:    httptest.NewRequest(http.MethodGet, "/ping", nil),
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}
+	}
+	files := httptest.NewServer(http.FileServer(http.Dir("testdata/bundle")))
+	defer files.Close()
+
+	sources := []struct {
+		name string
+		new  func() (*Verifier, error)
+	}{
+		{"file", func() (*Verifier, error) { return NewVerifierFromFile("testdata/bundle/openapi.yaml") }},
+		{"URL", func() (*Verifier, error) { return NewVerifierFromURL(files.URL + "/openapi.yaml") }},
+	}
+
+	for _, src := range sources {
+		t.Run(src.name, func(t *testing.T) {
+			t.Run("external refs are resolved", func(t *testing.T) {
+				v, err := src.new()
+				require.NoError(t, err)
+
+				v.Record(pong(`{"message": 2}`))
+				assert.ErrorIs(t, v.CurrentError(), ErrResponseInvalid)
+			})
+
+			t.Run("valid response", func(t *testing.T) {
+				v, err := src.new()
+				require.NoError(t, err)
+
+				v.Record(pong(`{"message": "pong"}`))
+				assert.NoError(t, v.CurrentError())
+			})
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := NewVerifierFromFile("testdata/bundle/missing.yaml")
+		assert.ErrorContains(t, err, "could not read spec")
+	})
+
+	t.Run("missing URL", func(t *testing.T) {
+		_, err := NewVerifierFromURL(files.URL + "/missing.yaml")
+		assert.ErrorContains(t, err, "404 Not Found")
+	})
+
+	t.Run("missing reference", func(t *testing.T) {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/openapi.yaml", func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, "testdata/bundle/openapi.yaml")
+		})
+		s := httptest.NewServer(mux)
+		defer s.Close()
+
+		_, err := NewVerifierFromURL(s.URL + "/openapi.yaml")
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		_, err := NewVerifierFromFile("testdata/bundle/openapi.yaml", WithMaxDepth(0))
+		assert.ErrorIs(t, err, ErrInvalidOptions)
+	})
+
+	t.Run("Swagger 2.0", func(t *testing.T) {
+		v, err := NewVerifierFromFile("testdata/swagger2-spec.yaml")
+		require.NoError(t, err)
+		assert.Len(t, v.Endpoints(), 5)
+	})
+}
+
+func TestWrapClientFromSource(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle("/openapi.yaml", http.FileServer(http.Dir("testdata/bundle")))
+	mux.Handle("/schemas/", http.FileServer(http.Dir("testdata/bundle")))
+	mux.HandleFunc("/ping", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"message": "pong"}`))
+	})
+	s := httptest.NewTLSServer(mux)
+	defer s.Close()
+
+	t.Run("URL of the service under test", func(t *testing.T) {
+		_, err := WrapClientFromURL(http.DefaultClient, s.URL+"/openapi.yaml")
+		assert.Error(t, err, "the certificate of the server is not trusted")
+
+		c, err := WrapClientFromURL(&http.Client{}, s.URL+"/openapi.yaml", WithInsecureTLS())
+		require.NoError(t, err)
+
+		_, err = c.Get(s.URL + "/ping")
+		require.NoError(t, err)
+		c.Verify(t)
+	})
+
+	t.Run("file", func(t *testing.T) {
+		c, err := WrapClientFromFile(&http.Client{}, "testdata/bundle/openapi.yaml", WithInsecureTLS())
+		require.NoError(t, err)
+
+		_, err = c.Get(s.URL + "/ping")
+		require.NoError(t, err)
+		c.Verify(t)
+	})
+}
@@ -0,0 +1,302 @@
+package copper
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+)
+
+// sentinels are the sentinels that errors can have, by their message, for restoring errors from JSON.
+var sentinels = map[string]SentinelError{
+	ErrNotChecked.msg:            ErrNotChecked,
+	ErrNotPartOfSpec.msg:         ErrNotPartOfSpec,
+	ErrResponseInvalid.msg:       ErrResponseInvalid,
+	ErrRequestInvalid.msg:        ErrRequestInvalid,
+	ErrUnsupportedBodyFormat.msg: ErrUnsupportedBodyFormat,
+	ErrSecurityViolation.msg:     ErrSecurityViolation,
+}
+
+// stateJSON is the coverage and the errors of a Verifier, as they are written to JSON.
+type stateJSON struct {
+	// Checked are the coordinates that have been checked, with their traffic.
+	Checked []checkedJSON `json:"checked"`
+	// Errors are the errors found in the recorded traffic, in the order they were found.
+	Errors []errorJSON `json:"errors"`
+	// Schemes are the security schemes that requests have presented credentials for.
+	Schemes []string `json:"exercisedSchemes"`
+	// MediaTypes are the request media types of operations that accept several, that requests have been sent with.
+	MediaTypes []RequestMediaType `json:"sentRequestMediaTypes,omitempty"`
+	// Dropped counts the errors that the error window has dropped, by their sentinel.
+	Dropped map[string]int `json:"droppedErrors,omitempty"`
+}
+
+type checkedJSON struct {
+	Path         string    `json:"path"`
+	Method       string    `json:"method"`
+	ResponseCode string    `json:"responseCode"`
+	Hits         int       `json:"hits"`
+	FirstSeen    time.Time `json:"firstSeen"`
+	LastSeen     time.Time `json:"lastSeen"`
+}
+
+type errorJSON struct {
+	Sentinel string `json:"sentinel"`
+	Message  string `json:"message"`
+	// Endpoint is the coordinate that the error is counted as a failure of, if any.
+	Endpoint *Endpoint `json:"endpoint,omitempty"`
+	// RequestID is the ID of the request that the error was found in, if any.
+	RequestID string `json:"requestId,omitempty"`
+	// Kind is what the error was found for, if it was found in an exchange.
+	Kind FailureKind `json:"kind,omitempty"`
+}
+
+// MarshalJSON writes the coverage and the errors of the Verifier as JSON, but not the spec or the options, so that the
+// state can be stored with the artifacts of a test run and restored with UnmarshalJSON. Errors for coverage are not
+// part of the state, since they follow from it, and neither are the links that are waiting to be followed.
+func (v *Verifier) MarshalJSON() ([]byte, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	s := stateJSON{Checked: []checkedJSON{}, Errors: []errorJSON{}, Schemes: []string{}}
+	for _, e := range v.endpoints.All() {
+		if !v.endpoints.IsChecked(e.Path, e.Method, e.ResponseCode) {
+			continue
+		}
+		hits, first, last := v.endpoints.Traffic(e)
+		s.Checked = append(s.Checked, checkedJSON{
+			Path:         e.Path,
+			Method:       e.Method,
+			ResponseCode: e.ResponseCode,
+			Hits:         hits,
+			FirstSeen:    first,
+			LastSeen:     last,
+		})
+	}
+
+	failed := make(map[error]Endpoint)
+	for c, errs := range v.endpoints.failures {
+		for _, err := range errs {
+			failed[err] = Endpoint{Path: c.path, Method: c.method, ResponseCode: c.responseCode}
+		}
+	}
+	for _, err := range v.errors {
+		var verr *VerificationError
+		if !errors.As(err, &verr) {
+			continue
+		}
+		e := errorJSON{Sentinel: verr.sentinel.msg, Message: verr.err.Error(), RequestID: verr.requestID, Kind: verr.kind}
+		if end, ok := failed[err]; ok {
+			e.Endpoint = &end
+		}
+		s.Errors = append(s.Errors, e)
+	}
+
+	for name, exercised := range v.schemes {
+		if exercised {
+			s.Schemes = append(s.Schemes, name)
+		}
+	}
+	slices.Sort(s.Schemes)
+
+	for mt, used := range v.mediaTypes {
+		if used {
+			s.MediaTypes = append(s.MediaTypes, mt)
+		}
+	}
+	sortRequestMediaTypes(s.MediaTypes)
+
+	for sentinel, n := range v.dropped {
+		if s.Dropped == nil {
+			s.Dropped = make(map[string]int)
+		}
+		s.Dropped[sentinel.msg] = n
+	}
+
+	return json.Marshal(s)
+}
+
+// UnmarshalJSON replaces the coverage and the errors of the Verifier with the ones written by MarshalJSON, which have
+// to be for the same spec. The state is shared with views created by With, so it is replaced for them as well.
+func (v *Verifier) UnmarshalJSON(data []byte) error {
+	var s stateJSON
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("could not read state: %w", err)
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	r, err := v.restoreState(s)
+	if err != nil {
+		return err
+	}
+	v.endpoints = r.endpoints
+	v.errors = r.errors
+	v.links = newLinks(v.model)
+	v.schemes = r.schemes
+	v.mediaTypes = r.mediaTypes
+	v.drift = nil
+	v.dropped = r.dropped
+	return nil
+}
+
+// ExportState writes the coverage and the errors of the Verifier as JSON, like MarshalJSON, so that the state of
+// separate test packages or CI shards can be combined with MergeState.
+func (v *Verifier) ExportState(w io.Writer) error {
+	b, err := v.MarshalJSON()
+	if err != nil {
+		return fmt.Errorf("could not write state: %w", err)
+	}
+	if _, err := w.Write(append(b, '\n')); err != nil {
+		return fmt.Errorf("could not write state: %w", err)
+	}
+	return nil
+}
+
+// MergeState adds the coverage and the errors of a state written by ExportState, which has to be for the same spec, to
+// the ones of the Verifier, rather than replacing them like UnmarshalJSON does. The hits of coordinates are summed, and
+// the errors are appended in the order of the state, so that full coverage can be verified once over the union of the
+// traffic of several test packages or CI shards. Nothing is merged if the state can not be read.
+func (v *Verifier) MergeState(r io.Reader) error {
+	var s stateJSON
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return fmt.Errorf("could not read state: %w", err)
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	restored, err := v.restoreState(s)
+	if err != nil {
+		return err
+	}
+
+	for c := range restored.endpoints.traffic {
+		if !v.endpoints.Has(c.path, c.method, c.responseCode) {
+			return fmt.Errorf("could not merge state: %s %s %s is not part of the spec", c.method, c.path, c.responseCode)
+		}
+	}
+	for c, t := range restored.endpoints.traffic {
+		v.endpoints.responseMap(c.path, c.method)[c.responseCode] = true
+		merged, ok := v.endpoints.traffic[c]
+		if !ok {
+			v.endpoints.traffic[c] = t
+			continue
+		}
+		merged.hits += t.hits
+		if merged.first.IsZero() || !t.first.IsZero() && t.first.Before(merged.first) {
+			merged.first = t.first
+		}
+		if t.last.After(merged.last) {
+			merged.last = t.last
+		}
+	}
+
+	failed := make(map[error]Endpoint)
+	for c, errs := range restored.endpoints.failures {
+		for _, err := range errs {
+			failed[err] = Endpoint{Path: c.path, Method: c.method, ResponseCode: c.responseCode}
+		}
+	}
+	for _, err := range restored.errors {
+		if end, ok := failed[err]; ok {
+			v.endpoints.AddFailure(end, err)
+		}
+		v.errors = append(v.errors, err)
+		v.appended++
+	}
+	v.trimErrors()
+
+	for sentinel, n := range restored.dropped {
+		if v.dropped == nil {
+			v.dropped = make(map[SentinelError]int)
+		}
+		v.dropped[sentinel] += n
+	}
+	for name, exercised := range restored.schemes {
+		if exercised {
+			v.schemes[name] = true
+		}
+	}
+	for mt, used := range restored.mediaTypes {
+		if used {
+			v.mediaTypes[mt] = true
+		}
+	}
+	return nil
+}
+
+// restoredState is a state read from JSON, for the spec of the Verifier that restored it.
+type restoredState struct {
+	endpoints  *endpoints
+	errors     []error
+	dropped    map[SentinelError]int
+	schemes    map[string]bool
+	mediaTypes map[RequestMediaType]bool
+}
+
+// restoreState returns the state for the spec and the options of the Verifier, or an error if it has coordinates that
+// are not part of the spec, or sentinels that are not known. It must be called with the lock held.
+func (v *Verifier) restoreState(s stateJSON) (restoredState, error) {
+	end := newEndpoints(v.model, v.endpoints.conf)
+	for _, c := range s.Checked {
+		if !end.Has(c.Path, c.Method, c.ResponseCode) {
+			return restoredState{}, fmt.Errorf("could not read state: %s %s %s is not part of the spec", c.Method, c.Path, c.ResponseCode)
+		}
+		end.responseMap(c.Path, c.Method)[c.ResponseCode] = true
+		end.traffic[coordinate{path: c.Path, method: c.Method, responseCode: c.ResponseCode}] = &traffic{
+			hits:  c.Hits,
+			first: c.FirstSeen,
+			last:  c.LastSeen,
+		}
+	}
+
+	errs := make([]error, 0, len(s.Errors))
+	for _, e := range s.Errors {
+		sentinel, ok := sentinels[e.Sentinel]
+		if !ok {
+			return restoredState{}, fmt.Errorf("could not read state: unknown sentinel %q", e.Sentinel)
+		}
+		verr := v.conf.withTemplate(joinError(sentinel, errors.New(e.Message)))
+		verr.requestID, verr.kind = e.RequestID, e.Kind
+		errs = append(errs, verr)
+		if e.Endpoint != nil {
+			verr.endpoint = *e.Endpoint
+			end.AddFailure(*e.Endpoint, verr)
+		}
+	}
+
+	dropped := make(map[SentinelError]int)
+	for msg, n := range s.Dropped {
+		sentinel, ok := sentinels[msg]
+		if !ok {
+			return restoredState{}, fmt.Errorf("could not read state: unknown sentinel %q", msg)
+		}
+		dropped[sentinel] = n
+	}
+
+	schemes := securitySchemes(v.model, v.endpoints.conf.filters)
+	for _, name := range s.Schemes {
+		if _, ok := schemes[name]; ok {
+			schemes[name] = true
+		}
+	}
+
+	mediaTypes := requestMediaTypes(v.model, v.endpoints.conf.filters)
+	for _, mt := range s.MediaTypes {
+		if _, ok := mediaTypes[mt]; ok {
+			mediaTypes[mt] = true
+		}
+	}
+
+	return restoredState{
+		endpoints:  end,
+		errors:     errs,
+		dropped:    dropped,
+		schemes:    schemes,
+		mediaTypes: mediaTypes,
+	}, nil
+}
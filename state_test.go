@@ -0,0 +1,163 @@
+package copper
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateJSON(t *testing.T) {
+	f, err := os.ReadFile("testdata/apikey-spec.yaml")
+	require.NoError(t, err)
+
+	v, err := NewVerifier(f, WithRequestValidation())
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/things", nil)
+	req.Header.Set("X-API-Key", "secret")
+	v.Record(&http.Response{StatusCode: http.StatusNoContent, Request: req})
+	v.Record(&http.Response{StatusCode: http.StatusNoContent, Request: httptest.NewRequest(http.MethodGet, "/things", nil)})
+	v.Record(&http.Response{StatusCode: http.StatusNoContent, Request: httptest.NewRequest(http.MethodGet, "/missing", nil)})
+
+	b, err := json.Marshal(v)
+	require.NoError(t, err)
+
+	t.Run("restored", func(t *testing.T) {
+		restored, err := NewVerifier(f, WithRequestValidation())
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(b, restored))
+
+		assert.Equal(t, withoutTimes(v.Endpoints()), withoutTimes(restored.Endpoints()))
+		for i, e := range v.Endpoints() {
+			assert.True(t, e.FirstSeen.Equal(restored.Endpoints()[i].FirstSeen))
+			assert.True(t, e.LastSeen.Equal(restored.Endpoints()[i].LastSeen))
+		}
+		assert.Equal(t, v.Unchecked(), restored.Unchecked())
+		assert.Equal(t, v.Summary(), restored.Summary())
+
+		again, err := json.Marshal(restored)
+		require.NoError(t, err)
+		assert.JSONEq(t, string(b), string(again))
+	})
+
+	t.Run("replaced", func(t *testing.T) {
+		other, err := NewVerifier(f, WithRequestValidation())
+		require.NoError(t, err)
+		other.Record(&http.Response{StatusCode: http.StatusNoContent, Request: httptest.NewRequest(http.MethodGet, "/optional?key=secret", nil)})
+		require.NoError(t, json.Unmarshal(b, other))
+
+		assert.Equal(t, v.Unchecked(), other.Unchecked())
+		assert.Equal(t, v.CurrentErrors()[len(v.CurrentErrors())-1].Error(), "security violation: security scheme QueryKey is never exercised")
+		assert.Equal(t, len(v.CurrentErrors()), len(other.CurrentErrors()))
+	})
+
+	t.Run("another spec", func(t *testing.T) {
+		thing, err := os.ReadFile("testdata/thing-spec.yaml")
+		require.NoError(t, err)
+		other, err := NewVerifier(thing)
+		require.NoError(t, err)
+
+		assert.ErrorContains(t, json.Unmarshal(b, other), "GET /things 204 is not part of the spec")
+	})
+
+	t.Run("undocumented response code", func(t *testing.T) {
+		other, err := NewVerifier(f)
+		require.NoError(t, err)
+
+		state := `{"checked": [{"path": "/things", "method": "GET", "responseCode": "418", "hits": 1}]}`
+		assert.ErrorContains(t, json.Unmarshal([]byte(state), other), "GET /things 418 is not part of the spec")
+		assert.ErrorContains(t, other.MergeState(strings.NewReader(state)), "GET /things 418 is not part of the spec")
+		assert.Equal(t, 0.0, other.Coverage())
+		assert.Len(t, other.Endpoints(), 2)
+	})
+
+	t.Run("unknown sentinel", func(t *testing.T) {
+		other, err := NewVerifier(f)
+		require.NoError(t, err)
+
+		err = json.Unmarshal([]byte(`{"errors": [{"sentinel": "borked", "message": "yes"}]}`), other)
+		assert.ErrorContains(t, err, `unknown sentinel "borked"`)
+	})
+}
+
+func TestMergeState(t *testing.T) {
+	f, err := os.ReadFile("testdata/thing-spec.yaml")
+	require.NoError(t, err)
+
+	record := func(v *Verifier, path, body string) {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		v.Record(&http.Response{
+			StatusCode: http.StatusOK,
+			Request:    req,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+		})
+	}
+	export := func(v *Verifier) []byte {
+		var buf bytes.Buffer
+		require.NoError(t, v.ExportState(&buf))
+		return buf.Bytes()
+	}
+
+	first, err := NewVerifier(f)
+	require.NoError(t, err)
+	record(first, "/ping", `{"message": "pong"}`)
+	record(first, "/ping", `{"message": "pong"}`)
+	second, err := NewVerifier(f)
+	require.NoError(t, err)
+	record(second, "/ping", `{"message": "pong"}`)
+	record(second, "/other", `{"thing": "yes"}`)
+	record(second, "/missing", `{}`)
+
+	t.Run("union", func(t *testing.T) {
+		merged, err := NewVerifier(f)
+		require.NoError(t, err)
+		require.NoError(t, merged.MergeState(bytes.NewReader(export(first))))
+		assert.NotEmpty(t, merged.Unchecked())
+		require.NoError(t, merged.MergeState(bytes.NewReader(export(second))))
+
+		assert.Empty(t, merged.Unchecked())
+		hits := make(map[string]int)
+		for _, e := range merged.Endpoints() {
+			hits[e.Path] = e.Hits
+		}
+		assert.Equal(t, map[string]int{"/ping": 3, "/other": 1}, hits)
+
+		errs := merged.CurrentErrors()
+		require.Len(t, errs, 1)
+		assert.True(t, errors.Is(errs[0], ErrNotPartOfSpec))
+	})
+
+	t.Run("into recorded", func(t *testing.T) {
+		v, err := NewVerifier(f)
+		require.NoError(t, err)
+		record(v, "/other", `{"thing": "yes"}`)
+		require.NoError(t, v.MergeState(bytes.NewReader(export(first))))
+
+		assert.Empty(t, v.Unchecked())
+		assert.NoError(t, v.CurrentError())
+	})
+
+	t.Run("another spec", func(t *testing.T) {
+		apikey, err := os.ReadFile("testdata/apikey-spec.yaml")
+		require.NoError(t, err)
+		other, err := NewVerifier(apikey)
+		require.NoError(t, err)
+		other.Record(&http.Response{StatusCode: http.StatusNoContent, Request: httptest.NewRequest(http.MethodGet, "/things", nil)})
+
+		v, err := NewVerifier(f)
+		require.NoError(t, err)
+		record(v, "/ping", `{"message": "pong"}`)
+		assert.ErrorContains(t, v.MergeState(bytes.NewReader(export(other))), "GET /things 204 is not part of the spec")
+		assert.Equal(t, []Endpoint{{Path: "/other", Method: http.MethodGet, ResponseCode: "200"}}, v.Unchecked())
+	})
+}
@@ -0,0 +1,295 @@
+package copper
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// strictness holds the checks of a body that go beyond what its schema requires.
+type strictness struct {
+	// formats checks the values against the format of their schema, which is otherwise only an annotation.
+	formats bool
+	// properties reports properties of objects that are not documented, unless the schema allows additional ones.
+	properties bool
+	// drift collects the properties of objects that are not documented, even where the schema allows them, without
+	// reporting them as errors.
+	drift bool
+}
+
+func (s strictness) enabled() bool {
+	return s.formats || s.properties || s.drift
+}
+
+// checkStrict walks the body together with the schema, and reports anything that the strictness does not allow, along
+// with the drift that it finds. Bodies that are not valid JSON are left for the schema validation to report.
+func checkStrict(schema *base.Schema, body []byte, s strictness) ([]driftField, error) {
+	if schema == nil || len(body) == 0 || !s.enabled() {
+		return nil, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return nil, nil
+	}
+
+	w := strictWalker{strictness: s}
+	w.walk(value, "", "", []*base.Schema{schema}, nil)
+	return w.drifted, errors.Join(w.errs...)
+}
+
+// contentSchema returns the schema of the content, or nil if there is none. The location of the schema is accepted so
+// that the result of requestContent and responseContent can be passed as is.
+func contentSchema(mediaType *v3.MediaType, _ string) *base.Schema {
+	if mediaType == nil || mediaType.Schema == nil {
+		return nil
+	}
+	return mediaType.Schema.Schema()
+}
+
+type strictWalker struct {
+	strictness
+	errs    []error
+	drifted []driftField
+}
+
+// walk checks the value against the schemas that always apply to it, and the schemas that might apply to it depending
+// on which branch of an anyOf or oneOf that matches. The field is the location without the indexes of arrays, which
+// are replaced by *, so that the drift of every item is the same field.
+func (w *strictWalker) walk(value any, location, field string, all, branches []*base.Schema) {
+	all, branches = flattenSchemas(all, branches)
+	if len(all)+len(branches) == 0 {
+		return
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		w.walkObject(v, location, field, all, branches)
+	case []any:
+		// Since OpenAPI 3.1, prefixItems has the schemas of the first items, and items only applies to the rest.
+		items := func(schemas []*base.Schema, i int) []*base.Schema {
+			var items []*base.Schema
+			for _, s := range schemas {
+				switch {
+				case i < len(s.PrefixItems):
+					items = appendSchema(items, s.PrefixItems[i])
+				case s.Items != nil && s.Items.IsA():
+					items = appendSchema(items, s.Items.A)
+				default:
+					items = appendSchema(items, s.UnevaluatedItems)
+				}
+			}
+			return items
+		}
+		for i, item := range v {
+			w.walk(item, location+"/"+strconv.Itoa(i), field+"/*", items(all, i), items(branches, i))
+		}
+	case string, json.Number:
+		if !w.formats {
+			return
+		}
+		// The format of a branch of anyOf or oneOf only applies if the branch is the one that matches, so only the
+		// schemas that always apply are checked.
+		for _, s := range all {
+			if err := checkFormat(s.Format, v); err != nil {
+				w.errs = append(w.errs, fmt.Errorf("value at %s is not a valid %s: %w",
+					displayLocation(location), s.Format, err))
+				return
+			}
+		}
+	}
+}
+
+func (w *strictWalker) walkObject(obj map[string]any, location, field string, all, branches []*base.Schema) {
+	schemas := slices.Concat(all, branches)
+
+	documented := false
+	open := false
+	for _, s := range schemas {
+		if s.Properties != nil && s.Properties.Len() > 0 {
+			documented = true
+		}
+		if s.AdditionalProperties != nil || s.UnevaluatedProperties != nil ||
+			(s.PatternProperties != nil && s.PatternProperties.Len() > 0) {
+			open = true
+		}
+	}
+
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	props := func(schemas []*base.Schema, k string) []*base.Schema {
+		var props []*base.Schema
+		for _, s := range schemas {
+			if s.Properties != nil {
+				props = appendSchema(props, s.Properties.GetOrZero(k))
+			}
+			if s.AdditionalProperties != nil && s.AdditionalProperties.IsA() {
+				props = appendSchema(props, s.AdditionalProperties.A)
+			}
+			if s.UnevaluatedProperties != nil && s.UnevaluatedProperties.IsA() {
+				props = appendSchema(props, s.UnevaluatedProperties.A)
+			}
+		}
+		return props
+	}
+
+	for _, k := range keys {
+		propAll, propBranches := props(all, k), props(branches, k)
+
+		// Drift is only collected for objects with documented properties, since free-form objects are maps.
+		if w.drift && documented && !slices.ContainsFunc(schemas, func(s *base.Schema) bool {
+			return s.Properties != nil && s.Properties.GetOrZero(k) != nil
+		}) {
+			w.drifted = append(w.drifted, driftField{field: field + pointer(k), value: obj[k]})
+		}
+
+		// An object without any documented properties is free-form, and can have any properties.
+		if w.properties && documented && !open && len(propAll)+len(propBranches) == 0 {
+			w.errs = append(w.errs, fmt.Errorf("property %q at %s is not documented", k, displayLocation(location)))
+			continue
+		}
+		w.walk(obj[k], location+pointer(k), field+pointer(k), propAll, propBranches)
+	}
+}
+
+// flattenSchemas adds the subschemas of allOf, which apply whenever the schema itself does, and the subschemas of anyOf
+// and oneOf, which only apply if they match. The conditional subschemas of OpenAPI 3.1, then, else and dependentSchemas,
+// only apply in some cases as well, so they are branches too.
+func flattenSchemas(schemas, branchSchemas []*base.Schema) (all, branches []*base.Schema) {
+	seen := make(map[*base.Schema]bool)
+
+	var flatten func(s *base.Schema, branch bool)
+	flatten = func(s *base.Schema, branch bool) {
+		if s == nil || seen[s] {
+			return
+		}
+		seen[s] = true
+
+		if branch {
+			branches = append(branches, s)
+		} else {
+			all = append(all, s)
+		}
+		for _, p := range s.AllOf {
+			flatten(p.Schema(), branch)
+		}
+		for _, p := range slices.Concat(s.AnyOf, s.OneOf) {
+			flatten(p.Schema(), true)
+		}
+		for _, p := range []*base.SchemaProxy{s.Then, s.Else} {
+			if p != nil {
+				flatten(p.Schema(), true)
+			}
+		}
+		for p := range s.DependentSchemas.ValuesFromOldest() {
+			flatten(p.Schema(), true)
+		}
+	}
+
+	for _, s := range schemas {
+		flatten(s, false)
+	}
+	for _, s := range branchSchemas {
+		flatten(s, true)
+	}
+	return all, branches
+}
+
+func appendSchema(schemas []*base.Schema, proxy *base.SchemaProxy) []*base.Schema {
+	if proxy == nil {
+		return schemas
+	}
+	if s := proxy.Schema(); s != nil {
+		return append(schemas, s)
+	}
+	return schemas
+}
+
+func displayLocation(location string) string {
+	if location == "" {
+		return "/"
+	}
+	return location
+}
+
+var (
+	formatsMu sync.Mutex
+	formats   = make(map[string]*jsonschema.Schema)
+)
+
+// checkFormat checks the value against the format. The integer formats of OpenAPI are checked for their range, and the
+// string formats of JSON schema by the JSON schema library. Formats that are not known are not checked.
+func checkFormat(format string, value any) error {
+	if n, ok := value.(json.Number); ok {
+		switch format {
+		case "int32":
+			return checkIntRange(n, math.MinInt32, math.MaxInt32)
+		case "int64":
+			return checkIntRange(n, math.MinInt64, math.MaxInt64)
+		}
+		return nil
+	}
+
+	s, ok := value.(string)
+	if !ok || format == "" {
+		return nil
+	}
+
+	schema, err := formatSchema(format)
+	if err != nil {
+		return nil
+	}
+	if err := schema.Validate(s); err != nil {
+		return fmt.Errorf("%q does not match the format", s)
+	}
+	return nil
+}
+
+// checkIntRange checks that the number is within the range. Numbers that are not integers are left for the schema
+// validation to report.
+func checkIntRange(n json.Number, min, max int64) error {
+	i, err := strconv.ParseInt(n.String(), 10, 64)
+	if errors.Is(err, strconv.ErrRange) || (err == nil && (i < min || i > max)) {
+		return fmt.Errorf("%s is out of range", n)
+	}
+	return nil
+}
+
+// formatSchema returns a compiled schema that only asserts the format, which is compiled once per format.
+func formatSchema(format string) (*jsonschema.Schema, error) {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+
+	if s, ok := formats[format]; ok {
+		return s, nil
+	}
+
+	c := jsonschema.NewCompiler()
+	c.AssertFormat()
+	if err := c.AddResource("format.json", map[string]any{"format": format}); err != nil {
+		return nil, err
+	}
+	s, err := c.Compile("format.json")
+	if err != nil {
+		return nil, err
+	}
+	formats[format] = s
+	return s, nil
+}
@@ -0,0 +1,133 @@
+package copper
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStrictResponses(t *testing.T) {
+	f, err := os.ReadFile("testdata/strict-spec.yaml")
+	require.NoError(t, err)
+
+	tt := []struct {
+		name       string
+		body       string
+		formats    bool
+		properties bool
+	}{
+		{"valid pet", `{"id": "1b4e28ba-2fa1-11d2-883f-0016d3cca427", "name": "Rex", "born": "2020-01-02", "age": 3}`, true, true},
+		{"invalid uuid", `{"id": "not-a-uuid"}`, false, true},
+		{"invalid date from allOf", `{"born": "yesterday"}`, false, true},
+		{"int32 out of range", `{"age": 2147483648}`, false, true},
+		{"undocumented property", `{"name": "Rex", "color": "brown"}`, true, false},
+		{"undocumented property in array item", `{"tags": [{"name": "good"}, {"name": "boy", "extra": 1}]}`, true, false},
+		{"free-form object", `{"metadata": {"anything": "goes"}}`, true, true},
+		{"additional properties", `{"labels": {"vet": "vet@example.com"}}`, true, true},
+		{"invalid format of additional property", `{"labels": {"vet": "nope"}}`, false, true},
+		{"properties of oneOf branches", `{"owner": {"person": "Alice"}}`, true, true},
+		{"undocumented property in oneOf", `{"owner": {"person": "Alice", "animal": "cat"}}`, true, false},
+		{"format of oneOf branch is not checked", `{"owner": {"company": "not a hostname"}}`, true, true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			check := func(valid bool, opts ...Option) {
+				v, err := NewVerifier(f, append(opts, WithoutFullCoverage())...)
+				require.NoError(t, err)
+
+				v.Record(&http.Response{
+					StatusCode: http.StatusCreated,
+					Header:     http.Header{"Content-Type": {"application/json"}},
+					Body:       io.NopCloser(strings.NewReader(tc.body)),
+					Request:    httptest.NewRequest(http.MethodPost, "/pets", nil),
+				})
+
+				if valid {
+					assert.NoError(t, v.CurrentError())
+				} else {
+					assert.ErrorIs(t, v.CurrentError(), ErrResponseInvalid)
+				}
+			}
+
+			check(true)
+			check(tc.formats, WithStrictFormats())
+			check(tc.properties, WithStrictResponseProperties())
+			check(tc.formats && tc.properties, Strict(), WithoutFullCoverage())
+		})
+	}
+}
+
+func TestStrictRequests(t *testing.T) {
+	f, err := os.ReadFile("testdata/strict-spec.yaml")
+	require.NoError(t, err)
+
+	record := func(v *Verifier, body string) {
+		req := httptest.NewRequest(http.MethodPost, "/pets", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		v.Record(&http.Response{StatusCode: http.StatusInternalServerError, Request: req})
+	}
+
+	t.Run("formats are checked", func(t *testing.T) {
+		v, err := NewVerifier(f, WithRequestValidation(), WithStrictFormats(), WithoutFullCoverage())
+		require.NoError(t, err)
+
+		record(v, `{"born": "yesterday"}`)
+		assert.ErrorIs(t, v.CurrentError(), ErrRequestInvalid)
+	})
+
+	t.Run("properties are not checked", func(t *testing.T) {
+		v, err := NewVerifier(f, Strict(), WithoutFullCoverage())
+		require.NoError(t, err)
+
+		record(v, `{"name": "Rex", "color": "brown"}`)
+		assert.NoError(t, v.CurrentError())
+	})
+}
+
+func TestPresets(t *testing.T) {
+	f, err := os.ReadFile("testdata/strict-spec.yaml")
+	require.NoError(t, err)
+
+	t.Run("strict requires internal server errors", func(t *testing.T) {
+		v, err := NewVerifier(f, Strict())
+		require.NoError(t, err)
+
+		v.Record(&http.Response{
+			StatusCode: http.StatusCreated,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{}`)),
+			Request:    httptest.NewRequest(http.MethodPost, "/pets", nil),
+		})
+		assert.ErrorIs(t, v.CurrentError(), ErrNotChecked)
+	})
+
+	t.Run("lenient only tracks coverage", func(t *testing.T) {
+		v, err := NewVerifier(f, Strict(), Lenient())
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/pets", strings.NewReader(`{"name": 1}`))
+		req.Header.Set("Content-Type", "application/json")
+		v.Record(&http.Response{
+			StatusCode: http.StatusCreated,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{"name": 1, "color": "brown"}`)),
+			Request:    req,
+		})
+		assert.NoError(t, v.CurrentError())
+
+		v.Record(&http.Response{StatusCode: http.StatusTeapot, Request: httptest.NewRequest(http.MethodPost, "/pets", nil)})
+		assert.ErrorIs(t, v.CurrentError(), ErrResponseInvalid)
+	})
+
+	t.Run("options after a preset tweak it", func(t *testing.T) {
+		assert.True(t, getConfig(Lenient(), WithRequestValidation()).checkRequest)
+		assert.False(t, getConfig(Strict(), WithoutFullCoverage()).disableResponseValidation)
+	})
+}
@@ -0,0 +1,238 @@
+//go:build !copper_lite
+
+package copper
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"net/url"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/pb33f/libopenapi"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+// Stubs generates Go source code for the package pkg, with a Register function that registers a handler on an
+// http.ServeMux for every operation in the spec, using the method and path patterns of Go 1.22. Integer, number and
+// boolean path parameters are parsed, with a 400 Bad Request response for values that do not fit the format of their
+// schema, before the handler gives up with a 501 Not Implemented response where the TODO for the implementation is
+// left. The handlers are named after the operationId of the operation,
+// or after the method and path if it has none. The path of the first server in the spec is used as the base path.
+//
+// The generated code is meant as a starting point for a server that copper can later verify, and is not meant to be
+// regenerated once it has been edited.
+func Stubs(specBytes []byte, pkg string) ([]byte, error) {
+	spec, err := libopenapi.NewDocument(specBytes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse spec data: %w", err)
+	}
+	model, errs := spec.BuildV3Model()
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("unable to create model: %w", errors.Join(errs...))
+	}
+
+	g := stubGenerator{names: make(map[string]bool)}
+	if err := g.generate(&model.Model); err != nil {
+		return nil, err
+	}
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by copper as a starting point for the server of %q. Edit as needed.\n\n", model.Model.Info.Title)
+	fmt.Fprintf(&src, "package %s\n\nimport (\n\t\"net/http\"\n", pkg)
+	if g.strconv {
+		src.WriteString("\t\"strconv\"\n")
+	}
+	src.WriteString(")\n\n")
+	src.WriteString("// Register registers a handler for every operation in the spec on the ServeMux.\n")
+	src.WriteString("func Register(mux *http.ServeMux) {\n")
+	src.Write(g.register.Bytes())
+	src.WriteString("}\n")
+	src.Write(g.handlers.Bytes())
+
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("unable to format stubs: %w", err)
+	}
+	return formatted, nil
+}
+
+type stubGenerator struct {
+	register bytes.Buffer
+	handlers bytes.Buffer
+	// names holds the names of the handlers so far, to keep them unique.
+	names map[string]bool
+	// strconv is set when any path parameter has to be parsed.
+	strconv bool
+}
+
+func (g *stubGenerator) generate(model *v3.Document) error {
+	if model.Paths == nil {
+		return nil
+	}
+
+	var basePath string
+	if len(model.Servers) > 0 {
+		if u, err := url.Parse(model.Servers[0].URL); err == nil && !strings.Contains(u.Path, "{") {
+			basePath = strings.TrimSuffix(u.Path, "/")
+		}
+	}
+
+	for path, pathItem := range model.Paths.PathItems.FromOldest() {
+		pattern, err := servePattern(basePath + path)
+		if err != nil {
+			return err
+		}
+		for method, op := range pathItem.GetOperations().FromOldest() {
+			method = strings.ToUpper(method)
+			name := g.handlerName(op.OperationId, method, path)
+			fmt.Fprintf(&g.register, "\tmux.HandleFunc(%q, %s)\n", method+" "+pattern, name)
+			g.handler(name, method, path, operationParams(pathItem, op))
+		}
+	}
+	return nil
+}
+
+func (g *stubGenerator) handler(name, method, path string, params []*v3.Parameter) {
+	fmt.Fprintf(&g.handlers, "\n// %s handles %s %s.\n", name, method, path)
+	fmt.Fprintf(&g.handlers, "func %s(w http.ResponseWriter, r *http.Request) {\n", name)
+
+	var vars []string
+	for _, p := range params {
+		if p.In != "path" {
+			continue
+		}
+		v := identifier(p.Name)
+		vars = append(vars, v)
+		if parse := parsePathValue(p, v); parse != "" {
+			g.strconv = true
+			g.handlers.WriteString(parse)
+		} else {
+			fmt.Fprintf(&g.handlers, "\t%s := r.PathValue(%q)\n", v, wildcardName(p.Name))
+		}
+	}
+	if len(vars) > 0 {
+		fmt.Fprintf(&g.handlers, "\t%s = %s\n\n", strings.Repeat("_, ", len(vars)-1)+"_", strings.Join(vars, ", "))
+	}
+
+	fmt.Fprintf(&g.handlers, "\t// TODO: implement %s.\n", name)
+	g.handlers.WriteString("\thttp.Error(w, http.StatusText(http.StatusNotImplemented), http.StatusNotImplemented)\n}\n")
+}
+
+// parsePathValue returns the code that parses the value of a path parameter into the Go type of its schema, or an
+// empty string if the value is used as a string. Only the simple style can be parsed, since the other styles have a
+// prefix in the value.
+func parsePathValue(p *v3.Parameter, v string) string {
+	if p.Style != "" && p.Style != "simple" || p.Schema == nil {
+		return ""
+	}
+	s := p.Schema.Schema()
+	if s == nil || len(s.Type) != 1 {
+		return ""
+	}
+
+	var parse string
+	switch s.Type[0] {
+	case "integer":
+		parse = "strconv.ParseInt(%s, 10, 64)"
+		if s.Format == "int32" {
+			parse = "strconv.ParseInt(%s, 10, 32)"
+		}
+	case "number":
+		parse = "strconv.ParseFloat(%s, 64)"
+		if s.Format == "float" {
+			parse = "strconv.ParseFloat(%s, 32)"
+		}
+	case "boolean":
+		parse = "strconv.ParseBool(%s)"
+	default:
+		return ""
+	}
+
+	value := fmt.Sprintf("r.PathValue(%q)", wildcardName(p.Name))
+	return fmt.Sprintf("\t%s, err := "+parse+"\n", v, value) +
+		"\tif err != nil {\n" +
+		fmt.Sprintf("\t\thttp.Error(w, %q, http.StatusBadRequest)\n", "invalid path parameter "+p.Name) +
+		"\t\treturn\n\t}\n"
+}
+
+// servePattern converts a path template into the path of a ServeMux pattern. A ServeMux only supports wildcards that
+// are whole segments, and a path that ends with a slash would otherwise match any path below it.
+func servePattern(path string) (string, error) {
+	segs := strings.Split(path, "/")
+	for i, seg := range segs {
+		if !strings.Contains(seg, "{") {
+			continue
+		}
+		if !strings.HasPrefix(seg, "{") || !strings.HasSuffix(seg, "}") || strings.Count(seg, "{") > 1 {
+			return "", fmt.Errorf("path %s can not be expressed as a ServeMux pattern", path)
+		}
+		segs[i] = "{" + wildcardName(seg[1:len(seg)-1]) + "}"
+	}
+
+	pattern := strings.Join(segs, "/")
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "{$}"
+	}
+	return pattern, nil
+}
+
+// wildcardName returns the name of a path parameter as a ServeMux wildcard, which has to be a Go identifier.
+func wildcardName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// handlerName returns a unique name for the handler of an operation.
+func (g *stubGenerator) handlerName(operationID, method, path string) string {
+	name := identifier(operationID)
+	if operationID == "" {
+		name = identifier(strings.ToLower(method) + " " + strings.NewReplacer("{", "", "}", "").Replace(path))
+	}
+
+	unique := name
+	for i := 2; g.names[unique]; i++ {
+		unique = fmt.Sprintf("%s%d", name, i)
+	}
+	g.names[unique] = true
+	return unique
+}
+
+// identifier turns a name into an unexported camel cased Go identifier.
+func identifier(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var b strings.Builder
+	for i, w := range words {
+		r := []rune(w)
+		if i == 0 {
+			r[0] = unicode.ToLower(r[0])
+		} else {
+			r[0] = unicode.ToUpper(r[0])
+		}
+		b.WriteString(string(r))
+	}
+
+	id := b.String()
+	if id == "" || unicode.IsDigit([]rune(id)[0]) {
+		id = "op" + id
+	}
+	if slices.Contains(goKeywords, id) || id == "err" || id == "w" || id == "r" {
+		id += "_"
+	}
+	return id
+}
+
+var goKeywords = []string{
+	"break", "case", "chan", "const", "continue", "default", "defer", "else", "fallthrough", "for", "func", "go", "goto",
+	"if", "import", "interface", "map", "package", "range", "return", "select", "struct", "switch", "type", "var",
+}
@@ -0,0 +1,63 @@
+//go:build !copper_lite
+
+package copper
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStubs(t *testing.T) {
+	spec, err := os.ReadFile("testdata/stubs-spec.yaml")
+	require.NoError(t, err)
+
+	src, err := Stubs(spec, "api")
+	require.NoError(t, err)
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "stubs.go", src, parser.ParseComments)
+	require.NoError(t, err, string(src))
+
+	t.Run("generated code type checks", func(t *testing.T) {
+		conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+		_, err := conf.Check("api", fset, []*ast.File{f}, nil)
+		assert.NoError(t, err, string(src))
+	})
+
+	t.Run("operations are registered with their patterns", func(t *testing.T) {
+		assert.Contains(t, string(src), `mux.HandleFunc("GET /api/{$}", get)`)
+		assert.Contains(t, string(src), `mux.HandleFunc("GET /api/things/{thing_id}", getThing)`)
+		assert.Contains(t, string(src), `mux.HandleFunc("DELETE /api/things/{thing_id}", getThing2)`)
+		assert.Contains(t, string(src), `mux.HandleFunc("PUT /api/things/{thing_id}/parts/{part}", putThingsThingIdPartsPart)`)
+	})
+
+	t.Run("path parameters are parsed", func(t *testing.T) {
+		assert.Contains(t, string(src), `thingId, err := strconv.ParseInt(r.PathValue("thing_id"), 10, 32)`)
+		assert.Contains(t, string(src), `thingId, err := strconv.ParseInt(r.PathValue("thing_id"), 10, 64)`)
+		assert.Contains(t, string(src), `part := r.PathValue("part")`)
+	})
+
+	t.Run("paths that a ServeMux can not express are reported", func(t *testing.T) {
+		_, err := Stubs([]byte(`
+openapi: 3.0.1
+info:
+  title: stubs test
+  version: '1.0'
+paths:
+  /files/{name}.json:
+    get:
+      responses:
+        "200":
+          description: A file
+`), "api")
+		assert.ErrorContains(t, err, "/files/{name}.json")
+	})
+}
@@ -0,0 +1,284 @@
+package copper
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	validatorerr "github.com/pb33f/libopenapi-validator/errors"
+	"github.com/pb33f/libopenapi-validator/helpers"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+// operationParams returns the parameters that apply to the operation, where the parameters of the operation override
+// the ones of the path item with the same name and location.
+func operationParams(pathItem *v3.PathItem, op *v3.Operation) []*v3.Parameter {
+	var params []*v3.Parameter
+	if op != nil {
+		params = append(params, op.Parameters...)
+	}
+
+	for _, p := range pathItem.Parameters {
+		overridden := false
+		for _, o := range params {
+			if o.Name == p.Name && o.In == p.In {
+				overridden = true
+				break
+			}
+		}
+		if !overridden {
+			params = append(params, p)
+		}
+	}
+	return params
+}
+
+// normalizePathStyles returns a copy of the request where path parameters serialized with the matrix or label style
+// have been rewritten to the simple style. The validator library does not take the prefixes of these styles into
+// account, so the prefixes are checked here and the values are then left to the library to validate. If no path
+// parameter uses any of these styles, the request itself is returned.
+func normalizePathStyles(req *http.Request, pathItem *v3.PathItem, foundPath string) (*http.Request, error) {
+	op := pathItem.GetOperations().GetOrZero(strings.ToLower(req.Method))
+
+	tSegs := strings.Split(strings.Trim(foundPath, "/"), "/")
+	pSegs := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	if len(pSegs) < len(tSegs) {
+		return req, nil
+	}
+	// The request path might contain a base path, so the segments are aligned from the end.
+	offset := len(pSegs) - len(tSegs)
+
+	var (
+		errs    []error
+		changed bool
+	)
+	for _, p := range operationParams(pathItem, op) {
+		if p.In != "path" || (p.Style != "matrix" && p.Style != "label") {
+			continue
+		}
+
+		i := segmentIndex(tSegs, p.Name)
+		if i < 0 {
+			continue
+		}
+
+		simple, err := simpleStyle(p, pSegs[offset+i])
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		pSegs[offset+i] = simple
+		changed = true
+	}
+
+	if len(errs) > 0 {
+		return req, errors.Join(errs...)
+	}
+	if !changed {
+		return req, nil
+	}
+
+	normalized := req.Clone(req.Context())
+	normalized.URL.Path = "/" + strings.Join(pSegs, "/")
+	normalized.URL.RawPath = ""
+	return normalized, nil
+}
+
+func segmentIndex(segs []string, name string) int {
+	for i, seg := range segs {
+		if seg == "{"+name+"}" {
+			return i
+		}
+	}
+	return -1
+}
+
+// simpleStyle converts a single path segment from the matrix or label style into the simple style.
+func simpleStyle(p *v3.Parameter, seg string) (string, error) {
+	prefix := "."
+	if p.Style == "matrix" {
+		prefix = ";"
+	}
+
+	value, ok := strings.CutPrefix(seg, prefix)
+	if !ok {
+		return "", fmt.Errorf("path parameter %s uses the %s style, but %q does not start with %q",
+			p.Name, p.Style, seg, prefix)
+	}
+
+	composite := isComposite(p)
+	if p.Style == "label" {
+		if p.IsExploded() && composite {
+			return strings.ReplaceAll(value, ".", ","), nil
+		}
+		return value, nil
+	}
+
+	parts := strings.Split(value, ";")
+	if !p.IsExploded() && len(parts) > 1 {
+		return "", fmt.Errorf("path parameter %s uses the matrix style without explode, but %q has several values", p.Name, seg)
+	}
+
+	var values []string
+	for _, part := range parts {
+		name, v, found := strings.Cut(part, "=")
+		switch {
+		case name == p.Name:
+			values = append(values, v)
+		case p.IsExploded() && composite && found:
+			// An exploded object has the properties as names instead of the parameter name.
+			values = append(values, part)
+		default:
+			return "", fmt.Errorf("path parameter %s uses the matrix style, but %q does not name it", p.Name, seg)
+		}
+	}
+	return strings.Join(values, ","), nil
+}
+
+func isComposite(p *v3.Parameter) bool {
+	if p.Schema == nil {
+		return false
+	}
+	for _, t := range p.Schema.Schema().Type {
+		if t == "array" || t == "object" {
+			return true
+		}
+	}
+	return false
+}
+
+// checkQueryStyles checks that array and scalar query parameters are passed in the form that their style describes,
+// which the validator library does not fully do. An exploded array is given as repeated keys (tag=a&tag=b), while a
+// non-exploded one is given as a single comma separated value (tag=a,b). Any parameter that is not an array can only be
+// given once. The delimited styles are left out, since the library already checks them.
+func checkQueryStyles(req *http.Request, pathItem *v3.PathItem) error {
+	op := pathItem.GetOperations().GetOrZero(strings.ToLower(req.Method))
+	raw := rawQueryValues(req.URL.RawQuery)
+
+	var errs []error
+	for _, p := range operationParams(pathItem, op) {
+		if p.In != "query" || (p.Style != "" && p.Style != "form") {
+			continue
+		}
+
+		values := raw[p.Name]
+		switch {
+		case isArray(p) && exploded(p):
+			if p.AllowReserved {
+				// A literal comma is then part of the value, and not a delimiter.
+				break
+			}
+			for _, v := range values {
+				if strings.Contains(v, ",") {
+					errs = append(errs, fmt.Errorf("query parameter %s is an exploded array, so the values should be "+
+						"given as repeated keys (%s=a&%s=b), but got %s=%s", p.Name, p.Name, p.Name, p.Name, v))
+					break
+				}
+			}
+		case isArray(p):
+			if len(values) > 1 {
+				errs = append(errs, fmt.Errorf("query parameter %s is an array that is not exploded, so the values "+
+					"should be given as a single comma separated value (%s=a,b), but the key was repeated %d times",
+					p.Name, p.Name, len(values)))
+			}
+		case isComposite(p) && exploded(p):
+			// The properties of an exploded object are given as separate keys, and not under the parameter name.
+		default:
+			if len(values) > 1 {
+				errs = append(errs, fmt.Errorf("query parameter %s is not an array, but the key was repeated %d times",
+					p.Name, len(values)))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// rawQueryValues splits the query into keys and values like url.ParseQuery, except that the values are kept encoded
+// so that a literal delimiter can be told apart from an encoded one.
+func rawQueryValues(query string) map[string][]string {
+	values := make(map[string][]string)
+	for _, pair := range strings.Split(query, "&") {
+		if pair == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(pair, "=")
+		if unescaped, err := url.QueryUnescape(key); err == nil {
+			key = unescaped
+		}
+		values[key] = append(values[key], value)
+	}
+	return values
+}
+
+func isArray(p *v3.Parameter) bool {
+	return p.Schema != nil && slices.Contains(p.Schema.Schema().Type, "array")
+}
+
+// exploded returns if the parameter is exploded, taking into account that explode defaults to true for the form style.
+func exploded(p *v3.Parameter) bool {
+	if p.Explode == nil {
+		return p.Style == "" || p.Style == "form"
+	}
+	return *p.Explode
+}
+
+// reservedCharacters are the reserved characters of RFC 3986 that can appear literally in a query. A plus is left out
+// since it is commonly used for encoding spaces, and the ampersand and equals sign since they delimit the query.
+const reservedCharacters = ":/?[]@!$'()*,;"
+
+// checkQueryEncoding checks that the query values of the parameters that do not allow reserved characters have them
+// percent-encoded, and that the percent-encoding is valid. The delimiter of the style of the parameter is allowed.
+func checkQueryEncoding(req *http.Request, pathItem *v3.PathItem) error {
+	op := pathItem.GetOperations().GetOrZero(strings.ToLower(req.Method))
+	raw := rawQueryValues(req.URL.RawQuery)
+
+	var errs []error
+	for _, p := range operationParams(pathItem, op) {
+		if p.In != "query" {
+			continue
+		}
+
+		for key, values := range raw {
+			if key != p.Name && (p.Style != "deepObject" || !strings.HasPrefix(key, p.Name+"[")) {
+				continue
+			}
+
+			for _, v := range values {
+				if _, err := url.QueryUnescape(v); err != nil {
+					errs = append(errs, fmt.Errorf("query parameter %s has an invalid percent-encoding in %s=%s",
+						p.Name, key, v))
+					continue
+				}
+				if p.AllowReserved {
+					continue
+				}
+				for i := 0; i < len(v); i++ {
+					if strings.IndexByte(reservedCharacters, v[i]) >= 0 && !isDelimiter(p, v[i]) {
+						errs = append(errs, fmt.Errorf("query parameter %s does not allow reserved characters, but "+
+							"%q is not percent-encoded in %s=%s", p.Name, v[i], key, v))
+						break
+					}
+				}
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// isDelimiter checks if the character delimits the values of a non-exploded form parameter.
+func isDelimiter(p *v3.Parameter, c byte) bool {
+	return c == ',' && (p.Style == "" || p.Style == "form") && !exploded(p)
+}
+
+// withoutReservedValueErrors removes the errors of the validator library about reserved characters in query values.
+// The library looks for the characters after the values have been decoded, which reports properly encoded values as
+// invalid, so the encoding is instead checked by checkQueryEncoding.
+func withoutReservedValueErrors(errs []*validatorerr.ValidationError) []*validatorerr.ValidationError {
+	return slices.DeleteFunc(errs, func(err *validatorerr.ValidationError) bool {
+		return err.ValidationSubType == helpers.ParameterValidationQuery &&
+			strings.HasSuffix(err.Message, "value contains reserved values")
+	})
+}
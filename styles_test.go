@@ -0,0 +1,111 @@
+package copper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParameterStyles(t *testing.T) {
+	f, err := os.ReadFile("testdata/style-spec.yaml")
+	require.NoError(t, err)
+
+	tt := []struct {
+		name  string
+		url   string
+		valid bool
+	}{
+		{"deep object", "/query?filter[color]=red&filter[size]=2", true},
+		{"deep object with invalid property", "/query?filter[color]=red&filter[size]=big", false},
+		{"pipe delimited", "/query?ids=1|2|3", true},
+		{"pipe delimited with commas", "/query?ids=1,2,3", false},
+		{"matrix array", "/matrix/;id=1,2", true},
+		{"matrix array with invalid item", "/matrix/;id=1,x", false},
+		{"matrix without prefix", "/matrix/1,2", false},
+		{"matrix with other name", "/matrix/;other=1,2", false},
+		{"label", "/label/.5", true},
+		{"label with invalid value", "/label/.x", false},
+		{"label without prefix", "/label/5", false},
+		{"exploded matrix and label", "/exploded/;id=1;id=2/.3.4", true},
+		{"exploded matrix with invalid item", "/exploded/;id=1;id=x/.3.4", false},
+		{"exploded label with invalid item", "/exploded/;id=1;id=2/.3.x", false},
+		{"exploded array as repeated keys", "/repeated?tag=1&tag=2", true},
+		{"exploded array as single value", "/repeated?tag=1", true},
+		{"exploded array as comma separated value", "/repeated?tag=1,2", false},
+		{"exploded string array with encoded comma", "/repeated?name=a%2Cb&name=c", true},
+		{"exploded string array with literal comma", "/repeated?name=a,b", false},
+		{"non-exploded array as comma separated value", "/query?tags=1,2", true},
+		{"non-exploded array as repeated keys", "/query?tags=1&tags=2", false},
+		{"scalar given once", "/repeated?limit=1", true},
+		{"scalar as repeated keys", "/repeated?limit=1&limit=2", false},
+		{"pipe delimited as repeated keys", "/repeated?ids=1&ids=2", false},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			v, err := NewVerifier(f, WithRequestValidation(), WithoutFullCoverage())
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, tc.url, nil)
+			v.Record(&http.Response{StatusCode: 204, Request: req})
+
+			if tc.valid {
+				assert.NoError(t, v.CurrentError())
+			} else {
+				assert.ErrorIs(t, v.CurrentError(), ErrRequestInvalid)
+			}
+		})
+	}
+}
+
+func TestQueryEncoding(t *testing.T) {
+	f, err := os.ReadFile("testdata/style-spec.yaml")
+	require.NoError(t, err)
+
+	tt := []struct {
+		name    string
+		url     string
+		strict  bool
+		lenient bool
+	}{
+		{"encoded url", "/encoding?url=http%3A%2F%2Fexample.com%2Fa", true, true},
+		{"literal url", "/encoding?url=http://example.com/a", false, true},
+		{"literal url with allowReserved", "/encoding?redirect=http://example.com/a?b=c", true, true},
+		{"spaces as plus", "/encoding?url=a+b", true, true},
+		{"invalid percent-encoding", "/encoding?url=a%zzb", false, true},
+		{"delimiter of non-exploded array", "/encoding?tags=a,b", true, true},
+		{"other reserved character in non-exploded array", "/encoding?tags=a,b;c", false, true},
+		{"literal comma in exploded array with allowReserved", "/encoding?names=a,b&names=c", true, true},
+		{"encoded deep object property", "/query?filter[color]=r%3Aed", true, true},
+		{"deep object property", "/query?filter[color]=r:ed", false, true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, strict := range []bool{true, false} {
+				opts := []Option{WithRequestValidation(), WithoutFullCoverage()}
+				valid := tc.lenient
+				if strict {
+					opts = append(opts, WithStrictQueryEncoding())
+					valid = tc.strict
+				}
+
+				v, err := NewVerifier(f, opts...)
+				require.NoError(t, err)
+
+				req := httptest.NewRequest(http.MethodGet, tc.url, nil)
+				v.Record(&http.Response{StatusCode: 204, Request: req})
+
+				if valid {
+					assert.NoError(t, v.CurrentError(), "strict: %v", strict)
+				} else {
+					assert.ErrorIs(t, v.CurrentError(), ErrRequestInvalid, "strict: %v", strict)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,722 @@
+package copper
+
+import (
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// convertedVersion is the OpenAPI version that Swagger 2.0 specs are converted to.
+const convertedVersion = "3.0.3"
+
+// WithSwagger2 is a functional Option for converting the spec from Swagger 2.0 to OpenAPI 3.0 when the verifier is
+// created. Specs with a swagger field of 2.0 are converted without it, so the option is for the ones that leave the
+// field out, and makes NewVerifier fail for an OpenAPI spec rather than verifying it as it is.
+func WithSwagger2() Option {
+	return func(c *config) {
+		c.swagger2 = true
+	}
+}
+
+// convertSwagger2 returns the spec converted to OpenAPI 3.0 if it is a Swagger 2.0 spec, or forced to be treated as one,
+// and otherwise the spec as it is. The conversion is done on the YAML tree, which keeps the paths in the order that
+// they are in the spec. Only the document itself is converted, so the files that it references are not.
+func convertSwagger2(specBytes []byte, force bool) ([]byte, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(specBytes, &root); err != nil || len(root.Content) == 0 ||
+		root.Content[0].Kind != yaml.MappingNode {
+		if force {
+			return nil, fmt.Errorf("WithSwagger2 is given, but the spec is not a YAML or JSON object")
+		}
+		// The spec is not valid YAML, which parsing it as a document reports.
+		return specBytes, nil
+	}
+	doc := root.Content[0]
+
+	if openapi := mappingValue(doc, "openapi"); openapi != nil {
+		if force {
+			return nil, fmt.Errorf("WithSwagger2 is given, but the spec is OpenAPI %s", openapi.Value)
+		}
+		return specBytes, nil
+	}
+	version := mappingValue(doc, "swagger")
+	switch {
+	case version == nil && !force:
+		return specBytes, nil
+	case version != nil && version.Value != "2.0" && version.Value != "2":
+		return nil, fmt.Errorf("swagger version %s is not supported, only 2.0 is", version.Value)
+	}
+
+	converted, err := newSwaggerConverter(doc).convert()
+	if err != nil {
+		return nil, fmt.Errorf("could not convert Swagger 2.0 spec: %w", err)
+	}
+	return yaml.Marshal(converted)
+}
+
+// swaggerConverter converts a Swagger 2.0 document to OpenAPI 3.0.
+type swaggerConverter struct {
+	doc      *yaml.Node
+	consumes []string
+	produces []string
+	// bodies and forms are the global parameters that are in the body or the form data, which OpenAPI 3.0 does not
+	// have parameters for. The bodies become request bodies in the components, and the forms are inlined where they
+	// are referenced.
+	bodies map[string]bool
+	forms  map[string]*yaml.Node
+}
+
+func newSwaggerConverter(doc *yaml.Node) *swaggerConverter {
+	c := &swaggerConverter{
+		doc:      doc,
+		consumes: stringList(mappingValue(doc, "consumes")),
+		produces: stringList(mappingValue(doc, "produces")),
+		bodies:   make(map[string]bool),
+		forms:    make(map[string]*yaml.Node),
+	}
+	if params := mappingValue(doc, "parameters"); params != nil {
+		for name, p := range mappingPairs(params) {
+			switch scalarValue(p, "in") {
+			case "body":
+				c.bodies[name] = true
+			case "formData":
+				c.forms[name] = p
+			}
+		}
+	}
+	return c
+}
+
+func (c *swaggerConverter) convert() (*yaml.Node, error) {
+	out := newMapping()
+	setValue(out, "openapi", newString(convertedVersion))
+
+	for key, value := range mappingPairs(c.doc) {
+		switch key {
+		case "swagger", "host", "basePath", "schemes", "consumes", "produces", "definitions", "parameters",
+			"responses", "securityDefinitions":
+		case "paths":
+			if servers := c.servers(); servers != nil {
+				setValue(out, "servers", servers)
+			}
+			paths, err := c.paths(value)
+			if err != nil {
+				return nil, err
+			}
+			setValue(out, "paths", paths)
+		default:
+			// Like info, tags, security, externalDocs and the extensions, which are the same in both versions.
+			setValue(out, key, value)
+		}
+	}
+	if mappingValue(out, "paths") == nil {
+		if servers := c.servers(); servers != nil {
+			setValue(out, "servers", servers)
+		}
+	}
+	if components := c.components(); len(components.Content) > 0 {
+		setValue(out, "components", components)
+	}
+
+	rewriteSwaggerRefs(out)
+	return out, nil
+}
+
+// servers returns the servers from the schemes, host and base path, or nil when there is neither a host nor a base
+// path, which is the same as a server at /. Without schemes, the spec is served with https.
+func (c *swaggerConverter) servers() *yaml.Node {
+	host := scalarValue(c.doc, "host")
+	basePath := strings.TrimSuffix(scalarValue(c.doc, "basePath"), "/")
+	if host == "" && basePath == "" {
+		return nil
+	}
+
+	servers := newSequence()
+	if host == "" {
+		server := newMapping()
+		setValue(server, "url", newString(basePath))
+		servers.Content = append(servers.Content, server)
+		return servers
+	}
+	schemes := stringList(mappingValue(c.doc, "schemes"))
+	if len(schemes) == 0 {
+		schemes = []string{"https"}
+	}
+	for _, scheme := range schemes {
+		server := newMapping()
+		setValue(server, "url", newString(scheme+"://"+host+basePath))
+		servers.Content = append(servers.Content, server)
+	}
+	return servers
+}
+
+func (c *swaggerConverter) components() *yaml.Node {
+	components := newMapping()
+
+	if definitions := mappingValue(c.doc, "definitions"); definitions != nil {
+		schemas := newMapping()
+		for name, schema := range mappingPairs(definitions) {
+			setValue(schemas, name, convertSchema(schema))
+		}
+		setValue(components, "schemas", schemas)
+	}
+
+	if params := mappingValue(c.doc, "parameters"); params != nil {
+		parameters, bodies := newMapping(), newMapping()
+		for name, p := range mappingPairs(params) {
+			switch {
+			case c.bodies[name]:
+				setValue(bodies, name, c.requestBody(p, c.consumes))
+			case c.forms[name] == nil:
+				setValue(parameters, name, convertParameter(p))
+			}
+		}
+		if len(parameters.Content) > 0 {
+			setValue(components, "parameters", parameters)
+		}
+		if len(bodies.Content) > 0 {
+			setValue(components, "requestBodies", bodies)
+		}
+	}
+
+	if responses := mappingValue(c.doc, "responses"); responses != nil {
+		converted := newMapping()
+		for name, r := range mappingPairs(responses) {
+			setValue(converted, name, convertResponse(r, c.produces))
+		}
+		setValue(components, "responses", converted)
+	}
+
+	if definitions := mappingValue(c.doc, "securityDefinitions"); definitions != nil {
+		schemes := newMapping()
+		for name, scheme := range mappingPairs(definitions) {
+			setValue(schemes, name, convertSecurityScheme(scheme))
+		}
+		setValue(components, "securitySchemes", schemes)
+	}
+
+	return components
+}
+
+func (c *swaggerConverter) paths(paths *yaml.Node) (*yaml.Node, error) {
+	out := newMapping()
+	for path, item := range mappingPairs(paths) {
+		if ref := scalarValue(item, "$ref"); ref != "" {
+			return nil, fmt.Errorf("path %s: references to path items are not supported", path)
+		}
+
+		// Parameters of the path that are in the body or the form data are moved to the operations, since a request
+		// body is part of the operation in OpenAPI 3.0.
+		var shared, moved []*yaml.Node
+		if params := mappingValue(item, "parameters"); params != nil {
+			for _, p := range params.Content {
+				if c.isBodyParameter(p) {
+					moved = append(moved, p)
+				} else {
+					shared = append(shared, p)
+				}
+			}
+		}
+
+		converted := newMapping()
+		for key, value := range mappingPairs(item) {
+			switch key {
+			case "get", "put", "post", "delete", "options", "head", "patch":
+				setValue(converted, key, c.operation(value, moved))
+			case "parameters":
+				if len(shared) > 0 {
+					params := newSequence()
+					for _, p := range shared {
+						params.Content = append(params.Content, convertParameter(p))
+					}
+					setValue(converted, key, params)
+				}
+			default:
+				setValue(converted, key, value)
+			}
+		}
+		setValue(out, path, converted)
+	}
+	return out, nil
+}
+
+// isBodyParameter returns whether the parameter, or the global parameter it references, is in the body or the form
+// data.
+func (c *swaggerConverter) isBodyParameter(p *yaml.Node) bool {
+	if name, ok := strings.CutPrefix(scalarValue(p, "$ref"), "#/parameters/"); ok {
+		return c.bodies[name] || c.forms[name] != nil
+	}
+	in := scalarValue(p, "in")
+	return in == "body" || in == "formData"
+}
+
+// operation converts an operation, with the body and form data parameters of its path, which it overrides by name.
+func (c *swaggerConverter) operation(op *yaml.Node, inherited []*yaml.Node) *yaml.Node {
+	consumes := c.consumes
+	if list := mappingValue(op, "consumes"); list != nil {
+		consumes = stringList(list)
+	}
+	produces := c.produces
+	if list := mappingValue(op, "produces"); list != nil {
+		produces = stringList(list)
+	}
+
+	var own []*yaml.Node
+	if list := mappingValue(op, "parameters"); list != nil {
+		own = list.Content
+	}
+	names := make(map[string]bool)
+	for _, p := range own {
+		names[c.parameterName(p)] = true
+	}
+	var all []*yaml.Node
+	for _, p := range inherited {
+		if !names[c.parameterName(p)] {
+			all = append(all, p)
+		}
+	}
+	all = append(all, own...)
+
+	params := newSequence()
+	var body *yaml.Node
+	var form []*yaml.Node
+	for _, p := range all {
+		ref := scalarValue(p, "$ref")
+		name, isGlobal := strings.CutPrefix(ref, "#/parameters/")
+		switch {
+		case isGlobal && c.bodies[name]:
+			body = newMapping()
+			setValue(body, "$ref", newString("#/components/requestBodies/"+name))
+		case isGlobal && c.forms[name] != nil:
+			form = append(form, c.forms[name])
+		case ref != "":
+			params.Content = append(params.Content, convertParameter(p))
+		case scalarValue(p, "in") == "body":
+			body = c.requestBody(p, consumes)
+		case scalarValue(p, "in") == "formData":
+			form = append(form, p)
+		default:
+			params.Content = append(params.Content, convertParameter(p))
+		}
+	}
+	if len(form) > 0 {
+		body = formRequestBody(form, consumes)
+	}
+
+	out := newMapping()
+	for key, value := range mappingPairs(op) {
+		switch key {
+		case "consumes", "produces", "schemes":
+		case "parameters":
+			if len(params.Content) > 0 {
+				setValue(out, key, params)
+			}
+			if body != nil {
+				setValue(out, "requestBody", body)
+			}
+		case "responses":
+			responses := newMapping()
+			for code, r := range mappingPairs(value) {
+				setValue(responses, code, convertResponse(r, produces))
+			}
+			setValue(out, key, responses)
+		default:
+			setValue(out, key, value)
+		}
+	}
+	if mappingValue(op, "parameters") == nil {
+		if len(params.Content) > 0 {
+			setValue(out, "parameters", params)
+		}
+		if body != nil {
+			setValue(out, "requestBody", body)
+		}
+	}
+	return out
+}
+
+// parameterName returns the name and location of the parameter, which identify it, or the reference to it.
+func (c *swaggerConverter) parameterName(p *yaml.Node) string {
+	if ref := scalarValue(p, "$ref"); ref != "" {
+		if name, ok := strings.CutPrefix(ref, "#/parameters/"); ok && c.forms[name] != nil {
+			p = c.forms[name]
+		} else {
+			return ref
+		}
+	}
+	return scalarValue(p, "in") + ":" + scalarValue(p, "name")
+}
+
+// requestBody converts a body parameter to a request body, with the schema for each of the media types that the
+// operation consumes, or JSON if it does not say.
+func (c *swaggerConverter) requestBody(p *yaml.Node, consumes []string) *yaml.Node {
+	if len(consumes) == 0 {
+		consumes = []string{"application/json"}
+	}
+	content := newMapping()
+	for _, mediaType := range consumes {
+		mt := newMapping()
+		if schema := mappingValue(p, "schema"); schema != nil {
+			setValue(mt, "schema", convertSchema(schema))
+		}
+		setValue(content, mediaType, mt)
+	}
+
+	body := newMapping()
+	for key, value := range mappingPairs(p) {
+		if key == "description" || key == "required" || strings.HasPrefix(key, "x-") {
+			setValue(body, key, value)
+		}
+	}
+	setValue(body, "content", content)
+	return body
+}
+
+// formRequestBody converts the form data parameters to a request body with an object schema, which has a property for
+// each parameter. The media types are the form ones that the operation consumes, or else multipart for operations
+// that upload files and URL encoded for the others.
+func formRequestBody(params []*yaml.Node, consumes []string) *yaml.Node {
+	schema := newMapping()
+	setValue(schema, "type", newString("object"))
+	properties := newMapping()
+	required := newSequence()
+	files := false
+	for _, p := range params {
+		name := scalarValue(p, "name")
+		setValue(properties, name, parameterSchema(p))
+		if scalarValue(p, "required") == "true" {
+			required.Content = append(required.Content, newString(name))
+		}
+		files = files || scalarValue(p, "type") == "file"
+	}
+	setValue(schema, "properties", properties)
+	if len(required.Content) > 0 {
+		setValue(schema, "required", required)
+	}
+
+	var mediaTypes []string
+	for _, mediaType := range consumes {
+		if mediaType == "multipart/form-data" || mediaType == "application/x-www-form-urlencoded" {
+			mediaTypes = append(mediaTypes, mediaType)
+		}
+	}
+	if len(mediaTypes) == 0 {
+		mediaTypes = []string{"application/x-www-form-urlencoded"}
+		if files {
+			mediaTypes = []string{"multipart/form-data"}
+		}
+	}
+
+	content := newMapping()
+	for _, mediaType := range mediaTypes {
+		mt := newMapping()
+		setValue(mt, "schema", schema)
+		setValue(content, mediaType, mt)
+	}
+	body := newMapping()
+	if len(required.Content) > 0 {
+		setValue(body, "required", newBool(true))
+	}
+	setValue(body, "content", content)
+	return body
+}
+
+// parameterFields are the fields of a parameter that stay on it, rather than being moved to its schema.
+var parameterFields = []string{"name", "in", "description", "required", "allowEmptyValue"}
+
+// convertParameter converts a parameter that is not in the body or the form data, moving its type and constraints to
+// a schema, and its collection format to a style.
+func convertParameter(p *yaml.Node) *yaml.Node {
+	if ref := mappingValue(p, "$ref"); ref != nil {
+		out := newMapping()
+		setValue(out, "$ref", ref)
+		return out
+	}
+
+	out := newMapping()
+	for key, value := range mappingPairs(p) {
+		if slices.Contains(parameterFields, key) || strings.HasPrefix(key, "x-") {
+			setValue(out, key, value)
+		}
+	}
+	if scalarValue(p, "type") == "array" {
+		// The default of Swagger 2.0 is csv, but the default of OpenAPI 3.0 for the query is to repeat the parameter.
+		in := scalarValue(p, "in")
+		switch format := scalarValue(p, "collectionFormat"); {
+		case format == "multi":
+			setValue(out, "style", newString("form"))
+			setValue(out, "explode", newBool(true))
+		case format == "ssv":
+			setValue(out, "style", newString("spaceDelimited"))
+			setValue(out, "explode", newBool(false))
+		case format == "pipes":
+			setValue(out, "style", newString("pipeDelimited"))
+			setValue(out, "explode", newBool(false))
+		case in == "query":
+			setValue(out, "style", newString("form"))
+			setValue(out, "explode", newBool(false))
+		}
+	}
+	setValue(out, "schema", parameterSchema(p))
+	return out
+}
+
+// parameterSchema returns the schema of a parameter, a header or the items of either, which all have the type and
+// constraints of the value next to their other fields.
+func parameterSchema(p *yaml.Node) *yaml.Node {
+	schema := newMapping()
+	for key, value := range mappingPairs(p) {
+		switch {
+		case slices.Contains(parameterFields, key), key == "collectionFormat", strings.HasPrefix(key, "x-"):
+		case key == "type" && value.Value == "file":
+			setValue(schema, "type", newString("string"))
+			setValue(schema, "format", newString("binary"))
+		case key == "items":
+			setValue(schema, key, parameterSchema(value))
+		default:
+			setValue(schema, key, value)
+		}
+	}
+	return schema
+}
+
+// convertResponse converts a response, with the schema and examples for each of the media types that the operation
+// produces, or JSON if it does not say.
+func convertResponse(r *yaml.Node, produces []string) *yaml.Node {
+	if ref := mappingValue(r, "$ref"); ref != nil {
+		out := newMapping()
+		setValue(out, "$ref", ref)
+		return out
+	}
+
+	out := newMapping()
+	for key, value := range mappingPairs(r) {
+		switch key {
+		case "schema", "examples":
+		case "headers":
+			headers := newMapping()
+			for name, h := range mappingPairs(value) {
+				header := newMapping()
+				if description := mappingValue(h, "description"); description != nil {
+					setValue(header, "description", description)
+				}
+				setValue(header, "schema", parameterSchema(h))
+				setValue(headers, name, header)
+			}
+			setValue(out, key, headers)
+		default:
+			setValue(out, key, value)
+		}
+	}
+	if mappingValue(out, "description") == nil {
+		setValue(out, "description", newString(""))
+	}
+
+	schema := mappingValue(r, "schema")
+	if schema == nil {
+		return out
+	}
+	examples := mappingValue(r, "examples")
+	mediaTypes := produces
+	if len(mediaTypes) == 0 {
+		mediaTypes = []string{"application/json"}
+	}
+	content := newMapping()
+	for _, mediaType := range mediaTypes {
+		mt := newMapping()
+		setValue(mt, "schema", convertSchema(schema))
+		if example := mappingValue(examples, mediaType); example != nil {
+			setValue(mt, "example", example)
+		}
+		setValue(content, mediaType, mt)
+	}
+	setValue(out, "content", content)
+	return out
+}
+
+// convertSchema converts the parts of a schema that are different in OpenAPI 3.0: x-nullable is nullable, the
+// discriminator is an object, and files are binary strings.
+func convertSchema(schema *yaml.Node) *yaml.Node {
+	if schema.Kind != yaml.MappingNode {
+		return schema
+	}
+	out := newMapping()
+	for key, value := range mappingPairs(schema) {
+		switch key {
+		case "x-nullable":
+			setValue(out, "nullable", value)
+		case "discriminator":
+			discriminator := newMapping()
+			setValue(discriminator, "propertyName", value)
+			setValue(out, key, discriminator)
+		case "type":
+			if value.Value == "file" {
+				setValue(out, "type", newString("string"))
+				setValue(out, "format", newString("binary"))
+			} else {
+				setValue(out, key, value)
+			}
+		case "properties":
+			properties := newMapping()
+			for name, property := range mappingPairs(value) {
+				setValue(properties, name, convertSchema(property))
+			}
+			setValue(out, key, properties)
+		case "items", "additionalProperties":
+			setValue(out, key, convertSchema(value))
+		case "allOf":
+			all := newSequence()
+			for _, s := range value.Content {
+				all.Content = append(all.Content, convertSchema(s))
+			}
+			setValue(out, key, all)
+		default:
+			setValue(out, key, value)
+		}
+	}
+	return out
+}
+
+// oauthFlows maps the OAuth2 flows of Swagger 2.0 to the ones of OpenAPI 3.0.
+var oauthFlows = map[string]string{
+	"implicit":    "implicit",
+	"password":    "password",
+	"application": "clientCredentials",
+	"accessCode":  "authorizationCode",
+}
+
+func convertSecurityScheme(scheme *yaml.Node) *yaml.Node {
+	out := newMapping()
+	switch scalarValue(scheme, "type") {
+	case "basic":
+		setValue(out, "type", newString("http"))
+		setValue(out, "scheme", newString("basic"))
+	case "oauth2":
+		setValue(out, "type", newString("oauth2"))
+		flow := newMapping()
+		for _, key := range []string{"authorizationUrl", "tokenUrl"} {
+			if value := mappingValue(scheme, key); value != nil {
+				setValue(flow, key, value)
+			}
+		}
+		scopes := mappingValue(scheme, "scopes")
+		if scopes == nil {
+			scopes = newMapping()
+		}
+		setValue(flow, "scopes", scopes)
+		flows := newMapping()
+		setValue(flows, oauthFlows[scalarValue(scheme, "flow")], flow)
+		setValue(out, "flows", flows)
+	default:
+		for key, value := range mappingPairs(scheme) {
+			if key == "type" || key == "name" || key == "in" {
+				setValue(out, key, value)
+			}
+		}
+	}
+	for key, value := range mappingPairs(scheme) {
+		if key == "description" || strings.HasPrefix(key, "x-") {
+			setValue(out, key, value)
+		}
+	}
+	return out
+}
+
+// swaggerRefs replaces the prefixes of the references of Swagger 2.0 with the ones of OpenAPI 3.0.
+var swaggerRefs = strings.NewReplacer(
+	"#/definitions/", "#/components/schemas/",
+	"#/parameters/", "#/components/parameters/",
+	"#/responses/", "#/components/responses/",
+)
+
+// rewriteSwaggerRefs rewrites the references within the converted document to where the components are in OpenAPI 3.0.
+// References to other files are left as they are, since those are not converted.
+func rewriteSwaggerRefs(node *yaml.Node) {
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == "$ref" && strings.HasPrefix(node.Content[i+1].Value, "#/") {
+				node.Content[i+1].Value = swaggerRefs.Replace(node.Content[i+1].Value)
+			}
+		}
+	}
+	for _, child := range node.Content {
+		rewriteSwaggerRefs(child)
+	}
+}
+
+// mappingPairs iterates over the keys and values of a YAML mapping, in order. Anything else has no pairs.
+func mappingPairs(node *yaml.Node) iter.Seq2[string, *yaml.Node] {
+	return func(yield func(string, *yaml.Node) bool) {
+		if node == nil || node.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if !yield(node.Content[i].Value, node.Content[i+1]) {
+				return
+			}
+		}
+	}
+}
+
+// mappingValue returns the value of the key in a YAML mapping, or nil if there is none.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// scalarValue returns the value of the key in a YAML mapping if it is a scalar, or an empty string.
+func scalarValue(node *yaml.Node, key string) string {
+	if value := mappingValue(node, key); value != nil && value.Kind == yaml.ScalarNode {
+		return value.Value
+	}
+	return ""
+}
+
+// stringList returns the scalars of a YAML sequence.
+func stringList(node *yaml.Node) []string {
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return nil
+	}
+	list := make([]string, 0, len(node.Content))
+	for _, item := range node.Content {
+		list = append(list, item.Value)
+	}
+	return list
+}
+
+// setValue sets the key of a YAML mapping, replacing the value if the key is already there.
+func setValue(node *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content[i+1] = value
+			return
+		}
+	}
+	node.Content = append(node.Content, newString(key), value)
+}
+
+func newMapping() *yaml.Node {
+	return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+}
+
+func newSequence() *yaml.Node {
+	return &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+}
+
+func newString(s string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: s}
+}
+
+func newBool(b bool) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: fmt.Sprint(b)}
+}
@@ -0,0 +1,174 @@
+package copper
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSwagger2(t *testing.T) {
+	f, err := os.ReadFile("testdata/swagger2-spec.yaml")
+	require.NoError(t, err)
+
+	response := func(method, target, contentType, reqBody string, status int, body string) *http.Response {
+		req := httptest.NewRequest(method, target, strings.NewReader(reqBody))
+		req.Header.Set("Authorization", "Bearer token")
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		res := &http.Response{StatusCode: status, Request: req, Header: http.Header{}, Body: http.NoBody}
+		if body != "" {
+			res.Header.Set("Content-Type", "application/json")
+			res.Body = io.NopCloser(strings.NewReader(body))
+		}
+		return res
+	}
+
+	t.Run("converted and verified", func(t *testing.T) {
+		v, err := NewVerifier(f, WithRequestValidation())
+		require.NoError(t, err)
+
+		v.Record(response(http.MethodGet, "https://api.example.com/v1/things?tags=a,b", "", "", 200,
+			`[{"id":"a","name":"b","owner":null}]`))
+		v.Record(response(http.MethodPost, "https://api.example.com/v1/things", "application/json",
+			`{"id":"a","name":"b"}`, 201, `{"id":"a","name":"b"}`))
+		v.Record(response(http.MethodGet, "https://api.example.com/v1/things/a", "", "", 200, `{"id":"a","name":"b"}`))
+		v.Record(response(http.MethodGet, "https://api.example.com/v1/things/b", "", "", 404, `{"message":"gone"}`))
+
+		var form bytes.Buffer
+		w := multipart.NewWriter(&form)
+		picture, err := w.CreateFormFile("picture", "a.png")
+		require.NoError(t, err)
+		_, err = picture.Write([]byte("\x89PNG"))
+		require.NoError(t, err)
+		require.NoError(t, w.WriteField("caption", "a thing"))
+		require.NoError(t, w.Close())
+		v.Record(response(http.MethodPut, "https://api.example.com/v1/things/a/picture", w.FormDataContentType(),
+			form.String(), 204, ""))
+
+		assert.NoError(t, v.CurrentError())
+	})
+
+	t.Run("invalid traffic", func(t *testing.T) {
+		tt := []struct {
+			name string
+			res  *http.Response
+			err  error
+		}{
+			{
+				name: "response without a required property",
+				res:  response(http.MethodGet, "https://api.example.com/v1/things/a", "", "", 200, `{"id":"a"}`),
+				err:  ErrResponseInvalid,
+			},
+			{
+				name: "response of a global response without a required property",
+				res:  response(http.MethodGet, "https://api.example.com/v1/things/a", "", "", 404, `{}`),
+				err:  ErrResponseInvalid,
+			},
+			{
+				name: "request body of a global parameter",
+				res: response(http.MethodPost, "https://api.example.com/v1/things", "application/json",
+					`{"id":"a"}`, 201, `{"id":"a","name":"b"}`),
+				err: ErrRequestInvalid,
+			},
+			{
+				name: "undocumented path",
+				res:  response(http.MethodGet, "https://api.example.com/v1/others", "", "", 200, `{}`),
+				err:  ErrNotPartOfSpec,
+			},
+		}
+
+		for _, tc := range tt {
+			t.Run(tc.name, func(t *testing.T) {
+				v, err := NewVerifier(f, WithRequestValidation(), WithoutFullCoverage())
+				require.NoError(t, err)
+
+				v.Record(tc.res)
+				assert.ErrorIs(t, v.CurrentError(), tc.err)
+			})
+		}
+	})
+
+	t.Run("coordinates", func(t *testing.T) {
+		v, err := NewVerifier(f)
+		require.NoError(t, err)
+
+		var coordinates []string
+		for _, e := range v.Endpoints() {
+			coordinates = append(coordinates, e.Method+" "+e.Path+" "+e.ResponseCode)
+		}
+		assert.ElementsMatch(t, []string{
+			"GET /things 200",
+			"POST /things 201",
+			"GET /things/{id} 200",
+			"GET /things/{id} 404",
+			"PUT /things/{id}/picture 204",
+		}, coordinates)
+	})
+
+	t.Run("forced", func(t *testing.T) {
+		withoutVersion := bytes.Replace(f, []byte(`swagger: "2.0"`), nil, 1)
+		_, err := NewVerifier(withoutVersion)
+		assert.Error(t, err, "a spec without a version is not detected as Swagger")
+
+		_, err = NewVerifier(withoutVersion, WithSwagger2())
+		assert.NoError(t, err)
+	})
+
+	t.Run("forced for OpenAPI", func(t *testing.T) {
+		spec, err := os.ReadFile("testdata/minimal-spec.yaml")
+		require.NoError(t, err)
+
+		_, err = NewVerifier(spec, WithSwagger2())
+		assert.ErrorContains(t, err, "WithSwagger2 is given, but the spec is OpenAPI 3.0")
+	})
+
+	t.Run("unsupported version", func(t *testing.T) {
+		_, err := NewVerifier(bytes.Replace(f, []byte(`swagger: "2.0"`), []byte(`swagger: "1.2"`), 1))
+		assert.ErrorContains(t, err, "swagger version 1.2 is not supported")
+	})
+}
+
+func TestConvertSwagger2(t *testing.T) {
+	f, err := os.ReadFile("testdata/swagger2-spec.yaml")
+	require.NoError(t, err)
+
+	converted, err := convertSwagger2(f, false)
+	require.NoError(t, err)
+
+	for _, expected := range []string{
+		`openapi: 3.0.3`,
+		`- url: https://api.example.com/v1`,
+		`$ref: '#/components/schemas/Thing'`,
+		`$ref: '#/components/requestBodies/newThing'`,
+		`$ref: '#/components/parameters/thingId'`,
+		`$ref: '#/components/responses/notFound'`,
+		`nullable: true`,
+		`style: form`,
+		`explode: false`,
+		`multipart/form-data:`,
+		`format: binary`,
+		`scheme: basic`,
+		`authorizationCode:`,
+	} {
+		assert.Contains(t, string(converted), expected)
+	}
+	assert.NotContains(t, string(converted), "#/definitions/")
+
+	t.Run("OpenAPI is left as it is", func(t *testing.T) {
+		spec, err := os.ReadFile("testdata/minimal-spec.yaml")
+		require.NoError(t, err)
+
+		converted, err := convertSwagger2(spec, false)
+		require.NoError(t, err)
+		assert.Equal(t, spec, converted)
+	})
+}
@@ -0,0 +1,123 @@
+//go:build !copper_lite
+
+package copper
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteTAP writes the verification results in the Test Anything Protocol (version 13), for harnesses and CI plugins
+// that understand TAP. Every coordinate of the spec is a test point, named by the coordinate and its operation, if the
+// spec names it. A test point fails if any error was found for it, or if it has not been checked while full coverage is
+// required, or the coverage is below the threshold of WithCoverageThreshold. Otherwise, coordinates that have not been
+// checked are skipped. Errors that do not belong to a coordinate, like requests to paths that are not part of the spec,
+// are added as failing test points at the end.
+// Coordinates that are waived, like 500 responses without WithInternalServerErrors, are skipped test points with the
+// reason. The errors of a failing test point are listed in its YAML block.
+func (v *Verifier) WriteTAP(w io.Writer) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	ends := v.endpoints.All()
+	attributed := make(map[error]bool)
+	for _, e := range ends {
+		for _, err := range v.endpoints.Failures(e) {
+			attributed[err] = true
+		}
+	}
+
+	var other []error
+	for _, err := range v.currentErrors() {
+		if !attributed[err] && !errors.Is(err, ErrNotChecked) {
+			other = append(other, err)
+		}
+	}
+
+	waived := v.endpoints.Waived()
+
+	t := tapWriter{w: w}
+	t.printf("TAP version 13\n1..%d\n", len(ends)+len(waived)+len(other))
+	for _, e := range ends {
+		desc := tapDescription(e)
+		failures := v.endpoints.Failures(e)
+		switch {
+		case len(failures) > 0:
+			t.point(false, desc, "", failures)
+		case v.endpoints.IsChecked(e.Path, e.Method, e.ResponseCode):
+			t.point(true, desc, "", nil)
+		case !v.requiresCoverage():
+			t.point(true, desc, "SKIP not checked", nil)
+		default:
+			t.point(false, desc, "", []error{ErrNotChecked})
+		}
+	}
+	for _, e := range waived {
+		t.point(true, tapDescription(e.Endpoint), "SKIP waived: "+e.Reason, nil)
+	}
+	for _, err := range other {
+		desc := "error"
+		var verr *VerificationError
+		if errors.As(err, &verr) {
+			desc = verr.Sentinel().Error()
+		}
+		t.point(false, desc, "", []error{err})
+	}
+	return t.err
+}
+
+// tapDescription describes the test point of a coordinate, with its operation if the spec names it.
+func tapDescription(e Endpoint) string {
+	desc := fmt.Sprintf("%s %s %s", e.Method, e.Path, e.ResponseCode)
+	if op := e.Operation(); op != "" {
+		desc += ": " + op
+	}
+	return desc
+}
+
+// tapWriter writes test points, and keeps the first error from the writer so that it only has to be checked once.
+type tapWriter struct {
+	w   io.Writer
+	n   int
+	err error
+}
+
+func (t *tapWriter) printf(format string, args ...any) {
+	if t.err == nil {
+		_, t.err = fmt.Fprintf(t.w, format, args...)
+	}
+}
+
+func (t *tapWriter) point(ok bool, desc, directive string, errs []error) {
+	t.n++
+
+	status := "ok"
+	if !ok {
+		status = "not ok"
+	}
+	line := fmt.Sprintf("%s %d - %s", status, t.n, tapEscape(desc))
+	if directive != "" {
+		line += " # " + directive
+	}
+	t.printf("%s\n", line)
+
+	if len(errs) == 0 {
+		return
+	}
+	t.printf("  ---\n  errors:\n")
+	for _, err := range errs {
+		// A JSON string is also a valid YAML string, and keeps errors that span several lines on one.
+		quoted, _ := json.Marshal(err.Error())
+		t.printf("    - %s\n", quoted)
+	}
+	t.printf("  ...\n")
+}
+
+// tapEscape makes the description of a test point safe to put on a single line, where a # would start a directive.
+func tapEscape(desc string) string {
+	desc = strings.ReplaceAll(desc, "\n", " ")
+	return strings.ReplaceAll(desc, "#", `\#`)
+}
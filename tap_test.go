@@ -0,0 +1,112 @@
+//go:build !copper_lite
+
+package copper
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteTAP(t *testing.T) {
+	f, err := os.ReadFile("testdata/thing-spec.yaml")
+	require.NoError(t, err)
+
+	record := func(v *Verifier, path, body string) {
+		v.Record(&http.Response{
+			StatusCode: 200,
+			Request:    httptest.NewRequest(http.MethodGet, path, nil),
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+		})
+	}
+
+	t.Run("all passing", func(t *testing.T) {
+		v, err := NewVerifier(f)
+		require.NoError(t, err)
+		record(v, "/ping", `{"message": "pong"}`)
+		record(v, "/other", `{"thing": "thing"}`)
+
+		var buf bytes.Buffer
+		require.NoError(t, v.WriteTAP(&buf))
+		assert.Equal(t, "TAP version 13\n1..2\nok 1 - GET /other 200\nok 2 - GET /ping 200\n", buf.String())
+	})
+
+	t.Run("failures are reported per coordinate", func(t *testing.T) {
+		v, err := NewVerifier(f)
+		require.NoError(t, err)
+		record(v, "/ping", `{"message": 1}`)
+		record(v, "/missing", `{}`)
+
+		var buf bytes.Buffer
+		require.NoError(t, v.WriteTAP(&buf))
+
+		lines := strings.Split(buf.String(), "\n")
+		assert.Equal(t, "1..3", lines[1])
+		assert.Equal(t, "not ok 1 - GET /other 200", lines[2])
+		assert.Contains(t, buf.String(), "    - \"not checked\"\n")
+		assert.Contains(t, buf.String(), "not ok 2 - GET /ping 200\n  ---\n  errors:\n    - \"response invalid: GET /ping:")
+		assert.Contains(t, buf.String(), "not ok 3 - not part of spec\n")
+	})
+
+	t.Run("unchecked coordinates are skipped without full coverage", func(t *testing.T) {
+		v, err := NewVerifier(f, WithoutFullCoverage())
+		require.NoError(t, err)
+		record(v, "/ping", `{"message": "pong"}`)
+
+		var buf bytes.Buffer
+		require.NoError(t, v.WriteTAP(&buf))
+		assert.Equal(t, "TAP version 13\n1..2\nok 1 - GET /other 200 # SKIP not checked\nok 2 - GET /ping 200\n", buf.String())
+	})
+
+	t.Run("unchecked coordinates are skipped above the coverage threshold", func(t *testing.T) {
+		v, err := NewVerifier(f, WithCoverageThreshold(50))
+		require.NoError(t, err)
+		record(v, "/ping", `{"message": "pong"}`)
+
+		var buf bytes.Buffer
+		require.NoError(t, v.WriteTAP(&buf))
+		assert.Equal(t, "TAP version 13\n1..2\nok 1 - GET /other 200 # SKIP not checked\nok 2 - GET /ping 200\n", buf.String())
+
+		v, err = NewVerifier(f, WithCoverageThreshold(75))
+		require.NoError(t, err)
+		record(v, "/ping", `{"message": "pong"}`)
+
+		buf.Reset()
+		require.NoError(t, v.WriteTAP(&buf))
+		assert.Contains(t, buf.String(), "not ok 1 - GET /other 200\n")
+	})
+
+	t.Run("test points are named by their operations", func(t *testing.T) {
+		routes, err := os.ReadFile("testdata/route-spec.yaml")
+		require.NoError(t, err)
+		v, err := NewVerifier(routes, WithoutFullCoverage())
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		require.NoError(t, v.WriteTAP(&buf))
+		assert.Equal(t, "TAP version 13\n1..3\n"+
+			"ok 1 - GET /things/export 200 # SKIP not checked\n"+
+			"ok 2 - GET /things/{id} 200: getThing (Things) # SKIP not checked\n"+
+			"ok 3 - PUT /things/{id} 204: Update a thing # SKIP not checked\n", buf.String())
+	})
+
+	t.Run("waived coordinates are skipped with the reason", func(t *testing.T) {
+		faults, err := os.ReadFile("testdata/server-error-spec.yaml")
+		require.NoError(t, err)
+		v, err := NewVerifier(faults)
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		require.NoError(t, v.WriteTAP(&buf))
+		assert.Equal(t, "TAP version 13\n1..1\nok 1 - GET /fault 500 # SKIP waived: 500 responses are not required "+
+			"without WithInternalServerErrors or x-copper-strict\n", buf.String())
+	})
+}
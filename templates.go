@@ -0,0 +1,50 @@
+package copper
+
+import (
+	"strings"
+	"text/template"
+)
+
+// ErrorTemplates are text/template templates for how errors are rendered, so that the failures can match an in-house
+// format, or link to runbooks.
+type ErrorTemplates struct {
+	// Errors has the template for the errors of each sentinel, which is executed with an ErrorData. The errors of
+	// sentinels without a template are rendered as usual.
+	Errors map[SentinelError]*template.Template
+	// Summary is the template for the failure that Verify reports, which is executed with the Summary of the results.
+	// Its Errors are rendered with the templates for them. Without it, Verify reports the errors as they are.
+	Summary *template.Template
+}
+
+// ErrorData is what the template for the errors of a sentinel is executed with.
+type ErrorData struct {
+	// Sentinel is the message of the sentinel, like "response invalid".
+	Sentinel string
+	// Message is the message of the error, without the sentinel.
+	Message string
+	// RequestID is the ID of the request that the error was found in, if WithRequestIDs is given.
+	RequestID string
+}
+
+// WithErrorTemplates is a functional Option for rendering the errors and the failure of Verify with templates. A template
+// that fails to execute falls back to the usual rendering, with the reason appended.
+func WithErrorTemplates(templates ErrorTemplates) Option {
+	return func(c *config) {
+		c.errorTemplates = templates
+	}
+}
+
+// render executes the template with the data, and returns false if that fails, along with the reason.
+func render(tmpl *template.Template, data any) (string, bool) {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return err.Error(), false
+	}
+	return b.String(), true
+}
+
+// withTemplate sets the template for the sentinel of the error, if there is one.
+func (c config) withTemplate(verr *VerificationError) *VerificationError {
+	verr.template = c.errorTemplates.Errors[verr.sentinel]
+	return verr
+}
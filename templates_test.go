@@ -0,0 +1,98 @@
+package copper
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorTemplates(t *testing.T) {
+	f, err := os.ReadFile("testdata/thing-spec.yaml")
+	require.NoError(t, err)
+
+	templates := ErrorTemplates{
+		Errors: map[SentinelError]*template.Template{
+			ErrNotChecked: template.Must(template.New("").Parse("[COVERAGE] {{.Message}}")),
+			ErrResponseInvalid: template.Must(template.New("").Parse(
+				"[CONTRACT] {{.Sentinel}}: {{.Message}} (see https://runbooks.example.com/contract)")),
+		},
+		Summary: template.Must(template.New("").Parse(
+			"{{.Title}} {{.Version}}: {{.Checked}}/{{.Endpoints}} checked{{range .Errors}}\n- {{.}}{{end}}")),
+	}
+
+	record := func(v *Verifier, path, body string) {
+		v.Record(&http.Response{
+			StatusCode: 200,
+			Request:    httptest.NewRequest(http.MethodGet, path, nil),
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+		})
+	}
+
+	t.Run("errors are rendered by their sentinel", func(t *testing.T) {
+		v, err := NewVerifier(f, WithErrorTemplates(templates))
+		require.NoError(t, err)
+		record(v, "/ping", `{}`)
+
+		errs := v.CurrentErrors()
+		require.Len(t, errs, 2)
+		assert.ErrorIs(t, errs[0], ErrResponseInvalid)
+		assert.True(t, strings.HasPrefix(errs[0].Error(), "[CONTRACT] response invalid: GET /ping: "))
+		assert.True(t, strings.HasSuffix(errs[0].Error(), "(see https://runbooks.example.com/contract)"))
+		assert.ErrorIs(t, errs[1], ErrNotChecked)
+		assert.EqualError(t, errs[1], "[COVERAGE] 1 coordinate on 1 path\n  /other: GET 200")
+	})
+
+	t.Run("sentinels without a template are rendered as usual", func(t *testing.T) {
+		v, err := NewVerifier(f, WithErrorTemplates(templates))
+		require.NoError(t, err)
+		v.Record(&http.Response{
+			StatusCode: 200,
+			Request:    httptest.NewRequest(http.MethodGet, "/nowhere", nil),
+			Body:       http.NoBody,
+		})
+
+		errs := v.CurrentErrors()
+		require.NotEmpty(t, errs)
+		assert.ErrorIs(t, errs[0], ErrNotPartOfSpec)
+		assert.True(t, strings.HasPrefix(errs[0].Error(), "not part of spec: GET /nowhere"))
+	})
+
+	t.Run("failure of Verify is rendered with the summary", func(t *testing.T) {
+		v, err := NewVerifier(f, WithErrorTemplates(templates))
+		require.NoError(t, err)
+		record(v, "/ping", `{"message": "pong"}`)
+
+		msg, failed := v.failure()
+		assert.True(t, failed)
+		assert.Equal(t, "thing test 1.0: 1/2 checked\n- [COVERAGE] 1 coordinate on 1 path\n  /other: GET 200", msg)
+
+		record(v, "/other", `{"thing": "thing"}`)
+		_, failed = v.failure()
+		assert.False(t, failed)
+	})
+
+	t.Run("failing templates fall back to the usual rendering", func(t *testing.T) {
+		broken := template.Must(template.New("").Parse("{{.Missing}}"))
+		v, err := NewVerifier(f, WithErrorTemplates(ErrorTemplates{
+			Errors:  map[SentinelError]*template.Template{ErrNotChecked: broken},
+			Summary: broken,
+		}))
+		require.NoError(t, err)
+
+		errs := v.CurrentErrors()
+		require.Len(t, errs, 1)
+		assert.True(t, strings.HasPrefix(errs[0].Error(), "not checked: 2 coordinates on 2 paths\n  /other: GET 200\n  /ping: GET 200 (error template failed: "))
+
+		msg, failed := v.failure()
+		assert.True(t, failed)
+		assert.Contains(t, msg, "(summary template failed: ")
+	})
+}
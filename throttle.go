@@ -0,0 +1,73 @@
+package copper
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// WithRateLimit is a functional Option for the client from WrapClient, which spaces the requests it sends so that there
+// are at most rps of them per second, for suites that run against shared environments with rate limiters. Requests wait
+// for their turn, or until their context is done. If a 429 response is received anyway, the requests after it also wait
+// for as long as its Retry-After header says.
+func WithRateLimit(rps float64) Option {
+	return func(c *config) {
+		if rps <= 0 {
+			c.conflicts = append(c.conflicts, fmt.Errorf("WithRateLimit is given %v, but the rate must be above 0", rps))
+		}
+		c.rateLimit = rps
+	}
+}
+
+// throttle spaces the requests of a client, which share it between goroutines.
+type throttle struct {
+	mu sync.Mutex
+	// next is the earliest time that the next request can be sent.
+	next time.Time
+}
+
+// wait blocks until the request can be sent at the rate, and reserves its turn. It returns the error of the context if
+// that is done first.
+func (t *throttle) wait(ctx context.Context, rps float64) error {
+	t.mu.Lock()
+	at := t.next
+	if now := time.Now(); at.Before(now) {
+		at = now
+	}
+	t.next = at.Add(time.Duration(float64(time.Second) / rps))
+	t.mu.Unlock()
+
+	d := time.Until(at)
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// backOff holds back the next request until the time that the Retry-After header of a 429 response says. A header
+// that is missing or malformed is ignored, which the validation of the response reports if it is turned on.
+func (t *throttle) backOff(retryAfter string, now time.Time) {
+	var until time.Time
+	if seconds, err := parseNonNegative(retryAfter); err == nil {
+		until = now.Add(time.Duration(seconds) * time.Second)
+	} else if date, err := http.ParseTime(retryAfter); err == nil {
+		until = date
+	} else {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if until.After(t.next) {
+		t.next = until
+	}
+}
@@ -0,0 +1,79 @@
+package copper
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithRateLimit(t *testing.T) {
+	f, err := os.ReadFile("testdata/minimal-spec.yaml")
+	require.NoError(t, err)
+
+	s := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}),
+	)
+	defer s.Close()
+
+	t.Run("spaced requests", func(t *testing.T) {
+		c := MustWrapClient(http.DefaultClient, bytes.NewReader(f), WithRateLimit(20))
+
+		start := time.Now()
+		for range 5 {
+			_, err := c.Get(s.URL + "/ping")
+			require.NoError(t, err)
+		}
+		assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+		c.Verify(t)
+	})
+
+	t.Run("context done while waiting", func(t *testing.T) {
+		c := MustWrapClient(http.DefaultClient, bytes.NewReader(f), WithRateLimit(0.1))
+		_, err := c.Get(s.URL + "/ping")
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL+"/ping", nil)
+		require.NoError(t, err)
+		_, err = c.Do(req)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	for _, rps := range []float64{0, -1} {
+		_, err := WrapClient(http.DefaultClient, bytes.NewReader(f), WithRateLimit(rps))
+		assert.ErrorIs(t, err, ErrInvalidOptions)
+	}
+}
+
+func TestThrottleBackOff(t *testing.T) {
+	now := time.Date(2015, 10, 21, 7, 0, 0, 0, time.UTC)
+
+	tt := []struct {
+		name       string
+		retryAfter string
+		next       time.Time
+	}{
+		{"seconds", "120", now.Add(2 * time.Minute)},
+		{"date", "Wed, 21 Oct 2015 07:28:00 GMT", time.Date(2015, 10, 21, 7, 28, 0, 0, time.UTC)},
+		{"missing", "", time.Time{}},
+		{"malformed", "soon", time.Time{}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			th := &throttle{}
+			th.backOff(tc.retryAfter, now)
+			assert.True(t, tc.next.Equal(th.next), "expected %v, got %v", tc.next, th.next)
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package copper
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Timings are how long the phases of a request took on the network, as reported by httptrace for the requests that the
+// client from WrapClient sends. They tell a slow server apart from slow validation, which the request log reports as
+// the duration. Phases that did not happen, like the DNS lookup of an IP address or the connect and TLS handshake of a
+// reused connection, are zero. For redirected requests, the timings are the ones of the final request.
+type Timings struct {
+	DNS     time.Duration `json:"dns"`
+	Connect time.Duration `json:"connect"`
+	TLS     time.Duration `json:"tls"`
+	// TTFB is the time from when the request was written to the first byte of the response, which is mostly spent in
+	// the server.
+	TTFB time.Duration `json:"ttfb"`
+}
+
+// String formats the timings in the logfmt style of the request log.
+func (t Timings) String() string {
+	return fmt.Sprintf("dns=%s connect=%s tls=%s ttfb=%s", t.DNS, t.Connect, t.TLS, t.TTFB)
+}
+
+// timingsKey is the context key of the timer of a request.
+type timingsKey struct{}
+
+// timer collects the timings of a request from its httptrace hooks, which the transport can call from other
+// goroutines.
+type timer struct {
+	mu                               sync.Mutex
+	dnsStart, connectStart, tlsStart time.Time
+	wrote                            time.Time
+	timings                          Timings
+	gotFirstByte                     bool
+}
+
+// hook adds the hooks of the timer to the trace, and returns a context with the timer for requestTimings to find.
+func (t *timer) hook(ctx context.Context, trace *httptrace.ClientTrace) context.Context {
+	trace.DNSStart = func(httptrace.DNSStartInfo) { t.start(&t.dnsStart) }
+	trace.DNSDone = func(httptrace.DNSDoneInfo) { t.done(&t.dnsStart, &t.timings.DNS) }
+	trace.ConnectStart = func(string, string) { t.start(&t.connectStart) }
+	trace.ConnectDone = func(string, string, error) { t.done(&t.connectStart, &t.timings.Connect) }
+	trace.TLSHandshakeStart = func() { t.start(&t.tlsStart) }
+	trace.TLSHandshakeDone = func(_ tls.ConnectionState, _ error) { t.done(&t.tlsStart, &t.timings.TLS) }
+	trace.GetConn = func(string) { t.reset() }
+	trace.WroteRequest = func(httptrace.WroteRequestInfo) { t.start(&t.wrote) }
+	trace.GotFirstResponseByte = func() {
+		t.done(&t.wrote, &t.timings.TTFB)
+		t.mu.Lock()
+		t.gotFirstByte = true
+		t.mu.Unlock()
+	}
+	return context.WithValue(ctx, timingsKey{}, t)
+}
+
+// reset forgets the timings of an earlier request, like one that was redirected, when a new one gets a connection.
+func (t *timer) reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.timings = Timings{}
+	t.gotFirstByte = false
+}
+
+func (t *timer) start(at *time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	*at = time.Now()
+}
+
+// done sets the duration since the start. Connects that are raced, like for IPv4 and IPv6, count from the last start.
+func (t *timer) done(start *time.Time, d *time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !start.IsZero() {
+		*d = time.Since(*start)
+	}
+}
+
+// requestTimings returns the timings of the request with the context, if it was sent by the client from WrapClient
+// and a response was received.
+func requestTimings(ctx context.Context) (Timings, bool) {
+	t, ok := ctx.Value(timingsKey{}).(*timer)
+	if !ok {
+		return Timings{}, false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.timings, t.gotFirstByte
+}
@@ -0,0 +1,60 @@
+package copper
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimings(t *testing.T) {
+	f, err := os.ReadFile("testdata/minimal-spec.yaml")
+	require.NoError(t, err)
+
+	s := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(20 * time.Millisecond)
+			w.WriteHeader(http.StatusNoContent)
+		}),
+	)
+	defer s.Close()
+
+	t.Run("client", func(t *testing.T) {
+		c := MustWrapClient(s.Client(), bytes.NewReader(f), WithoutFullCoverage())
+		for range 2 {
+			_, err := c.Get(s.URL + "/ping")
+			require.NoError(t, err)
+		}
+		c.Verify(t)
+
+		for _, e := range c.Endpoints() {
+			if e.Path != "/ping" || e.ResponseCode != "204" {
+				assert.Nil(t, e.Timings)
+				continue
+			}
+			if assert.NotNil(t, e.Timings) {
+				assert.GreaterOrEqual(t, e.Timings.TTFB, 20*time.Millisecond)
+				// Only the first of the two requests made a connection, so the averages are below their durations.
+				assert.Positive(t, e.Timings.Connect)
+				assert.Positive(t, e.Timings.TLS)
+				assert.Zero(t, e.Timings.DNS, "no lookup is made for an IP address")
+			}
+		}
+	})
+
+	t.Run("recorded", func(t *testing.T) {
+		v := MustNewVerifier(f, WithoutFullCoverage())
+		res, err := s.Client().Get(s.URL + "/ping")
+		require.NoError(t, err)
+		v.Record(res)
+
+		for _, e := range v.Endpoints() {
+			assert.Nil(t, e.Timings, "only the client from WrapClient has timings")
+		}
+	})
+}
@@ -0,0 +1,68 @@
+package copper
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+)
+
+// WithTLSConfig is a functional Option for the client from WrapClient, which sends its requests with the TLS
+// configuration, like one that trusts the certificate of a staging endpoint. The client that is given to WrapClient is
+// copied rather than changed, along with its transport, which has to be an *http.Transport. The configuration can not
+// be changed with SetOptions once the client has been created.
+func WithTLSConfig(conf *tls.Config) Option {
+	return func(c *config) {
+		c.tlsConfig = conf
+	}
+}
+
+// WithInsecureTLS is a functional Option for the client from WrapClient, which skips the verification of the
+// certificates of servers, for contract tests against httptest.NewTLSServer or endpoints with self-signed certificates.
+// It is applied on top of a configuration from WithTLSConfig, if there is one, and must never be used against
+// production.
+func WithInsecureTLS() Option {
+	return func(c *config) {
+		conf := &tls.Config{}
+		if c.tlsConfig != nil {
+			conf = c.tlsConfig.Clone()
+		}
+		conf.InsecureSkipVerify = true
+		c.tlsConfig = conf
+	}
+}
+
+// withTLSConfig returns a copy of the client with a copy of its transport that uses the TLS configuration. The client
+// is returned as it is without a configuration.
+func withTLSConfig(c *http.Client, conf *tls.Config) (*http.Client, error) {
+	if conf == nil {
+		return c, nil
+	}
+
+	transport, err := withTLSTransport(c.Transport, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	client := *c
+	client.Transport = transport
+	return &client, nil
+}
+
+// withTLSTransport returns a copy of the transport that uses the TLS configuration, or http.DefaultTransport if the
+// transport is nil. The transport is returned as it is without a configuration.
+func withTLSTransport(rt http.RoundTripper, conf *tls.Config) (http.RoundTripper, error) {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	if conf == nil {
+		return rt, nil
+	}
+
+	transport, ok := rt.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("%w: WithTLSConfig needs an *http.Transport, but got a %T", ErrInvalidOptions, rt)
+	}
+	transport = transport.Clone()
+	transport.TLSClientConfig = conf
+	return transport, nil
+}
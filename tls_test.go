@@ -0,0 +1,90 @@
+package copper
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type roundTripper func(*http.Request) (*http.Response, error)
+
+func (f roundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestTLSOptions(t *testing.T) {
+	f, err := os.ReadFile("testdata/minimal-spec.yaml")
+	require.NoError(t, err)
+
+	s := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}),
+	)
+	defer s.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(s.Certificate())
+
+	tt := []struct {
+		name        string
+		opts        []Option
+		shouldError bool
+	}{
+		{"without TLS options", nil, true},
+		{"insecure", []Option{WithInsecureTLS()}, false},
+		{"trusted certificate", []Option{WithTLSConfig(&tls.Config{RootCAs: pool})}, false},
+		{"insecure on top of a config", []Option{WithTLSConfig(&tls.Config{}), WithInsecureTLS()}, false},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			hc := &http.Client{}
+			c, err := WrapClient(hc, bytes.NewReader(f), append(tc.opts, WithServer(s.URL))...)
+			require.NoError(t, err)
+
+			_, err = c.Get(s.URL + "/ping")
+			if tc.shouldError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Nil(t, hc.Transport, "the given client is not changed")
+			c.Verify(t)
+		})
+	}
+
+	t.Run("transport that can not be configured", func(t *testing.T) {
+		hc := &http.Client{Transport: roundTripper(http.DefaultTransport.RoundTrip)}
+		_, err := WrapClient(hc, bytes.NewReader(f), WithInsecureTLS())
+		assert.ErrorIs(t, err, ErrInvalidOptions)
+	})
+
+	t.Run("switched client", func(t *testing.T) {
+		c := MustWrapClient(http.DefaultClient, bytes.NewReader(f), WithInsecureTLS(), WithServer(s.URL))
+		hc := &http.Client{}
+		other, err := c.WithClient(hc)
+		require.NoError(t, err)
+
+		_, err = other.Get(s.URL + "/ping")
+		require.NoError(t, err)
+		assert.Nil(t, hc.Transport, "the given client is not changed")
+		c.Verify(t)
+
+		_, err = c.WithClient(&http.Client{Transport: roundTripper(http.DefaultTransport.RoundTrip)})
+		assert.ErrorIs(t, err, ErrInvalidOptions)
+	})
+
+	t.Run("changed after wrapping", func(t *testing.T) {
+		c := MustWrapClient(http.DefaultClient, bytes.NewReader(f), WithInsecureTLS())
+		assert.ErrorIs(t, c.SetOptions(WithTLSConfig(&tls.Config{})), ErrInvalidOptions)
+		assert.NoError(t, c.SetOptions(WithoutFullCoverage()))
+	})
+}
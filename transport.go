@@ -0,0 +1,59 @@
+package copper
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// validatingTransport is the http.RoundTripper from WrapTransport.
+type validatingTransport struct {
+	rt http.RoundTripper
+	*Verifier
+	throttle *throttle
+}
+
+// WrapTransport takes an HTTP transport and io.Reader for the OpenAPI spec. The spec is parsed, and the transport is
+// wrapped so that every request that it sends is recorded with the response, like with WrapClient. This instruments
+// any existing *http.Client by swapping its Transport, which suits generated SDKs that create clients of their own. A
+// nil transport is http.DefaultTransport. The transport sees every request of a redirect on its own, so each of them
+// is recorded, rather than only the last one as with WrapClient. The Verifier is returned for checking the results.
+func WrapTransport(rt http.RoundTripper, spec io.Reader, opts ...Option) (http.RoundTripper, *Verifier, error) {
+	s, err := io.ReadAll(spec)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not read spec: %w", err)
+	}
+
+	verifier, err := NewVerifier(s, opts...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not create verifier: %w", err)
+	}
+	rt, err = withTLSTransport(rt, verifier.conf.tlsConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not wrap transport: %w", err)
+	}
+
+	return &validatingTransport{
+		rt:       rt,
+		Verifier: verifier,
+		throttle: &throttle{},
+	}, verifier, nil
+}
+
+// RoundTrip sends the request with the wrapped transport, and records the response. The request of the caller is not
+// changed, as the http.RoundTripper contract requires.
+func (t *validatingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	res, err := t.send(r, t.throttle, t.rt.RoundTrip)
+	if err == nil {
+		t.Record(res)
+	}
+	return res, err
+}
+
+// CloseIdleConnections closes the idle connections of the wrapped transport, if it can, which lets
+// http.Client.CloseIdleConnections reach it.
+func (t *validatingTransport) CloseIdleConnections() {
+	if c, ok := t.rt.(interface{ CloseIdleConnections() }); ok {
+		c.CloseIdleConnections()
+	}
+}
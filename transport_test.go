@@ -0,0 +1,89 @@
+package copper
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrapTransport(t *testing.T) {
+	f, err := os.ReadFile("testdata/request-body-spec.yaml")
+	require.NoError(t, err)
+
+	s := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/old" {
+				http.Redirect(w, r, "/req", http.StatusTemporaryRedirect)
+				return
+			}
+			body, _ := io.ReadAll(r.Body)
+			if len(body) == 0 {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}),
+	)
+	defer s.Close()
+
+	t.Run("covered", func(t *testing.T) {
+		rt, v, err := WrapTransport(nil, bytes.NewReader(f), WithRequestValidation(), WithRequestIDs())
+		require.NoError(t, err)
+		c := &http.Client{Transport: rt}
+
+		req, err := http.NewRequest(http.MethodPost, s.URL+"/req", strings.NewReader(`{"input": "pem"}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		res, err := c.Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		assert.Equal(t, http.StatusNoContent, res.StatusCode)
+		assert.Empty(t, req.Header.Get(RequestIDHeader), "the request of the caller is not changed")
+		v.Verify(t)
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		rt, v, err := WrapTransport(http.DefaultTransport, bytes.NewReader(f), WithRequestValidation())
+		require.NoError(t, err)
+		c := &http.Client{Transport: rt}
+
+		_, err = c.Post(s.URL+"/req", "application/json", strings.NewReader(`{"output": "pem"}`))
+		require.NoError(t, err)
+		errs := v.CurrentErrors()
+		if assert.Len(t, errs, 1) {
+			assert.True(t, errors.Is(errs[0], ErrRequestInvalid))
+		}
+	})
+
+	t.Run("every redirect is recorded", func(t *testing.T) {
+		rt, v, err := WrapTransport(nil, bytes.NewReader(f), WithoutFullCoverage())
+		require.NoError(t, err)
+		c := &http.Client{Transport: rt}
+
+		res, err := c.Post(s.URL+"/old", "application/json", strings.NewReader(`{"input": "pem"}`))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, res.StatusCode)
+
+		errs := v.CurrentErrors()
+		if assert.Len(t, errs, 1) {
+			assert.True(t, errors.Is(errs[0], ErrNotPartOfSpec))
+			assert.Contains(t, errs[0].Error(), "/old")
+		}
+		assert.True(t, v.Endpoints()[0].Checked)
+	})
+
+	t.Run("TLS needs an http.Transport", func(t *testing.T) {
+		rt := roundTripper(func(r *http.Request) (*http.Response, error) { return nil, errors.New("unused") })
+		_, _, err := WrapTransport(rt, bytes.NewReader(f), WithInsecureTLS())
+		assert.ErrorIs(t, err, ErrInvalidOptions)
+	})
+}
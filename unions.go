@@ -0,0 +1,204 @@
+package copper
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	validatorerr "github.com/pb33f/libopenapi-validator/errors"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+)
+
+// unionBranch finds a branch of a oneOf or anyOf in the keyword location of a schema validation failure.
+var unionBranch = regexp.MustCompile(`/(oneOf|anyOf)/(\d+)`)
+
+// discriminating matches the keyword location of a failure within a branch that shows that the value is of another
+// kind than the branch is for: a different type, or a property with an enum or const (like a discriminator) that does
+// not match.
+var discriminating = regexp.MustCompile(`^(/\$ref)?/type$|^(/properties/[^/]+)?(/\$ref)?/(enum|const)$`)
+
+// bestUnionMatches replaces the failures of every branch of a oneOf or anyOf that nothing matched with the failures of
+// the branch that matched the best, along with a failure that says which branch that is. The validator library lists
+// the failures of all branches, which for larger unions is a wall of errors where the ones that matter are hard to
+// find.
+func bestUnionMatches(errs []*validatorerr.ValidationError) []*validatorerr.ValidationError {
+	for _, err := range errs {
+		if len(err.SchemaValidationErrors) > 0 {
+			err.SchemaValidationErrors = bestBranches(err.SchemaValidationErrors, 0)
+		}
+	}
+	return errs
+}
+
+// bestBranches reduces the failures of the unions found in the keyword locations after the offset. Unions nested in
+// the best branch are reduced as well.
+func bestBranches(failures []*validatorerr.SchemaValidationFailure, offset int) []*validatorerr.SchemaValidationFailure {
+	type union struct {
+		keyword  string
+		branches map[int][]*validatorerr.SchemaValidationFailure
+		// end is the offset of the end of the branch in the location, for finding nested unions.
+		end map[int]int
+	}
+
+	var (
+		result []*validatorerr.SchemaValidationFailure
+		order  []string
+		unions = make(map[string]*union)
+	)
+	for _, f := range failures {
+		if len(f.Location) < offset {
+			result = append(result, f)
+			continue
+		}
+		m := unionBranch.FindStringSubmatchIndex(f.Location[offset:])
+		if m == nil {
+			result = append(result, f)
+			continue
+		}
+
+		// The union is identified by the location of its keyword, like /properties/owner/oneOf.
+		at := f.Location[:offset+m[3]]
+		u, ok := unions[at]
+		if !ok {
+			u = &union{
+				keyword:  f.Location[offset+m[2] : offset+m[3]],
+				branches: make(map[int][]*validatorerr.SchemaValidationFailure),
+				end:      make(map[int]int),
+			}
+			unions[at] = u
+			order = append(order, at)
+		}
+		branch, _ := strconv.Atoi(f.Location[offset+m[4] : offset+m[5]])
+		u.branches[branch] = append(u.branches[branch], f)
+		u.end[branch] = offset + m[5]
+	}
+
+	for _, at := range order {
+		u := unions[at]
+		// Nested unions are reduced first, so that a branch is scored by what is left of it.
+		for branch, fs := range u.branches {
+			u.branches[branch] = bestBranches(fs, u.end[branch])
+		}
+		if len(u.branches) < 2 {
+			// A single failing branch is already as specific as it gets.
+			for _, fs := range u.branches {
+				result = append(result, fs...)
+			}
+			continue
+		}
+
+		best := bestBranch(u.branches, u.end)
+		result = append(result, &validatorerr.SchemaValidationFailure{
+			Reason: fmt.Sprintf("does not match any of the %d failing %s branches, the closest match is branch %d",
+				len(u.branches), u.keyword, best),
+			Location: at,
+		})
+		result = append(result, u.branches[best]...)
+	}
+	return result
+}
+
+// branchScore ranks how well a value matched a branch. Branches where the value is of another kind are the worst
+// matches, and after that the branch with the fewest failures is the best one. When that is a tie as well, the branch
+// with the deepest failure got the furthest.
+type branchScore struct {
+	mismatches, failures, depth int
+}
+
+func (s branchScore) better(o branchScore) bool {
+	if s.mismatches != o.mismatches {
+		return s.mismatches < o.mismatches
+	}
+	if s.failures != o.failures {
+		return s.failures < o.failures
+	}
+	return s.depth > o.depth
+}
+
+// bestBranch returns the branch that matched the best. Ties go to the first branch.
+func bestBranch(branches map[int][]*validatorerr.SchemaValidationFailure, end map[int]int) int {
+	score := func(branch int) branchScore {
+		s := branchScore{failures: len(branches[branch])}
+		for _, f := range branches[branch] {
+			if discriminating.MatchString(f.Location[end[branch]:]) {
+				s.mismatches++
+			}
+			s.depth = max(s.depth, strings.Count(f.Location[end[branch]:], "/"))
+		}
+		return s
+	}
+
+	indexes := make([]int, 0, len(branches))
+	for i := range branches {
+		indexes = append(indexes, i)
+	}
+	slices.Sort(indexes)
+
+	best, bestScore := indexes[0], score(indexes[0])
+	for _, i := range indexes[1:] {
+		if s := score(i); s.better(bestScore) {
+			best, bestScore = i, s
+		}
+	}
+	return best
+}
+
+// bestUnionCauses does what bestUnionMatches does for the errors of the JSON schema library, which are used for
+// recursive schemas. The causes of a oneOf or anyOf that nothing matched are one per branch, and only the cause of the
+// branch that matched the best is kept.
+func bestUnionCauses(err *jsonschema.ValidationError) {
+	failed := false
+	switch k := err.ErrorKind.(type) {
+	case *kind.AnyOf:
+		failed = true
+	case *kind.OneOf:
+		// A oneOf that matched several branches fails without causes per branch.
+		failed = k.Subschemas == nil
+	}
+
+	// Nested unions are reduced first, so that a branch is scored by what is left of it.
+	for _, c := range err.Causes {
+		bestUnionCauses(c)
+	}
+
+	if failed && len(err.Causes) > 1 {
+		best, bestScore := err.Causes[0], causeScore(err.Causes[0], len(err.InstanceLocation))
+		for _, c := range err.Causes[1:] {
+			if s := causeScore(c, len(err.InstanceLocation)); s.better(bestScore) {
+				best, bestScore = c, s
+			}
+		}
+		err.Causes = []*jsonschema.ValidationError{best}
+	}
+}
+
+// causeScore scores a branch of a union of the value at the given depth, in the same way as for the failures of the
+// validator library.
+func causeScore(err *jsonschema.ValidationError, depth int) branchScore {
+	if len(err.Causes) == 0 {
+		s := branchScore{failures: 1, depth: len(err.InstanceLocation)}
+		switch err.ErrorKind.(type) {
+		case *kind.Type:
+			if len(err.InstanceLocation) == depth {
+				s.mismatches = 1
+			}
+		case *kind.Enum, *kind.Const:
+			if len(err.InstanceLocation) <= depth+1 {
+				s.mismatches = 1
+			}
+		}
+		return s
+	}
+
+	var s branchScore
+	for _, c := range err.Causes {
+		cs := causeScore(c, depth)
+		s.mismatches += cs.mismatches
+		s.failures += cs.failures
+		s.depth = max(s.depth, cs.depth)
+	}
+	return s
+}
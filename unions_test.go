@@ -0,0 +1,92 @@
+package copper
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnionFailures(t *testing.T) {
+	f, err := os.ReadFile("testdata/union-spec.yaml")
+	require.NoError(t, err)
+
+	record := func(v *Verifier, method, path string, status int, body string) {
+		v.Record(&http.Response{
+			StatusCode: status,
+			Request:    httptest.NewRequest(method, path, nil),
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+		})
+	}
+
+	tt := []struct {
+		name        string
+		path        string
+		body        string
+		contains    []string
+		notContains []string
+	}{
+		{
+			name: "branch with a matching discriminator",
+			path: "/payments",
+			body: `{"type": "card", "number": "12", "expiry": "12/30"}`,
+			contains: []string{
+				"does not match any of the 3 failing oneOf branches, the closest match is branch 0",
+				"missing property 'cvc'",
+				"does not match pattern",
+			},
+			notContains: []string{"iban", "value must be 'bank'"},
+		},
+		{
+			name: "branch with the fewest failures",
+			path: "/payments",
+			body: `{"type": "voucher"}`,
+			contains: []string{
+				"the closest match is branch 2",
+				"missing property 'code'",
+			},
+			notContains: []string{"cvc", "iban"},
+		},
+		{
+			name:     "anyOf of other types",
+			path:     "/pets",
+			body:     `[true]`,
+			contains: []string{"failing anyOf branches, the closest match is branch 0"},
+		},
+		{
+			name:        "recursive schema",
+			path:        "/nodes",
+			body:        `{"children": [{"leaf": 1}]}`,
+			contains:    []string{"got number, want string"},
+			notContains: []string{"missing property"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			v, err := NewVerifier(f, WithoutFullCoverage())
+			require.NoError(t, err)
+
+			method, status := http.MethodGet, http.StatusOK
+			if tc.path == "/payments" {
+				method, status = http.MethodPost, http.StatusCreated
+			}
+			record(v, method, tc.path, status, tc.body)
+
+			err = v.CurrentError()
+			require.ErrorIs(t, err, ErrResponseInvalid)
+			for _, s := range tc.contains {
+				assert.Contains(t, err.Error(), s)
+			}
+			for _, s := range tc.notContains {
+				assert.NotContains(t, err.Error(), s)
+			}
+		})
+	}
+}
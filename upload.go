@@ -0,0 +1,153 @@
+package copper
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+// Upload builds a multipart/form-data request, one part at a time, and sends it with the ValidatingClient that created
+// it. The content type of each part is taken from the encoding that the spec documents for the property, where it
+// declares a single one, so that upload endpoints can be covered without getting the parts right by hand.
+type Upload struct {
+	c      *ValidatingClient
+	url    string
+	method string
+	parts  []uploadPart
+}
+
+type uploadPart struct {
+	name   string
+	value  string
+	path   string
+	header textproto.MIMEHeader
+}
+
+// Upload starts a multipart/form-data POST request to the URL, which is sent by Do once the parts have been added.
+func (v *ValidatingClient) Upload(url string) *Upload {
+	return &Upload{c: v, url: url, method: http.MethodPost}
+}
+
+// Method changes the method of the request, which is POST by default.
+func (u *Upload) Method(method string) *Upload {
+	u.method = method
+	return u
+}
+
+// File adds a part with the contents of the file at the path, which is read when the request is sent. Without a
+// content type in the spec, it is worked out from the extension of the file, or else from its contents.
+func (u *Upload) File(name, path string) *Upload {
+	u.parts = append(u.parts, uploadPart{name: name, path: path, header: make(textproto.MIMEHeader)})
+	return u
+}
+
+// Field adds a part with the value, which is plain text unless the spec declares another content type for it.
+func (u *Upload) Field(name, value string) *Upload {
+	u.parts = append(u.parts, uploadPart{name: name, value: value, header: make(textproto.MIMEHeader)})
+	return u
+}
+
+// PartHeader adds a header to the parts with the name, like a checksum that the encoding of the property requires.
+func (u *Upload) PartHeader(name, key, value string) *Upload {
+	for _, p := range u.parts {
+		if p.name == name {
+			p.header.Add(key, value)
+		}
+	}
+	return u
+}
+
+// Do builds the multipart body and sends the request, which is recorded like any other request of the client.
+func (u *Upload) Do(ctx context.Context) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, u.method, u.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	content := u.content(req)
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for _, p := range u.parts {
+		data := []byte(p.value)
+		header := make(textproto.MIMEHeader)
+		for key, values := range p.header {
+			header[key] = values
+		}
+
+		disposition := fmt.Sprintf(`form-data; name="%s"`, escapeQuotes(p.name))
+		if p.path != "" {
+			if data, err = os.ReadFile(p.path); err != nil {
+				return nil, fmt.Errorf("could not read file for part %s: %w", p.name, err)
+			}
+			disposition += fmt.Sprintf(`; filename="%s"`, escapeQuotes(filepath.Base(p.path)))
+		}
+		header.Set("Content-Disposition", disposition)
+		var encoding *v3.Encoding
+		if content != nil {
+			encoding = content.Encoding.GetOrZero(p.name)
+		}
+		if contentType := partContentType(p, data, encoding); contentType != "" && header.Get("Content-Type") == "" {
+			header.Set("Content-Type", contentType)
+		}
+
+		part, err := w.CreatePart(header)
+		if err != nil {
+			return nil, fmt.Errorf("could not write part %s: %w", p.name, err)
+		}
+		if _, err := part.Write(data); err != nil {
+			return nil, fmt.Errorf("could not write part %s: %w", p.name, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("could not write multipart body: %w", err)
+	}
+
+	req, err = http.NewRequestWithContext(ctx, u.method, u.url, bytes.NewReader(body.Bytes()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	return u.c.Do(req)
+}
+
+// content returns the multipart/form-data content of the operation for the request, or nil if it has none.
+func (u *Upload) content(req *http.Request) *v3.MediaType {
+	pathItem, errs, _ := u.c.findPath(req)
+	if len(errs) > 0 {
+		return nil
+	}
+	op := pathItem.GetOperations().GetOrZero(strings.ToLower(req.Method))
+	if op == nil || op.RequestBody == nil {
+		return nil
+	}
+	return op.RequestBody.Content.GetOrZero("multipart/form-data")
+}
+
+// partContentType returns the content type for a part. A content type that the encoding declares on its own is used
+// as it is. Files otherwise get the one of their extension, or of their contents, and fields are plain text.
+func partContentType(p uploadPart, data []byte, encoding *v3.Encoding) string {
+	if encoding != nil && encoding.ContentType != "" && !strings.ContainsAny(encoding.ContentType, ",*") {
+		return strings.TrimSpace(encoding.ContentType)
+	}
+	if p.path == "" {
+		return ""
+	}
+	if contentType := mime.TypeByExtension(filepath.Ext(p.path)); contentType != "" {
+		return contentType
+	}
+	return http.DetectContentType(data)
+}
+
+// escapeQuotes escapes a value for a quoted string of the Content-Disposition header, like mime/multipart does.
+func escapeQuotes(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
@@ -0,0 +1,77 @@
+package copper
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpload(t *testing.T) {
+	f, err := os.ReadFile("testdata/multipart-spec.yaml")
+	require.NoError(t, err)
+
+	png := filepath.Join(t.TempDir(), "avatar.png")
+	require.NoError(t, os.WriteFile(png, []byte("\x89PNG\r\n\x1a\nnot really"), 0o600))
+	unknown := filepath.Join(t.TempDir(), "avatar")
+	require.NoError(t, os.WriteFile(unknown, []byte("\x89PNG\r\n\x1a\nnot really"), 0o600))
+
+	types := make(map[string]string)
+	s := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if err := r.ParseMultipartForm(1 << 16); err == nil {
+				for name, files := range r.MultipartForm.File {
+					types[name] = files[0].Header.Get("Content-Type")
+				}
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}),
+	)
+	defer s.Close()
+
+	tt := []struct {
+		name string
+		file string
+		meta string
+		err  string
+	}{
+		{"according to spec", png, `{"name": "bob"}`, ""},
+		{"content type from contents", unknown, `{"name": "bob"}`, ""},
+		{"invalid part", png, `{}`, "part meta is invalid"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			c := MustWrapClient(http.DefaultClient, bytes.NewReader(f), WithRequestValidation())
+
+			res, err := c.Upload(s.URL+"/uploads").
+				File("file", tc.file).
+				PartHeader("file", "X-Checksum", "abc").
+				Field("meta", tc.meta).
+				Do(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusNoContent, res.StatusCode)
+			assert.Equal(t, "image/png", types["file"])
+
+			if tc.err == "" {
+				assert.NoError(t, c.CurrentError())
+			} else {
+				assert.ErrorIs(t, c.CurrentError(), ErrRequestInvalid)
+				assert.ErrorContains(t, c.CurrentError(), tc.err)
+			}
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		c := MustWrapClient(http.DefaultClient, bytes.NewReader(f))
+
+		_, err := c.Upload(s.URL+"/uploads").File("file", "testdata/missing.png").Do(context.Background())
+		assert.ErrorContains(t, err, "could not read file for part file")
+	})
+}
@@ -1,43 +1,106 @@
 package copper
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"io"
+	"mime"
 	"net/http"
-	"net/http/httputil"
+	"net/http/httptest"
+	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/pb33f/libopenapi"
 	validator "github.com/pb33f/libopenapi-validator"
 	validatorerr "github.com/pb33f/libopenapi-validator/errors"
-	"github.com/pb33f/libopenapi-validator/paths"
 	"github.com/pb33f/libopenapi-validator/schema_validation"
 	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
 )
 
+// Verifier checks recorded requests and responses against a spec, and keeps track of the coverage. It is safe for
+// concurrent use: Record works on copies of the request and the response, and never changes a request that has
+// GetBody, so a Verifier, and a request, can be shared by goroutines.
 type Verifier struct {
+	*state
+	conf      config
+	view      bool
+	validator validator.Validator
+	model     *v3.Document
+	// matching is the model with its paths in the order of precedence, for finding the path of a request.
+	matching *v3.Document
+	// spec is the spec as it was given, for writing parts of it.
+	spec      []byte
+	recursive *recursiveSchemas
+	// jwtSchemes are the security schemes that WithJWTClaims checks the tokens of.
+	jwtSchemes jwtSchemes
+}
+
+// state is what a Verifier has recorded so far, which is shared with the views created by With. The config of the
+// endpoints is the one of the Verifier that created the state, and decides the coverage verdict.
+type state struct {
+	mu         sync.Mutex
 	endpoints  *endpoints
 	errors     []error
-	conf       config
-	mu         sync.Mutex
 	reqCounter atomic.Int64
-	validator  validator.Validator
-	model      *v3.Document
+	// samples counts the recorded requests, to pick the ones to validate when sampling.
+	samples atomic.Int64
+	links   *links
+	// schemes has the security schemes that the spec uses, and whether a request has presented credentials for them.
+	schemes map[string]bool
+	// mediaTypes has the request media types of the operations that accept several, and whether a request has been sent
+	// with them.
+	mediaTypes map[RequestMediaType]bool
+	// drift has the undocumented properties that responses have had, by coordinate and field.
+	drift map[driftKey]*DriftField
+	// appended counts the errors that have been found, including the ones that the error window has dropped.
+	appended int
+	// dropped counts the errors that the error window has dropped, by their sentinel.
+	dropped      map[SentinelError]int
+	lastSnapshot time.Time
 }
 
 // NewVerifier takes bytes for an OpenAPI spec and options, and then returns a new Verifier for the given spec. Supply
-// zero or more Option instances to change the behaviour of the Verifier.
+// zero or more Option instances to change the behaviour of the Verifier. Options that contradict each other result in an
+// error wrapping ErrInvalidOptions.
 func NewVerifier(specBytes []byte, opts ...Option) (*Verifier, error) {
+	conf := getConfig(opts...)
+	if err := conf.validate(); err != nil {
+		return nil, err
+	}
+
+	specBytes, err := convertSwagger2(specBytes, conf.swagger2)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse spec data: %w", err)
+	}
+	return newVerifier(specBytes, conf)
+}
+
+// newVerifier returns a new Verifier for an OpenAPI spec, with options that have been validated.
+func newVerifier(specBytes []byte, conf config) (*Verifier, error) {
 	spec, err := libopenapi.NewDocument(specBytes)
 	if err != nil {
 		return nil, fmt.Errorf("unable to parse spec data: %w", err)
 	}
 
-	ok, validationErrs := schema_validation.ValidateOpenAPIDocument(spec)
+	blanked, err := checkPatterns(specBytes, conf.ecmaPatterns)
+	if err != nil {
+		return nil, fmt.Errorf("schema is not valid: %w", err)
+	}
+	checked := spec
+	if blanked != nil {
+		if checked, err = libopenapi.NewDocument(blanked); err != nil {
+			return nil, fmt.Errorf("unable to parse spec data: %w", err)
+		}
+	}
+
+	ok, validationErrs := schema_validation.ValidateOpenAPIDocument(checked)
 	if !ok {
 		return nil, fmt.Errorf("schema is not valid: %w", toError(validationErrs))
 	}
@@ -47,7 +110,19 @@ func NewVerifier(specBytes []byte, opts ...Option) (*Verifier, error) {
 		return nil, fmt.Errorf("unable to create model: %w", errors.Join(errs...))
 	}
 
-	conf := getConfig(opts...)
+	if err := applyNullability(&model.Model, conf.nullability); err != nil {
+		return nil, fmt.Errorf("schema is not valid: %w", err)
+	}
+	if err := checkParameterPatterns(&model.Model); err != nil {
+		return nil, fmt.Errorf("schema is not valid: %w", err)
+	}
+	if err := checkResponseBudgets(&model.Model); err != nil {
+		return nil, fmt.Errorf("schema is not valid: %w", err)
+	}
+	if err := checkDiscriminators(&model.Model); err != nil {
+		return nil, fmt.Errorf("schema is not valid: %w", err)
+	}
+
 	if conf.serverBase != "" {
 		model.Model.Servers = []*v3.Server{
 			{
@@ -57,73 +132,612 @@ func NewVerifier(specBytes []byte, opts ...Option) (*Verifier, error) {
 		}
 	}
 
+	jwt, err := newJWTSchemes(&model.Model)
+	if err != nil {
+		return nil, fmt.Errorf("schema is not valid: %w", err)
+	}
+
 	docValidator := validator.NewValidatorFromV3Model(&model.Model)
 
 	var v = &Verifier{
-		conf:      conf,
-		validator: docValidator,
-		model:     &model.Model,
-		endpoints: newEndpoints(&model.Model, conf.checkInternalServerErrors),
+		state: &state{
+			endpoints:  newEndpoints(&model.Model, conf),
+			links:      newLinks(&model.Model),
+			schemes:    securitySchemes(&model.Model, conf.filters),
+			mediaTypes: requestMediaTypes(&model.Model, conf.filters),
+		},
+		conf:       conf,
+		validator:  docValidator,
+		model:      &model.Model,
+		matching:   byPrecedence(&model.Model),
+		spec:       specBytes,
+		recursive:  newRecursiveSchemas(specBytes, conf),
+		jwtSchemes: jwt,
 	}
 
+	if conf.exampleValidation {
+		if err := v.checkExamples(); err != nil {
+			return nil, fmt.Errorf("examples do not match their schemas: %w", err)
+		}
+	}
 	return v, nil
 }
 
-func (v *Verifier) check(req *http.Request, res *http.Response) {
-	_, errs, foundPath := paths.FindPath(req, v.model)
-	if len(errs) > 0 {
-		v.appendErr(ErrNotPartOfSpec, fmt.Errorf("%v %v: %v", req.Method, req.URL.Path, toError(errs)))
-		return
+// NewServerVerifier is like NewVerifier, but for verifying a server from the inside, like the adapters for routers do,
+// rather than from the client side. Since the Verifier sits on the provider side, the requests that the server receives
+// are validated by default, strictly encoded queries included, as they must match the spec just as much as the
+// responses do. Requests to paths or methods that are not documented are always reported with ErrNotPartOfSpec. The
+// options are applied after the defaults, so they can still be turned off.
+func NewServerVerifier(specBytes []byte, opts ...Option) (*Verifier, error) {
+	defaults := []Option{WithRequestValidation(), WithStrictQueryEncoding()}
+	return NewVerifier(specBytes, append(defaults, opts...)...)
+}
+
+// MustNewVerifier is like NewVerifier, but panics if the Verifier can not be created. It is meant for test helpers and
+// examples, where a spec that can not be used is a bug in the test.
+func MustNewVerifier(specBytes []byte, opts ...Option) *Verifier {
+	v, err := NewVerifier(specBytes, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// check verifies the request and response, and returns the path in the spec that they were matched with, or an empty
+// string if there is none. For operations that are discriminated by a query parameter, the path includes the value.
+func (v *Verifier) check(req *http.Request, res *http.Response, route string) string {
+	pathItem, foundPath := v.routePath(route)
+	if pathItem == nil || pathItem.GetOperations().GetOrZero(strings.ToLower(req.Method)) == nil {
+		// Without a documented operation for the route, the validator library reports what is wrong with the request.
+		var errs []*validatorerr.ValidationError
+		pathItem, errs, foundPath = v.findPath(req)
+		if len(errs) > 0 {
+			unknown := Endpoint{Path: req.URL.Path, Method: strings.ToUpper(req.Method), ResponseCode: strconv.Itoa(res.StatusCode)}
+			v.appendFailure(ErrNotPartOfSpec, FailureUnknownPath, req, unknown, toError(errs))
+			return ""
+		}
+	}
+
+	// Requests to operations that the filters leave out are ignored, rather than validated, but they can still follow the
+	// links of the operations that are verified.
+	op := pathItem.GetOperations().GetOrZero(strings.ToLower(req.Method))
+	if _, excluded := v.conf.filters.excluded(foundPath, strings.ToUpper(req.Method), op); op != nil && excluded {
+		if v.endpoints.conf.links {
+			v.links.follow(req, foundPath)
+		}
+		return ""
+	}
+
+	covered, err := coveredPath(req, pathItem, foundPath)
+	if err != nil {
+		unknown := Endpoint{Path: req.URL.Path, Method: strings.ToUpper(req.Method), ResponseCode: strconv.Itoa(res.StatusCode)}
+		v.appendFailure(ErrNotPartOfSpec, FailureUnknownPath, req, unknown, err)
+		return ""
+	}
+
+	coord := Endpoint{Path: covered, Method: strings.ToUpper(req.Method), ResponseCode: strconv.Itoa(res.StatusCode)}
+	v.endpoints.MarkChecked(coord.Path, coord.Method, coord.ResponseCode)
+	if timings, ok := requestTimings(req.Context()); ok {
+		v.endpoints.AddTimings(coord, timings)
+	}
+	if req.Method == http.MethodGet && v.conf.headCoverageFromGet {
+		head := req.Clone(req.Context())
+		head.Method = http.MethodHead
+		if headPath, err := coveredPath(head, pathItem, foundPath); err == nil && v.endpoints.Has(headPath, http.MethodHead, coord.ResponseCode) {
+			v.endpoints.MarkChecked(headPath, http.MethodHead, coord.ResponseCode)
+		}
 	}
 
-	v.endpoints.MarkChecked(foundPath, req.Method, strconv.Itoa(res.StatusCode))
+	// The spec can override the options for single operations.
+	conf := v.conf.forOperation(op)
+
+	validate := v.sampled()
+	v.exerciseSecurity(req, op)
+	v.useRequestMediaType(req, foundPath, op)
 
 	// Select the right function for validation.
-	if v.conf.checkRequest {
-		ok, validationErrors := v.validator.ValidateHttpRequest(req)
-		if !ok {
-			v.appendErr(ErrRequestInvalid, fmt.Errorf("%s %s: %w", req.Method, req.URL.Path, toError(validationErrors)))
+	if (conf.checkRequest || conf.securityValidation) && validate {
+		if err := v.checkSecurity(req, op, res.StatusCode); err != nil {
+			v.endpoints.AddFailure(coord, v.appendFailure(ErrSecurityViolation, FailureRequest, req, coord, err))
+		}
+	}
+	if conf.authErrorCoverage && validate {
+		if err := checkAuthResponse(req, res.StatusCode, v.model, op); err != nil {
+			v.endpoints.AddFailure(coord, v.appendFailure(ErrSecurityViolation, FailureResponse, req, coord, err))
+		}
+	}
+	if conf.checkRequest && validate {
+		if err := v.validateRequest(req, pathItem, foundPath); err != nil {
+			v.endpoints.AddFailure(coord, v.appendFailure(ErrRequestInvalid, FailureRequest, req, coord, err))
+		}
+		if err := checkRequestBodyFormat(req, op); err != nil && !v.conf.ignoreUnsupportedBodyFormats {
+			v.endpoints.AddFailure(coord, v.appendFailure(ErrUnsupportedBodyFormat, FailureRequest, req, coord, fmt.Errorf("request %w", err)))
+		}
+	}
+
+	// An undocumented response that WithIgnoredResponses acknowledges is a known gap in the spec, and not checked.
+	ignored := v.undocumentedIgnoredResponse(foundPath, coord.Method, op, res.StatusCode)
+	if !ignored && (conf.disableResponseValidation || !validate) {
+		// Even without validation, an undocumented status code is a hit outside of the spec.
+		if response, _ := documentedResponse(op, res.StatusCode); response == nil {
+			v.appendFailure(ErrResponseInvalid, FailureResponse, req, coord, fmt.Errorf("%d response is not documented", res.StatusCode))
+		}
+	} else if !ignored {
+		if err := v.validateResponse(req, res, pathItem, foundPath); err != nil {
+			v.endpoints.AddFailure(coord, v.appendFailure(ErrResponseInvalid, FailureResponse, req, coord, err))
+		}
+		if err := checkResponseBodyFormat(req, res, op); err != nil && !v.conf.ignoreUnsupportedBodyFormats {
+			v.endpoints.AddFailure(coord, v.appendFailure(ErrUnsupportedBodyFormat, FailureResponse, req, coord, fmt.Errorf("response %w", err)))
+		}
+	}
+
+	if v.endpoints.conf.links {
+		v.recordLinks(req, res, pathItem, foundPath)
+	}
+	return coord.Path
+}
+
+// sampled returns true if the current request and response should be validated. The count of recorded requests is
+// scaled by the sampling rate, and a request is validated whenever that crosses another whole number.
+func (v *Verifier) sampled() bool {
+	if v.conf.sampling >= 1 {
+		return true
+	}
+	n := v.samples.Add(1)
+	return int64(float64(n)*v.conf.sampling) > int64(float64(n-1)*v.conf.sampling)
+}
+
+// recordLinks marks the links that the request follows, and then adds the links that the response makes available.
+func (v *Verifier) recordLinks(req *http.Request, res *http.Response, pathItem *v3.PathItem, foundPath string) {
+	v.links.follow(req, foundPath)
+
+	reqBody, _ := readBody(&req.Body)
+	resBody, _ := readBody(&res.Body)
+	response, code := documentedResponse(pathItem.GetOperations().GetOrZero(strings.ToLower(req.Method)), res.StatusCode)
+	v.links.add(exchange{
+		req:       req,
+		reqBody:   reqBody,
+		res:       res,
+		resBody:   resBody,
+		foundPath: foundPath,
+	}, response, code)
+}
+
+func (v *Verifier) validateRequest(req *http.Request, pathItem *v3.PathItem, foundPath string) error {
+	body, err := readBody(&req.Body)
+	if err != nil {
+		return fmt.Errorf("could not read request body: %w", err)
+	}
+
+	req, err = normalizePathStyles(req, pathItem, foundPath)
+	if err != nil {
+		return err
+	}
+	req = normalizeRequest(req, body, v.conf.headerValues)
+
+	op := pathItem.GetOperations().GetOrZero(strings.ToLower(req.Method))
+	conf := v.conf.forOperation(op)
+	// The checks that copper does on top of the validator library.
+	checkErr := errors.Join(checkQueryStyles(req, pathItem), checkMultipart(req, body, op))
+	if conf.strictQueryEncoding {
+		checkErr = errors.Join(checkErr, checkQueryEncoding(req, pathItem))
+	}
+
+	var bodyErr error
+	if err := checkDepth(body, v.conf.maxDepth); err != nil {
+		bodyErr = err
+	} else {
+		strict := strictness{formats: conf.strictFormats}
+		_, location := requestContent(req, pathItem, foundPath)
+		_, strictErr := checkStrict(contentSchema(requestContent(req, pathItem, foundPath)), body, strict)
+
+		if s := v.requestSchema(req, pathItem, foundPath); s != nil {
+			bodyErr = errors.Join(newBodyError(s.validate(body), location, body), strictErr)
+		} else {
+			_, validationErrors := v.validator.ValidateHttpRequestWithPathItem(req, pathItem, foundPath)
+			if validationErrors = withoutSecurityErrors(withoutReservedValueErrors(validationErrors)); len(validationErrors) > 0 {
+				return errors.Join(checkErr, newBodyError(toError(validationErrors), location, body), strictErr)
+			}
+			return errors.Join(checkErr, strictErr)
 		}
 	}
 
-	ok, validationErrors := v.validator.ValidateHttpResponse(req, res)
+	// The body has been handled by copper, so only the parameters are left for the validator library. The security is
+	// checked by checkSecurity.
+	var validationErrors []*validatorerr.ValidationError
+	params := v.validator.GetParameterValidator()
+	for _, validate := range []func(*http.Request, *v3.PathItem, string) (bool, []*validatorerr.ValidationError){
+		params.ValidatePathParamsWithPathItem,
+		params.ValidateQueryParamsWithPathItem,
+		params.ValidateHeaderParamsWithPathItem,
+		params.ValidateCookieParamsWithPathItem,
+	} {
+		_, errs := validate(req, pathItem, foundPath)
+		validationErrors = append(validationErrors, errs...)
+	}
+
+	if validationErrors = withoutReservedValueErrors(validationErrors); len(validationErrors) > 0 {
+		return errors.Join(checkErr, bodyErr, toError(validationErrors))
+	}
+	return errors.Join(checkErr, bodyErr)
+}
+
+func (v *Verifier) validateResponse(req *http.Request, res *http.Response, pathItem *v3.PathItem, foundPath string) error {
+	// Reading the full body also makes the trailers of the response available.
+	body, err := readBody(&res.Body)
+	if err != nil {
+		return fmt.Errorf("could not read response body: %w", err)
+	}
+	res = normalizeResponse(res, body, v.conf.headerValues)
+
+	op := pathItem.GetOperations().GetOrZero(strings.ToLower(req.Method))
+	response, _ := documentedResponse(op, res.StatusCode)
+
+	size := int64(len(body))
+	var bodyErr error
+	if streamed, ok := streamedSize(req); ok {
+		// The body was streamed past the Verifier, so only what is known about it is checked.
+		size = streamed
+		bodyErr = checkStreamedResponse(res, response)
+	} else {
+		bodyErr = v.validateResponseBody(req, res, body, pathItem, foundPath)
+	}
+
+	errs := []error{
+		bodyErr,
+		v.checkProblemDetails(req, res, body),
+		checkResponseTrailers(response, res.Trailer),
+		checkResponseSize(op, size),
+		checkContentLength(req, res, size),
+		checkStatusHeaders(res, v.conf.statusHeaders),
+	}
+	if v.conf.rateLimitHeaders {
+		errs = append(errs, checkRateLimit(res))
+	}
+	if v.conf.contentSniffing {
+		errs = append(errs, checkSniffedContent(res, body))
+	}
+	return errors.Join(errs...)
+}
+
+func (v *Verifier) validateResponseBody(req *http.Request, res *http.Response, body []byte, pathItem *v3.PathItem, foundPath string) error {
+	op := pathItem.GetOperations().GetOrZero(strings.ToLower(req.Method))
+	switch {
+	case isBodiless(req, res.StatusCode):
+		// There is no body to validate, so any content documented for the response does not apply.
+		if response, _ := documentedResponse(op, res.StatusCode); response == nil {
+			return fmt.Errorf("%d response is not documented", res.StatusCode)
+		}
+		if req.Method == http.MethodHead && v.conf.headFromGet {
+			// A HEAD response carries the same headers as the GET response would have.
+			getResponse, _ := documentedResponse(pathItem.Get, res.StatusCode)
+			return errors.Join(checkBodiless(req, res.StatusCode, body), checkResponseHeaders(getResponse, res.Header))
+		}
+		return checkBodiless(req, res.StatusCode, body)
+	case res.StatusCode == http.StatusPartialContent:
+		// A partial body can not be validated against the schema of the full representation, so only the range of it
+		// is checked.
+		if response, _ := documentedResponse(op, res.StatusCode); response == nil {
+			return fmt.Errorf("%d response is not documented", res.StatusCode)
+		}
+		return checkPartialContent(res.Header, body)
+	}
+
+	if err := checkDepth(body, v.conf.maxDepth); err != nil {
+		return err
+	}
+
+	conf := v.conf.forOperation(op)
+	strict := strictness{formats: conf.strictFormats, properties: conf.strictResponseProperties, drift: true}
+	_, location := responseContent(req, res, pathItem, foundPath)
+	drift, strictErr := checkStrict(contentSchema(responseContent(req, res, pathItem, foundPath)), body, strict)
+	v.recordDrift(req, res, pathItem, foundPath, drift)
+
+	if s := v.responseSchema(req, res, pathItem, foundPath); s != nil {
+		return errors.Join(newBodyError(s.validate(body), location, body), strictErr)
+	}
+
+	ok, validationErrors := v.validator.GetResponseBodyValidator().ValidateResponseBodyWithPathItem(req, res, pathItem, foundPath)
 	if !ok {
-		v.appendErr(ErrResponseInvalid, fmt.Errorf("%s %s: %w", req.Method, req.URL.Path, toError(validationErrors)))
+		return errors.Join(newBodyError(toError(validationErrors), location, body), strictErr)
 	}
+	return strictErr
 }
 
-func (v *Verifier) appendErr(sentinel SentinelError, err error) {
-	v.errors = append(
-		v.errors,
-		joinError(sentinel, err),
-	)
+// requestSchema returns the recursive schema for the request body, or nil if the body should be validated by the
+// validator library.
+func (v *Verifier) requestSchema(req *http.Request, pathItem *v3.PathItem, foundPath string) *recursiveSchema {
+	return v.recursive.lookup(requestContent(req, pathItem, foundPath))
+}
+
+// responseSchema returns the recursive schema for the response body, or nil if the body should be validated by the
+// validator library.
+func (v *Verifier) responseSchema(req *http.Request, res *http.Response, pathItem *v3.PathItem, foundPath string) *recursiveSchema {
+	return v.recursive.lookup(responseContent(req, res, pathItem, foundPath))
+}
+
+// requestContent returns the documented JSON content of the request body, along with the location of its schema in the
+// spec. If there is no such content, nil is returned.
+func requestContent(req *http.Request, pathItem *v3.PathItem, foundPath string) (*v3.MediaType, string) {
+	method := strings.ToLower(req.Method)
+	op := pathItem.GetOperations().GetOrZero(method)
+	if op == nil || op.RequestBody == nil || op.RequestBody.Content == nil {
+		return nil, ""
+	}
+
+	mediaType, ok := jsonMediaType(req.Header)
+	if !ok {
+		return nil, ""
+	}
+
+	location := pointer("paths", foundPath, method, "requestBody", "content", mediaType, "schema")
+	return op.RequestBody.Content.GetOrZero(mediaType), location
+}
+
+// responseContent returns the documented JSON content of the response body, along with the location of its schema in
+// the spec. If there is no such content, nil is returned.
+func responseContent(req *http.Request, res *http.Response, pathItem *v3.PathItem, foundPath string) (*v3.MediaType, string) {
+	method := strings.ToLower(req.Method)
+	response, code := documentedResponse(pathItem.GetOperations().GetOrZero(method), res.StatusCode)
+	if response == nil || response.Content == nil {
+		return nil, ""
+	}
+
+	mediaType, ok := jsonMediaType(res.Header)
+	if !ok {
+		return nil, ""
+	}
+
+	location := pointer("paths", foundPath, method, "responses", code, "content", mediaType, "schema")
+	return response.Content.GetOrZero(mediaType), location
+}
+
+// documentedResponse returns the response documented for the status code along with the key that it is documented
+// under. Response codes are resolved in the same order as the validator library does it: the exact code, the range of
+// the code (2XX) and then the default response.
+func documentedResponse(op *v3.Operation, statusCode int) (*v3.Response, string) {
+	if op == nil || op.Responses == nil {
+		return nil, ""
+	}
+
+	code := strconv.Itoa(statusCode)
+	if response := op.Responses.Codes.GetOrZero(code); response != nil {
+		return response, code
+	}
+
+	code = fmt.Sprintf("%dXX", statusCode/100)
+	if response := op.Responses.Codes.GetOrZero(code); response != nil {
+		return response, code
+	}
+
+	if op.Responses.Default != nil {
+		return op.Responses.Default, "default"
+	}
+	return nil, ""
+}
+
+// jsonMediaType returns the media type of the Content-Type header, but only if it is a JSON based one since those are
+// the only bodies that are validated against their schema.
+func jsonMediaType(h http.Header) (string, bool) {
+	mediaType, _, err := mime.ParseMediaType(h.Get("Content-Type"))
+	if err != nil || !strings.Contains(mediaType, "json") {
+		return "", false
+	}
+	return mediaType, true
+}
+
+func (v *Verifier) appendErr(sentinel SentinelError, err error) error {
+	return v.appendVerificationError(joinError(sentinel, err))
+}
+
+// appendFailure is like appendErr, but for an error found in the exchange with the coordinate, which is given without
+// the method and path of the request.
+func (v *Verifier) appendFailure(sentinel SentinelError, kind FailureKind, req *http.Request, coord Endpoint, err error) error {
+	verr := joinError(sentinel, fmt.Errorf("%s %s: %w", req.Method, req.URL.Path, err))
+	verr.kind, verr.endpoint, verr.cause = kind, coord, err
+	return v.appendVerificationError(verr)
+}
+
+func (v *Verifier) appendVerificationError(verr *VerificationError) error {
+	verr = v.conf.withTemplate(verr)
+	v.errors = append(v.errors, verr)
+	v.appended++
+	v.trimErrors()
+	return verr
 }
 
+// Record checks the given response, and the request that it was made for, against the spec. The path in the spec is
+// found from the URL, where static segments take precedence over templates, as MatchPath shows. Informational (1xx)
+// responses are never final, so they are noted in the request log but otherwise ignored.
 func (v *Verifier) Record(res *http.Response) {
-	req := res.Request
+	v.RecordRoute(res, "")
+}
 
-	// The body has already been read, so try to reset the body
-	if req.GetBody != nil {
-		req.Body, _ = req.GetBody()
+// RecordRoute is like Record, but uses the route that a server matched the request with to find the path in the spec,
+// rather than working it out from the URL. This avoids ambiguity when several paths match the same URL. The route is a
+// path template like /things/{id}, where the names of the parameters do not have to be the same as in the spec, and
+// it can include the base path of the server. If no path in the spec matches the route, the URL is used as by Record.
+func (v *Verifier) RecordRoute(res *http.Response, route string) {
+	if isInformational(res.StatusCode) {
+		v.noteInformational(res.Request, res.StatusCode, res.Header)
+		return
 	}
 
-	if v.conf.requestLogger != nil {
+	req, res := snapshot(res)
+	logger, verbosity := v.logging()
+	id := v.requestID(req, res)
+
+	v.mu.Lock()
+	goldenDir := v.conf.goldenDir
+	v.mu.Unlock()
+	var reqBody, resBody []byte
+	if goldenDir != "" {
+		reqBody, _ = readBody(&req.Body)
+		resBody, _ = readBody(&res.Body)
+	}
+	if logger != nil && verbosity != LogProgress {
 		count := v.reqCounter.Add(1)
-		reqDump, err := httputil.DumpRequestOut(req, true)
-		if err == nil {
-			v.conf.requestLogger.Logf("REQUEST  %04d ====\n%s", count, string(reqDump))
+		banner := fmt.Sprintf("%04d", count)
+		if id != "" {
+			banner += " " + id
+		}
+		logDumps(logger, banner, req, res)
+	}
+
+	start := time.Now()
+	var handler func(*VerificationError)
+	path, errs := func() (string, []error) {
+		v.mu.Lock()
+		defer v.mu.Unlock()
+
+		before := v.appended
+		path := v.check(req, res, route)
+		// The error window can have dropped some of the errors that were just found, if there were many.
+		found := v.errors[max(len(v.errors)-(v.appended-before), 0):]
+		for _, err := range found {
+			var verr *VerificationError
+			if errors.As(err, &verr) {
+				verr.requestID = id
+			}
 		}
+		handler = v.conf.violationHandler
+		return path, slices.Clone(found)
+	}()
 
-		resDump, err := httputil.DumpResponse(res, true)
-		if err == nil {
-			v.conf.requestLogger.Logf("RESPONSE %04d ====\n%s", count, string(resDump))
+	// The handler is called without holding the lock, so that it can use the Verifier.
+	if handler != nil {
+		for _, err := range errs {
+			var verr *VerificationError
+			if errors.As(err, &verr) {
+				handler(verr)
+			}
 		}
 	}
 
+	if logger != nil && verbosity != LogDumps {
+		logProgress(logger, req, res, path, id, errs, time.Since(start))
+	}
+	if goldenDir != "" {
+		writeGolden(goldenDir, req, res, reqBody, resBody, path, logger)
+	}
+	v.snapshotIfDue(time.Now())
+}
+
+// snapshot returns copies of the request and the response for the Verifier to work on, with bodies of their own. The
+// request is never changed when it has GetBody, so it can be shared by goroutines that record concurrently, and the
+// body of the response is left readable for the caller. A request body without GetBody can only be read once, so it is
+// read and replaced on the request itself.
+func snapshot(res *http.Response) (*http.Request, *http.Response) {
+	req := res.Request.Clone(res.Request.Context())
+	switch {
+	case res.Request.GetBody != nil:
+		// The body has already been read when the request was sent, so a new one is needed.
+		if body, err := res.Request.GetBody(); err == nil {
+			req.Body = body
+		}
+	case res.Request.Body != nil && res.Request.Body != http.NoBody:
+		body, _ := readBody(&res.Request.Body)
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	copied := *res
+	copied.Request = req
+	if res.Body != nil && res.Body != http.NoBody {
+		body, _ := readBody(&res.Body)
+		copied.Body = io.NopCloser(bytes.NewReader(body))
+		// Trailers that were not declared up front are only merged into the response once its body has been read.
+		copied.Trailer = res.Trailer
+	}
+	return req, &copied
+}
+
+// logProgress logs a single line in the logfmt style for a recorded request and response, with the coordinate, the
+// sentinels of the errors found for it and how long it took to verify. Requests that were sent by the client from
+// WrapClient also have their Timings, to tell a slow server apart from slow validation. The path is the one from the spec, or the one
+// of the URL if no path in the spec matched.
+func logProgress(logger RequestLogger, req *http.Request, res *http.Response, path, id string, errs []error, d time.Duration) {
+	if path == "" {
+		path = req.URL.Path
+	}
+
+	var verdicts []string
+	for _, err := range errs {
+		var verr *VerificationError
+		if errors.As(err, &verr) && !slices.Contains(verdicts, verr.Sentinel().Error()) {
+			verdicts = append(verdicts, verr.Sentinel().Error())
+		}
+	}
+	verdict := "ok"
+	if len(verdicts) > 0 {
+		verdict = strings.Join(verdicts, ",")
+	}
+
+	line := fmt.Sprintf("copper endpoint=%q verdict=%q errors=%d duration=%s",
+		fmt.Sprintf("%s %s %d", req.Method, path, res.StatusCode), verdict, len(errs), d)
+	if id != "" {
+		line += fmt.Sprintf(" request_id=%q", id)
+	}
+	if timings, ok := requestTimings(req.Context()); ok {
+		line += " " + timings.String()
+	}
+	logger.Logf("%s", line)
+}
+
+// RecordRecorder records the response that a handler wrote to the recorder, as the response to the request, which makes
+// it possible to check handlers in unit tests without starting a server. The recorder must not be written to anymore.
+// If the handler reads the body of the request, the body can only be checked if GetBody is set on the request, which
+// http.NewRequest does for in-memory bodies but httptest.NewRequest does not.
+func (v *Verifier) RecordRecorder(req *http.Request, rr *httptest.ResponseRecorder) {
+	res := rr.Result()
+	res.Request = req
+	v.Record(res)
+}
+
+// noteInformational logs an informational response to the request logger, if there is one.
+func (v *Verifier) noteInformational(req *http.Request, code int, header http.Header) {
+	logger, verbosity := v.logging()
+	if logger == nil || verbosity == LogProgress {
+		return
+	}
+
+	s := strings.Builder{}
+	_ = header.Write(&s)
+	logger.Logf("INFORMATIONAL ==== %s %s: %d %s\n%s", req.Method, req.URL.Path, code, http.StatusText(code), s.String())
+}
+
+// clientOptions are the options that change how the client from WrapClient sends requests.
+type clientOptions struct {
+	// base is the URL that relative URLs are resolved against, or nil if there is none.
+	base       *url.URL
+	requestIDs bool
+	// rateLimit is the number of requests per second, or 0 if there is no limit.
+	rateLimit float64
+}
+
+// clientOptions returns the options for sending requests. The options have been validated, so the URL can be parsed.
+func (v *Verifier) clientOptions() clientOptions {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	opts := clientOptions{requestIDs: v.conf.requestIDs, rateLimit: v.conf.rateLimit}
+	if v.conf.baseURL != "" {
+		opts.base, _ = url.Parse(v.conf.baseURL)
+	}
+	return opts
+}
+
+// logging returns the request logger, if there is one, and what to log to it. Requests are logged outside of the lock,
+// and the logger can be changed by SetOptions, so it is read under the lock.
+func (v *Verifier) logging() (RequestLogger, Verbosity) {
 	v.mu.Lock()
 	defer v.mu.Unlock()
+	return v.conf.requestLogger, v.conf.verbosity
+}
 
-	v.check(req, res)
+// isInformational returns true for 1xx responses that are followed by a final response. 101 Switching Protocols is the
+// final response to an upgrade request, and is therefore treated like any other status.
+func isInformational(code int) bool {
+	return code >= 100 && code < 200 && code != http.StatusSwitchingProtocols
 }
 
 // CurrentError is a convenience method for CurrentErrors, where the errors are joined into a single error, making
@@ -137,44 +751,310 @@ func (v *Verifier) CurrentErrors() []error {
 	v.mu.Lock()
 	defer v.mu.Unlock()
 
+	return v.currentErrors()
+}
+
+func (v *Verifier) currentErrors() []error {
 	var errs []error
-	if !v.conf.disableFullCoverage {
-		for _, e := range v.endpoints.Unchecked() {
-			err := fmt.Errorf("%s %s: %s", e.Method, e.Path, e.ResponseCode)
-			errs = append(errs, joinError(ErrNotChecked, err))
+	if v.requiresCoverage() {
+		// A single error for all of them keeps large specs with little coverage readable.
+		if byPath := v.uncheckedByPath(); len(byPath) > 0 {
+			errs = append(errs, v.conf.withTemplate(joinError(ErrNotChecked, &CoverageError{
+				Missing:   byPath,
+				Coverage:  v.endpoints.Coverage(),
+				Threshold: v.endpoints.conf.coverageThreshold,
+			})))
+		}
+	}
+	if !v.endpoints.conf.disableFullCoverage && v.endpoints.conf.coverageThreshold == 0 {
+		if v.conf.checkRequest || v.conf.securityValidation {
+			errs = append(errs, v.unexercisedSchemes()...)
+		}
+		if v.conf.requestMediaTypeCoverage {
+			errs = append(errs, v.unusedRequestMediaTypeErrors()...)
+		}
+	}
+	if v.conf.authErrorCoverage {
+		errs = append(errs, v.uncoveredAuthErrors()...)
+	}
+	if v.endpoints.conf.links {
+		for _, err := range v.links.unfollowed() {
+			errs = append(errs, v.conf.withTemplate(err))
 		}
 	}
 
 	return append(v.errors, errs...)
 }
 
+// Unchecked returns the coordinates of the spec that have not been checked yet, sorted by path, method and response
+// code. Unlike CurrentErrors, they are returned even if full coverage is not required.
+func (v *Verifier) Unchecked() []Endpoint {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	ends := v.endpoints.Unchecked()
+	sortEndpoints(ends)
+	return ends
+}
+
+// UncheckedByPath returns the method and response code pairs of the spec that have not been checked yet, grouped by
+// path and sorted by method and response code. Like Unchecked, they are returned even if full coverage is not required.
+func (v *Verifier) UncheckedByPath() map[string][]MethodStatus {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	return v.uncheckedByPath()
+}
+
+func (v *Verifier) uncheckedByPath() map[string][]MethodStatus {
+	ends := v.endpoints.Unchecked()
+	sortEndpoints(ends)
+
+	byPath := make(map[string][]MethodStatus)
+	for _, e := range ends {
+		byPath[e.Path] = append(byPath[e.Path], MethodStatus{Method: e.Method, ResponseCode: e.ResponseCode})
+	}
+	return byPath
+}
+
+// Endpoints returns every coordinate of the spec with whether it has been checked, sorted by path, method and response
+// code. This is the coverage model that CurrentErrors reports ErrNotChecked from, for tooling like dashboards and
+// custom reporters that want to present coverage in their own way.
+func (v *Verifier) Endpoints() []EndpointStatus {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	return v.endpointStatuses()
+}
+
+func (v *Verifier) endpointStatuses() []EndpointStatus {
+	ends := v.endpoints.All()
+	statuses := make([]EndpointStatus, 0, len(ends))
+	for _, e := range ends {
+		hits, first, last := v.endpoints.Traffic(e)
+		statuses = append(statuses, EndpointStatus{
+			Endpoint:  e,
+			Checked:   v.endpoints.IsChecked(e.Path, e.Method, e.ResponseCode),
+			Failures:  v.endpoints.failed[e.coordinate()],
+			Hits:      hits,
+			FirstSeen: first,
+			LastSeen:  last,
+			Timings:   v.endpoints.Timings(e),
+		})
+	}
+	return statuses
+}
+
+// Waived returns the coordinates of the spec that are left out of the coverage, with the reason why, sorted by path,
+// method and response code.
+func (v *Verifier) Waived() []WaivedEndpoint {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	return v.endpoints.Waived()
+}
+
 // Verify will cause the given test context to fail with an error if Error returns a non-nil error.
 func (v *Verifier) Verify(t *testing.T) {
 	t.Helper()
 
+	if msg, failed := v.failure(); failed {
+		t.Error(msg)
+	}
+}
+
+// failure returns the message that Verify fails with, rendered with the summary template if there is one, or false if
+// there are no errors.
+func (v *Verifier) failure() (string, bool) {
 	err := v.CurrentError()
-	if err != nil {
-		t.Error(err)
+	if err == nil {
+		return "", false
+	}
+
+	tmpl := v.conf.errorTemplates.Summary
+	if tmpl == nil {
+		return err.Error(), true
+	}
+	summary, ok := render(tmpl, v.Summary())
+	if !ok {
+		return fmt.Sprintf("%v\n(summary template failed: %s)", err, summary), true
+	}
+	return summary, true
+}
+
+// SetOptions applies the options on top of the current ones, which allows for example request validation to be turned
+// on for one phase of a test suite and off for the next, without creating another Verifier. Recorded coverage and
+// errors are kept. If the resulting options contradict each other, an error wrapping ErrInvalidOptions is returned and
+// the current options are left as they are. The server can not be changed once the Verifier has been created. For a
+// view created by With, only the options of the view are changed.
+func (v *Verifier) SetOptions(opts ...Option) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	conf := v.conf
+	conf.conflicts = nil
+	for _, opt := range opts {
+		opt(&conf)
+	}
+
+	if err := conf.validate(); err != nil {
+		return err
+	}
+	if conf.serverBase != v.conf.serverBase {
+		return fmt.Errorf("%w: the server can not be changed once the verifier has been created", ErrInvalidOptions)
+	}
+	if conf.nullability != v.conf.nullability {
+		return fmt.Errorf("%w: the nullability can not be changed once the verifier has been created", ErrInvalidOptions)
+	}
+	if conf.ecmaPatterns != v.conf.ecmaPatterns {
+		return fmt.Errorf("%w: ECMAScript patterns can not be turned on or off once the verifier has been created", ErrInvalidOptions)
+	}
+	if conf.exampleValidation != v.conf.exampleValidation {
+		return fmt.Errorf("%w: the examples can only be checked when the verifier is created", ErrInvalidOptions)
+	}
+	if conf.tlsConfig != v.conf.tlsConfig {
+		return fmt.Errorf("%w: the TLS configuration can not be changed once the client has been created", ErrInvalidOptions)
+	}
+	if !conf.filters.equal(v.conf.filters) {
+		return fmt.Errorf("%w: the filters can not be changed once the verifier has been created", ErrInvalidOptions)
+	}
+
+	v.conf = conf
+	if !v.view {
+		v.endpoints.conf = conf
 	}
+	return nil
 }
 
-// Reset will remove all current errors, and start the Verifier from scratch. This allows it to be reused.
+// With returns a view of the Verifier that validates with the options applied on top of the current ones, but records
+// coverage and errors into the same state. This allows for example some tests to validate requests while others do not,
+// and still get a single coverage verdict. The verdict is decided by the options of the Verifier that the view was
+// created from, so WithoutFullCoverage, WithInternalServerErrors and WithLinks have no effect on a view. Since the
+// options are given in code, options that contradict each other, or that change the server, panic.
+func (v *Verifier) With(opts ...Option) *Verifier {
+	v.mu.Lock()
+	conf := v.conf
+	v.mu.Unlock()
+
+	server, nullability, ecmaPatterns, filters := conf.serverBase, conf.nullability, conf.ecmaPatterns, conf.filters
+	conf.conflicts = nil
+	for _, opt := range opts {
+		opt(&conf)
+	}
+
+	if err := conf.validate(); err != nil {
+		panic(err)
+	}
+	if conf.serverBase != server {
+		panic(fmt.Errorf("%w: the server of a view can not differ from the verifier", ErrInvalidOptions))
+	}
+	if conf.nullability != nullability {
+		panic(fmt.Errorf("%w: the nullability of a view can not differ from the verifier", ErrInvalidOptions))
+	}
+	if conf.ecmaPatterns != ecmaPatterns {
+		panic(fmt.Errorf("%w: ECMAScript patterns can not be turned on or off for a view", ErrInvalidOptions))
+	}
+	if !conf.filters.equal(filters) {
+		panic(fmt.Errorf("%w: the filters of a view can not differ from the verifier", ErrInvalidOptions))
+	}
+
+	return &Verifier{
+		state:      v.state,
+		conf:       conf,
+		view:       true,
+		validator:  v.validator,
+		model:      v.model,
+		matching:   v.matching,
+		spec:       v.spec,
+		recursive:  v.recursive,
+		jwtSchemes: v.jwtSchemes,
+	}
+}
+
+// Reset will remove all current errors, and start the Verifier from scratch. This allows it to be reused. The state is
+// shared with views created by With, so they are reset as well.
 func (v *Verifier) Reset() {
 	v.mu.Lock()
 	defer v.mu.Unlock()
 	v.errors = nil
-	v.endpoints = newEndpoints(v.model, v.conf.checkInternalServerErrors)
+	v.endpoints = newEndpoints(v.model, v.endpoints.conf)
+	v.links = newLinks(v.model)
+	v.schemes = securitySchemes(v.model, v.endpoints.conf.filters)
+	v.mediaTypes = requestMediaTypes(v.model, v.endpoints.conf.filters)
+	v.drift = nil
+	v.dropped = nil
 }
 
 func toError(validationErrs []*validatorerr.ValidationError) error {
+	validationErrs = bestUnionMatches(validationErrs)
 	if len(validationErrs) == 1 {
 		return validationErrs[0]
 	}
 
+	return validatorErrors(validationErrs)
+}
+
+// validatorErrors are several errors of the validator library, listed in a single error.
+type validatorErrors []*validatorerr.ValidationError
+
+func (e validatorErrors) Error() string {
 	s := strings.Builder{}
-	for _, err := range validationErrs {
+	for _, err := range e {
 		s.WriteString("\n - ")
 		s.WriteString(err.Error())
 	}
-	return fmt.Errorf("validation errors: %s", s.String())
+	return fmt.Sprintf("validation errors: %s", s.String())
+}
+
+func (e validatorErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// routePath returns the path in the spec that the route of a server matches, or nil if there is none. The segments are
+// aligned from the end, so that the route can include a base path, and when several paths match, the longest one is
+// used.
+func (v *Verifier) routePath(route string) (*v3.PathItem, string) {
+	if route == "" || v.model.Paths == nil {
+		return nil, ""
+	}
+
+	routeSegs := strings.Split(strings.Trim(route, "/"), "/")
+
+	var (
+		found     *v3.PathItem
+		foundPath string
+		foundLen  int
+	)
+	for path, pathItem := range v.model.Paths.PathItems.FromOldest() {
+		segs := strings.Split(strings.Trim(path, "/"), "/")
+		if len(segs) > len(routeSegs) || len(segs) <= foundLen {
+			continue
+		}
+		if matchesRoute(segs, routeSegs[len(routeSegs)-len(segs):]) {
+			found, foundPath, foundLen = pathItem, path, len(segs)
+		}
+	}
+	return found, foundPath
+}
+
+// matchesRoute checks if the segments of a path in the spec match the segments of a route. Parameters match each other
+// regardless of their names, and any pattern that the route has for them.
+func matchesRoute(segs, routeSegs []string) bool {
+	isParam := func(seg string) bool {
+		return strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}")
+	}
+
+	for i, seg := range segs {
+		if isParam(seg) != isParam(routeSegs[i]) {
+			return false
+		}
+		if !isParam(seg) && seg != routeSegs[i] {
+			return false
+		}
+	}
+	return true
 }
@@ -6,8 +6,11 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -21,6 +24,17 @@ func TestInvalidSpec(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestMustNewVerifier(t *testing.T) {
+	f, err := os.ReadFile("testdata/thing-spec.yaml")
+	require.NoError(t, err)
+	invalid, err := os.ReadFile("testdata/invalid-spec.yaml")
+	require.NoError(t, err)
+
+	assert.NotNil(t, MustNewVerifier(f))
+	assert.Panics(t, func() { MustNewVerifier(invalid) })
+	assert.Panics(t, func() { MustNewVerifier(f, WithMaxDepth(0)) })
+}
+
 func TestCurrentErrors(t *testing.T) {
 	f, err := os.ReadFile("testdata/thing-spec.yaml")
 	require.NoError(t, err)
@@ -59,6 +73,7 @@ func TestWithInternalServerErrors(t *testing.T) {
 		v, err := NewVerifier(f, WithInternalServerErrors())
 		require.NoError(t, err)
 		assert.ErrorIs(t, v.CurrentError(), ErrNotChecked)
+		assert.Empty(t, v.Waived())
 	})
 
 	t.Run("unchecked 500 is fine", func(t *testing.T) {
@@ -66,6 +81,10 @@ func TestWithInternalServerErrors(t *testing.T) {
 		v, err := NewVerifier(f)
 		require.NoError(t, err)
 		assert.NoError(t, v.CurrentError())
+		assert.Equal(t, []WaivedEndpoint{{
+			Endpoint: Endpoint{Path: "/fault", Method: http.MethodGet, ResponseCode: "500"},
+			Reason:   "500 responses are not required without WithInternalServerErrors or x-copper-strict",
+		}}, v.Waived())
 	})
 
 	t.Run("checked 500 is fine", func(t *testing.T) {
@@ -80,6 +99,23 @@ func TestWithInternalServerErrors(t *testing.T) {
 	})
 }
 
+func TestInformationalResponsesAreIgnored(t *testing.T) {
+	f, err := os.ReadFile("testdata/minimal-spec.yaml")
+	require.NoError(t, err)
+	v, err := NewVerifier(f)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	v.Record(&http.Response{StatusCode: http.StatusContinue, Request: req})
+
+	err = v.CurrentError()
+	assert.ErrorIs(t, err, ErrNotChecked)
+	assert.NotErrorIs(t, err, ErrResponseInvalid)
+
+	v.Record(&http.Response{StatusCode: http.StatusNoContent, Request: req})
+	assert.NoError(t, v.CurrentError())
+}
+
 func TestReset(t *testing.T) {
 	f, err := os.ReadFile("testdata/delete-spec.yaml")
 	require.NoError(t, err)
@@ -103,6 +139,395 @@ func TestReset(t *testing.T) {
 	v.Verify(t)
 }
 
+func TestSetOptions(t *testing.T) {
+	f, err := os.ReadFile("testdata/request-body-spec.yaml")
+	require.NoError(t, err)
+
+	record := func(v *Verifier) {
+		req := httptest.NewRequest(http.MethodPost, "/req", strings.NewReader(`{"borken": "yes"}`))
+		req.Header.Set("Content-Type", "application/json")
+		v.Record(&http.Response{StatusCode: 204, Request: req})
+	}
+
+	t.Run("options can be changed between phases", func(t *testing.T) {
+		v, err := NewVerifier(f, WithRequestValidation())
+		require.NoError(t, err)
+
+		record(v)
+		assert.Len(t, v.CurrentErrors(), 1)
+
+		require.NoError(t, v.SetOptions(WithoutRequestValidation()))
+		record(v)
+		assert.Len(t, v.CurrentErrors(), 1)
+
+		require.NoError(t, v.SetOptions(WithRequestValidation()))
+		record(v)
+		assert.Len(t, v.CurrentErrors(), 2)
+	})
+
+	t.Run("conflicting options are rejected", func(t *testing.T) {
+		v, err := NewVerifier(f, WithStrictResponseProperties())
+		require.NoError(t, err)
+
+		assert.ErrorIs(t, v.SetOptions(WithoutResponseValidation()), ErrInvalidOptions)
+		assert.False(t, v.conf.disableResponseValidation)
+	})
+
+	t.Run("server can not be changed", func(t *testing.T) {
+		v, err := NewVerifier(f, WithServer("http://localhost:8000"))
+		require.NoError(t, err)
+
+		assert.NoError(t, v.SetOptions(WithServer("http://localhost:8000")))
+		assert.ErrorIs(t, v.SetOptions(WithServer("http://localhost:9000")), ErrInvalidOptions)
+	})
+
+	t.Run("nullability can not be changed", func(t *testing.T) {
+		v, err := NewVerifier(f, WithNullability(NullableLenient))
+		require.NoError(t, err)
+
+		assert.NoError(t, v.SetOptions(WithNullability(NullableLenient)))
+		assert.ErrorIs(t, v.SetOptions(WithNullability(NullableStrict)), ErrInvalidOptions)
+	})
+
+	t.Run("options can be changed while recording", func(t *testing.T) {
+		v, err := NewVerifier(f)
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				record(v)
+			}()
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, v.SetOptions(WithRequestValidation(), WithRequestLogging(t)))
+			}()
+		}
+		wg.Wait()
+	})
+}
+
+func TestWith(t *testing.T) {
+	f, err := os.ReadFile("testdata/request-body-spec.yaml")
+	require.NoError(t, err)
+
+	record := func(v *Verifier) {
+		req := httptest.NewRequest(http.MethodPost, "/req", strings.NewReader(`{"borken": "yes"}`))
+		req.Header.Set("Content-Type", "application/json")
+		v.Record(&http.Response{StatusCode: 204, Request: req})
+	}
+
+	t.Run("view validates with its own options", func(t *testing.T) {
+		v, err := NewVerifier(f)
+		require.NoError(t, err)
+		strict := v.With(WithRequestValidation())
+
+		record(v)
+		assert.NoError(t, v.CurrentError())
+
+		record(strict)
+		assert.ErrorIs(t, v.CurrentError(), ErrRequestInvalid)
+		assert.ErrorIs(t, strict.CurrentError(), ErrRequestInvalid)
+		assert.False(t, v.conf.checkRequest)
+	})
+
+	t.Run("coverage is shared", func(t *testing.T) {
+		v, err := NewVerifier(f)
+		require.NoError(t, err)
+		view := v.With(WithoutFullCoverage())
+
+		assert.ErrorIs(t, view.CurrentError(), ErrNotChecked)
+
+		record(view)
+		assert.NoError(t, v.CurrentError())
+		assert.NoError(t, view.CurrentError())
+	})
+
+	t.Run("reset is shared", func(t *testing.T) {
+		v, err := NewVerifier(f, WithRequestValidation())
+		require.NoError(t, err)
+		view := v.With()
+
+		record(view)
+		require.Error(t, v.CurrentError())
+
+		view.Reset()
+		assert.ErrorIs(t, v.CurrentError(), ErrNotChecked)
+		assert.NotErrorIs(t, v.CurrentError(), ErrRequestInvalid)
+	})
+
+	t.Run("setting options of a view leaves the verifier as it is", func(t *testing.T) {
+		v, err := NewVerifier(f)
+		require.NoError(t, err)
+		view := v.With()
+
+		require.NoError(t, view.SetOptions(WithoutFullCoverage()))
+		assert.ErrorIs(t, v.CurrentError(), ErrNotChecked)
+	})
+
+	t.Run("conflicting options panic", func(t *testing.T) {
+		v, err := NewVerifier(f, WithStrictResponseProperties())
+		require.NoError(t, err)
+
+		assert.Panics(t, func() { v.With(WithoutResponseValidation()) })
+		assert.Panics(t, func() { v.With(WithServer("http://localhost:9000")) })
+	})
+}
+
+func TestRecordRoute(t *testing.T) {
+	f, err := os.ReadFile("testdata/route-spec.yaml")
+	require.NoError(t, err)
+
+	tt := []struct {
+		name  string
+		url   string
+		route string
+		path  string
+	}{
+		{"static route", "/api/things/export", "/api/things/export", "/things/export"},
+		{"route without base path", "/api/things/export", "/things/export", "/things/export"},
+		{"parameter with another name", "/api/things/1", "/api/things/{thingID}", "/things/{id}"},
+		{"parameter with pattern", "/api/things/abc", "/api/things/{id:[a-z]+}", "/things/{id}"},
+		{"unknown route falls back to the url", "/api/things/1", "/stuff/{id}", "/things/{id}"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			v, err := NewVerifier(f)
+			require.NoError(t, err)
+
+			v.RecordRoute(&http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": {"text/csv"}},
+				Body:       io.NopCloser(strings.NewReader("id\n1")),
+				Request:    httptest.NewRequest(http.MethodGet, tc.url, nil),
+			}, tc.route)
+
+			assert.True(t, v.endpoints.IsChecked(tc.path, http.MethodGet, "200"))
+		})
+	}
+
+	t.Run("route without the method falls back to the url", func(t *testing.T) {
+		v, err := NewVerifier(f)
+		require.NoError(t, err)
+
+		v.RecordRoute(&http.Response{
+			StatusCode: http.StatusNoContent,
+			Request:    httptest.NewRequest(http.MethodDelete, "/api/things/1", nil),
+		}, "/things/{id}")
+
+		assert.ErrorIs(t, v.CurrentError(), ErrNotPartOfSpec)
+	})
+}
+
+func TestRecordRecorder(t *testing.T) {
+	f, err := os.ReadFile("testdata/route-spec.yaml")
+	require.NoError(t, err)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if len(body) == 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	t.Run("valid request", func(t *testing.T) {
+		v, err := NewVerifier(f, WithRequestValidation(), WithoutFullCoverage())
+		require.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPut, "/api/things/1", strings.NewReader(`{"name": "thing"}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		v.RecordRecorder(req, rr)
+		assert.NoError(t, v.CurrentError())
+		assert.True(t, v.endpoints.IsChecked("/things/{id}", http.MethodPut, "204"))
+	})
+
+	t.Run("invalid request body read by the handler", func(t *testing.T) {
+		v, err := NewVerifier(f, WithRequestValidation(), WithoutFullCoverage())
+		require.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPut, "/api/things/1", strings.NewReader(`{"title": "thing"}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		v.RecordRecorder(req, rr)
+		assert.ErrorIs(t, v.CurrentError(), ErrRequestInvalid)
+	})
+
+	t.Run("invalid response", func(t *testing.T) {
+		v, err := NewVerifier(f, WithoutFullCoverage())
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/things/1", nil)
+		rr := httptest.NewRecorder()
+		rr.Header().Set("Content-Type", "application/json")
+		_, _ = rr.WriteString(`{"name": "thing"}`)
+
+		v.RecordRecorder(req, rr)
+		assert.ErrorIs(t, v.CurrentError(), ErrResponseInvalid)
+	})
+}
+
+func TestUnchecked(t *testing.T) {
+	f, err := os.ReadFile("testdata/route-spec.yaml")
+	require.NoError(t, err)
+
+	v, err := NewVerifier(f, WithoutFullCoverage())
+	require.NoError(t, err)
+
+	assert.Equal(t, []Endpoint{
+		{Path: "/things/export", Method: http.MethodGet, ResponseCode: "200"},
+		{Path: "/things/{id}", Method: http.MethodGet, ResponseCode: "200", OperationID: "getThing", Tags: []string{"Things"}},
+		{Path: "/things/{id}", Method: http.MethodPut, ResponseCode: "204", Summary: "Update a thing"},
+	}, v.Unchecked())
+
+	v.RecordRoute(&http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"text/csv"}},
+		Body:       io.NopCloser(strings.NewReader("id\n1")),
+		Request:    httptest.NewRequest(http.MethodGet, "/api/things/export", nil),
+	}, "/things/export")
+
+	assert.Equal(t, []Endpoint{
+		{Path: "/things/{id}", Method: http.MethodGet, ResponseCode: "200", OperationID: "getThing", Tags: []string{"Things"}},
+		{Path: "/things/{id}", Method: http.MethodPut, ResponseCode: "204", Summary: "Update a thing"},
+	}, v.Unchecked())
+	assert.NoError(t, v.CurrentError())
+}
+
+func TestEndpoints(t *testing.T) {
+	f, err := os.ReadFile("testdata/route-spec.yaml")
+	require.NoError(t, err)
+
+	v, err := NewVerifier(f, WithoutFullCoverage())
+	require.NoError(t, err)
+
+	v.RecordRoute(&http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"text/csv"}},
+		Body:       io.NopCloser(strings.NewReader("id\n1")),
+		Request:    httptest.NewRequest(http.MethodGet, "/api/things/export", nil),
+	}, "/things/export")
+	v.RecordRoute(&http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader("{}")),
+		Request:    httptest.NewRequest(http.MethodGet, "/api/things/1", nil),
+	}, "/things/{id}")
+	v.RecordRoute(&http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"text/csv"}},
+		Body:       io.NopCloser(strings.NewReader("id\n2")),
+		Request:    httptest.NewRequest(http.MethodGet, "/api/things/export", nil),
+	}, "/things/export")
+
+	statuses := v.Endpoints()
+	assert.Equal(t, []EndpointStatus{
+		{Endpoint: Endpoint{Path: "/things/export", Method: http.MethodGet, ResponseCode: "200"}, Checked: true, Hits: 2},
+		{
+			Endpoint: Endpoint{
+				Path:         "/things/{id}",
+				Method:       http.MethodGet,
+				ResponseCode: "200",
+				OperationID:  "getThing",
+				Tags:         []string{"Things"},
+			},
+			Checked:  true,
+			Failures: 1,
+			Hits:     1,
+		},
+		{Endpoint: Endpoint{Path: "/things/{id}", Method: http.MethodPut, ResponseCode: "204", Summary: "Update a thing"}},
+	}, withoutTimes(statuses))
+
+	export, thing := statuses[0], statuses[1]
+	assert.False(t, export.FirstSeen.IsZero())
+	assert.False(t, export.FirstSeen.After(thing.FirstSeen))
+	assert.True(t, thing.FirstSeen.Equal(thing.LastSeen))
+	assert.False(t, thing.LastSeen.After(export.LastSeen))
+	assert.True(t, statuses[2].FirstSeen.IsZero())
+
+	t.Run("only coordinates of the spec", func(t *testing.T) {
+		faults, err := os.ReadFile("testdata/server-error-spec.yaml")
+		require.NoError(t, err)
+		v, err := NewVerifier(faults)
+		require.NoError(t, err)
+
+		v.Record(&http.Response{StatusCode: http.StatusInternalServerError, Request: httptest.NewRequest(http.MethodGet, "/fault", nil)})
+		v.Record(&http.Response{StatusCode: http.StatusTeapot, Request: httptest.NewRequest(http.MethodGet, "/fault", nil)})
+
+		assert.Empty(t, v.Endpoints())
+		require.Len(t, v.Waived(), 1)
+		assert.Equal(t, "500", v.Waived()[0].ResponseCode)
+	})
+}
+
+// withoutTimes clears the times of the statuses, so that the rest can be compared.
+func withoutTimes(statuses []EndpointStatus) []EndpointStatus {
+	cleared := slices.Clone(statuses)
+	for i := range cleared {
+		cleared[i].FirstSeen, cleared[i].LastSeen = time.Time{}, time.Time{}
+	}
+	return cleared
+}
+
+func TestEndpoint_Operation(t *testing.T) {
+	tt := []struct {
+		name     string
+		endpoint Endpoint
+		expected string
+	}{
+		{"operation id and tags", Endpoint{OperationID: "getPetById", Summary: "Get a pet", Tags: []string{"Pets", "Public"}}, "getPetById (Pets, Public)"},
+		{"summary without operation id", Endpoint{Summary: "Get a pet"}, "Get a pet"},
+		{"tags alone", Endpoint{Tags: []string{"Pets"}}, ""},
+		{"no metadata", Endpoint{Path: "/pets/{id}", Method: http.MethodGet, ResponseCode: "200"}, ""},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.endpoint.Operation())
+		})
+	}
+}
+
+func TestUncheckedByPath(t *testing.T) {
+	f, err := os.ReadFile("testdata/status-headers-spec.yaml")
+	require.NoError(t, err)
+
+	v, err := NewVerifier(f)
+	require.NoError(t, err)
+	assert.EqualError(t, v.CurrentError(), "not checked: 4 coordinates on 1 path\n  /things: GET 302; POST 201, 401, 405")
+
+	v.Record(&http.Response{
+		StatusCode: http.StatusFound,
+		Header:     http.Header{"Location": {"/elsewhere"}},
+		Body:       http.NoBody,
+		Request:    httptest.NewRequest(http.MethodGet, "/things", nil),
+	})
+
+	assert.Equal(t, map[string][]MethodStatus{
+		"/things": {
+			{Method: http.MethodPost, ResponseCode: "201"},
+			{Method: http.MethodPost, ResponseCode: "401"},
+			{Method: http.MethodPost, ResponseCode: "405"},
+		},
+	}, v.UncheckedByPath())
+
+	errs := v.CurrentErrors()
+	require.Len(t, errs, 1)
+	assert.ErrorIs(t, errs[0], ErrNotChecked)
+	assert.EqualError(t, errs[0], "not checked: 3 coordinates on 1 path\n  /things: POST 201, 401, 405")
+}
+
 func TestBinaryBodies(t *testing.T) {
 	videoSpec, err := os.ReadFile("testdata/video-spec.yaml")
 	require.NoError(t, err)
@@ -190,3 +615,338 @@ func TestWithRequestValidation(t *testing.T) {
 		assert.ErrorIs(t, v.CurrentError(), ErrNotPartOfSpec)
 	})
 }
+
+func TestNewServerVerifier(t *testing.T) {
+	bodySpec, err := os.ReadFile("testdata/request-body-spec.yaml")
+	require.NoError(t, err)
+
+	record := func(v *Verifier, method, url, body string) {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		v.Record(&http.Response{StatusCode: 204, Request: req})
+	}
+
+	t.Run("requests are validated by default", func(t *testing.T) {
+		v, err := NewServerVerifier(bodySpec, WithoutFullCoverage())
+		require.NoError(t, err)
+
+		record(v, http.MethodPost, "/req", `{"borken": "yes"}`)
+		assert.ErrorIs(t, v.CurrentError(), ErrRequestInvalid)
+	})
+
+	t.Run("request validation can be turned off", func(t *testing.T) {
+		v, err := NewServerVerifier(bodySpec, WithoutFullCoverage(), WithoutRequestValidation())
+		require.NoError(t, err)
+
+		record(v, http.MethodPost, "/req", `{"borken": "yes"}`)
+		assert.NoError(t, v.CurrentError())
+	})
+
+	t.Run("undocumented paths are reported even when lenient", func(t *testing.T) {
+		v, err := NewServerVerifier(bodySpec, Lenient(), WithoutFullCoverage())
+		require.NoError(t, err)
+
+		record(v, http.MethodPost, "/some-other-path", "")
+		assert.ErrorIs(t, v.CurrentError(), ErrNotPartOfSpec)
+	})
+
+	t.Run("contradicting options are reported", func(t *testing.T) {
+		_, err := NewServerVerifier(bodySpec, WithMaxDepth(0))
+		assert.ErrorIs(t, err, ErrInvalidOptions)
+	})
+}
+
+func TestWithoutResponseValidation(t *testing.T) {
+	f, err := os.ReadFile("testdata/thing-spec.yaml")
+	require.NoError(t, err)
+
+	record := func(v *Verifier, path string, statusCode int) {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		v.Record(&http.Response{
+			StatusCode: statusCode,
+			Request:    req,
+			Header:     http.Header{"Content-Type": []string{"text/plain"}},
+			Body:       io.NopCloser(strings.NewReader("not json")),
+		})
+	}
+
+	t.Run("invalid responses still give coverage", func(t *testing.T) {
+		v, err := NewVerifier(f, WithoutResponseValidation())
+		require.NoError(t, err)
+
+		record(v, "/ping", 200)
+		record(v, "/other", 200)
+		assert.NoError(t, v.CurrentError())
+	})
+
+	t.Run("undocumented status codes are reported", func(t *testing.T) {
+		v, err := NewVerifier(f, WithoutResponseValidation(), WithoutFullCoverage())
+		require.NoError(t, err)
+
+		record(v, "/ping", 418)
+		assert.ErrorIs(t, v.CurrentError(), ErrResponseInvalid)
+	})
+
+	t.Run("undocumented paths are reported", func(t *testing.T) {
+		v, err := NewVerifier(f, WithoutResponseValidation(), WithoutFullCoverage())
+		require.NoError(t, err)
+
+		record(v, "/missing", 200)
+		assert.ErrorIs(t, v.CurrentError(), ErrNotPartOfSpec)
+	})
+}
+
+func TestWithSampling(t *testing.T) {
+	f, err := os.ReadFile("testdata/thing-spec.yaml")
+	require.NoError(t, err)
+
+	record := func(v *Verifier, path string, statusCode int) {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		v.Record(&http.Response{
+			StatusCode: statusCode,
+			Request:    req,
+			Header:     http.Header{"Content-Type": []string{"text/plain"}},
+			Body:       io.NopCloser(strings.NewReader("not json")),
+		})
+	}
+
+	t.Run("a fraction is validated", func(t *testing.T) {
+		v, err := NewVerifier(f, WithSampling(0.25), WithoutFullCoverage())
+		require.NoError(t, err)
+
+		for range 8 {
+			record(v, "/ping", 200)
+		}
+		assert.Len(t, v.CurrentErrors(), 2)
+	})
+
+	t.Run("all are covered", func(t *testing.T) {
+		v, err := NewVerifier(f, WithSampling(0.5))
+		require.NoError(t, err)
+
+		record(v, "/ping", 200)
+		record(v, "/other", 200)
+		for _, err := range v.CurrentErrors() {
+			assert.NotErrorIs(t, err, ErrNotChecked)
+		}
+	})
+
+	t.Run("undocumented status codes are always reported", func(t *testing.T) {
+		v, err := NewVerifier(f, WithSampling(0.5), WithoutFullCoverage())
+		require.NoError(t, err)
+
+		record(v, "/ping", 418)
+		record(v, "/ping", 418)
+		assert.Len(t, v.CurrentErrors(), 2)
+	})
+}
+
+func TestWithViolationHandler(t *testing.T) {
+	f, err := os.ReadFile("testdata/thing-spec.yaml")
+	require.NoError(t, err)
+
+	var violations []*VerificationError
+	var v *Verifier
+	v, err = NewVerifier(f, WithoutFullCoverage(), WithViolationHandler(func(verr *VerificationError) {
+		violations = append(violations, verr)
+		// The handler can use the Verifier.
+		assert.NotEmpty(t, v.CurrentErrors())
+	}))
+	require.NoError(t, err)
+
+	record := func(path string, statusCode int) {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		v.Record(&http.Response{StatusCode: statusCode, Request: req, Body: io.NopCloser(strings.NewReader(""))})
+	}
+
+	record("/missing", 200)
+	require.Len(t, violations, 1)
+	assert.ErrorIs(t, violations[0], ErrNotPartOfSpec)
+
+	record("/ping", 418)
+	require.Len(t, violations, 2)
+	assert.ErrorIs(t, violations[1], ErrResponseInvalid)
+
+	assert.Len(t, v.CurrentErrors(), 2)
+}
+
+func TestRecursiveSchemas(t *testing.T) {
+	treeSpec, err := os.ReadFile("testdata/tree-spec.yaml")
+	require.NoError(t, err)
+
+	linkedList := func(depth int) string {
+		return strings.Repeat(`{"name":"a","next":`, depth) + `{"name":"b"}` + strings.Repeat("}", depth)
+	}
+
+	tt := []struct {
+		name  string
+		body  string
+		opts  []Option
+		valid bool
+	}{
+		{"valid tree", `{"name":"a","children":[{"name":"b","children":[{"name":"c"}]}]}`, nil, true},
+		{"valid linked list", linkedList(20), nil, true},
+		{"invalid nested node", `{"name":"a","children":[{"name":5}]}`, nil, false},
+		{"missing nested property", `{"name":"a","next":{"next":{"name":"c"}}}`, nil, false},
+		{"too deep for default limit", linkedList(200), nil, false},
+		{"too deep for configured limit", linkedList(20), []Option{WithMaxDepth(10)}, false},
+		{"deep enough for configured limit", linkedList(200), []Option{WithMaxDepth(250)}, true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			v, err := NewVerifier(treeSpec, tc.opts...)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "/tree", nil)
+			v.Record(&http.Response{
+				StatusCode: 200,
+				Request:    req,
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(tc.body)),
+			})
+
+			if tc.valid {
+				assert.NoError(t, v.CurrentError())
+			} else {
+				assert.ErrorIs(t, v.CurrentError(), ErrResponseInvalid)
+			}
+		})
+	}
+}
+
+func TestOpenAPI31(t *testing.T) {
+	f, err := os.ReadFile("testdata/openapi31-spec.yaml")
+	require.NoError(t, err)
+
+	strict := []Option{WithStrictResponseProperties()}
+	tt := []struct {
+		name  string
+		body  string
+		opts  []Option
+		valid bool
+	}{
+		{"type with null", `{"id":"a","kind":"thing","name":null}`, nil, true},
+		{"type with string", `{"id":"a","kind":"thing","name":"b"}`, nil, true},
+		{"wrong type", `{"id":"a","kind":"thing","name":1}`, nil, false},
+		{"const", `{"id":"a","kind":"other"}`, nil, false},
+		{"exclusive minimum as a number", `{"id":"a","kind":"thing","size":0}`, nil, false},
+		{"prefix items", `{"id":"a","kind":"thing","point":[{"x":1},{"y":2},{"z":3}]}`, strict, true},
+		{"undocumented property of prefix item", `{"id":"a","kind":"thing","point":[{"y":1}]}`, strict, false},
+		{"then", `{"id":"a","kind":"thing","shape":{"kind":"circle","radius":1}}`, strict, true},
+		{"else", `{"id":"a","kind":"thing","shape":{"kind":"square","width":1}}`, strict, true},
+		{"undocumented property of condition", `{"id":"a","kind":"thing","shape":{"kind":"square","depth":1}}`, strict, false},
+		{"unevaluated properties", `{"id":"a","kind":"thing","labels":{"main":"a","other":"b"}}`, strict, true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			v, err := NewVerifier(f, tc.opts...)
+			require.NoError(t, err)
+
+			v.Record(&http.Response{
+				StatusCode: 200,
+				Request:    httptest.NewRequest(http.MethodGet, "/things/a", nil),
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(tc.body)),
+			})
+
+			if tc.valid {
+				assert.NoError(t, v.CurrentError())
+			} else {
+				assert.ErrorIs(t, v.CurrentError(), ErrResponseInvalid)
+			}
+		})
+	}
+
+	t.Run("webhooks only", func(t *testing.T) {
+		hooks, err := os.ReadFile("testdata/webhooks-spec.yaml")
+		require.NoError(t, err)
+		v, err := NewVerifier(hooks)
+		require.NoError(t, err)
+		assert.Empty(t, v.Endpoints(), "webhooks are not coordinates, since the provider sends them")
+
+		v.Record(&http.Response{
+			StatusCode: 204,
+			Request:    httptest.NewRequest(http.MethodPost, "/thingCreated", nil),
+			Body:       http.NoBody,
+		})
+		assert.ErrorIs(t, v.CurrentError(), ErrNotPartOfSpec)
+
+		assert.NoError(t, CheckCompatibility(hooks, f))
+		assert.ErrorIs(t, CheckCompatibility(f, hooks), ErrNotPartOfSpec)
+	})
+
+	t.Run("compatibility of const", func(t *testing.T) {
+		changed := bytes.Replace(f, []byte("const: thing"), []byte("const: other"), 1)
+		err := CheckCompatibility(f, changed)
+		assert.ErrorIs(t, err, ErrResponseInvalid)
+		assert.ErrorContains(t, err, "the provider can send other, which the consumer does not accept")
+	})
+}
+
+func TestWithHeadFromGet(t *testing.T) {
+	f, err := os.ReadFile("testdata/head-spec.yaml")
+	require.NoError(t, err)
+
+	t.Run("head without get headers fails", func(t *testing.T) {
+		v, err := NewVerifier(f, WithHeadFromGet(), WithoutFullCoverage())
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodHead, "/file", nil)
+		v.Record(&http.Response{StatusCode: 200, Request: req})
+		assert.ErrorIs(t, v.CurrentError(), ErrResponseInvalid)
+	})
+
+	t.Run("head with get headers is fine", func(t *testing.T) {
+		v, err := NewVerifier(f, WithHeadFromGet(), WithoutFullCoverage())
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodHead, "/file", nil)
+		v.Record(&http.Response{StatusCode: 200, Request: req, Header: http.Header{"Etag": []string{`"v1"`}}})
+		assert.NoError(t, v.CurrentError())
+	})
+
+	t.Run("get headers are not required by default", func(t *testing.T) {
+		v, err := NewVerifier(f, WithoutFullCoverage())
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodHead, "/file", nil)
+		v.Record(&http.Response{StatusCode: 200, Request: req})
+		assert.NoError(t, v.CurrentError())
+	})
+}
+
+func TestWithHeadCoverageFromGet(t *testing.T) {
+	f, err := os.ReadFile("testdata/head-spec.yaml")
+	require.NoError(t, err)
+
+	record := func(v *Verifier, statusCode int) {
+		req := httptest.NewRequest(http.MethodGet, "/file", nil)
+		v.Record(&http.Response{
+			StatusCode: statusCode,
+			Request:    req,
+			Header:     http.Header{"Content-Type": []string{"text/plain"}},
+			Body:       io.NopCloser(strings.NewReader("content")),
+		})
+	}
+
+	t.Run("get covers head", func(t *testing.T) {
+		v, err := NewVerifier(f, WithHeadCoverageFromGet())
+		require.NoError(t, err)
+
+		record(v, 200)
+		record(v, 404)
+		assert.NoError(t, v.CurrentError())
+		assert.False(t, v.endpoints.Has("/file", http.MethodHead, "404"))
+	})
+
+	t.Run("get does not cover head by default", func(t *testing.T) {
+		v, err := NewVerifier(f)
+		require.NoError(t, err)
+
+		record(v, 200)
+		record(v, 404)
+		assert.ErrorIs(t, v.CurrentError(), ErrNotChecked)
+	})
+}